@@ -0,0 +1,84 @@
+package css
+
+// arenaChunk is the number of rules or declarations allocated at once by
+// an Arena.
+const arenaChunk = 1024
+
+// Arena allocates the rules and declarations of stylesheets in chunks, so
+// parsing a stylesheet makes a few large allocations instead of one per
+// rule and per block of declarations. All stylesheets parsed with an
+// arena are freed together once none of them is referenced anymore, or
+// reused with Reset.
+//
+// An Arena must not be used by several parses at the same time.
+type Arena struct {
+	rules [][]StyleRule
+	decls [][]Declaration
+	// ri and di are the index of the chunks being filled, and nrules and
+	// ndecls the number of items used in them.
+	ri, di         int
+	nrules, ndecls int
+}
+
+// NewArena returns an empty arena.
+func NewArena() *Arena {
+	return &Arena{}
+}
+
+// Reset makes the memory of the arena available to the next parses. The
+// stylesheets parsed before with the arena must not be used anymore.
+func (a *Arena) Reset() {
+	// drop the references of the old stylesheets so their strings can
+	// be collected
+	for _, chunk := range a.rules {
+		for i := range chunk {
+			chunk[i] = StyleRule{}
+		}
+	}
+	for _, chunk := range a.decls {
+		for i := range chunk {
+			chunk[i] = Declaration{}
+		}
+	}
+	a.ri, a.di, a.nrules, a.ndecls = 0, 0, 0, 0
+}
+
+// rule returns a new zero rule. A nil arena allocates it separately.
+func (a *Arena) rule() *StyleRule {
+	if a == nil {
+		return &StyleRule{}
+	}
+	if a.ri < len(a.rules) && a.nrules == len(a.rules[a.ri]) {
+		a.ri, a.nrules = a.ri+1, 0
+	}
+	if a.ri == len(a.rules) {
+		a.rules = append(a.rules, make([]StyleRule, arenaChunk))
+	}
+	r := &a.rules[a.ri][a.nrules]
+	a.nrules++
+	return r
+}
+
+// declarations returns a copy of ds allocated in the arena. A nil arena
+// returns ds.
+func (a *Arena) declarations(ds []Declaration) []Declaration {
+	if a == nil {
+		return ds
+	}
+	if a.di < len(a.decls) && a.ndecls+len(ds) > len(a.decls[a.di]) {
+		a.di, a.ndecls = a.di+1, 0
+	}
+	// skip the chunks too small for ds, which only happens after Reset
+	for a.di < len(a.decls) && len(ds) > len(a.decls[a.di]) {
+		a.di, a.ndecls = a.di+1, 0
+	}
+	if a.di == len(a.decls) {
+		a.decls = append(a.decls, make([]Declaration, max(arenaChunk, len(ds))))
+	}
+	chunk := a.decls[a.di]
+	start := a.ndecls
+	a.ndecls += copy(chunk[start:], ds)
+	// the capacity is limited so that appending to the declarations of a
+	// rule doesn't overwrite the next rule
+	return chunk[start:a.ndecls:a.ndecls]
+}
@@ -0,0 +1,61 @@
+package css
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestArena(t *testing.T) {
+	arena := NewArena()
+	src := bundle(arenaChunk + 10)
+	sheet, err := UnmarshalStylesheetWithOptions(src, ParseOptions{Arena: arena})
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, _ := UnmarshalStylesheet(src)
+	if len(sheet.Rules) != len(plain.Rules) {
+		t.Fatalf("expected %d rules, got %d", len(plain.Rules), len(sheet.Rules))
+	}
+	for i, r := range sheet.Rules {
+		if r.Selector != plain.Rules[i].Selector || len(r.Declarations) != 4 ||
+			r.Declarations[3] != plain.Rules[i].Declarations[3] {
+			t.Fatalf("rule %d differs: %v, expected %v", i, r, plain.Rules[i])
+		}
+	}
+	if len(arena.rules) != 2 {
+		t.Fatalf("expected 2 rule chunks, got %d", len(arena.rules))
+	}
+
+	// appending to the declarations of a rule must not change the next one
+	first := sheet.Rules[0]
+	first.Declarations = append(first.Declarations, NewDeclaration("top", "0"))
+	if sheet.Rules[1].Declarations[0].Property != "color" {
+		t.Fatal("appending overwrote the next rule")
+	}
+
+	arena.Reset()
+	again, _ := UnmarshalStylesheetWithOptions([]byte(".x {\n\tcolor: blue;\n}\n"), ParseOptions{Arena: arena})
+	if again.Rules[0] != &arena.rules[0][0] || again.Rules[0].Declarations[0].Value != "blue" {
+		t.Fatal("arena memory was not reused after Reset")
+	}
+}
+
+func BenchmarkUnmarshalStylesheetArena(b *testing.B) {
+	src := bundle(1000)
+	for _, reuse := range []bool{false, true} {
+		b.Run(fmt.Sprintf("Reset=%v", reuse), func(b *testing.B) {
+			arena := NewArena()
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if reuse {
+					arena.Reset()
+				} else {
+					arena = NewArena()
+				}
+				if _, err := UnmarshalStylesheetWithOptions(src, ParseOptions{Arena: arena}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
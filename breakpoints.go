@@ -0,0 +1,173 @@
+package css
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"text/scanner"
+)
+
+// DefaultBreakpointTolerance is the distance in px under which different
+// breakpoints are reported as near-duplicates.
+const DefaultBreakpointTolerance = 4
+
+// Breakpoint is a viewport width tested by a media query.
+type Breakpoint struct {
+	// Query is the media query list of the @media rule.
+	Query string
+	// Feature is the width test, such as "max-width" or "width <".
+	Feature string
+	// Width is the tested width in px.
+	Width float64
+	// Boundary is the smallest width on the upper side of the
+	// breakpoint, so that (max-width: 767px) and (min-width: 768px) both
+	// have the boundary 768.
+	Boundary float64
+	// Pos is the position of the @media rule. Pos.Filename is set for
+	// the breakpoints of a Project.
+	Pos scanner.Position
+}
+
+// BreakpointCluster is a group of breakpoints with boundaries close to
+// each other.
+type BreakpointCluster struct {
+	// Boundary is the most used boundary of the cluster.
+	Boundary    float64
+	Breakpoints []Breakpoint
+}
+
+// Consistent reports whether all breakpoints of the cluster have the
+// same boundary.
+func (c BreakpointCluster) Consistent() bool {
+	for _, bp := range c.Breakpoints {
+		if bp.Boundary != c.Boundary {
+			return false
+		}
+	}
+	return true
+}
+
+// BreakpointReport is the result of AnalyzeBreakpoints.
+type BreakpointReport struct {
+	// Clusters are ordered by boundary.
+	Clusters []BreakpointCluster
+	// Issues report the breakpoints of inconsistent clusters that differ
+	// from the boundary of their cluster.
+	Issues []Issue
+}
+
+// Breakpoints returns the viewport widths tested by the @media rules of
+// the stylesheet, in document order. Widths in em and rem are converted
+// to px assuming a 16px font size.
+func Breakpoints(b []byte) []Breakpoint {
+	breakpoints := []Breakpoint{}
+	for _, s := range RuleSources(b) {
+		prelude := string(s.Prelude)
+		if atRuleName(prelude) != "media" {
+			continue
+		}
+		query := strings.TrimSpace(strings.TrimSpace(prelude)[len("@media"):])
+		pos := offsetPosition(b, s.Start)
+		for _, q := range compileMediaList(query) {
+			if q.condition == nil {
+				continue
+			}
+			for _, f := range q.condition.features() {
+				if bp, ok := newBreakpoint(f); ok {
+					bp.Query, bp.Pos = query, pos
+					breakpoints = append(breakpoints, bp)
+				}
+			}
+		}
+	}
+	return breakpoints
+}
+
+// newBreakpoint returns the breakpoint of a width test.
+func newBreakpoint(f mediaFeature) (Breakpoint, bool) {
+	if f.name != "width" && f.name != "device-width" || f.boolean || !f.value.numeric || f.value.viewport != "" {
+		return Breakpoint{}, false
+	}
+	bp := Breakpoint{Feature: f.name + " " + f.op, Width: f.value.number, Boundary: f.value.number}
+	switch f.op {
+	case ">=":
+		bp.Feature = "min-" + f.name
+	case "<=":
+		bp.Feature = "max-" + f.name
+		bp.Boundary = math.Floor(f.value.number) + 1
+	case ">":
+		bp.Boundary = math.Floor(f.value.number) + 1
+	case "=":
+		bp.Feature = f.name
+	}
+	return bp, true
+}
+
+// features returns the feature tests of the condition.
+func (c mediaCondition) features() []mediaFeature {
+	if c.feature != nil {
+		return []mediaFeature{*c.feature}
+	}
+	features := []mediaFeature{}
+	for _, t := range c.terms {
+		features = append(features, t.features()...)
+	}
+	return features
+}
+
+// AnalyzeBreakpoints clusters the breakpoints whose boundaries are at most
+// tolerance px apart and reports the clusters using more than one
+// boundary, such as (min-width: 767px) next to (min-width: 768px).
+func AnalyzeBreakpoints(breakpoints []Breakpoint, tolerance float64) BreakpointReport {
+	sorted := append([]Breakpoint{}, breakpoints...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Boundary < sorted[j].Boundary })
+
+	report := BreakpointReport{Clusters: []BreakpointCluster{}, Issues: []Issue{}}
+	for i, bp := range sorted {
+		if i == 0 || bp.Boundary-sorted[i-1].Boundary > tolerance {
+			report.Clusters = append(report.Clusters, BreakpointCluster{})
+		}
+		c := &report.Clusters[len(report.Clusters)-1]
+		c.Breakpoints = append(c.Breakpoints, bp)
+	}
+
+	for i := range report.Clusters {
+		c := &report.Clusters[i]
+		uses := map[float64]int{}
+		for _, bp := range c.Breakpoints {
+			uses[bp.Boundary]++
+			if n := uses[bp.Boundary]; n > uses[c.Boundary] || n == uses[c.Boundary] && bp.Boundary < c.Boundary {
+				c.Boundary = bp.Boundary
+			}
+		}
+		if c.Consistent() {
+			continue
+		}
+		for _, bp := range c.Breakpoints {
+			if bp.Boundary != c.Boundary {
+				report.Issues = append(report.Issues, Issue{
+					Lint: "breakpoints",
+					Message: fmt.Sprintf("(%s: %gpx) is %gpx off the %gpx breakpoint used %d times",
+						bp.Feature, bp.Width, math.Abs(bp.Boundary-c.Boundary), c.Boundary, uses[c.Boundary]),
+					Selector: Rule("@media " + bp.Query),
+					Pos:      bp.Pos,
+				})
+			}
+		}
+	}
+	return report
+}
+
+// Breakpoints analyses the breakpoints of all files of the project with
+// the DefaultBreakpointTolerance.
+func (p *Project) Breakpoints() BreakpointReport {
+	breakpoints := []Breakpoint{}
+	for _, f := range p.files {
+		for _, bp := range Breakpoints(f.Data) {
+			bp.Pos.Filename = f.Name
+			breakpoints = append(breakpoints, bp)
+		}
+	}
+	return AnalyzeBreakpoints(breakpoints, DefaultBreakpointTolerance)
+}
@@ -0,0 +1,66 @@
+package css
+
+import "testing"
+
+func TestBreakpoints(t *testing.T) {
+	bps := Breakpoints([]byte(`@media (max-width: 767px) {
+	nav {
+		display: none;
+	}
+}
+@media screen and (min-width: 48em) and (max-width: 1023.98px) {
+	nav {
+		display: flex;
+	}
+}
+@media (400px < width <= 600px), print {
+	main {
+		padding: 0;
+	}
+}
+@media (min-width: 50vw), (hover) {
+	a {
+		color: red;
+	}
+}
+`))
+	expected := []struct {
+		feature         string
+		width, boundary float64
+		line            int
+	}{
+		{"max-width", 767, 768, 1},
+		{"min-width", 768, 768, 6},
+		{"max-width", 1023.98, 1024, 6},
+		{"width >", 400, 401, 11},
+		{"max-width", 600, 601, 11},
+	}
+	if len(bps) != len(expected) {
+		t.Fatalf("expected %d breakpoints, got %v", len(expected), bps)
+	}
+	for i, e := range expected {
+		bp := bps[i]
+		if bp.Feature != e.feature || bp.Width != e.width || bp.Boundary != e.boundary || bp.Pos.Line != e.line {
+			t.Errorf("%d: expected %v, got %+v", i, e, bp)
+		}
+	}
+}
+
+func TestProjectBreakpoints(t *testing.T) {
+	p := NewProject()
+	p.Add("a.css", []byte("@media (min-width: 768px) {\n\ta {\n\t\ttop: 0;\n\t}\n}\n@media (max-width: 767px) {\n\ta {\n\t\ttop: 1px;\n\t}\n}\n"))
+	p.Add("b.css", []byte("@media (min-width: 767px) {\n\tb {\n\t\ttop: 0;\n\t}\n}\n@media (min-width: 1200px) {\n\tb {\n\t\ttop: 1px;\n\t}\n}\n"))
+
+	report := p.Breakpoints()
+	if len(report.Clusters) != 2 || report.Clusters[0].Boundary != 768 || report.Clusters[0].Consistent() || !report.Clusters[1].Consistent() {
+		t.Fatalf("unexpected clusters %+v", report.Clusters)
+	}
+	if len(report.Issues) != 1 {
+		t.Fatalf("expected 1 issue, got %v", report.Issues)
+	}
+	issue := report.Issues[0]
+	expected := "(min-width: 767px) is 1px off the 768px breakpoint used 2 times"
+	if issue.Message != expected || issue.Pos.Filename != "b.css" || issue.Selector != "@media (min-width: 767px)" {
+		t.Errorf("unexpected issue %+v", issue)
+	}
+}
@@ -0,0 +1,59 @@
+package css
+
+import (
+	"bytes"
+	"fmt"
+	"text/scanner"
+)
+
+// SourceLocation is the file and position a rule of a bundle was copied
+// from.
+type SourceLocation struct {
+	File string
+	Pos  scanner.Position
+}
+
+func (o SourceLocation) String() string {
+	return fmt.Sprintf("%s:%d:%d", o.File, o.Pos.Line, o.Pos.Column)
+}
+
+// Bundle concatenates the files the same way as Concat and parses the
+// result. Every rule of the returned stylesheet has its Location set, so
+// diagnostics on the bundle can point back to the source files.
+func Bundle(files ...SourceFile) ([]byte, *Stylesheet, error) {
+	sheets := make([][]byte, len(files))
+	for i, f := range files {
+		sheets[i] = f.Data
+	}
+
+	var (
+		buf    bytes.Buffer
+		items  = concatItems(sheets)
+		starts = make([]int, len(items))
+	)
+	for i, item := range items {
+		starts[i] = buf.Len()
+		buf.WriteString(item.text)
+		buf.WriteByte('\n')
+	}
+
+	out := buf.Bytes()
+	sheet, err := UnmarshalStylesheet(out)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	i := 0
+	for _, r := range sheet.Rules {
+		for i+1 < len(items) && starts[i+1] <= r.Source.Start {
+			i++
+		}
+		if i >= len(items) || items[i].sheet < 0 {
+			continue
+		}
+		f := files[items[i].sheet]
+		offset := items[i].offset + r.Source.Start - starts[i]
+		r.Location = &SourceLocation{File: f.Name, Pos: offsetPosition(f.Data, offset)}
+	}
+	return out, sheet, nil
+}
@@ -0,0 +1,36 @@
+package css
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestBundle(t *testing.T) {
+	files := []SourceFile{
+		{"base.css", []byte("@charset \"utf-8\";\n\nbody {\n\tmargin: 0;\n}\n")},
+		{"buttons.css", []byte("@import \"icons.css\";\n@layer components;\n\n/* buttons */\n.btn {\n\tcolor: red;\n}\n@media print {\n\t.btn {\n\t\tcolor: black;\n\t}\n}\n")},
+	}
+	out, sheet, err := Bundle(files...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out, Concat(files[0].Data, files[1].Data)) {
+		t.Errorf("bundle differs from Concat:\n%s", out)
+	}
+
+	expected := []string{"base.css:3:1", "buttons.css:5:1", "buttons.css:9:2"}
+	if len(sheet.Rules) != len(expected) {
+		t.Fatalf("expected %d rules, got %d", len(expected), len(sheet.Rules))
+	}
+	for i, r := range sheet.Rules {
+		if r.Location == nil {
+			t.Errorf("rule %s has no location", r.Selector)
+		} else if r.Location.String() != expected[i] {
+			t.Errorf("rule %s: expected location %s, got %s", r.Selector, expected[i], r.Location)
+		}
+	}
+
+	if sheet, _ := UnmarshalStylesheet(out); sheet.Rules[0].Location != nil {
+		t.Error("expected no location outside of bundles")
+	}
+}
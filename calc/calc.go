@@ -0,0 +1,484 @@
+// Package calc parses and evaluates css math functions such as
+// calc(100% - 2 * 10px), min(), max() and clamp().
+package calc
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Node is a node of an expression tree.
+type Node interface {
+	// String returns the expression as css.
+	String() string
+	eval(ctx *Context) (Value, error)
+}
+
+// Number is a number, a percentage or a dimension such as 10px.
+type Number struct {
+	Value float64
+	// Unit is the lower case unit, "%" for percentages and empty for
+	// numbers.
+	Unit string
+}
+
+// Binary is an operation on two expressions.
+type Binary struct {
+	// Op is one of '+', '-', '*' and '/'.
+	Op          byte
+	Left, Right Node
+}
+
+// Func is a math function: calc(), min(), max() or clamp().
+type Func struct {
+	Name string
+	Args []Node
+}
+
+// Constant is one of the constants e, pi, infinity, -infinity and NaN.
+type Constant struct {
+	Name string
+}
+
+// Value is the result of an expression.
+type Value struct {
+	Number float64
+	// Unit is "px" for lengths, "deg" for angles, "s" for times and empty
+	// for numbers.
+	Unit string
+}
+
+func (v Value) String() string {
+	return strconv.FormatFloat(v.Number, 'g', -1, 64) + v.Unit
+}
+
+// Context gives the values relative units are resolved against.
+type Context struct {
+	// PercentBase is the length in px percentages are relative to, such as
+	// the width of the containing block.
+	PercentBase float64
+	// FontSize is the font size of the element in px, and RootFontSize
+	// the one of the root element. Both are 16 if zero.
+	FontSize, RootFontSize float64
+	// ViewportWidth and ViewportHeight are the size of the viewport in px.
+	ViewportWidth, ViewportHeight float64
+	// Units converts other units, by lower case name, to px. It is checked
+	// before the absolute units, so it can also redefine them.
+	Units map[string]float64
+}
+
+// ErrIncompatibleUnits is returned by Eval for expressions mixing units
+// that can't be combined, such as 1px + 2s or 2px * 3px.
+var ErrIncompatibleUnits = errors.New("calc: incompatible units")
+
+// units are the canonical unit and the scale of the units that don't
+// depend on the context.
+var units = map[string]struct {
+	unit  string
+	scale float64
+}{
+	"px":   {"px", 1},
+	"in":   {"px", 96},
+	"cm":   {"px", 96 / 2.54},
+	"mm":   {"px", 96 / 25.4},
+	"q":    {"px", 96 / 101.6},
+	"pt":   {"px", 96.0 / 72},
+	"pc":   {"px", 16},
+	"deg":  {"deg", 1},
+	"grad": {"deg", 0.9},
+	"rad":  {"deg", 180 / math.Pi},
+	"turn": {"deg", 360},
+	"s":    {"s", 1},
+	"ms":   {"s", 0.001},
+}
+
+// Parse parses a math function such as calc(100% - 2 * 10px), or an
+// expression without the function.
+func Parse(s string) (Node, error) {
+	p := &parser{s: s}
+	node, err := p.sum()
+	if err != nil {
+		return nil, err
+	}
+	if p.skipSpace(); p.i < len(p.s) {
+		return nil, p.errorf("unexpected %q", p.s[p.i:])
+	}
+	return node, nil
+}
+
+// Eval computes the value of the expression. Relative units are resolved
+// against the context, and the result is in the canonical unit of its
+// type.
+func Eval(expr Node, ctx Context) (Value, error) {
+	return expr.eval(&ctx)
+}
+
+type parser struct {
+	s string
+	i int
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("calc: "+format+" at %d", append(args, p.i)...)
+}
+
+func (p *parser) skipSpace() bool {
+	start := p.i
+	for p.i < len(p.s) && strings.IndexByte(" \t\n\r\f", p.s[p.i]) >= 0 {
+		p.i++
+	}
+	return p.i > start
+}
+
+// sum parses additions and subtractions. The operators must be
+// surrounded by whitespace, so 1px -2px is not a subtraction.
+func (p *parser) sum() (Node, error) {
+	left, err := p.product()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		start := p.i
+		if !p.skipSpace() || p.i+1 >= len(p.s) || (p.s[p.i] != '+' && p.s[p.i] != '-') || !isSpace(p.s[p.i+1]) {
+			p.i = start
+			return left, nil
+		}
+		op := p.s[p.i]
+		p.i++
+		right, err := p.product()
+		if err != nil {
+			return nil, err
+		}
+		left = &Binary{Op: op, Left: left, Right: right}
+	}
+}
+
+func (p *parser) product() (Node, error) {
+	left, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		start := p.i
+		p.skipSpace()
+		if p.i >= len(p.s) || (p.s[p.i] != '*' && p.s[p.i] != '/') {
+			p.i = start
+			return left, nil
+		}
+		op := p.s[p.i]
+		p.i++
+		right, err := p.operand()
+		if err != nil {
+			return nil, err
+		}
+		left = &Binary{Op: op, Left: left, Right: right}
+	}
+}
+
+func (p *parser) operand() (Node, error) {
+	p.skipSpace()
+	if p.i >= len(p.s) {
+		return nil, p.errorf("missing value")
+	}
+	if p.s[p.i] == '(' {
+		p.i++
+		node, err := p.sum()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.close(); err != nil {
+			return nil, err
+		}
+		return node, nil
+	}
+	if startsNumber(p.s, p.i) {
+		return p.number()
+	}
+
+	start := p.i
+	if p.s[p.i] == '-' {
+		p.i++
+	}
+	for p.i < len(p.s) && isNameByte(p.s[p.i]) {
+		p.i++
+	}
+	name := strings.ToLower(p.s[start:p.i])
+	if name == "" || name == "-" {
+		return nil, p.errorf("unexpected %q", p.s[start:])
+	}
+	if p.i < len(p.s) && p.s[p.i] == '(' {
+		p.i++
+		return p.function(name)
+	}
+	switch name {
+	case "e", "pi", "infinity", "-infinity", "nan":
+		return &Constant{Name: name}, nil
+	}
+	p.i = start
+	return nil, p.errorf("unknown value %q", name)
+}
+
+func (p *parser) number() (Node, error) {
+	start := p.i
+	p.i = scanNumber(p.s, p.i)
+	n, err := strconv.ParseFloat(p.s[start:p.i], 64)
+	if err != nil {
+		return nil, p.errorf("invalid number %q", p.s[start:p.i])
+	}
+	unitStart := p.i
+	if p.i < len(p.s) && p.s[p.i] == '%' {
+		p.i++
+	} else {
+		for p.i < len(p.s) && isNameByte(p.s[p.i]) {
+			p.i++
+		}
+	}
+	return &Number{Value: n, Unit: strings.ToLower(p.s[unitStart:p.i])}, nil
+}
+
+// function parses the arguments of the function after its parenthesis.
+func (p *parser) function(name string) (Node, error) {
+	f := &Func{Name: name}
+	for {
+		arg, err := p.sum()
+		if err != nil {
+			return nil, err
+		}
+		f.Args = append(f.Args, arg)
+		p.skipSpace()
+		if p.i < len(p.s) && p.s[p.i] == ',' {
+			p.i++
+			continue
+		}
+		if err := p.close(); err != nil {
+			return nil, err
+		}
+		break
+	}
+
+	want := map[string]int{"calc": 1, "clamp": 3}
+	switch name {
+	case "calc", "clamp":
+		if len(f.Args) != want[name] {
+			return nil, p.errorf("%s() takes %d arguments, got %d", name, want[name], len(f.Args))
+		}
+	case "min", "max":
+	default:
+		return nil, p.errorf("unknown function %s()", name)
+	}
+	return f, nil
+}
+
+func (p *parser) close() error {
+	p.skipSpace()
+	if p.i >= len(p.s) || p.s[p.i] != ')' {
+		return p.errorf("missing )")
+	}
+	p.i++
+	return nil
+}
+
+func (n *Number) String() string {
+	return strconv.FormatFloat(n.Value, 'g', -1, 64) + n.Unit
+}
+
+func (n *Number) eval(ctx *Context) (Value, error) {
+	if n.Unit == "" {
+		return Value{Number: n.Value}, nil
+	}
+	if scale, ok := ctx.Units[n.Unit]; ok {
+		return Value{Number: n.Value * scale, Unit: "px"}, nil
+	}
+	if u, ok := units[n.Unit]; ok {
+		return Value{Number: n.Value * u.scale, Unit: u.unit}, nil
+	}
+
+	var base float64
+	switch n.Unit {
+	case "%":
+		base = ctx.PercentBase / 100
+	case "em":
+		base = orDefault(ctx.FontSize, 16)
+	case "rem":
+		base = orDefault(ctx.RootFontSize, 16)
+	case "ex", "ch":
+		base = orDefault(ctx.FontSize, 16) / 2
+	case "vw":
+		base = ctx.ViewportWidth / 100
+	case "vh":
+		base = ctx.ViewportHeight / 100
+	case "vmin":
+		base = math.Min(ctx.ViewportWidth, ctx.ViewportHeight) / 100
+	case "vmax":
+		base = math.Max(ctx.ViewportWidth, ctx.ViewportHeight) / 100
+	default:
+		return Value{}, fmt.Errorf("calc: unknown unit %q", n.Unit)
+	}
+	return Value{Number: n.Value * base, Unit: "px"}, nil
+}
+
+func (b *Binary) String() string {
+	return wrap(b.Left, b.Op, false) + " " + string(b.Op) + " " + wrap(b.Right, b.Op, true)
+}
+
+// wrap returns the operand of op as css, in parentheses when needed to
+// keep the order of the operations.
+func wrap(n Node, op byte, right bool) string {
+	inner, ok := n.(*Binary)
+	if !ok {
+		return n.String()
+	}
+	if precedence(inner.Op) < precedence(op) || (right && precedence(inner.Op) == precedence(op)) {
+		return "(" + n.String() + ")"
+	}
+	return n.String()
+}
+
+func precedence(op byte) int {
+	if op == '*' || op == '/' {
+		return 1
+	}
+	return 0
+}
+
+func (b *Binary) eval(ctx *Context) (Value, error) {
+	l, err := b.Left.eval(ctx)
+	if err != nil {
+		return Value{}, err
+	}
+	r, err := b.Right.eval(ctx)
+	if err != nil {
+		return Value{}, err
+	}
+	switch b.Op {
+	case '+', '-':
+		if l.Unit != r.Unit {
+			return Value{}, ErrIncompatibleUnits
+		}
+		if b.Op == '-' {
+			r.Number = -r.Number
+		}
+		return Value{Number: l.Number + r.Number, Unit: l.Unit}, nil
+	case '*':
+		if l.Unit != "" && r.Unit != "" {
+			return Value{}, ErrIncompatibleUnits
+		}
+		return Value{Number: l.Number * r.Number, Unit: l.Unit + r.Unit}, nil
+	default:
+		if r.Unit != "" {
+			return Value{}, ErrIncompatibleUnits
+		}
+		return Value{Number: l.Number / r.Number, Unit: l.Unit}, nil
+	}
+}
+
+func (f *Func) String() string {
+	args := make([]string, len(f.Args))
+	for i, arg := range f.Args {
+		args[i] = arg.String()
+	}
+	return f.Name + "(" + strings.Join(args, ", ") + ")"
+}
+
+func (f *Func) eval(ctx *Context) (Value, error) {
+	values := make([]Value, len(f.Args))
+	for i, arg := range f.Args {
+		v, err := arg.eval(ctx)
+		if err != nil {
+			return Value{}, err
+		}
+		if i > 0 && v.Unit != values[0].Unit {
+			return Value{}, ErrIncompatibleUnits
+		}
+		values[i] = v
+	}
+
+	switch f.Name {
+	case "calc":
+		return values[0], nil
+	case "clamp":
+		// the minimum wins over the maximum
+		v := math.Max(values[0].Number, math.Min(values[1].Number, values[2].Number))
+		return Value{Number: v, Unit: values[0].Unit}, nil
+	}
+	v := values[0]
+	for _, arg := range values[1:] {
+		if (f.Name == "min") == (arg.Number < v.Number) {
+			v = arg
+		}
+	}
+	return v, nil
+}
+
+func (c *Constant) String() string {
+	return c.Name
+}
+
+func (c *Constant) eval(ctx *Context) (Value, error) {
+	switch c.Name {
+	case "e":
+		return Value{Number: math.E}, nil
+	case "pi":
+		return Value{Number: math.Pi}, nil
+	case "infinity":
+		return Value{Number: math.Inf(1)}, nil
+	case "-infinity":
+		return Value{Number: math.Inf(-1)}, nil
+	}
+	return Value{Number: math.NaN()}, nil
+}
+
+func orDefault(v, def float64) float64 {
+	if v == 0 {
+		return def
+	}
+	return v
+}
+
+func isSpace(c byte) bool {
+	return strings.IndexByte(" \t\n\r\f", c) >= 0
+}
+
+func isNameByte(c byte) bool {
+	return c == '-' || c == '_' || c >= 0x80 ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// startsNumber reports whether a number starts at index i.
+func startsNumber(s string, i int) bool {
+	isDigit := func(j int) bool { return j < len(s) && s[j] >= '0' && s[j] <= '9' }
+	if s[i] == '+' || s[i] == '-' {
+		i++
+	}
+	return isDigit(i) || (i < len(s) && s[i] == '.' && isDigit(i+1))
+}
+
+// scanNumber returns the index after the number starting at index i.
+func scanNumber(s string, i int) int {
+	digits := func(j int) int {
+		for j < len(s) && s[j] >= '0' && s[j] <= '9' {
+			j++
+		}
+		return j
+	}
+	if s[i] == '+' || s[i] == '-' {
+		i++
+	}
+	i = digits(i)
+	if i+1 < len(s) && s[i] == '.' && s[i+1] >= '0' && s[i+1] <= '9' {
+		i = digits(i + 1)
+	}
+	if i+1 < len(s) && (s[i] == 'e' || s[i] == 'E') {
+		j := i + 1
+		if s[j] == '+' || s[j] == '-' {
+			j++
+		}
+		if j < len(s) && s[j] >= '0' && s[j] <= '9' {
+			i = digits(j)
+		}
+	}
+	return i
+}
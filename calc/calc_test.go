@@ -0,0 +1,89 @@
+package calc
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"calc(100% - 2 * 10px)", "calc(100% - 2 * 10px)"},
+		{"calc( (1px + 2px) * 3 )", "calc((1px + 2px) * 3)"},
+		{"calc(1px - (2px - 3px))", "calc(1px - (2px - 3px))"},
+		{"CLAMP(1rem, 2.5vw, 3rem)", "clamp(1rem, 2.5vw, 3rem)"},
+		{"min(10px,5%)", "min(10px, 5%)"},
+		{"calc(-5px * pi)", "calc(-5px * pi)"},
+		{"10px / 2", "10px / 2"},
+	}
+	for _, test := range tests {
+		node, err := Parse(test.in)
+		if err != nil {
+			t.Errorf("%s: %v", test.in, err)
+			continue
+		}
+		if got := node.String(); got != test.want {
+			t.Errorf("%s: got %q, want %q", test.in, got, test.want)
+		}
+	}
+
+	for _, in := range []string{"calc(1px -2px)", "calc(1px +)", "calc(1px", "foo(1px)", "calc(1px, 2px)", "var(--x)", ""} {
+		if _, err := Parse(in); err == nil {
+			t.Errorf("%s: expected an error", in)
+		}
+	}
+}
+
+func TestEval(t *testing.T) {
+	ctx := Context{PercentBase: 300, FontSize: 20, ViewportWidth: 1000, ViewportHeight: 500}
+	tests := []struct {
+		in   string
+		want Value
+	}{
+		{"calc(100% - 2 * 10px)", Value{280, "px"}},
+		{"calc(2em + 1rem)", Value{56, "px"}},
+		{"calc(10vw / 4)", Value{25, "px"}},
+		{"calc(1in - 6pt)", Value{88, "px"}},
+		{"clamp(100px, 50vmin, 200px)", Value{200, "px"}},
+		{"clamp(100px, 10vh, 200px)", Value{100, "px"}},
+		{"max(10px, 5%, 2em)", Value{40, "px"}},
+		{"min(10px, 5%, 2em)", Value{10, "px"}},
+		{"calc(0.5turn + 10deg)", Value{190, "deg"}},
+		{"calc(200ms * 3)", Value{0.6, "s"}},
+		{"calc(3 / 2)", Value{1.5, ""}},
+	}
+	for _, test := range tests {
+		node, err := Parse(test.in)
+		if err != nil {
+			t.Errorf("%s: %v", test.in, err)
+			continue
+		}
+		got, err := Eval(node, ctx)
+		if err != nil {
+			t.Errorf("%s: %v", test.in, err)
+			continue
+		}
+		if got.Unit != test.want.Unit || math.Abs(got.Number-test.want.Number) > 1e-9 {
+			t.Errorf("%s: got %s, want %s", test.in, got, test.want)
+		}
+	}
+
+	for _, in := range []string{"calc(1px + 1)", "calc(1px * 2px)", "calc(1 / 1px)", "min(1px, 1s)"} {
+		node, err := Parse(in)
+		if err != nil {
+			t.Fatalf("%s: %v", in, err)
+		}
+		if _, err := Eval(node, ctx); err != ErrIncompatibleUnits {
+			t.Errorf("%s: got error %v", in, err)
+		}
+	}
+
+	node, _ := Parse("calc(2foo + 1px)")
+	if got, err := Eval(node, Context{Units: map[string]float64{"foo": 4}}); err != nil || got.Number != 9 {
+		t.Errorf("custom unit: got %v, %v", got, err)
+	}
+	if _, err := Eval(node, ctx); err == nil {
+		t.Error("expected an error for an unknown unit")
+	}
+}
@@ -0,0 +1,90 @@
+package css
+
+import "sort"
+
+// Origin is where a declaration comes from in the cascade.
+type Origin int
+
+const (
+	// OriginUserAgent is the default stylesheet of the browser.
+	OriginUserAgent Origin = iota
+	// OriginUser is a stylesheet set by the user.
+	OriginUser
+	// OriginAuthor is a stylesheet of the document.
+	OriginAuthor
+)
+
+// CascadeEntry is a declaration competing in the cascade, annotated with
+// everything needed to decide whether it wins.
+type CascadeEntry struct {
+	Declaration Declaration
+	Origin      Origin
+	// Layer is the 1-based position of the cascade layer in layer order.
+	// Zero means the declaration is not in a layer.
+	Layer int
+	// Inline is set for declarations of a style attribute.
+	Inline      bool
+	Specificity [3]int
+	// Order is the position of the declaration in the source order.
+	Order int
+	// Rule is the rule of the declaration, nil for style and presentation
+	// attributes or when unknown.
+	Rule *StyleRule
+}
+
+// SortCascade returns the entries sorted in winning order, the winning
+// declaration first. Entries are compared by origin and importance, style
+// attributes, cascade layers, specificity and finally source order. The
+// entries are expected to declare the same property.
+func SortCascade(entries []CascadeEntry) []CascadeEntry {
+	sorted := make([]CascadeEntry, len(entries))
+	copy(sorted, entries)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return cascadeBeats(sorted[i], sorted[j])
+	})
+	return sorted
+}
+
+// cascadeBeats reports whether a wins over b in the cascade.
+func cascadeBeats(a, b CascadeEntry) bool {
+	if pa, pb := originPrecedence(a), originPrecedence(b); pa != pb {
+		return pa > pb
+	}
+	if a.Inline != b.Inline {
+		return a.Inline
+	}
+	if la, lb := layerPrecedence(a), layerPrecedence(b); la != lb {
+		return la > lb
+	}
+	if a.Specificity != b.Specificity {
+		for i := range a.Specificity {
+			if a.Specificity[i] != b.Specificity[i] {
+				return a.Specificity[i] > b.Specificity[i]
+			}
+		}
+	}
+	return a.Order > b.Order
+}
+
+// originPrecedence ranks origin and importance: important declarations
+// reverse the order of the origins and beat every normal declaration.
+func originPrecedence(e CascadeEntry) int {
+	if e.Declaration.Important {
+		return int(OriginAuthor) + 1 + int(OriginAuthor-e.Origin)
+	}
+	return int(e.Origin)
+}
+
+// layerPrecedence ranks cascade layers. Later layers win for normal
+// declarations and unlayered declarations win over layered ones. The
+// order is reversed for important declarations.
+func layerPrecedence(e CascadeEntry) int {
+	layer := e.Layer
+	if layer == 0 {
+		layer = int(^uint(0) >> 2)
+	}
+	if e.Declaration.Important {
+		return -layer
+	}
+	return layer
+}
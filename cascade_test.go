@@ -0,0 +1,31 @@
+package css
+
+import "testing"
+
+func TestSortCascade(t *testing.T) {
+	normal := NewDeclaration("color", "red")
+	important := NewDeclaration("color", "red !important")
+
+	tests := []struct {
+		name   string
+		winner CascadeEntry
+		loser  CascadeEntry
+	}{
+		{"Origin", CascadeEntry{Declaration: normal, Origin: OriginAuthor}, CascadeEntry{Declaration: normal, Origin: OriginUser}},
+		{"Importance", CascadeEntry{Declaration: important, Origin: OriginUserAgent}, CascadeEntry{Declaration: normal, Origin: OriginAuthor}},
+		{"ImportantOrigin", CascadeEntry{Declaration: important, Origin: OriginUser}, CascadeEntry{Declaration: important, Origin: OriginAuthor}},
+		{"Inline", CascadeEntry{Declaration: normal, Origin: OriginAuthor, Inline: true}, CascadeEntry{Declaration: normal, Origin: OriginAuthor, Specificity: [3]int{1, 0, 0}}},
+		{"Unlayered", CascadeEntry{Declaration: normal, Origin: OriginAuthor}, CascadeEntry{Declaration: normal, Origin: OriginAuthor, Layer: 2}},
+		{"LaterLayer", CascadeEntry{Declaration: normal, Layer: 2}, CascadeEntry{Declaration: normal, Layer: 1, Specificity: [3]int{1, 0, 0}}},
+		{"ImportantEarlierLayer", CascadeEntry{Declaration: important, Layer: 1}, CascadeEntry{Declaration: important, Layer: 2}},
+		{"ImportantLayered", CascadeEntry{Declaration: important, Layer: 1}, CascadeEntry{Declaration: important}},
+		{"Specificity", CascadeEntry{Declaration: normal, Specificity: [3]int{0, 1, 0}}, CascadeEntry{Declaration: normal, Specificity: [3]int{0, 0, 5}, Order: 1}},
+		{"Order", CascadeEntry{Declaration: normal, Order: 2}, CascadeEntry{Declaration: normal, Order: 1}},
+	}
+	for _, test := range tests {
+		sorted := SortCascade([]CascadeEntry{test.loser, test.winner})
+		if sorted[0] != test.winner {
+			t.Fatalf("%s: wrong winner %+v", test.name, sorted[0])
+		}
+	}
+}
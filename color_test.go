@@ -0,0 +1,56 @@
+package css
+
+import (
+	imagecolor "image/color"
+	"testing"
+)
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		in   string
+		want imagecolor.RGBA
+	}{
+		{"#fff", imagecolor.RGBA{0xff, 0xff, 0xff, 0xff}},
+		{"#0f08", imagecolor.RGBA{0x00, 0xff, 0x00, 0x88}},
+		{"#1A2b3c", imagecolor.RGBA{0x1a, 0x2b, 0x3c, 0xff}},
+		{"#11223344", imagecolor.RGBA{0x11, 0x22, 0x33, 0x44}},
+		{"rgb(255, 0, 0)", imagecolor.RGBA{255, 0, 0, 255}},
+		{"rgba(0, 0, 255, 0.5)", imagecolor.RGBA{0, 0, 255, 128}},
+		{"rgb(100% 50% 0 / 25%)", imagecolor.RGBA{255, 128, 0, 64}},
+		{"rgb(300, -1, 0)", imagecolor.RGBA{255, 0, 0, 255}},
+		{"hsl(120, 100%, 50%)", imagecolor.RGBA{0, 255, 0, 255}},
+		{"hsla(0.5turn 100% 25% / .5)", imagecolor.RGBA{0, 128, 128, 128}},
+		{"hsl(-120deg, 100%, 50%)", imagecolor.RGBA{0, 0, 255, 255}},
+		{"RebeccaPurple", imagecolor.RGBA{0x66, 0x33, 0x99, 0xff}},
+		{"lavenderblush", imagecolor.RGBA{0xff, 0xf0, 0xf5, 0xff}},
+		{" transparent ", imagecolor.RGBA{}},
+	}
+	for _, test := range tests {
+		got, err := ParseColor(test.in)
+		if err != nil {
+			t.Errorf("%s: %v", test.in, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("%s: got %v, want %v", test.in, got, test.want)
+		}
+	}
+
+	for _, in := range []string{"", "bla", "#ff", "#fffff", "#ggg", "rgb(1, 2)", "rgb(1 2, 3)", "rgb(1, 2, 3 / 1)", "rgb(1px, 2, 3)", "hsl(1x, 0%, 0%)", "currentcolor"} {
+		if _, err := ParseColor(in); err == nil {
+			t.Errorf("%s: expected an error", in)
+		}
+	}
+}
+
+func TestColorStyle(t *testing.T) {
+	for _, property := range []string{"color", "background-color"} {
+		style, err := CSSStyle(property, map[string]string{property: "#f00"})
+		if err != nil {
+			t.Fatalf("%s: %v", property, err)
+		}
+		if c, ok := style.Value.(imagecolor.RGBA); !ok || c != (imagecolor.RGBA{255, 0, 0, 255}) {
+			t.Errorf("%s: got %#v", property, style.Value)
+		}
+	}
+}
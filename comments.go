@@ -0,0 +1,111 @@
+package css
+
+import (
+	"bytes"
+	"strings"
+	"text/scanner"
+)
+
+// Comment is a css comment with its position in the source and the rule
+// or declaration that directly follows it.
+type Comment struct {
+	Text string
+	Pos  scanner.Position
+	// Rule is the selector of the rule the comment precedes, or of the
+	// rule containing the declaration it precedes.
+	Rule Rule
+	// Property is the property of the declaration the comment precedes.
+	// It is empty when the comment precedes a rule.
+	Property string
+}
+
+// commentTarget is the start of a rule or declaration in the source.
+type commentTarget struct {
+	offset   int
+	rule     Rule
+	property string
+}
+
+// CommentsWithPositions returns all css comments with their positions and
+// the rule or declaration each of them precedes.
+func CommentsWithPositions(b []byte) []Comment {
+	var (
+		comments = []Comment{}
+		targets  = commentTargets(b)
+	)
+	for _, loc := range commentIndexes(b) {
+		c := Comment{
+			Text: string(b[loc[0]:loc[1]]),
+			Pos:  offsetPosition(b, loc[0]),
+		}
+		for _, t := range targets {
+			if t.offset >= loc[1] {
+				c.Rule = t.rule
+				c.Property = t.property
+				break
+			}
+		}
+		comments = append(comments, c)
+	}
+	return comments
+}
+
+// commentTargets returns the start of every rule and declaration in b.
+func commentTargets(b []byte) []commentTarget {
+	var (
+		targets = []commentTarget{}
+		stack   = []Rule{}
+		item    = []TokenEntry{}
+		text    = ""
+		end     = 0
+	)
+
+	e := Tokenize(b).Front()
+	for e != nil {
+		tok := e.Value.(TokenEntry)
+
+		switch tok.typ() {
+		case tokenBlockStart:
+			if len(item) > 0 {
+				targets = append(targets, commentTarget{item[0].pos.Offset, Rule(text), ""})
+			}
+			stack = append(stack, Rule(text))
+			item, text = nil, ""
+		case tokenBlockEnd, tokenStatementEnd:
+			if len(item) > 0 {
+				t := commentTarget{offset: item[0].pos.Offset}
+				if strings.HasPrefix(text, "@") {
+					t.rule = Rule(text)
+				} else {
+					t.property = item[0].value
+					if len(stack) > 0 {
+						t.rule = stack[len(stack)-1]
+					}
+				}
+				targets = append(targets, t)
+			}
+			if tok.typ() == tokenBlockEnd && len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+			item, text = nil, ""
+		default:
+			if text != "" && tok.pos.Offset > end {
+				text += " "
+			}
+			text += tok.value
+			item = append(item, tok)
+		}
+
+		end = tok.pos.Offset + len(tok.value)
+		e = e.Next()
+	}
+
+	return targets
+}
+
+// offsetPosition returns the line and column of the byte offset in b.
+func offsetPosition(b []byte, offset int) scanner.Position {
+	line := bytes.Count(b[:offset], []byte("\n")) + 1
+	column := offset - bytes.LastIndexByte(b[:offset], '\n')
+	return scanner.Position{Offset: offset, Line: line, Column: column}
+}
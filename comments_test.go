@@ -0,0 +1,76 @@
+package css
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCommentsWithPositions(t *testing.T) {
+	ex1 := `/* header */
+rule1 {
+	/* first */
+	style1: value1;
+	style2: value2; /* trailing */
+}
+/* footer */`
+
+	comments := CommentsWithPositions([]byte(ex1))
+	if len(comments) != 4 {
+		t.Fatalf("expected 4 comments, got %d", len(comments))
+	}
+
+	expected := []Comment{
+		{Text: "/* header */", Rule: "rule1"},
+		{Text: "/* first */", Rule: "rule1", Property: "style1"},
+		{Text: "/* trailing */"},
+		{Text: "/* footer */"},
+	}
+	for i, c := range comments {
+		if c.Text != expected[i].Text || c.Rule != expected[i].Rule || c.Property != expected[i].Property {
+			t.Fatalf("expected %+v, got %+v", expected[i], c)
+		}
+	}
+
+	if pos := comments[1].Pos; pos.Line != 3 || pos.Column != 2 {
+		t.Fatalf("wrong position for comment: %v", pos)
+	}
+}
+
+func TestCommentsInStringsAndURLs(t *testing.T) {
+	src := []byte(`a {
+	content: "/* x */";
+	background: url(img/*.png) /* real */;
+}
+/*! license */
+b[title='/*! no */'] {
+	color: red;
+}`)
+
+	comments := CommentsWithPositions(src)
+	if len(comments) != 2 || comments[0].Text != "/* real */" || comments[0].Pos.Line != 3 || comments[1].Text != "/*! license */" {
+		t.Fatalf("unexpected comments %+v", comments)
+	}
+	if c := Comments(src); len(c) != 2 || c[0] != "/* real */" {
+		t.Fatalf("unexpected comments %q", c)
+	}
+	if l := Licenses(src); len(l) != 1 || l[0] != "/*! license */" {
+		t.Fatalf("unexpected licenses %q", l)
+	}
+
+	sheet, err := ParseStylesheet(bytes.NewReader(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sheet.Comments) != 2 {
+		t.Fatalf("unexpected stylesheet comments %+v", sheet.Comments)
+	}
+
+	out, err := Format(src, FormatOptions{Preset: FormatCompact})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "a { content: \"/* x */\"; background: url(img/*.png); /* real */ }\n/*! license */\nb[title='/*! no */'] { color: red; }\n"
+	if string(out) != expected {
+		t.Fatalf("got %q, expected %q", out, expected)
+	}
+}
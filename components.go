@@ -0,0 +1,134 @@
+package css
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// StyleComponent is a group of rules of a stylesheet that style the same
+// feature, found by FindComponents.
+type StyleComponent struct {
+	// Name is the class name prefix shared by the rules, such as "btn" for
+	// .btn, .btn-primary and .btn__icon. Rules without classes are grouped
+	// by section and named after the section, or "global" before the
+	// first section.
+	Name string
+	// Section is the title of the comment banner the first rule of the
+	// component follows, empty when there is none.
+	Section string
+	Rules   []*StyleRule
+	// Size is the size in bytes of the source of the rules.
+	Size int
+	// Dependencies are the names of the other components whose classes
+	// are used in the selectors of the rules, such as btn for .card .btn
+	// in the card component.
+	Dependencies []string
+}
+
+// rBanner matches the decorations of comment banners, such as
+// /* ==== Buttons ==== */.
+var rBanner = regexp.MustCompile(`[=\-*#~_]{3,}`)
+
+// FindComponents clusters the style rules of the stylesheet in b into
+// components, in order of appearance. A rule belongs to the component of
+// the first class of its selector, up to the first "-" or "_", so the
+// BEM blocks and the prefixed utility classes form components. Comment
+// banners start sections, which name the components of rules without
+// classes. Rules of at-rules such as @media are grouped with the other
+// rules; @font-face rules and keyframes are left out.
+func FindComponents(b []byte) []StyleComponent {
+	sheet, _ := UnmarshalStylesheet(b)
+	banners := commentBanners(b)
+
+	var (
+		components = []*StyleComponent{}
+		byName     = map[string]*StyleComponent{}
+		uses       = map[*StyleComponent][]string{}
+		section    = ""
+	)
+	for _, r := range sheet.Rules {
+		if !isStyleRule(r) {
+			continue
+		}
+		for len(banners) > 0 && banners[0].offset < r.Source.Start {
+			section = banners[0].title
+			banners = banners[1:]
+		}
+
+		prefixes := classPrefixes(r.Selector)
+		name := section
+		if len(prefixes) > 0 {
+			name = prefixes[0]
+		} else if name == "" {
+			name = "global"
+		}
+		c, ok := byName[name]
+		if !ok {
+			c = &StyleComponent{Name: name, Section: section, Rules: []*StyleRule{}, Dependencies: []string{}}
+			byName[name] = c
+			components = append(components, c)
+		}
+		c.Rules = append(c.Rules, r)
+		c.Size += r.Source.End - r.Source.Start
+		uses[c] = append(uses[c], prefixes...)
+	}
+
+	all := make([]StyleComponent, len(components))
+	for i, c := range components {
+		for _, prefix := range uses[c] {
+			if _, ok := byName[prefix]; ok && prefix != c.Name && !containsString(c.Dependencies, prefix) {
+				c.Dependencies = append(c.Dependencies, prefix)
+			}
+		}
+		sort.Strings(c.Dependencies)
+		all[i] = *c
+	}
+	return all
+}
+
+// classPrefixes returns the prefixes of the classes of the selector in
+// order of appearance, without duplicates.
+func classPrefixes(selector Rule) []string {
+	prefixes := []string{}
+	selectors, err := ParseSelector(selector)
+	if err != nil {
+		return prefixes
+	}
+	for _, sel := range selectors {
+		for _, compound := range sel.Compounds {
+			for _, class := range compound.Classes {
+				prefix := class
+				if i := strings.IndexAny(class, "-_"); i > 0 {
+					prefix = class[:i]
+				}
+				if !containsString(prefixes, prefix) {
+					prefixes = append(prefixes, prefix)
+				}
+			}
+		}
+	}
+	return prefixes
+}
+
+type banner struct {
+	offset int
+	title  string
+}
+
+// commentBanners returns the comments of b decorated as section titles,
+// such as /* ==== Buttons ==== */, with their text as title.
+func commentBanners(b []byte) []banner {
+	banners := []banner{}
+	for _, loc := range commentIndexes(b) {
+		text := string(b[loc[0]+2 : loc[1]-2])
+		if !rBanner.MatchString(text) {
+			continue
+		}
+		title := strings.Join(strings.Fields(rBanner.ReplaceAllString(text, " ")), " ")
+		if title != "" && len(title) <= 60 {
+			banners = append(banners, banner{offset: loc[0], title: title})
+		}
+	}
+	return banners
+}
@@ -0,0 +1,47 @@
+package css
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindComponents(t *testing.T) {
+	src := `html { margin: 0; }
+
+/* ===== Buttons ===== */
+.btn { padding: 4px; }
+.btn-primary, .btn__icon { color: blue; }
+
+/* -------------------
+   Cards
+   ------------------- */
+.card { border: 1px solid; }
+.card .btn { margin: 0; }
+@media print { .card__title { display: none; } }
+/* a regular comment */
+h2 { font-size: 2em; }
+@font-face { font-family: X; src: url(x.woff2); }
+`
+	components := FindComponents([]byte(src))
+	type summary struct {
+		Name, Section string
+		Rules         int
+		Dependencies  []string
+	}
+	got := []summary{}
+	for _, c := range components {
+		got = append(got, summary{c.Name, c.Section, len(c.Rules), c.Dependencies})
+	}
+	want := []summary{
+		{"global", "", 1, []string{}},
+		{"btn", "Buttons", 2, []string{}},
+		{"card", "Cards", 3, []string{"btn"}},
+		{"Cards", "Cards", 1, []string{}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v\nwant %+v", got, want)
+	}
+	if size := components[1].Size; size != len(".btn { padding: 4px; }")+len(".btn-primary, .btn__icon { color: blue; }") {
+		t.Errorf("got size %d", size)
+	}
+}
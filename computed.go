@@ -0,0 +1,91 @@
+package css
+
+import "strings"
+
+// ComputedStyle maps every property of an element to its value after
+// the cascade, inheritance and the CSS-wide keywords are applied.
+type ComputedStyle map[string]string
+
+// ComputeStyle returns the computed style of an element from the
+// declarations that apply to it. parent is the computed style of the
+// parent element, or nil for the root element. Every property in
+// Properties gets a value: declared properties take the cascade winner,
+// the others inherit or take their initial value. The CSS-wide keywords
+// inherit, initial, unset, revert and revert-layer are resolved using the
+// property metadata, including when they are set through the all
+// shorthand.
+func ComputeStyle(entries []CascadeEntry, parent ComputedStyle) ComputedStyle {
+	var (
+		style      = ComputedStyle{}
+		candidates = map[string][]CascadeEntry{}
+	)
+	for _, e := range entries {
+		if !strings.EqualFold(e.Declaration.Property, "all") {
+			p := e.Declaration.Property
+			candidates[p] = append(candidates[p], e)
+			continue
+		}
+		for _, d := range ExpandShorthand(e.Declaration) {
+			longhand := e
+			longhand.Declaration = d
+			candidates[d.Property] = append(candidates[d.Property], longhand)
+		}
+	}
+
+	for p := range Properties {
+		if _, ok := candidates[p]; !ok {
+			style[p] = defaultValue(p, parent)
+		}
+	}
+	for p, list := range candidates {
+		style[p] = cascadedValue(p, SortCascade(list), parent)
+	}
+	return style
+}
+
+// cascadedValue resolves the value of the property from its candidates
+// in winning order.
+func cascadedValue(property string, sorted []CascadeEntry, parent ComputedStyle) string {
+	i := 0
+	for i < len(sorted) {
+		winner := sorted[i]
+		switch strings.ToLower(winner.Declaration.Value) {
+		case "inherit":
+			if v, ok := parent[property]; ok {
+				return v
+			}
+			return Properties[property].Initial
+		case "initial":
+			return Properties[property].Initial
+		case "unset":
+			return defaultValue(property, parent)
+		case "revert":
+			// roll back to the declarations of the lower origins
+			for i < len(sorted) && sorted[i].Origin >= winner.Origin {
+				i++
+			}
+		case "revert-layer":
+			// roll back to the declarations of the lower layers
+			for i < len(sorted) && sorted[i].Origin == winner.Origin &&
+				sorted[i].Layer == winner.Layer &&
+				sorted[i].Declaration.Important == winner.Declaration.Important {
+				i++
+			}
+		default:
+			return winner.Declaration.Value
+		}
+	}
+	return defaultValue(property, parent)
+}
+
+// defaultValue returns the value of a property without declarations:
+// the parent value for inherited properties, the initial value otherwise.
+func defaultValue(property string, parent ComputedStyle) string {
+	info := Properties[property]
+	if info.Inherited {
+		if v, ok := parent[property]; ok {
+			return v
+		}
+	}
+	return info.Initial
+}
@@ -0,0 +1,72 @@
+package css
+
+import "testing"
+
+func TestComputeStyle(t *testing.T) {
+	entry := func(property, value string, origin Origin, layer, order int) CascadeEntry {
+		return CascadeEntry{
+			Declaration: NewDeclaration(property, value),
+			Origin:      origin,
+			Layer:       layer,
+			Order:       order,
+		}
+	}
+
+	parent := ComputedStyle{"color": "red", "margin-top": "10px", "font-size": "20px"}
+	style := ComputeStyle([]CascadeEntry{
+		entry("display", "block", OriginUserAgent, 0, 0),
+		entry("display", "revert", OriginAuthor, 0, 1),
+		entry("margin-top", "inherit", OriginAuthor, 0, 2),
+		entry("color", "unset", OriginAuthor, 0, 3),
+		entry("width", "10px", OriginAuthor, 1, 4),
+		entry("width", "20px", OriginAuthor, 2, 5),
+		entry("width", "revert-layer", OriginAuthor, 0, 6),
+		entry("height", "initial", OriginAuthor, 0, 7),
+		entry("z-index", "revert", OriginAuthor, 0, 8),
+	}, parent)
+
+	expected := map[string]string{
+		"display":     "block",
+		"margin-top":  "10px",
+		"color":       "red",
+		"font-size":   "20px",
+		"width":       "20px",
+		"height":      "auto",
+		"z-index":     "auto",
+		"padding-top": "0",
+	}
+	for p, v := range expected {
+		if style[p] != v {
+			t.Fatalf("expected %s: %s, got %q", p, v, style[p])
+		}
+	}
+}
+
+func TestComputeStyleAll(t *testing.T) {
+	parent := ComputedStyle{"color": "red", "direction": "rtl"}
+	style := ComputeStyle([]CascadeEntry{
+		{Declaration: NewDeclaration("display", "block"), Origin: OriginUserAgent},
+		{Declaration: NewDeclaration("all", "revert"), Origin: OriginAuthor, Order: 1},
+		{Declaration: NewDeclaration("color", "blue"), Origin: OriginAuthor, Order: 2},
+		{Declaration: NewDeclaration("direction", "ltr"), Origin: OriginAuthor},
+	}, parent)
+
+	expected := map[string]string{
+		"display":   "block",
+		"color":     "blue",
+		"direction": "ltr",
+		"width":     "auto",
+	}
+	for p, v := range expected {
+		if style[p] != v {
+			t.Fatalf("expected %s: %s, got %q", p, v, style[p])
+		}
+	}
+
+	style = ComputeStyle([]CascadeEntry{
+		{Declaration: NewDeclaration("all", "inherit")},
+	}, ComputedStyle{"width": "10px", "unicode-bidi": "embed"})
+	if style["width"] != "10px" || style["unicode-bidi"] != "normal" {
+		t.Fatalf("all should inherit every property but unicode-bidi, got width %q and unicode-bidi %q", style["width"], style["unicode-bidi"])
+	}
+}
@@ -0,0 +1,162 @@
+package css
+
+import (
+	"bytes"
+	"strings"
+	"unicode"
+)
+
+// Concat merges the stylesheets into one, keeping it valid: only the first
+// @charset is kept and moved to the top, @import and @namespace statements
+// are moved in front of all other rules without duplicates, and a single
+// @layer statement declares every named layer in order of first
+// appearance, so the layer order stays the same as in the separate
+// stylesheets.
+func Concat(sheets ...[]byte) []byte {
+	var buf bytes.Buffer
+	for _, item := range concatItems(sheets) {
+		buf.WriteString(item.text)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+// concatItem is a top-level item of the output of Concat.
+type concatItem struct {
+	text string
+	// sheet is the index of the stylesheet the item was copied from, or
+	// -1 for the generated @layer statement.
+	sheet int
+	// offset is the start of the item in its stylesheet.
+	offset int
+}
+
+// concatItems returns the top-level items of the concatenation of the
+// stylesheets, in order.
+func concatItems(sheets [][]byte) []concatItem {
+	var (
+		charset *concatItem
+		imports []concatItem
+		spaces  []concatItem
+		layers  []string
+		rules   []concatItem
+		seen    = map[string]bool{}
+	)
+	addLayers := func(names string) {
+		for _, name := range strings.Split(names, ",") {
+			name = strings.TrimSpace(name)
+			if name != "" && !seen["@layer "+name] {
+				seen["@layer "+name] = true
+				layers = append(layers, name)
+			}
+		}
+	}
+
+	for i, sheet := range sheets {
+		for _, span := range topLevelSpans(sheet) {
+			item := concatItem{string(sheet[span[0]:span[1]]), i, span[0]}
+			name := atRuleName(item.text)
+			key := strings.Join(strings.Fields(item.text), " ")
+			body := strings.TrimSpace(string(blankComments([]byte(item.text))))
+			switch {
+			case name == "charset":
+				if charset == nil {
+					charset = &item
+				}
+			case name == "import" || name == "namespace":
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+				if name == "import" {
+					imports = append(imports, item)
+				} else {
+					spaces = append(spaces, item)
+				}
+			case name == "layer" && strings.HasSuffix(body, ";"):
+				addLayers(strings.TrimSuffix(body[len("@layer"):], ";"))
+			default:
+				if name == "layer" {
+					addLayers(body[len("@layer"):strings.IndexByte(body, '{')])
+				}
+				rules = append(rules, item)
+			}
+		}
+	}
+
+	out := []concatItem{}
+	if charset != nil {
+		out = append(out, *charset)
+	}
+	if len(layers) > 0 {
+		out = append(out, concatItem{"@layer " + strings.Join(layers, ", ") + ";", -1, 0})
+	}
+	out = append(out, imports...)
+	out = append(out, spaces...)
+	return append(out, rules...)
+}
+
+// atRuleName returns the lower case name of the at-rule starting the
+// item, or an empty string if the item is not an at-rule.
+func atRuleName(item string) string {
+	item = strings.TrimSpace(string(blankComments([]byte(item))))
+	if !strings.HasPrefix(item, "@") {
+		return ""
+	}
+	return strings.ToLower(item[1:scanName(item, 1)])
+}
+
+// topLevelItems splits the stylesheet into its top-level statements and
+// blocks. Comments between items are kept with the item following them.
+func topLevelItems(b []byte) []string {
+	items := []string{}
+	for _, span := range topLevelSpans(b) {
+		items = append(items, string(b[span[0]:span[1]]))
+	}
+	return items
+}
+
+// topLevelSpans returns the [start, end) byte ranges of the top-level
+// items of the stylesheet, without surrounding whitespace.
+func topLevelSpans(b []byte) [][2]int {
+	var (
+		spans = [][2]int{}
+		depth = 0
+		start = 0
+	)
+	add := func(end int) {
+		item := b[start:end]
+		s := end - len(bytes.TrimLeftFunc(item, unicode.IsSpace))
+		e := start + len(bytes.TrimRightFunc(item, unicode.IsSpace))
+		if s < e {
+			spans = append(spans, [2]int{s, e})
+		}
+		start = end
+	}
+	for i := 0; i < len(b); i++ {
+		switch c := b[i]; {
+		case c == '/' && i+1 < len(b) && b[i+1] == '*':
+			end := bytes.Index(b[i+2:], []byte("*/"))
+			if end < 0 {
+				i = len(b)
+			} else {
+				i += end + 3
+			}
+		case c == '"' || c == '\'':
+			i = scanString(string(b), i) - 1
+		case c == '{' || c == '(' || c == '[':
+			depth++
+		case c == ')' || c == ']':
+			depth--
+		case c == '}':
+			depth--
+			if depth == 0 {
+				add(i + 1)
+			}
+		case c == ';' && depth == 0:
+			add(i + 1)
+		}
+	}
+	add(len(b))
+	return spans
+}
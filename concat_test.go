@@ -0,0 +1,38 @@
+package css
+
+import "testing"
+
+func TestConcat(t *testing.T) {
+	a := []byte(`@charset "utf-8";
+@layer base, theme;
+@import url("reset.css");
+body {
+	color: red;
+}`)
+	b := []byte(`@charset "utf-8";
+@import url("reset.css");
+@import "b.css" print;
+@layer components {
+	.btn {
+		color: blue;
+	}
+}
+@layer theme;`)
+
+	expected := `@charset "utf-8";
+@layer base, theme, components;
+@import url("reset.css");
+@import "b.css" print;
+body {
+	color: red;
+}
+@layer components {
+	.btn {
+		color: blue;
+	}
+}
+`
+	if out := string(Concat(a, b)); out != expected {
+		t.Fatalf("got:\n%s\nexpected:\n%s", out, expected)
+	}
+}
@@ -0,0 +1,139 @@
+package css
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Constraints are the hard limits of an environment embedding css, such
+// as AMP pages or third party widgets, checked by CheckConstraints.
+type Constraints struct {
+	Name string
+	// MaxBytes is the maximum size of the stylesheet, zero for no limit.
+	MaxBytes int
+	// NoImportant forbids !important declarations.
+	NoImportant bool
+	// AtRules are the allowed at-rule names without the @. When nil,
+	// every at-rule is allowed.
+	AtRules []string
+	// NoExternalFonts forbids @font-face sources loaded from other
+	// origins. Relative and data: urls are allowed.
+	NoExternalFonts bool
+	// Properties are forbidden properties.
+	Properties []string
+}
+
+// AMP are the constraints of the <style amp-custom> stylesheet of AMP
+// pages.
+var AMP = Constraints{
+	Name:        "AMP",
+	MaxBytes:    75000,
+	NoImportant: true,
+	AtRules:     []string{"font-face", "keyframes", "media", "page", "supports"},
+	Properties:  []string{"behavior", "-moz-binding"},
+}
+
+// ConstraintReport is the outcome of CheckConstraints.
+type ConstraintReport struct {
+	Constraints string
+	// Size is the size of the stylesheet in bytes.
+	Size       int
+	Violations []Issue
+}
+
+// Passed reports whether the stylesheet satisfies every constraint.
+func (r ConstraintReport) Passed() bool {
+	return len(r.Violations) == 0
+}
+
+func (r ConstraintReport) String() string {
+	if r.Passed() {
+		return fmt.Sprintf("%s: passed", r.Constraints)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: %d violations", r.Constraints, len(r.Violations))
+	for _, v := range r.Violations {
+		b.WriteString("\n\t" + v.String())
+	}
+	return b.String()
+}
+
+var rURL = regexp.MustCompile(`(?i)url\(\s*['"]?([^'")\s]+)`)
+
+// CheckConstraints checks the stylesheet in b against the constraints.
+// The violations are reported as issues of the "constraints" lint in
+// document order, after the size violation.
+func CheckConstraints(b []byte, c Constraints) (ConstraintReport, error) {
+	report := ConstraintReport{Constraints: c.Name, Size: len(b), Violations: []Issue{}}
+	sheet, err := ParseStylesheet(bytes.NewReader(b))
+	if err != nil {
+		return report, err
+	}
+
+	violation := func(issue Issue, format string, args ...interface{}) {
+		issue.Lint = "constraints"
+		issue.Message = fmt.Sprintf(format, args...)
+		report.Violations = append(report.Violations, issue)
+	}
+	if c.MaxBytes > 0 && len(b) > c.MaxBytes {
+		violation(Issue{}, "stylesheet is %d bytes, over the limit of %d", len(b), c.MaxBytes)
+	}
+
+	seen := map[string]bool{}
+	atRule := func(prelude Rule, issue Issue) {
+		name := atRuleName(string(prelude))
+		if c.AtRules == nil || containsString(c.AtRules, name) || seen[string(prelude)] {
+			return
+		}
+		seen[string(prelude)] = true
+		issue.Selector = prelude
+		violation(issue, "at-rule @%s is not allowed", name)
+	}
+	for _, s := range sheet.Statements {
+		atRule(s.Prelude, Issue{Pos: s.Pos})
+	}
+	for _, r := range sheet.Rules {
+		for _, at := range r.AtRules {
+			atRule(at, Issue{Pos: r.Pos})
+		}
+		fontFace := atRuleName(string(r.Selector)) == "font-face"
+		if strings.HasPrefix(string(r.Selector), "@") {
+			atRule(r.Selector, Issue{Pos: r.Pos})
+		}
+
+		for _, d := range r.Declarations {
+			issue := Issue{Selector: r.Selector, Property: d.Property, Pos: d.Pos}
+			property := strings.ToLower(d.Property)
+			if c.NoImportant && d.Important {
+				violation(issue, "!important is not allowed")
+			}
+			if containsString(c.Properties, property) {
+				violation(issue, "property %s is not allowed", property)
+			}
+			if !c.NoExternalFonts || !fontFace || property != "src" {
+				continue
+			}
+			for _, m := range rURL.FindAllStringSubmatch(d.Value, -1) {
+				if isExternalURL(m[1]) {
+					violation(issue, "external font %s is not allowed", m[1])
+				}
+			}
+		}
+	}
+	return report, nil
+}
+
+// isExternalURL reports whether the url is absolute, with a scheme other
+// than data or starting with //.
+func isExternalURL(url string) bool {
+	if strings.HasPrefix(url, "//") {
+		return true
+	}
+	scheme, _, ok := strings.Cut(url, ":")
+	if !ok || strings.ContainsAny(scheme, "/?#") {
+		return false
+	}
+	return !strings.EqualFold(scheme, "data")
+}
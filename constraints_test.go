@@ -0,0 +1,75 @@
+package css
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCheckConstraints(t *testing.T) {
+	src := `@import url(theme.css);
+@font-face {
+	font-family: Brand;
+	src: url(https://fonts.example.com/brand.woff2) format("woff2"), url(brand.woff) format("woff");
+}
+p {
+	color: red !important;
+	behavior: url(x.htc);
+}
+@media print {
+	p {
+		color: black;
+	}
+}
+`
+	c := AMP
+	c.NoExternalFonts = true
+	report, err := CheckConstraints([]byte(src), c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Passed() {
+		t.Fatal("passed")
+	}
+	got := []string{}
+	for _, v := range report.Violations {
+		got = append(got, v.Message)
+	}
+	want := []string{
+		"at-rule @import is not allowed",
+		"external font https://fonts.example.com/brand.woff2 is not allowed",
+		"!important is not allowed",
+		"property behavior is not allowed",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	if !strings.HasPrefix(report.String(), "AMP: 4 violations\n\t") {
+		t.Errorf("wrong report %q", report)
+	}
+
+	c.MaxBytes = 10
+	report, _ = CheckConstraints([]byte("p {\n\tcolor: red;\n}\n"), c)
+	if len(report.Violations) != 1 || report.Violations[0].Message != "stylesheet is 19 bytes, over the limit of 10" {
+		t.Errorf("got %v", report.Violations)
+	}
+
+	report, _ = CheckConstraints([]byte("p {\n\tcolor: red;\n}\n"), AMP)
+	if !report.Passed() || report.String() != "AMP: passed" {
+		t.Errorf("got %v", report)
+	}
+}
+
+func TestIsExternalURL(t *testing.T) {
+	for url, want := range map[string]bool{
+		"https://a.com/f.woff":        true,
+		"//a.com/f.woff":              true,
+		"fonts/f.woff":                false,
+		"data:font/woff2;base64,AAAA": false,
+		"f.woff?v=1:2":                false,
+	} {
+		if got := isExternalURL(url); got != want {
+			t.Errorf("%s: got %v", url, got)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package css
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RuleUsage is the runtime usage of a rule.
+type RuleUsage struct {
+	Rule *StyleRule
+	// Used are the selectors of the selector group that were matched at
+	// runtime, and Unused the others, in the order of the group.
+	Used, Unused SelectorGroup
+}
+
+// IsUsed reports whether a selector of the rule was matched.
+func (u RuleUsage) IsUsed() bool {
+	return len(u.Used) > 0
+}
+
+// CoverageReport is the usage of every style rule of a stylesheet.
+type CoverageReport struct {
+	Rules []RuleUsage
+}
+
+// Coverage annotates the style rules of the stylesheet with their usage,
+// given the selectors matched at runtime, such as the ones collected by a
+// browser extension. Selectors are compared after serialization, so
+// "a>b" and "a > b" are the same. Rules that are not style rules, such
+// as @font-face and keyframes, are not part of the report.
+func Coverage(sheet *Stylesheet, used []Rule) CoverageReport {
+	seen := map[string]bool{}
+	for _, group := range used {
+		for _, s := range group.Group() {
+			seen[s.String()] = true
+		}
+	}
+
+	report := CoverageReport{Rules: []RuleUsage{}}
+	for _, r := range sheet.Rules {
+		if !isStyleRule(r) {
+			continue
+		}
+		u := RuleUsage{Rule: r, Used: SelectorGroup{}, Unused: SelectorGroup{}}
+		for _, s := range r.Selector.Group() {
+			if seen[s.String()] {
+				u.Used = append(u.Used, s)
+			} else {
+				u.Unused = append(u.Unused, s)
+			}
+		}
+		report.Rules = append(report.Rules, u)
+	}
+	return report
+}
+
+// RangeCoverage is like Coverage, given the byte ranges of the source of
+// the stylesheet that were used, as exported by the coverage tools of
+// browsers. A rule is used when one of the ranges overlaps it. The
+// ranges don't tell which selector of a group matched, so all of them
+// are used or unused together.
+func RangeCoverage(sheet *Stylesheet, used []SourceRange) CoverageReport {
+	report := CoverageReport{Rules: []RuleUsage{}}
+	for _, r := range sheet.Rules {
+		if !isStyleRule(r) {
+			continue
+		}
+		u := RuleUsage{Rule: r, Used: SelectorGroup{}, Unused: r.Selector.Group()}
+		for _, rng := range used {
+			if rng.Start < r.Source.End && r.Source.Start < rng.End {
+				u.Used, u.Unused = u.Unused, SelectorGroup{}
+				break
+			}
+		}
+		report.Rules = append(report.Rules, u)
+	}
+	return report
+}
+
+// isStyleRule reports whether the rule is a style rule, not an at-rule
+// with declarations such as @font-face or a keyframe.
+func isStyleRule(r *StyleRule) bool {
+	if strings.HasPrefix(string(r.Selector), "@") {
+		return false
+	}
+	prelude, _ := r.keyframes()
+	return prelude == ""
+}
+
+// Unused returns the rules none of whose selectors were matched, which
+// can be deleted.
+func (report CoverageReport) Unused() []*StyleRule {
+	rules := []*StyleRule{}
+	for _, u := range report.Rules {
+		if !u.IsUsed() {
+			rules = append(rules, u.Rule)
+		}
+	}
+	return rules
+}
+
+// String returns the deletion report: a line per unused rule, and per
+// unused selector of used rules, with its position.
+func (report CoverageReport) String() string {
+	var b strings.Builder
+	for _, u := range report.Rules {
+		pos := fmt.Sprintf("%d:%d", u.Rule.Pos.Line, u.Rule.Pos.Column)
+		switch {
+		case !u.IsUsed():
+			fmt.Fprintf(&b, "%s: unused rule %s\n", pos, string(u.Rule.Selector))
+		case len(u.Unused) > 0:
+			fmt.Fprintf(&b, "%s: unused selectors %s in %s\n", pos, u.Unused, string(u.Rule.Selector))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,47 @@
+package css
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const coverageCSS = `.a>.b, .c { color: red; }
+.unused { top: 0; }
+@media print { .c { color: black; } }
+@font-face { font-family: X; src: url(x.woff2); }
+@keyframes spin { to { opacity: 0; } }
+`
+
+func TestCoverage(t *testing.T) {
+	sheet, _ := UnmarshalStylesheet([]byte(coverageCSS))
+	report := Coverage(sheet, []Rule{".a > .b", ".c, .d"})
+	if len(report.Rules) != 3 {
+		t.Fatalf("got %d rules, want 3", len(report.Rules))
+	}
+	if u := report.Rules[0]; !reflect.DeepEqual(u.Used, SelectorGroup{".a>.b", ".c"}) || len(u.Unused) != 0 {
+		t.Errorf("got %+v", u)
+	}
+	if unused := report.Unused(); len(unused) != 1 || unused[0].Selector != ".unused" {
+		t.Errorf("got unused rules %v", unused)
+	}
+
+	report = Coverage(sheet, []Rule{".c"})
+	want := "1:1: unused selectors .a>.b in .a>.b, .c\n2:1: unused rule .unused\n"
+	if got := report.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestRangeCoverage(t *testing.T) {
+	sheet, _ := UnmarshalStylesheet([]byte(coverageCSS))
+	start := strings.Index(coverageCSS, "print { .c") + len("print { ")
+	report := RangeCoverage(sheet, []SourceRange{{Start: start, End: start + 4}})
+	used := []bool{}
+	for _, u := range report.Rules {
+		used = append(used, u.IsUsed())
+	}
+	if !reflect.DeepEqual(used, []bool{false, false, true}) {
+		t.Errorf("got usage %v", used)
+	}
+}
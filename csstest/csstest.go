@@ -0,0 +1,158 @@
+// Package csstest provides helpers to test generated css: golden file
+// comparisons of stylesheets and assertions on their declarations.
+package csstest
+
+import (
+	"flag"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/itskass/go-css"
+)
+
+// Update makes AssertGolden write the golden files instead of comparing
+// them, for example with go test -csstest.update.
+var Update = flag.Bool("csstest.update", false, "update the golden files of csstest.AssertGolden")
+
+// Parse parses the css, failing the test if it can't be parsed.
+func Parse(t testing.TB, src string) *css.Stylesheet {
+	t.Helper()
+	sheet, err := css.UnmarshalStylesheet([]byte(src))
+	if err != nil {
+		t.Fatalf("parsing css: %v", err)
+	}
+	return sheet
+}
+
+// Normalize returns the stylesheet in a normalized form, so stylesheets
+// differing only in formatting normalize the same. Every declaration is
+// on its own line, selectors and values have single spaces and
+// properties are lower case. Consecutive rules in the same at-rules are
+// written in a single block.
+func Normalize(sheet *css.Stylesheet) string {
+	var (
+		b     strings.Builder
+		open  []css.Rule
+		depth = func() string { return strings.Repeat("\t", len(open)) }
+	)
+	for _, r := range sheet.Rules {
+		common := 0
+		for common < len(open) && common < len(r.AtRules) && normalizeSpace(string(open[common])) == normalizeSpace(string(r.AtRules[common])) {
+			common++
+		}
+		for len(open) > common {
+			open = open[:len(open)-1]
+			b.WriteString(depth() + "}\n")
+		}
+		for _, at := range r.AtRules[common:] {
+			b.WriteString(depth() + normalizeSpace(string(at)) + " {\n")
+			open = append(open, at)
+		}
+
+		b.WriteString(depth() + normalizeSelector(r.Selector) + " {\n")
+		for _, d := range r.Declarations {
+			b.WriteString(depth() + "\t" + strings.ToLower(d.Property) + ": " + declaredValue(d) + ";\n")
+		}
+		b.WriteString(depth() + "}\n")
+	}
+	for len(open) > 0 {
+		open = open[:len(open)-1]
+		b.WriteString(depth() + "}\n")
+	}
+	return b.String()
+}
+
+// AssertGolden checks that the normalized stylesheet matches the golden
+// file at path, reporting the differences otherwise. With the Update
+// flag set, the golden file is written instead.
+func AssertGolden(t testing.TB, sheet *css.Stylesheet, path string) {
+	t.Helper()
+	got := Normalize(sheet)
+	if *Update {
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatalf("updating golden file: %v", err)
+		}
+		return
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading golden file: %v (run the test with -csstest.update to create it)", err)
+	}
+	sheetWant, err := css.UnmarshalStylesheet(b)
+	if err != nil {
+		t.Fatalf("parsing golden file: %v", err)
+	}
+	if want := Normalize(sheetWant); got != want {
+		t.Errorf("stylesheet doesn't match %s (-want +got):\n%s", path, Diff(want, got))
+	}
+}
+
+// AssertDeclared checks that a rule of the stylesheet with the selector
+// declares the property with the value, such as
+//
+//	csstest.AssertDeclared(t, sheet, ".btn", "color", "red")
+//
+// The selector matches the rules having it in their selector group, out
+// of at-rules. When several rules declare the property, the value that
+// wins the cascade among them is checked. Values are compared after
+// normalizing whitespace, and a value ending with !important only
+// matches important declarations.
+func AssertDeclared(t testing.TB, sheet *css.Stylesheet, selector, property, value string) {
+	t.Helper()
+	d, ok := declared(sheet, selector, property)
+	if !ok {
+		t.Errorf("%s doesn't declare %s", selector, property)
+		return
+	}
+	want := normalizeSpace(strings.TrimSuffix(strings.TrimSpace(value), ";"))
+	if got := declaredValue(d); got != want && !(got == want+" !important" && !css.IsImportant(value)) {
+		t.Errorf("%s { %s }: got %q, want %q", selector, property, got, want)
+	}
+}
+
+// AssertNotDeclared checks that no rule of the stylesheet with the
+// selector declares the property.
+func AssertNotDeclared(t testing.TB, sheet *css.Stylesheet, selector, property string) {
+	t.Helper()
+	if d, ok := declared(sheet, selector, property); ok {
+		t.Errorf("%s declares %s: %s", selector, property, declaredValue(d))
+	}
+}
+
+// declared returns the declaration of the property winning among the
+// rules with the selector.
+func declared(sheet *css.Stylesheet, selector, property string) (css.Declaration, bool) {
+	var (
+		ds   css.Declarations
+		want = normalizeSelector(css.Rule(selector))
+	)
+	for _, r := range sheet.Rules {
+		if len(r.AtRules) > 0 {
+			continue
+		}
+		for _, s := range r.Selector.Group() {
+			if normalizeSelector(s) == want {
+				ds = append(ds, r.Declarations...)
+				break
+			}
+		}
+	}
+	return ds.Get(property)
+}
+
+func declaredValue(d css.Declaration) string {
+	if d.Important {
+		return normalizeSpace(d.Value) + " !important"
+	}
+	return normalizeSpace(d.Value)
+}
+
+func normalizeSelector(selector css.Rule) string {
+	return normalizeSpace(selector.String())
+}
+
+func normalizeSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,97 @@
+package csstest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// recorder is a testing.TB recording the failures instead of failing.
+type recorder struct {
+	testing.TB
+	failures []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...interface{}) {
+	r.Errorf(format, args...)
+}
+
+func TestNormalize(t *testing.T) {
+	a := Parse(t, ".a>b{COLOR:red}@media print{.a{top:0}.b{left : 1px  2px}}")
+	b := Parse(t, `.a > b {
+	color: red;
+}
+@media print {
+	.a { top: 0; }
+	.b { left: 1px 2px; }
+}
+`)
+	want := `.a > b {
+	color: red;
+}
+@media print {
+	.a {
+		top: 0;
+	}
+	.b {
+		left: 1px 2px;
+	}
+}
+`
+	if got := Normalize(a); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+	if Normalize(a) != Normalize(b) {
+		t.Error("equivalent stylesheets normalize differently")
+	}
+}
+
+func TestAssertGolden(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "golden.css")
+	if err := os.WriteFile(path, []byte(".a { color: red; }\n.b { top: 0; }\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	AssertGolden(t, Parse(t, ".a{color:red}.b{top:0}"), path)
+
+	r := &recorder{TB: t}
+	AssertGolden(r, Parse(t, ".a{color:blue}.b{top:0}"), path)
+	if len(r.failures) != 1 || !strings.Contains(r.failures[0], "- \tcolor: red;\n+ \tcolor: blue;") {
+		t.Errorf("got failures %q", r.failures)
+	}
+
+	r = &recorder{TB: t}
+	AssertGolden(r, Parse(t, ".a{}"), filepath.Join(t.TempDir(), "missing.css"))
+	if len(r.failures) == 0 {
+		t.Error("no failure for a missing golden file")
+	}
+}
+
+func TestAssertDeclared(t *testing.T) {
+	sheet := Parse(t, `.btn, .link { color: red; padding: 0  4px; }
+.btn { color: blue !important; }
+.btn { color: green; }
+@media print { .btn { margin: 0; } }
+`)
+	AssertDeclared(t, sheet, ".btn", "color", "blue")
+	AssertDeclared(t, sheet, ".btn", "color", "blue !important")
+	AssertDeclared(t, sheet, ".link", "padding", "0 4px")
+	AssertNotDeclared(t, sheet, ".btn", "margin")
+
+	r := &recorder{TB: t}
+	AssertDeclared(r, sheet, ".link", "color", "red !important")
+	AssertDeclared(r, sheet, ".link", "color", "blue")
+	AssertDeclared(r, sheet, ".other", "color", "red")
+	AssertNotDeclared(r, sheet, ".link", "color")
+	if len(r.failures) != 4 {
+		t.Errorf("got failures %q", r.failures)
+	}
+}
@@ -0,0 +1,14 @@
+package csstest
+
+import "testing"
+
+func TestDiff(t *testing.T) {
+	if got := Diff("a\nb\n", "a\nb\n"); got != "" {
+		t.Errorf("equal: got %q", got)
+	}
+	got := Diff("a\nb\nc\n", "a\nx\nc\nd\n")
+	want := "  a\n- b\n+ x\n  c\n+ d\n"
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
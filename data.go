@@ -0,0 +1,134 @@
+package css
+
+import (
+	"encoding/json"
+	"fmt"
+	imagecolor "image/color"
+	"io"
+	"strings"
+)
+
+// VendorPrefixes are the vendor prefixes current browsers still need for
+// the properties, such as -webkit- for backdrop-filter. You can add your
+// own properties or overwrite the existing ones, or load newer data with
+// LoadData.
+var VendorPrefixes = map[string][]string{
+	"appearance":           {"-webkit-", "-moz-"},
+	"backdrop-filter":      {"-webkit-"},
+	"background-clip":      {"-webkit-"},
+	"box-decoration-break": {"-webkit-"},
+	"hyphens":              {"-webkit-"},
+	"initial-letter":       {"-webkit-"},
+	"line-clamp":           {"-webkit-"},
+	"mask":                 {"-webkit-"},
+	"mask-image":           {"-webkit-"},
+	"mask-position":        {"-webkit-"},
+	"mask-repeat":          {"-webkit-"},
+	"mask-size":            {"-webkit-"},
+	"print-color-adjust":   {"-webkit-"},
+	"text-emphasis":        {"-webkit-"},
+	"text-size-adjust":     {"-webkit-", "-moz-"},
+	"user-select":          {"-webkit-"},
+}
+
+// DataTables are the standard data of the package, in the format read by
+// LoadData and written by WriteData:
+//
+//	{
+//		"properties": {"accent-color": {"inherited": true, "initial": "auto"}},
+//		"colors": {"rebeccapurple": "#663399"},
+//		"prefixes": {"user-select": ["-webkit-"]},
+//		"userAgentStylesheet": "p { display: block; }"
+//	}
+type DataTables struct {
+	// Properties are the longhand properties, see Properties.
+	Properties map[string]PropertyInfo `json:"properties,omitempty"`
+	// Colors are the named colors as hex colors.
+	Colors map[string]string `json:"colors,omitempty"`
+	// Prefixes are the vendor prefixes of the properties, see
+	// VendorPrefixes.
+	Prefixes map[string][]string `json:"prefixes,omitempty"`
+	// UserAgentStylesheet is the source of HTMLStylesheet.
+	UserAgentStylesheet string `json:"userAgentStylesheet,omitempty"`
+}
+
+// LoadData updates the standard data with the tables read as JSON from r,
+// so applications can follow the specifications without waiting for a
+// release of the package. The entries of the tables are added to the
+// package data, overwriting the existing ones, and a user agent
+// stylesheet replaces HTMLStylesheet, including as a registered default
+// stylesheet. Nothing is updated if the data is invalid. LoadData is
+// meant to be called at startup: the package data must not be used
+// concurrently.
+func LoadData(r io.Reader) error {
+	var data DataTables
+	if err := json.NewDecoder(r).Decode(&data); err != nil {
+		return err
+	}
+	return data.apply()
+}
+
+// apply updates the package data with the tables.
+func (data DataTables) apply() error {
+	colors := make(map[string]imagecolor.RGBA, len(data.Colors))
+	for name, value := range data.Colors {
+		c, err := parseHexColor(strings.TrimPrefix(value, "#"))
+		if err != nil || !strings.HasPrefix(value, "#") {
+			return fmt.Errorf("invalid color %s: %q", name, value)
+		}
+		colors[strings.ToLower(name)] = c
+	}
+	var sheet *Stylesheet
+	if data.UserAgentStylesheet != "" {
+		var err error
+		sheet, err = UnmarshalStylesheetWithOptions([]byte(data.UserAgentStylesheet), ParseOptions{Strict: true})
+		if err != nil {
+			return fmt.Errorf("invalid user agent stylesheet: %v", err)
+		}
+	}
+
+	for name, info := range data.Properties {
+		Properties[name] = info
+	}
+	for name, c := range colors {
+		namedColors[name] = c
+	}
+	for name, prefixes := range data.Prefixes {
+		VendorPrefixes[name] = prefixes
+	}
+	if sheet != nil {
+		defaults.Lock()
+		for i := range defaults.sheets {
+			if defaults.sheets[i].Stylesheet == HTMLStylesheet {
+				defaults.sheets[i].Stylesheet = sheet
+			}
+		}
+		HTMLStylesheet = sheet
+		userAgentSource = data.UserAgentStylesheet
+		defaults.Unlock()
+	}
+	return nil
+}
+
+// userAgentSource is the source of HTMLStylesheet.
+var userAgentSource = htmlStylesheet
+
+// WriteData writes the standard data as JSON, as a starting point for the
+// data given to LoadData.
+func WriteData(w io.Writer) error {
+	data := DataTables{
+		Properties:          Properties,
+		Colors:              make(map[string]string, len(namedColors)),
+		Prefixes:            VendorPrefixes,
+		UserAgentStylesheet: userAgentSource,
+	}
+	for name, c := range namedColors {
+		data.Colors[name] = fmt.Sprintf("#%02x%02x%02x%02x", c.R, c.G, c.B, c.A)
+		if c.A == 0xff {
+			data.Colors[name] = data.Colors[name][:7]
+		}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	return enc.Encode(data)
+}
@@ -0,0 +1,100 @@
+package css
+
+import (
+	"bytes"
+	"encoding/json"
+	imagecolor "image/color"
+	"strings"
+	"testing"
+)
+
+func TestLoadData(t *testing.T) {
+	defer restoreData()()
+	defer func() {
+		delete(Properties, "x-test-property")
+		delete(namedColors, "x-test-color")
+		delete(VendorPrefixes, "x-test-property")
+	}()
+
+	err := LoadData(strings.NewReader(`{
+		"properties": {"x-test-property": {"inherited": true, "initial": "auto"}},
+		"colors": {"X-Test-Color": "#12345678"},
+		"prefixes": {"x-test-property": ["-webkit-"]},
+		"userAgentStylesheet": "p { display: block; }"
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info := Properties["x-test-property"]; !info.Inherited || info.Initial != "auto" {
+		t.Fatalf("unexpected property %+v", info)
+	}
+	if c, err := ParseColor("x-test-color"); err != nil || c != (imagecolor.RGBA{0x12, 0x34, 0x56, 0x78}) {
+		t.Fatalf("unexpected color %v %v", c, err)
+	}
+	if p := VendorPrefixes["x-test-property"]; len(p) != 1 || p[0] != "-webkit-" {
+		t.Fatalf("unexpected prefixes %q", p)
+	}
+	if len(HTMLStylesheet.Rules) != 1 || HTMLStylesheet.Rules[0].Selector != "p" {
+		t.Fatalf("unexpected user agent stylesheet %v", HTMLStylesheet.Rules)
+	}
+	registered := false
+	for _, d := range DefaultStylesheets() {
+		registered = registered || d.Stylesheet == HTMLStylesheet
+	}
+	if !registered {
+		t.Fatal("the loaded user agent stylesheet should be registered")
+	}
+
+	for _, invalid := range []string{
+		`{"colors": {"x-test-bad": "red"}}`,
+		`{"userAgentStylesheet": "p { display: block;"}`,
+		`{"properties": []}`,
+	} {
+		if err := LoadData(strings.NewReader(invalid)); err == nil {
+			t.Fatalf("%s should be rejected", invalid)
+		}
+	}
+	if _, ok := namedColors["x-test-bad"]; ok {
+		t.Fatal("invalid data should not be loaded")
+	}
+}
+
+func TestWriteData(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteData(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var data DataTables
+	if err := json.Unmarshal(buf.Bytes(), &data); err != nil {
+		t.Fatal(err)
+	}
+	if data.Colors["rebeccapurple"] != "#663399" || data.Colors["transparent"] != "#00000000" {
+		t.Fatalf("unexpected colors %v", data.Colors)
+	}
+	if data.Properties["color"] != Properties["color"] || len(data.Prefixes) != len(VendorPrefixes) {
+		t.Fatal("unexpected properties or prefixes")
+	}
+	if data.UserAgentStylesheet != htmlStylesheet {
+		t.Fatal("the user agent stylesheet should be written")
+	}
+	defer restoreData()()
+	if err := LoadData(&buf); err != nil {
+		t.Fatalf("written data should load: %v", err)
+	}
+}
+
+// restoreData returns a function restoring the user agent stylesheet
+// replaced by LoadData.
+func restoreData() func() {
+	html, source := HTMLStylesheet, userAgentSource
+	return func() {
+		defaults.Lock()
+		defer defaults.Unlock()
+		for i := range defaults.sheets {
+			if defaults.sheets[i].Stylesheet == HTMLStylesheet {
+				defaults.sheets[i].Stylesheet = html
+			}
+		}
+		HTMLStylesheet, userAgentSource = html, source
+	}
+}
@@ -0,0 +1,128 @@
+package css
+
+import (
+	"regexp"
+	"strings"
+	"text/scanner"
+)
+
+var rImportant = regexp.MustCompile(`(?i)\s*!\s*important\s*$`)
+
+// Declaration is a single property and value pair of a rule.
+type Declaration struct {
+	Property string
+	// Value is the value without the !important flag.
+	Value string
+	// Important is set when the value was flagged with !important.
+	Important bool
+	// Raw is the value as it was written, including the flag.
+	Raw string
+	// Pos is the position of the property in the source.
+	Pos scanner.Position
+}
+
+// NewDeclaration returns the declaration of property with the raw value,
+// splitting off the !important flag.
+func NewDeclaration(property, raw string) Declaration {
+	d := Declaration{
+		Property: strings.TrimSpace(property),
+		Value:    strings.TrimSpace(raw),
+		Raw:      raw,
+	}
+	if loc := rImportant.FindStringIndex(d.Value); loc != nil {
+		d.Value = strings.TrimSpace(d.Value[:loc[0]])
+		d.Important = true
+	}
+	return d
+}
+
+// IsImportant reports whether the value, as found in the maps returned by
+// Unmarshal, is flagged with !important.
+func IsImportant(value string) bool {
+	return rImportant.MatchString(value)
+}
+
+// TrimImportant returns the value without its !important flag.
+func TrimImportant(value string) string {
+	if loc := rImportant.FindStringIndex(value); loc != nil {
+		return strings.TrimSpace(value[:loc[0]])
+	}
+	return strings.TrimSpace(value)
+}
+
+// String returns the declaration as css, without the trailing semicolon.
+func (d Declaration) String() string {
+	return d.Property + ": " + d.valueString()
+}
+
+// valueString returns the value with the !important flag, if set.
+func (d Declaration) valueString() string {
+	if d.Important {
+		return d.Value + " !important"
+	}
+	return d.Value
+}
+
+// Declarations are the declarations of a rule in document order,
+// including duplicates, so the cascade inside the rule can be resolved.
+type Declarations []Declaration
+
+// UnmarshalStyle parses a list of declarations without a selector, such
+// as the value of a style attribute. The list is parsed strictly: a
+// syntax error, such as a declaration without value or a brace, returns
+// a *ParseError.
+func UnmarshalStyle(b []byte) (Declarations, error) {
+	ds, err := parseDeclarationList(Tokenize(b), ParseOptions{Strict: true})
+	if err != nil {
+		return nil, err
+	}
+	return Declarations(ds), nil
+}
+
+// Get returns the declaration of the property that wins the cascade of
+// the rule: the last one flagged !important, or else the last one.
+// Properties are case insensitive.
+func (ds Declarations) Get(property string) (Declaration, bool) {
+	var (
+		winner Declaration
+		found  bool
+	)
+	for _, d := range ds {
+		if strings.EqualFold(d.Property, property) && (!found || d.Important || !winner.Important) {
+			winner, found = d, true
+		}
+	}
+	return winner, found
+}
+
+// Properties returns the properties of the declarations in order of
+// first appearance, without duplicates.
+func (ds Declarations) Properties() []string {
+	properties := []string{}
+	for _, d := range ds {
+		if !containsString(properties, d.Property) {
+			properties = append(properties, d.Property)
+		}
+	}
+	return properties
+}
+
+// String returns the declarations as the value of a style attribute.
+func (ds Declarations) String() string {
+	parts := make([]string, len(ds))
+	for i, d := range ds {
+		parts[i] = d.String()
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Map returns the winning value of every property in the format of the
+// maps returned by Unmarshal.
+func (ds Declarations) Map() map[string]string {
+	styles := make(map[string]string, len(ds))
+	for _, property := range ds.Properties() {
+		d, _ := ds.Get(property)
+		styles[property] = d.valueString()
+	}
+	return styles
+}
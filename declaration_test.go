@@ -0,0 +1,130 @@
+package css
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUnmarshalDeclarations(t *testing.T) {
+	ex1 := `rule {
+	color: red !important;
+	color: blue;
+}
+rule {
+	margin: 0;
+}`
+
+	css, err := UnmarshalDeclarations([]byte(ex1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	decls := css["rule"]
+	if len(decls) != 3 {
+		t.Fatalf("expected 3 declarations, got %d", len(decls))
+	}
+
+	first := decls[0]
+	if first.Property != "color" || first.Value != "red" || !first.Important {
+		t.Fatalf("unexpected first declaration %+v", first)
+	}
+	if first.Raw != "red !important" {
+		t.Fatalf("expected raw value 'red !important', got %q", first.Raw)
+	}
+	if first.Pos.Line != 2 || first.Pos.Column != 2 {
+		t.Fatalf("wrong position %v", first.Pos)
+	}
+	if decls[1].Important || decls[2].Property != "margin" {
+		t.Fatalf("unexpected declarations %+v", decls[1:])
+	}
+
+	_, err = ParseDeclarationsWithOptions(Tokenize([]byte("rule {\n\tcolor: ;\n}")), ParseOptions{Strict: true})
+	if err == nil || err.Error() != `2:2: unexpected "color", expected value` {
+		t.Fatalf("expected a strict parse error, got %v", err)
+	}
+	var warnings []string
+	_, err = ParseDeclarationsWithOptions(Tokenize([]byte("rule {\n\tcolor: ;\n}")), ParseOptions{
+		OnWarning: func(w Warning) { warnings = append(warnings, w.Message) },
+	})
+	if err != nil || len(warnings) != 1 {
+		t.Fatalf("expected a warning, got %v, %v", warnings, err)
+	}
+}
+
+func TestDeclarations(t *testing.T) {
+	ds, err := UnmarshalStyle([]byte("color: red !important; margin: 0; color: blue; Margin: 1px;\n\tpadding: 2px"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ds) != 5 {
+		t.Fatalf("expected 5 declarations, got %v", ds)
+	}
+	if d, ok := ds.Get("color"); !ok || d.Value != "red" || !d.Important {
+		t.Errorf("expected the important color to win, got %v", d)
+	}
+	if d, ok := ds.Get("margin"); !ok || d.Value != "1px" {
+		t.Errorf("expected the last margin to win, got %v", d)
+	}
+	if _, ok := ds.Get("top"); ok {
+		t.Error("unexpected top")
+	}
+	if properties := ds.Properties(); !reflect.DeepEqual(properties, []string{"color", "margin", "Margin", "padding"}) {
+		t.Errorf("unexpected properties %v", properties)
+	}
+	if m := ds.Map(); m["color"] != "red !important" || m["padding"] != "2px" {
+		t.Errorf("unexpected map %v", m)
+	}
+	if pos := ds[1].Pos; pos.Offset != 23 || pos.Line != 1 || pos.Column != 24 {
+		t.Errorf("unexpected position %v", pos)
+	}
+	if pos := ds[4].Pos; pos.Line != 2 || pos.Column != 2 {
+		t.Errorf("unexpected position %v", pos)
+	}
+
+	if ds, err := UnmarshalStyle(nil); err != nil || len(ds) != 0 {
+		t.Errorf("expected no declarations, got %v, %v", ds, err)
+	}
+}
+
+func TestUnmarshalStyleErrors(t *testing.T) {
+	tests := []struct{ in, err string }{
+		{"color: red } b { color: blue", `1:12: unexpected "}", expected declaration`},
+		{"color: red; } b { color: blue; }", `1:13: unexpected "}", expected declaration`},
+		{"color: red;\nmargin", `2:1: unexpected "margin", expected property`},
+		{"color: ;", `1:1: unexpected "color", expected value`},
+	}
+	for _, test := range tests {
+		ds, err := UnmarshalStyle([]byte(test.in))
+		if err == nil || err.Error() != test.err {
+			t.Errorf("%q: expected error %q, got %v, %v", test.in, test.err, ds, err)
+		}
+		if _, ok := err.(*ParseError); !ok {
+			t.Errorf("%q: expected a *ParseError, got %T", test.in, err)
+		}
+	}
+}
+
+func TestIsImportant(t *testing.T) {
+	tests := []struct {
+		value     string
+		important bool
+		trimmed   string
+	}{
+		{"red !important", true, "red"},
+		{"red!important", true, "red"},
+		{"1px solid ! IMPORTANT ", true, "1px solid"},
+		{"red", false, "red"},
+		{`"!important"`, false, `"!important"`},
+		{"important", false, "important"},
+	}
+	for _, test := range tests {
+		if IsImportant(test.value) != test.important || TrimImportant(test.value) != test.trimmed {
+			t.Errorf("%q: expected %v and %q, got %v and %q", test.value, test.important, test.trimmed,
+				IsImportant(test.value), TrimImportant(test.value))
+		}
+	}
+
+	css, _ := Unmarshal([]byte("a {\n\tcolor: red !important;\n}\n"))
+	if !IsImportant(css["a"]["color"]) || TrimImportant(css["a"]["color"]) != "red" {
+		t.Errorf("unexpected value %q", css["a"]["color"])
+	}
+}
@@ -0,0 +1,193 @@
+package css
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// Decoder reads rules from a stream one at a time. Unlike Unmarshal, it
+// doesn't read the whole source nor build the token list before parsing,
+// so memory use stays bounded by the largest rule on big stylesheets.
+type Decoder struct {
+	r *errorReader
+	t *tokenizer
+	p *blockParser
+	// done is set once the end of the source was reached.
+	done bool
+}
+
+// NewDecoder returns a decoder reading from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return NewDecoderWithOptions(r, ParseOptions{})
+}
+
+// NewDecoderWithOptions is like NewDecoder, reporting warnings as
+// configured in opts. Metrics are not reported by decoders.
+func NewDecoderWithOptions(r io.Reader, opts ParseOptions) *Decoder {
+	er := &errorReader{r: r}
+	return &Decoder{
+		r: er,
+		t: newTokenizer(&commentBlanker{r: bufio.NewReader(er)}),
+		p: newBlockParser(opts),
+	}
+}
+
+// Next returns the selector and the declarations of the next rule in
+// document order, or io.EOF after the last one. Rules with the same
+// selector are not merged. A strict decoder returns the *ParseError of
+// the source after the rules preceding it.
+func (d *Decoder) Next() (Rule, Declarations, error) {
+	for len(d.p.blocks) == 0 {
+		if d.p.err != nil {
+			return "", nil, d.p.err
+		}
+		if d.done {
+			if d.r.err != nil {
+				return "", nil, d.r.err
+			}
+			return "", nil, io.EOF
+		}
+		tok, err := d.t.next()
+		if err != nil {
+			d.p.finish()
+			d.done = true
+			continue
+		}
+		d.p.feed(tok)
+	}
+	b := d.p.blocks[0]
+	d.p.blocks = d.p.blocks[1:]
+	return Rule(b.selector), b.styles, nil
+}
+
+// errorReader keeps the first error other than io.EOF of the reader,
+// which the scanner would otherwise only report as the end of the source.
+type errorReader struct {
+	r   io.Reader
+	err error
+}
+
+func (r *errorReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if err != nil && err != io.EOF && r.err == nil {
+		r.err = err
+	}
+	return n, err
+}
+
+// commentBlanker replaces the comments of the stream with spaces while it
+// is read, keeping line breaks, like blankComments does for a whole
+// source. Strings and unquoted urls are skipped the same way, their state
+// being kept between reads.
+type commentBlanker struct {
+	r     *bufio.Reader
+	state blankerState
+	// quote is the quote of a string, star is set after a star in a
+	// comment, and escaped after a backslash outside of comments.
+	quote   byte
+	star    bool
+	escaped bool
+	// prev is the previous byte of the stream.
+	prev byte
+	// pending are the bytes read ahead that still have to be written.
+	pending []byte
+}
+
+type blankerState int
+
+const (
+	blankerText blankerState = iota
+	blankerString
+	blankerComment
+	// blankerURLStart is after "url(" and blankerURL in an unquoted url.
+	blankerURLStart
+	blankerURL
+)
+
+func (c *commentBlanker) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		if len(c.pending) > 0 {
+			p[n] = c.pending[0]
+			c.pending = c.pending[1:]
+			n++
+			continue
+		}
+		b, err := c.r.ReadByte()
+		if err != nil {
+			if n > 0 {
+				return n, nil
+			}
+			return 0, err
+		}
+		p[n] = c.blank(b)
+		c.prev = b
+		n++
+	}
+	return n, nil
+}
+
+// blank returns the byte to write for b, reading ahead when b may start a
+// comment or a url.
+func (c *commentBlanker) blank(b byte) byte {
+	if c.escaped {
+		c.escaped = false
+		return b
+	}
+	switch c.state {
+	case blankerComment:
+		if c.star && b == '/' {
+			c.state = blankerText
+		}
+		c.star = b == '*'
+		if b != '\n' {
+			b = ' '
+		}
+	case blankerString:
+		switch b {
+		case '\\':
+			c.escaped = true
+		case c.quote, '\n':
+			// a string ends at its quote or at a line break
+			c.state = blankerText
+		}
+	case blankerURLStart:
+		switch b {
+		case ' ', '\t', '\n', '\r', '\f':
+		case '"', '\'':
+			c.state, c.quote = blankerString, b
+		default:
+			c.state = blankerURL
+			return c.blank(b)
+		}
+	case blankerURL:
+		switch b {
+		case '\\':
+			c.escaped = true
+		case ')':
+			c.state = blankerText
+		}
+	default:
+		switch {
+		case b == '"' || b == '\'':
+			c.state, c.quote = blankerString, b
+		case b == '\\':
+			c.escaped = true
+		case b == '/':
+			if next, err := c.r.Peek(1); err == nil && next[0] == '*' {
+				c.r.ReadByte()
+				c.state, c.star = blankerComment, false
+				c.pending = append(c.pending, ' ')
+				b = ' '
+			}
+		case (b == 'u' || b == 'U') && !isNameByte(c.prev):
+			if next, err := c.r.Peek(3); err == nil && strings.EqualFold(string(next), "rl(") {
+				c.pending = append(c.pending, next...)
+				c.r.Discard(3)
+				c.state = blankerURLStart
+			}
+		}
+	}
+	return b
+}
@@ -0,0 +1,120 @@
+package css
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestDecoder(t *testing.T) {
+	src := `/* header
+   comment */
+body {
+	margin: 0; /* reset */
+	color: red !important;
+}
+@media print {
+	nav {
+		display: none;
+	}
+}
+body {
+	margin: 1px;
+}
+/**/a/* x */
+{
+	background: url(a.png);
+}
+`
+	var (
+		d        = NewDecoder(iotest.OneByteReader(strings.NewReader(src)))
+		expected = OrderedRules([]byte(src))
+		decoded  = []OrderedRule{}
+	)
+	for {
+		rule, decls, err := d.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded = append(decoded, OrderedRule{rule, decls})
+	}
+	if len(decoded) != 4 || !reflect.DeepEqual(decoded, expected) {
+		t.Errorf("expected %v, got %v", expected, decoded)
+	}
+	if _, _, err := d.Next(); err != io.EOF {
+		t.Errorf("expected io.EOF again, got %v", err)
+	}
+}
+
+func TestDecoderError(t *testing.T) {
+	boom := errors.New("boom")
+	r := io.MultiReader(strings.NewReader("a {\n\ttop: 0;\n}\n"), iotest.ErrReader(boom))
+	d := NewDecoder(r)
+	if rule, _, err := d.Next(); err != nil || rule != "a" {
+		t.Fatalf("expected rule a, got %q, %v", rule, err)
+	}
+	if _, _, err := d.Next(); err != boom {
+		t.Errorf("expected the read error, got %v", err)
+	}
+}
+
+func TestCommentBlanker(t *testing.T) {
+	for _, src := range []string{
+		"a/**/b /* c\n*/ d /*/ e */ f/",
+		`a { content: "/* x"; background: url(img/*.png) } b /* c */ { content: 'a\'/*' }`,
+		"a { background: URL( \"/*\" ) url(a\\)/*.png) u/**/rl(/*) } /* end */",
+		"a { content: \"/* x\n/* y */ }",
+	} {
+		out, err := io.ReadAll(iotest.OneByteReader(&commentBlanker{r: bufio.NewReader(strings.NewReader(src))}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected := blankComments([]byte(src)); !bytes.Equal(out, expected) {
+			t.Errorf("expected %q, got %q", expected, out)
+		}
+	}
+}
+
+func TestDecoderCommentsInStrings(t *testing.T) {
+	src := `a { content: "/* x"; background: url(img/*.png) } b { color: red } c { color: blue }`
+	expected, err := Unmarshal([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := map[Rule]map[string]string{}
+	d := NewDecoder(iotest.OneByteReader(strings.NewReader(src)))
+	for {
+		rule, decls, err := d.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded[rule] = decls.Map()
+	}
+	if len(decoded) != 3 || !reflect.DeepEqual(decoded, expected) {
+		t.Errorf("expected %v, got %v", expected, decoded)
+	}
+}
+
+func BenchmarkDecoder(b *testing.B) {
+	src := bytes.Repeat([]byte("div.item {\n\tmargin: 0 auto;\n\tcolor: #333;\n}\n"), 1000)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		d := NewDecoder(bytes.NewReader(src))
+		for {
+			if _, _, err := d.Next(); err != nil {
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package css
+
+import (
+	"strings"
+	"sync"
+)
+
+// DefaultStylesheet is a stylesheet applied before the author stylesheet
+// of every Cascade.
+type DefaultStylesheet struct {
+	Stylesheet *Stylesheet
+	Origin     Origin
+}
+
+var defaults struct {
+	sync.Mutex
+	sheets []DefaultStylesheet
+}
+
+func init() {
+	sheet, err := ParseStylesheet(strings.NewReader(htmlStylesheet))
+	if err != nil {
+		panic("css: invalid HTML stylesheet: " + err.Error())
+	}
+	HTMLStylesheet = sheet
+	RegisterDefaultStylesheet(HTMLStylesheet, OriginUserAgent)
+}
+
+// HTMLStylesheet is a minimal user agent stylesheet for HTML, MathML and
+// form controls. It sets the display, margins and fonts of the common
+// elements and is registered as a default stylesheet.
+var HTMLStylesheet *Stylesheet
+
+// RegisterDefaultStylesheet registers a stylesheet of the origin, usually
+// OriginUserAgent or OriginUser, for the cascades created afterwards.
+// Stylesheets are applied in order of registration.
+func RegisterDefaultStylesheet(sheet *Stylesheet, origin Origin) {
+	defaults.Lock()
+	defer defaults.Unlock()
+	defaults.sheets = append(defaults.sheets, DefaultStylesheet{sheet, origin})
+}
+
+// ResetDefaultStylesheets unregisters every default stylesheet, including
+// HTMLStylesheet.
+func ResetDefaultStylesheets() {
+	defaults.Lock()
+	defer defaults.Unlock()
+	defaults.sheets = nil
+}
+
+// DefaultStylesheets returns the registered default stylesheets in order
+// of registration.
+func DefaultStylesheets() []DefaultStylesheet {
+	defaults.Lock()
+	defer defaults.Unlock()
+	return append([]DefaultStylesheet(nil), defaults.sheets...)
+}
+
+// htmlStylesheet is the source of HTMLStylesheet. Margins are set with
+// longhands so the computed styles have them.
+const htmlStylesheet = `
+address, article, aside, blockquote, body, dd, details, dialog, div, dl, dt,
+fieldset, figcaption, figure, footer, form, h1, h2, h3, h4, h5, h6, header,
+hgroup, hr, html, legend, main, menu, nav, ol, p, pre, search, section,
+summary, ul {
+	display: block;
+}
+area, base, datalist, head, link, meta, noscript, script, style, template,
+title, [hidden] {
+	display: none;
+}
+li {
+	display: list-item;
+}
+table {
+	display: table;
+	border-collapse: separate;
+}
+caption {
+	display: table-caption;
+	text-align: center;
+}
+thead {
+	display: table-header-group;
+}
+tbody {
+	display: table-row-group;
+}
+tfoot {
+	display: table-footer-group;
+}
+tr {
+	display: table-row;
+}
+td, th {
+	display: table-cell;
+}
+th {
+	font-weight: bold;
+}
+body {
+	margin-top: 8px;
+	margin-right: 8px;
+	margin-bottom: 8px;
+	margin-left: 8px;
+}
+blockquote, dl, figure, hr, ol, p, pre, ul {
+	margin-top: 1em;
+	margin-bottom: 1em;
+}
+blockquote, figure {
+	margin-left: 40px;
+	margin-right: 40px;
+}
+dd {
+	margin-left: 40px;
+}
+ol, ul, menu {
+	padding-left: 40px;
+}
+h1 {
+	font-size: 2em;
+	margin-top: 0.67em;
+	margin-bottom: 0.67em;
+}
+h2 {
+	font-size: 1.5em;
+	margin-top: 0.83em;
+	margin-bottom: 0.83em;
+}
+h3 {
+	font-size: 1.17em;
+	margin-top: 1em;
+	margin-bottom: 1em;
+}
+h4 {
+	margin-top: 1.33em;
+	margin-bottom: 1.33em;
+}
+h5 {
+	font-size: 0.83em;
+	margin-top: 1.67em;
+	margin-bottom: 1.67em;
+}
+h6 {
+	font-size: 0.67em;
+	margin-top: 2.33em;
+	margin-bottom: 2.33em;
+}
+h1, h2, h3, h4, h5, h6, b, strong {
+	font-weight: bold;
+}
+i, cite, em, var, dfn {
+	font-style: italic;
+}
+code, kbd, pre, samp, tt {
+	font-family: monospace;
+}
+pre {
+	white-space: pre;
+}
+a {
+	color: #0000ee;
+	text-decoration: underline;
+	cursor: pointer;
+}
+u, ins {
+	text-decoration: underline;
+}
+s, del, strike {
+	text-decoration: line-through;
+}
+sub {
+	vertical-align: sub;
+	font-size: smaller;
+}
+sup {
+	vertical-align: super;
+	font-size: smaller;
+}
+small {
+	font-size: smaller;
+}
+input, button, select, textarea, meter, progress {
+	display: inline-block;
+	font-size: 13.333px;
+	font-family: sans-serif;
+	letter-spacing: normal;
+	word-spacing: normal;
+	text-transform: none;
+	text-indent: 0;
+	text-align: start;
+}
+input, textarea, select {
+	margin-top: 0;
+	margin-right: 0;
+	margin-bottom: 0;
+	margin-left: 0;
+}
+textarea {
+	white-space: pre-wrap;
+	font-family: monospace;
+}
+button {
+	text-align: center;
+	cursor: default;
+}
+fieldset {
+	margin-left: 2px;
+	margin-right: 2px;
+	padding-top: 0.35em;
+	padding-right: 0.75em;
+	padding-bottom: 0.625em;
+	padding-left: 0.75em;
+}
+math {
+	font-family: math;
+	font-style: normal;
+	font-weight: normal;
+}
+mrow, mfrac, msqrt, mroot, msub, msup, msubsup, munder, mover, munderover, mtable {
+	display: inline-block;
+}
+mtr {
+	display: table-row;
+}
+mtd {
+	display: table-cell;
+}
+`
@@ -0,0 +1,61 @@
+package css
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHTMLStylesheet(t *testing.T) {
+	c := NewCascade(&Stylesheet{})
+
+	tests := []struct {
+		el       *SimpleElement
+		property string
+		want     string
+	}{
+		{&SimpleElement{Tag: "p"}, "display", "block"},
+		{&SimpleElement{Tag: "p"}, "margin-top", "1em"},
+		{&SimpleElement{Tag: "span"}, "display", "inline"},
+		{&SimpleElement{Tag: "div", Attrs: map[string]string{"hidden": ""}}, "display", "none"},
+		{&SimpleElement{Tag: "input"}, "display", "inline-block"},
+		{&SimpleElement{Tag: "body"}, "margin-left", "8px"},
+		{&SimpleElement{Tag: "math", NamespaceURI: MathMLNamespace}, "font-style", "normal"},
+	}
+	for _, test := range tests {
+		style := c.ComputedStyle(test.el, nil, nil)
+		if got := style[test.property]; got != test.want {
+			t.Errorf("%s %s: got %q, want %q", test.el.Tag, test.property, got, test.want)
+		}
+	}
+}
+
+func TestRegisterDefaultStylesheet(t *testing.T) {
+	saved := DefaultStylesheets()
+	defer func() {
+		ResetDefaultStylesheets()
+		for _, d := range saved {
+			RegisterDefaultStylesheet(d.Stylesheet, d.Origin)
+		}
+	}()
+
+	user, err := ParseStylesheet(strings.NewReader("p {\n\tdisplay: flex;\n\tcolor: red !important;\n}\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	RegisterDefaultStylesheet(user, OriginUser)
+	author, err := ParseStylesheet(strings.NewReader("p {\n\tcolor: blue;\n}\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	style := NewCascade(author).ComputedStyle(&SimpleElement{Tag: "p"}, nil, nil)
+	if style["display"] != "flex" || style["color"] != "red" {
+		t.Fatalf("user stylesheet: got display %q color %q", style["display"], style["color"])
+	}
+
+	ResetDefaultStylesheets()
+	style = NewCascade(author).ComputedStyle(&SimpleElement{Tag: "p"}, nil, nil)
+	if style["display"] != "inline" || style["color"] != "blue" {
+		t.Fatalf("no default stylesheets: got display %q color %q", style["display"], style["color"])
+	}
+}
@@ -0,0 +1,192 @@
+package css
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// EmailSupport is a table of the css that email clients don't support,
+// used by LintEmail. Every map goes from a construct to the clients
+// lacking support for it. Keys are lower case.
+type EmailSupport struct {
+	// Properties are property names, such as "position", or properties
+	// with a keyword value, such as "display: flex".
+	Properties map[string][]string
+	// Selectors are selector features: pseudo-classes such as ":hover",
+	// pseudo-elements such as "::before", the combinators ">", "+" and
+	// "~", and "*", "#" and "[]" for universal, id and attribute
+	// selectors.
+	Selectors map[string][]string
+	// AtRules are at-rule names without the @, such as "media".
+	AtRules map[string][]string
+	// Functions are value functions with parentheses, such as "var()".
+	Functions map[string][]string
+}
+
+// EmailClients is the support of the major email clients: Gmail, Outlook
+// for Windows and Yahoo Mail. It only lists the most common gaps, callers
+// needing other clients or more details pass their own table.
+var EmailClients = EmailSupport{
+	Properties: map[string][]string{
+		"animation":        {"Gmail", "Outlook", "Yahoo Mail"},
+		"background-image": {"Outlook"},
+		"box-shadow":       {"Gmail", "Outlook"},
+		"display: flex":    {"Outlook", "Yahoo Mail"},
+		"display: grid":    {"Gmail", "Outlook", "Yahoo Mail"},
+		"float":            {"Outlook"},
+		"max-width":        {"Outlook"},
+		"object-fit":       {"Gmail", "Outlook"},
+		"opacity":          {"Outlook"},
+		"position":         {"Gmail", "Outlook", "Yahoo Mail"},
+		"transform":        {"Gmail", "Outlook"},
+		"transition":       {"Gmail", "Outlook"},
+	},
+	Selectors: map[string][]string{
+		"#":          {"Gmail"},
+		"*":          {"Gmail"},
+		"+":          {"Outlook"},
+		"::after":    {"Gmail", "Outlook"},
+		"::before":   {"Gmail", "Outlook"},
+		":hover":     {"Outlook"},
+		":nth-child": {"Gmail", "Outlook"},
+		"[]":         {"Gmail", "Outlook"},
+		"~":          {"Outlook"},
+	},
+	AtRules: map[string][]string{
+		"font-face": {"Gmail", "Outlook"},
+		"import":    {"Gmail", "Outlook", "Yahoo Mail"},
+		"keyframes": {"Gmail", "Outlook", "Yahoo Mail"},
+		"media":     {"Outlook"},
+		"supports":  {"Gmail", "Outlook", "Yahoo Mail"},
+	},
+	Functions: map[string][]string{
+		"calc()":            {"Outlook"},
+		"linear-gradient()": {"Outlook"},
+		"var()":             {"Gmail", "Outlook"},
+	},
+}
+
+var rFunction = regexp.MustCompile(`([a-zA-Z-]+)\(`)
+
+// LintEmail reports the constructs of the stylesheet that the email
+// clients of the support table don't handle. The severity of an issue is
+// the number of clients lacking support.
+func LintEmail(sheet *Stylesheet, support EmailSupport) []Issue {
+	var (
+		issues = []Issue{}
+		seen   = map[string]bool{}
+		report = func(kind, construct string, clients []string, issue Issue) {
+			issue.Lint = "email"
+			issue.Message = fmt.Sprintf("%s %s is not supported by %s", kind, construct, strings.Join(clients, ", "))
+			issue.Severity = len(clients)
+			issues = append(issues, issue)
+		}
+		atRule = func(prelude string, issue Issue) {
+			name := atRuleName(prelude)
+			if clients, ok := support.AtRules[name]; ok && !seen[prelude] {
+				seen[prelude] = true
+				report("at-rule", "@"+name, clients, issue)
+			}
+		}
+	)
+
+	for _, s := range sheet.Statements {
+		atRule(string(s.Prelude), Issue{Selector: s.Prelude, Pos: s.Pos})
+	}
+	for _, r := range sheet.Rules {
+		inKeyframes := false
+		for _, at := range r.AtRules {
+			atRule(string(at), Issue{Selector: at, Pos: r.Pos})
+			inKeyframes = inKeyframes || strings.HasSuffix(atRuleName(string(at)), "keyframes")
+		}
+		if strings.HasPrefix(string(r.Selector), "@") {
+			atRule(string(r.Selector), Issue{Selector: r.Selector, Pos: r.Pos})
+		} else if !inKeyframes {
+			for _, feature := range selectorFeatures(string(r.Selector)) {
+				if clients, ok := support.Selectors[feature]; ok {
+					report("selector", feature, clients, Issue{Selector: r.Selector, Pos: r.Pos})
+				}
+			}
+		}
+
+		for _, d := range r.Declarations {
+			property := strings.ToLower(d.Property)
+			issue := Issue{Selector: r.Selector, Property: d.Property, Pos: d.Pos}
+			if clients, ok := support.Properties[property]; ok {
+				report("property", property, clients, issue)
+			}
+			value := property + ": " + strings.ToLower(strings.TrimSpace(d.Value))
+			if clients, ok := support.Properties[value]; ok {
+				report("value", value, clients, issue)
+			}
+			functions := map[string]bool{}
+			for _, m := range rFunction.FindAllStringSubmatch(d.Value, -1) {
+				name := strings.ToLower(m[1]) + "()"
+				if clients, ok := support.Functions[name]; ok && !functions[name] {
+					functions[name] = true
+					report("function", name, clients, issue)
+				}
+			}
+		}
+	}
+	return issues
+}
+
+// selectorFeatures returns the features of the selector listed in
+// EmailSupport.Selectors, sorted and without duplicates.
+func selectorFeatures(selector string) []string {
+	set := map[string]bool{}
+	for i := 0; i < len(selector); i++ {
+		switch c := selector[i]; c {
+		case '"', '\'':
+			i = scanString(selector, i) - 1
+		case '\\':
+			i++
+		case '(':
+			// skip the arguments of functional pseudo-classes
+			for depth := 0; i < len(selector); i++ {
+				if selector[i] == '(' {
+					depth++
+				} else if selector[i] == ')' {
+					if depth--; depth == 0 {
+						break
+					}
+				}
+			}
+		case '>', '+', '~', '*':
+			set[string(c)] = true
+		case '#':
+			set["#"] = true
+		case '[':
+			set["[]"] = true
+			for i < len(selector) && selector[i] != ']' {
+				if selector[i] == '"' || selector[i] == '\'' {
+					i = scanString(selector, i) - 1
+				}
+				i++
+			}
+		case ':':
+			prefix := ":"
+			if i+1 < len(selector) && selector[i+1] == ':' {
+				prefix = "::"
+				i++
+			}
+			end := scanName(selector, i+1)
+			name := strings.ToLower(selector[i+1 : end])
+			if legacyPseudoElements[name] {
+				prefix = "::"
+			}
+			set[prefix+name] = true
+			i = end - 1
+		}
+	}
+
+	features := make([]string, 0, len(set))
+	for f := range set {
+		features = append(features, f)
+	}
+	sort.Strings(features)
+	return features
+}
@@ -0,0 +1,71 @@
+package css
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLintEmail(t *testing.T) {
+	sheet, err := ParseStylesheet(strings.NewReader(`@import url(fonts.css);
+td > p {
+	position: absolute;
+	display: flex;
+	width: calc(100% - var(--gap, calc(2px)));
+}
+li:nth-child(2n+1)
+{
+	color: red;
+}
+a:hover
+{
+	color: blue;
+}
+@media (max-width: 600px) {
+	p {
+		display: block;
+	}
+	td {
+		display: block;
+	}
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := []string{}
+	for _, issue := range LintEmail(sheet, EmailClients) {
+		got = append(got, issue.Message)
+	}
+	want := []string{
+		"at-rule @import is not supported by Gmail, Outlook, Yahoo Mail",
+		"property position is not supported by Gmail, Outlook, Yahoo Mail",
+		"value display: flex is not supported by Outlook, Yahoo Mail",
+		"function calc() is not supported by Outlook",
+		"function var() is not supported by Gmail, Outlook",
+		"selector :nth-child is not supported by Gmail, Outlook",
+		"selector :hover is not supported by Outlook",
+		"at-rule @media is not supported by Outlook",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestSelectorFeatures(t *testing.T) {
+	tests := []struct {
+		selector string
+		want     []string
+	}{
+		{"p", []string{}},
+		{"#a > .b + *", []string{"#", "*", "+", ">"}},
+		{"a[href='a>b']:first-child:after", []string{"::after", ":first-child", "[]"}},
+		{"li:not(.a ~ .b)::before", []string{"::before", ":not"}},
+	}
+	for _, test := range tests {
+		if got := selectorFeatures(test.selector); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %q, want %q", test.selector, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package css
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"text/scanner"
+)
+
+// Feature is a modern css feature detected by UsedFeatures.
+type Feature struct {
+	Name string
+	// Supports is the @supports condition testing the feature, or an
+	// empty string if the feature can't be tested with @supports.
+	Supports string
+}
+
+// FeatureUse is a feature used by a stylesheet.
+type FeatureUse struct {
+	Feature
+	// Count is the number of uses and Pos the position of the first one.
+	Count int
+	Pos   scanner.Position
+}
+
+// The features detected by UsedFeatures.
+var (
+	FeatureNesting        = Feature{"nesting", "selector(&)"}
+	FeatureHas            = Feature{":has()", "selector(:has(a))"}
+	FeatureIsWhere        = Feature{":is() and :where()", "selector(:is(a))"}
+	FeatureContainerQuery = Feature{"container queries", "(container-type: inline-size)"}
+	FeatureCascadeLayers  = Feature{"cascade layers", ""}
+	FeatureOklch          = Feature{"oklch() and oklab()", "(color: oklch(0 0 0))"}
+	FeatureLabLch         = Feature{"lab() and lch()", "(color: lab(0 0 0))"}
+	FeatureColorMix       = Feature{"color-mix()", "(color: color-mix(in srgb, red, red))"}
+	FeatureSubgrid        = Feature{"subgrid", "(grid-template-columns: subgrid)"}
+	FeatureCustomProperty = Feature{"custom properties", "(--a: 0)"}
+)
+
+var rFeatureColorFunctions = regexp.MustCompile(`(?i)\b(oklch|oklab|lab|lch|color-mix)\(`)
+
+// UsedFeatures returns the modern features used by the stylesheet, in
+// order of first use: nesting, :has(), :is() and :where(), container
+// queries, cascade layers, the oklch(), oklab(), lab(), lch() and
+// color-mix() color functions, subgrid and custom properties.
+func UsedFeatures(b []byte) []FeatureUse {
+	var (
+		uses  = []FeatureUse{}
+		index = map[string]int{}
+		use   = func(f Feature, offset int) {
+			i, ok := index[f.Name]
+			if !ok {
+				i = len(uses)
+				index[f.Name] = i
+				uses = append(uses, FeatureUse{Feature: f, Pos: offsetPosition(b, offset)})
+			}
+			uses[i].Count++
+		}
+		parents = []RuleSource{}
+	)
+
+	blank := blankComments(b)
+	for _, s := range RuleSources(b) {
+		for len(parents) > 0 && parents[len(parents)-1].End <= s.Start {
+			parents = parents[:len(parents)-1]
+		}
+		prelude := string(s.Prelude)
+		name := atRuleName(prelude)
+		switch {
+		case name == "container":
+			use(FeatureContainerQuery, s.Start)
+		case name == "layer":
+			use(FeatureCascadeLayers, s.Start)
+		case name == "":
+			if len(parents) > 0 && atRuleName(string(parents[len(parents)-1].Prelude)) == "" {
+				use(FeatureNesting, s.Start)
+			}
+			lower := strings.ToLower(prelude)
+			if strings.Contains(lower, ":has(") {
+				use(FeatureHas, s.Start)
+			}
+			if strings.Contains(lower, ":is(") || strings.Contains(lower, ":where(") {
+				use(FeatureIsWhere, s.Start)
+			}
+		}
+		if s.End > s.Start && blank[s.End-1] == '}' {
+			parents = append(parents, s)
+		}
+	}
+
+	sheet, _ := UnmarshalStylesheet(b)
+	for _, r := range sheet.Rules {
+		for _, d := range r.Declarations {
+			property := strings.ToLower(d.Property)
+			value := strings.ToLower(d.Value)
+			if strings.HasPrefix(property, "--") || strings.Contains(value, "var(--") {
+				use(FeatureCustomProperty, d.Pos.Offset)
+			}
+			if property == "container-type" || property == "container" {
+				use(FeatureContainerQuery, d.Pos.Offset)
+			}
+			if strings.HasPrefix(property, "grid-template") && strings.Contains(value, "subgrid") {
+				use(FeatureSubgrid, d.Pos.Offset)
+			}
+			for _, m := range rFeatureColorFunctions.FindAllStringSubmatch(value, -1) {
+				switch m[1] {
+				case "oklch", "oklab":
+					use(FeatureOklch, d.Pos.Offset)
+				case "lab", "lch":
+					use(FeatureLabLch, d.Pos.Offset)
+				case "color-mix":
+					use(FeatureColorMix, d.Pos.Offset)
+				}
+			}
+		}
+	}
+	return uses
+}
+
+// SupportsCondition returns the @supports condition testing all of the
+// features that can be tested, or an empty string if none can.
+func SupportsCondition(uses []FeatureUse) string {
+	conditions := []string{}
+	for _, u := range uses {
+		if u.Supports != "" && !containsString(conditions, u.Supports) {
+			conditions = append(conditions, u.Supports)
+		}
+	}
+	return strings.Join(conditions, " and ")
+}
+
+// GuardSupports wraps the stylesheet in an @supports rule testing the
+// features it uses, so browsers lacking any of them ignore it as a whole,
+// for progressive enhancement. Leading @charset and @import statements
+// stay in front, like with WrapMedia. The stylesheet is returned
+// unchanged if it uses no feature @supports can test.
+func GuardSupports(b []byte) []byte {
+	condition := SupportsCondition(UsedFeatures(b))
+	if condition == "" {
+		return b
+	}
+
+	var buf bytes.Buffer
+	rest := blankComments(b)
+	start := 0
+	for {
+		loc := rLeadingStatement.FindIndex(rest[start:])
+		if loc == nil {
+			break
+		}
+		buf.WriteString(strings.TrimSpace(string(b[start+loc[0]:start+loc[1]])) + "\n")
+		start += loc[1]
+	}
+
+	buf.WriteString("@supports " + condition + " {\n")
+	buf.Write(bytes.TrimSpace(b[start:]))
+	buf.WriteString("\n}\n")
+	return buf.Bytes()
+}
@@ -0,0 +1,70 @@
+package css
+
+import (
+	"strings"
+	"testing"
+)
+
+const featuresExample = `@charset "utf-8";
+@import url(base.css);
+@layer base;
+div.card {
+	color: oklch(70% 0.1 200);
+	container-type: inline-size;
+	& p {
+		margin: 0;
+	}
+}
+@container (width > 400px) {
+	div.title {
+		background: color-mix(in srgb, var(--brand), white);
+	}
+}
+article:has(img)
+{
+	display: grid;
+	grid-template-columns: subgrid;
+}
+`
+
+func TestUsedFeatures(t *testing.T) {
+	uses := UsedFeatures([]byte(featuresExample))
+	expected := []struct {
+		name  string
+		count int
+		line  int
+	}{
+		{"cascade layers", 1, 3},
+		{"nesting", 1, 7},
+		{"container queries", 2, 11},
+		{":has()", 1, 16},
+		{"oklch() and oklab()", 1, 5},
+		{"custom properties", 1, 13},
+		{"color-mix()", 1, 13},
+		{"subgrid", 1, 19},
+	}
+	if len(uses) != len(expected) {
+		t.Fatalf("expected %d features, got %v", len(expected), uses)
+	}
+	for i, e := range expected {
+		if u := uses[i]; u.Name != e.name || u.Count != e.count || u.Pos.Line != e.line {
+			t.Errorf("expected %v, got %+v", e, u)
+		}
+	}
+}
+
+func TestGuardSupports(t *testing.T) {
+	out := string(GuardSupports([]byte(featuresExample)))
+	expected := `@charset "utf-8";
+@import url(base.css);
+@supports selector(&) and (container-type: inline-size) and selector(:has(a)) and (color: oklch(0 0 0)) and (--a: 0) and (color: color-mix(in srgb, red, red)) and (grid-template-columns: subgrid) {
+@layer base;`
+	if !strings.HasPrefix(out, expected) || !strings.HasSuffix(out, "}\n}\n") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+
+	plain := []byte("a {\n\tcolor: red;\n}\n")
+	if out := GuardSupports(plain); string(out) != string(plain) {
+		t.Errorf("expected the stylesheet unchanged, got %s", out)
+	}
+}
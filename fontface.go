@@ -0,0 +1,102 @@
+package css
+
+import (
+	"strings"
+	"text/scanner"
+)
+
+// FontFace is an @font-face rule.
+type FontFace struct {
+	// Family is the font-family descriptor, unquoted.
+	Family string
+	// Sources are the sources of the src descriptor in order of
+	// preference.
+	Sources []FontSource
+	// Descriptors are all the descriptors of the rule, such as
+	// font-weight and unicode-range, including font-family and src.
+	Descriptors Declarations
+	Pos         scanner.Position
+	// AtRules are the at-rules the rule is nested in, such as @media.
+	AtRules []Rule
+}
+
+// FontSource is a source of the src descriptor of an @font-face rule,
+// such as url(a.woff2) format("woff2") or local(Brand Sans).
+type FontSource struct {
+	// URL is the url of a remote font, and empty for local fonts.
+	URL string
+	// Local is the name of a local font, and empty for remote fonts.
+	Local string
+	// Format is the format() hint, unquoted.
+	Format string
+	// Tech are the tech() hints, such as variations.
+	Tech []string
+}
+
+// FontFaces returns the @font-face rules of the stylesheet in document
+// order. They are not part of the map returned by ToLegacyMap.
+func (sheet *Stylesheet) FontFaces() []FontFace {
+	faces := []FontFace{}
+	for _, r := range sheet.Rules {
+		if !strings.EqualFold(string(r.Selector), "@font-face") {
+			continue
+		}
+		f := FontFace{Descriptors: r.Declarations, Pos: r.Pos, AtRules: r.AtRules, Sources: []FontSource{}}
+		if d, ok := f.Descriptors.Get("font-family"); ok {
+			f.Family = strings.TrimSpace(d.Value)
+			if strings.HasPrefix(f.Family, `"`) || strings.HasPrefix(f.Family, "'") {
+				f.Family = unquote(f.Family)
+			}
+		}
+		if d, ok := f.Descriptors.Get("src"); ok {
+			f.Sources = ParseFontSources(d.Value)
+		}
+		faces = append(faces, f)
+	}
+	return faces
+}
+
+// ParseFontSources parses the value of the src descriptor of an
+// @font-face rule. Invalid sources are skipped.
+func ParseFontSources(src string) []FontSource {
+	sources := []FontSource{}
+	for _, part := range splitTopLevel(src, ',') {
+		s := FontSource{}
+		for _, c := range parseComponents(strings.TrimSpace(part)) {
+			if c.Type != ComponentFunction {
+				continue
+			}
+			args := componentWords(c.Args)
+			switch strings.ToLower(c.Value) {
+			case "url":
+				if len(args) > 0 {
+					s.URL = args[0]
+				}
+			case "local":
+				s.Local = strings.Join(args, " ")
+			case "format":
+				if len(args) > 0 {
+					s.Format = args[0]
+				}
+			case "tech":
+				s.Tech = args
+			}
+		}
+		if s.URL != "" || s.Local != "" {
+			sources = append(sources, s)
+		}
+	}
+	return sources
+}
+
+// componentWords returns the values of the idents and strings of the
+// components.
+func componentWords(components []ComponentValue) []string {
+	words := []string{}
+	for _, c := range components {
+		if c.Type == ComponentIdent || c.Type == ComponentString {
+			words = append(words, c.Value)
+		}
+	}
+	return words
+}
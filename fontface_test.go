@@ -0,0 +1,49 @@
+package css
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFontFaces(t *testing.T) {
+	src := `@font-face {
+	font-family: "Brand Sans";
+	src: local(Brand Sans), url(brand.woff2) format('woff2') tech(variations, color-COLRv1), url("brand.woff") format("woff");
+	font-weight: 100 900;
+}
+@font-face {
+	font-family: Mono;
+	src: url(mono.ttf);
+}
+p {
+	font-family: "Brand Sans";
+}
+`
+	sheet, err := UnmarshalStylesheet([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	faces := sheet.FontFaces()
+	if len(faces) != 2 {
+		t.Fatalf("got %d font faces, want 2", len(faces))
+	}
+
+	want := []FontSource{
+		{Local: "Brand Sans"},
+		{URL: "brand.woff2", Format: "woff2", Tech: []string{"variations", "color-COLRv1"}},
+		{URL: "brand.woff", Format: "woff"},
+	}
+	if faces[0].Family != "Brand Sans" || !reflect.DeepEqual(faces[0].Sources, want) {
+		t.Errorf("got %q %+v", faces[0].Family, faces[0].Sources)
+	}
+	if weight, _ := faces[0].Descriptors.Get("font-weight"); weight.Value != "100 900" {
+		t.Errorf("got font-weight %q", weight.Value)
+	}
+	if faces[1].Family != "Mono" || len(faces[1].Sources) != 1 || faces[1].Sources[0].URL != "mono.ttf" || faces[1].Pos.Line != 6 {
+		t.Errorf("got %+v", faces[1])
+	}
+
+	if _, ok := sheet.ToLegacyMap()["@font-face"]; ok {
+		t.Error("@font-face in the legacy map")
+	}
+}
@@ -0,0 +1,222 @@
+package css
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+)
+
+// FormatPreset is a named layout used by Format.
+type FormatPreset int
+
+const (
+	// FormatExpanded writes every declaration on its own line, indented
+	// with a tab, and separates rules with an empty line.
+	FormatExpanded FormatPreset = iota
+	// FormatCompact writes every rule on a single line.
+	FormatCompact
+	// FormatIdiomatic follows the Idiomatic CSS conventions: two space
+	// indentation and one selector per line for selector groups.
+	FormatIdiomatic
+)
+
+// FormatOptions configures Format.
+type FormatOptions struct {
+	Preset FormatPreset
+	// Stable normalizes whitespace, commas and property case, and writes
+	// the selectors as described by the CSSOM spec, so that semantically
+	// identical input always produces byte-identical output.
+	// The stable output of a preset never changes between versions.
+	Stable bool
+	// Parse configures how warnings found while parsing are reported.
+	Parse ParseOptions
+}
+
+var (
+	rSpaces = regexp.MustCompile(`\s+`)
+	rCommas = regexp.MustCompile(`\s*,\s*`)
+)
+
+// Format pretty prints the css in b using the given options. At-rules
+// without a block, such as @import, come first, and comments are kept
+// before the rule or declaration they precede.
+func Format(b []byte, opts FormatOptions) ([]byte, error) {
+	blocks, err := opts.Parse.unmarshal(b)
+	if err != nil {
+		return nil, err
+	}
+	f := formatter{opts: opts, comments: CommentsWithPositions(b), indent: "\t"}
+	for _, s := range RuleSources(b) {
+		if strings.HasPrefix(string(s.Prelude), "@") {
+			f.atRules = append(f.atRules, s.SourceRange)
+		}
+	}
+	if opts.Preset == FormatIdiomatic {
+		f.indent = "  "
+	}
+	for _, st := range statementSources(b) {
+		f.writeComments(st.Start, 0)
+		f.separate()
+		f.buf.WriteString(strings.Join(strings.Fields(string(st.Prelude)), " ") + ";\n")
+	}
+	nestBlocks(blocks, func(bl block, depth int) {
+		f.writeComments(f.atRuleStart(bl, depth), depth)
+		f.separate()
+		f.buf.WriteString(strings.Repeat(f.indent, depth))
+		f.buf.WriteString(strings.Join(strings.Fields(bl.atRules[depth]), " ") + " {\n")
+		f.started = false
+	}, func(depth int) {
+		f.buf.WriteString(strings.Repeat(f.indent, depth) + "}\n")
+		f.started = true
+	}, f.writeBlock)
+	f.writeComments(len(b), 0)
+	return f.buf.Bytes(), nil
+}
+
+// formatter writes the blocks for Format.
+type formatter struct {
+	opts     FormatOptions
+	buf      bytes.Buffer
+	comments []Comment
+	// atRules are the source ranges of the at-rules, ordered by start.
+	atRules []SourceRange
+	indent  string
+	// started is set when an item was written at the current depth, so
+	// the next one is separated by an empty line.
+	started bool
+}
+
+// separate writes the empty line between two items, except in the compact
+// preset.
+func (f *formatter) separate() {
+	if f.started && f.opts.Preset != FormatCompact {
+		f.buf.WriteByte('\n')
+	}
+	f.started = true
+}
+
+// writeComments writes the comments before the offset on their own lines.
+func (f *formatter) writeComments(offset, depth int) {
+	for len(f.comments) > 0 && f.comments[0].Pos.Offset < offset {
+		f.separate()
+		f.buf.WriteString(strings.Repeat(f.indent, depth) + f.comments[0].Text + "\n")
+		f.started = false
+		f.comments = f.comments[1:]
+	}
+}
+
+// atRuleStart returns the offset of the at-rule bl.atRules[depth] in the
+// source.
+func (f *formatter) atRuleStart(bl block, depth int) int {
+	n := 0
+	for _, s := range f.atRules {
+		if s.Start < bl.source.Start && bl.source.End <= s.End {
+			if n == depth {
+				return s.Start
+			}
+			n++
+		}
+	}
+	return bl.source.Start
+}
+
+// selectors returns the selectors of the block, serialized as described by
+// the CSSOM spec when the output is stable.
+func (f *formatter) selectors(bl block) []string {
+	group := Rule(bl.selector).Group()
+	selectors := make([]string, len(group))
+	for i, sel := range group {
+		selectors[i] = string(sel)
+		if f.opts.Stable {
+			selectors[i] = sel.String()
+		}
+	}
+	return selectors
+}
+
+func (f *formatter) writeBlock(bl block, depth int) {
+	f.writeComments(bl.source.Start, depth)
+	f.separate()
+
+	var (
+		prefix = strings.Repeat(f.indent, depth)
+		inner  = prefix + f.indent
+	)
+	switch f.opts.Preset {
+	case FormatCompact:
+		f.buf.WriteString(prefix + strings.Join(f.selectors(bl), ", ") + " {")
+	case FormatIdiomatic:
+		f.buf.WriteString(prefix + strings.Join(f.selectors(bl), ",\n"+prefix) + " {\n")
+	default:
+		f.buf.WriteString(prefix + strings.Join(f.selectors(bl), ", ") + " {\n")
+	}
+	for _, d := range bl.styles {
+		if f.opts.Stable {
+			if !strings.HasPrefix(d.Property, "--") {
+				// custom properties are case sensitive
+				d.Property = strings.ToLower(d.Property)
+			}
+			d.Value = normalizeValue(d.Value)
+		}
+		for len(f.comments) > 0 && f.comments[0].Pos.Offset < d.Pos.Offset {
+			f.writeInner(inner, f.comments[0].Text)
+			f.comments = f.comments[1:]
+		}
+		f.writeInner(inner, d.String()+";")
+	}
+	for len(f.comments) > 0 && f.comments[0].Pos.Offset < bl.source.End {
+		f.writeInner(inner, f.comments[0].Text)
+		f.comments = f.comments[1:]
+	}
+	if f.opts.Preset == FormatCompact {
+		f.buf.WriteString(" }\n")
+	} else {
+		f.buf.WriteString(prefix + "}\n")
+	}
+}
+
+// writeInner writes a declaration or comment of a block.
+func (f *formatter) writeInner(inner, text string) {
+	if f.opts.Preset == FormatCompact {
+		f.buf.WriteString(" " + text)
+	} else {
+		f.buf.WriteString(inner + text + "\n")
+	}
+}
+
+// normalizeValue collapses whitespace and puts a single space after every
+// comma, outside of quoted strings.
+func normalizeValue(value string) string {
+	var (
+		out   strings.Builder
+		start = 0
+		quote = byte(0)
+	)
+	flush := func(end int) {
+		part := rSpaces.ReplaceAllString(value[start:end], " ")
+		out.WriteString(rCommas.ReplaceAllString(part, ", "))
+	}
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				out.WriteString(value[start : i+1])
+				start = i + 1
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			flush(i)
+			start = i
+			quote = c
+		}
+	}
+	if quote != 0 {
+		out.WriteString(value[start:])
+	} else {
+		flush(len(value))
+	}
+	return strings.TrimSpace(out.String())
+}
@@ -0,0 +1,94 @@
+package css
+
+import "testing"
+
+func TestFormat(t *testing.T) {
+	ex1 := `h1,h2 {
+	COLOR: red;
+	font-family: 'A  B',serif;
+}
+p {
+	margin: 0;
+}`
+
+	t.Run("Expanded", func(t *testing.T) {
+		out, err := Format([]byte(ex1), FormatOptions{Preset: FormatExpanded})
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := "h1, h2 {\n\tCOLOR: red;\n\tfont-family: 'A  B',serif;\n}\n\np {\n\tmargin: 0;\n}\n"
+		if string(out) != expected {
+			t.Fatalf("got %q, expected %q", out, expected)
+		}
+	})
+
+	t.Run("CompactStable", func(t *testing.T) {
+		out, err := Format([]byte(ex1), FormatOptions{Preset: FormatCompact, Stable: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := "h1, h2 { color: red; font-family: 'A  B', serif; }\np { margin: 0; }\n"
+		if string(out) != expected {
+			t.Fatalf("got %q, expected %q", out, expected)
+		}
+	})
+
+	t.Run("IdiomaticStable", func(t *testing.T) {
+		ex2 := "h1 ,  h2 {\ncolor: red;\nfont-family: 'A  B' ,  serif;\n}\np {\nmargin:0;\n}"
+		a, _ := Format([]byte(ex1), FormatOptions{Preset: FormatIdiomatic, Stable: true})
+		b, _ := Format([]byte(ex2), FormatOptions{Preset: FormatIdiomatic, Stable: true})
+		if string(a) != string(b) {
+			t.Fatalf("output is not stable:\n%s\n%s", a, b)
+		}
+		expected := "h1,\nh2 {\n  color: red;\n  font-family: 'A  B', serif;\n}\n\np {\n  margin: 0;\n}\n"
+		if string(a) != expected {
+			t.Fatalf("got %q, expected %q", a, expected)
+		}
+	})
+}
+
+func TestFormatAtRules(t *testing.T) {
+	src := `@import  url(a.css) screen;
+/* header */
+a { color: red; }
+@media print {
+	/* printed */
+	div>p { margin: 0; /* no margin */ }
+}`
+
+	out, err := Format([]byte(src), FormatOptions{Preset: FormatExpanded})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "@import url(a.css) screen;\n\n/* header */\na {\n\tcolor: red;\n}\n\n" +
+		"@media print {\n\t/* printed */\n\tdiv>p {\n\t\tmargin: 0;\n\t\t/* no margin */\n\t}\n}\n"
+	if string(out) != expected {
+		t.Fatalf("got %q, expected %q", out, expected)
+	}
+
+	out, err = Format([]byte(src), FormatOptions{Preset: FormatCompact, Stable: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = "@import url(a.css) screen;\n/* header */\na { color: red; }\n" +
+		"@media print {\n\t/* printed */\n\tdiv > p { margin: 0; /* no margin */ }\n}\n"
+	if string(out) != expected {
+		t.Fatalf("got %q, expected %q", out, expected)
+	}
+
+	out, err = Format([]byte(`a { --Main-Color: red; COLOR: var(--Main-Color); content: "a\" ,  b" ,  "c"; }`), FormatOptions{Preset: FormatCompact, Stable: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = `a { --Main-Color: red; color: var(--Main-Color); content: "a\" ,  b", "c"; }` + "\n"
+	if string(out) != expected {
+		t.Fatalf("got %q, expected %q", out, expected)
+	}
+
+	// stable selectors
+	a, _ := Format([]byte("div>p,a:not( .b ){color:red}"), FormatOptions{Stable: true})
+	b, _ := Format([]byte("div > p , a:not(.b) { color: red; }"), FormatOptions{Stable: true})
+	if string(a) != string(b) {
+		t.Fatalf("output is not stable:\n%s\n%s", a, b)
+	}
+}
@@ -0,0 +1,229 @@
+package css
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
+// GenerateOptions configures Generate.
+type GenerateOptions struct {
+	// Seed seeds the random choices. The same options always generate the
+	// same css.
+	Seed int64
+	// Size is the maximum size of the css in bytes, 4096 if zero. The css
+	// is filled with rules up to about that size.
+	Size int
+	// Media is the probability, between 0 and 1, for a group of rules to
+	// be nested in a @media rule.
+	Media float64
+}
+
+// Generate returns random valid css, for fuzzing the parser and for
+// load-testing code consuming stylesheets with realistic input. The rules
+// have selectors made of the common simple selectors and combinators,
+// and declarations of common properties with values of their type.
+func Generate(opts GenerateOptions) []byte {
+	size := opts.Size
+	if size <= 0 {
+		size = 4096
+	}
+	var (
+		r   = rand.New(rand.NewSource(opts.Seed))
+		buf bytes.Buffer
+	)
+	for {
+		var next string
+		if r.Float64() < opts.Media {
+			var rules []string
+			for i := 1 + r.Intn(3); i > 0; i-- {
+				rules = append(rules, indent(generateRule(r)))
+			}
+			next = "@media " + generateMediaQuery(r) + " {\n" + strings.Join(rules, "\n") + "}\n"
+		} else {
+			next = generateRule(r)
+		}
+		if buf.Len() > 0 {
+			next = "\n" + next
+		}
+		if buf.Len()+len(next) > size {
+			return buf.Bytes()
+		}
+		buf.WriteString(next)
+	}
+}
+
+// indent indents every line of s with a tab.
+func indent(s string) string {
+	lines := strings.SplitAfter(s, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = "\t" + line
+		}
+	}
+	return strings.Join(lines, "")
+}
+
+func generateRule(r *rand.Rand) string {
+	selectors := make([]string, 1+r.Intn(3))
+	for i := range selectors {
+		selectors[i] = generateSelector(r)
+	}
+	var b strings.Builder
+	b.WriteString(strings.Join(selectors, ", ") + " {\n")
+	for i := 1 + r.Intn(6); i > 0; i-- {
+		p := generatedProperties[r.Intn(len(generatedProperties))]
+		value := p.value(r)
+		if r.Intn(20) == 0 {
+			value += " !important"
+		}
+		b.WriteString("\t" + p.name + ": " + value + ";\n")
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+var (
+	generatedTags    = []string{"a", "body", "button", "div", "h1", "h2", "img", "input", "li", "nav", "p", "section", "span", "table", "td", "ul"}
+	generatedNames   = []string{"active", "btn", "card", "container", "footer", "header", "item", "main", "menu", "nav", "primary", "row", "title"}
+	generatedPseudos = []string{":hover", ":focus", ":first-child", ":last-child", ":nth-child(2n+1)", ":not(.disabled)", ":checked", "::before", "::after"}
+	generatedAttrs   = []string{"[disabled]", `[type="text"]`, `[href^="https:"]`, `[lang|="en"]`, `[class~="item"]`, `[data-state="open" i]`}
+	generatedCombs   = []string{" ", " > ", " + ", " ~ "}
+)
+
+func generateSelector(r *rand.Rand) string {
+	var b strings.Builder
+	for i := r.Intn(3); i >= 0; i-- {
+		b.WriteString(generateCompound(r, i == 0))
+		if i > 0 {
+			b.WriteString(pick(r, generatedCombs))
+		}
+	}
+	return b.String()
+}
+
+// generateCompound returns a compound selector, which can only have a
+// pseudo-element when it is the subject.
+func generateCompound(r *rand.Rand, subject bool) string {
+	var b strings.Builder
+	if r.Intn(2) == 0 {
+		b.WriteString(pick(r, generatedTags))
+	}
+	if r.Intn(8) == 0 {
+		b.WriteString("#" + pick(r, generatedNames))
+	}
+	for i := r.Intn(3); i > 0; i-- {
+		b.WriteString("." + pick(r, generatedNames))
+	}
+	if r.Intn(6) == 0 {
+		b.WriteString(pick(r, generatedAttrs))
+	}
+	if r.Intn(5) == 0 {
+		p := pick(r, generatedPseudos)
+		if subject || !strings.HasPrefix(p, "::") {
+			b.WriteString(p)
+		}
+	}
+	if b.Len() == 0 {
+		return "*"
+	}
+	return b.String()
+}
+
+func generateMediaQuery(r *rand.Rand) string {
+	switch r.Intn(4) {
+	case 0:
+		return "print"
+	case 1:
+		return fmt.Sprintf("(max-width: %dpx)", 320+r.Intn(20)*40)
+	case 2:
+		return fmt.Sprintf("screen and (min-width: %dpx)", 320+r.Intn(20)*40)
+	}
+	return "(prefers-color-scheme: dark)"
+}
+
+// generatedProperties are the properties used by Generate with their
+// value generators.
+var generatedProperties = []struct {
+	name  string
+	value func(r *rand.Rand) string
+}{
+	{"background", func(r *rand.Rand) string {
+		return generateColor(r) + ` url("img/` + pick(r, generatedNames) + `.png") no-repeat`
+	}},
+	{"background-color", generateColor},
+	{"border", func(r *rand.Rand) string {
+		return fmt.Sprintf("%dpx %s %s", 1+r.Intn(4), pick(r, []string{"solid", "dashed", "dotted"}), generateColor(r))
+	}},
+	{"border-radius", generateLength},
+	{"color", generateColor},
+	{"display", keywords("block", "inline", "inline-block", "flex", "grid", "none")},
+	{"font-family", keywords(`"Helvetica Neue", Arial, sans-serif`, "Georgia, serif", "monospace")},
+	{"font-size", generateLength},
+	{"font-weight", keywords("normal", "bold", "400", "700")},
+	{"height", generateLength},
+	{"line-height", keywords("1", "1.5", "normal", "20px")},
+	{"margin", generateBox},
+	{"opacity", func(r *rand.Rand) string { return fmt.Sprintf("%.2g", r.Float64()) }},
+	{"padding", generateBox},
+	{"position", keywords("static", "relative", "absolute", "fixed", "sticky")},
+	{"text-align", keywords("left", "right", "center", "justify")},
+	{"transform", func(r *rand.Rand) string {
+		return fmt.Sprintf("translate(%s, %s) rotate(%ddeg)", generateLength(r), generateLength(r), r.Intn(360))
+	}},
+	{"transition", func(r *rand.Rand) string {
+		return fmt.Sprintf("opacity %dms ease-in-out", 50*(1+r.Intn(10)))
+	}},
+	{"width", func(r *rand.Rand) string {
+		if r.Intn(4) == 0 {
+			return fmt.Sprintf("calc(100%% - %s)", generateLength(r))
+		}
+		return generateLength(r)
+	}},
+	{"z-index", func(r *rand.Rand) string { return fmt.Sprint(r.Intn(100)) }},
+}
+
+func keywords(values ...string) func(r *rand.Rand) string {
+	return func(r *rand.Rand) string {
+		return pick(r, values)
+	}
+}
+
+func generateLength(r *rand.Rand) string {
+	switch r.Intn(6) {
+	case 0:
+		return "0"
+	case 1:
+		return fmt.Sprintf("%d%%", r.Intn(101))
+	case 2:
+		return fmt.Sprintf("%.3gem", 0.25*float64(1+r.Intn(16)))
+	case 3:
+		return fmt.Sprintf("%.3grem", 0.25*float64(1+r.Intn(16)))
+	}
+	return fmt.Sprintf("%dpx", r.Intn(400))
+}
+
+func generateBox(r *rand.Rand) string {
+	values := make([]string, 1+r.Intn(4))
+	for i := range values {
+		values[i] = generateLength(r)
+	}
+	return strings.Join(values, " ")
+}
+
+func generateColor(r *rand.Rand) string {
+	switch r.Intn(4) {
+	case 0:
+		return fmt.Sprintf("#%06x", r.Intn(1<<24))
+	case 1:
+		return fmt.Sprintf("rgba(%d, %d, %d, %.2g)", r.Intn(256), r.Intn(256), r.Intn(256), r.Float64())
+	case 2:
+		return fmt.Sprintf("hsl(%d, %d%%, %d%%)", r.Intn(360), r.Intn(101), r.Intn(101))
+	}
+	return pick(r, []string{"red", "black", "white", "transparent", "rebeccapurple", "currentcolor"})
+}
+
+func pick(r *rand.Rand, values []string) string {
+	return values[r.Intn(len(values))]
+}
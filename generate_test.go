@@ -0,0 +1,76 @@
+package css
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	opts := GenerateOptions{Seed: 42, Size: 2000, Media: 0.2}
+	b := Generate(opts)
+	if !bytes.Equal(b, Generate(opts)) {
+		t.Fatal("the same options generated different css")
+	}
+	if len(b) > 2000 || len(b) < 1000 {
+		t.Fatalf("got %d bytes", len(b))
+	}
+	if bytes.Equal(b, Generate(GenerateOptions{Seed: 43, Size: 2000})) {
+		t.Fatal("different seeds generated the same css")
+	}
+	checkRoundTrip(t, b)
+}
+
+func FuzzGenerate(f *testing.F) {
+	for seed := int64(0); seed < 8; seed++ {
+		f.Add(seed, uint16(1024))
+	}
+	f.Fuzz(func(t *testing.T, seed int64, size uint16) {
+		checkRoundTrip(t, Generate(GenerateOptions{Seed: seed, Size: int(size), Media: 0.25}))
+	})
+}
+
+// checkRoundTrip checks that the generated css parses into the rules it
+// was generated with, and that serializing and parsing them again gives
+// the same rules.
+func checkRoundTrip(t *testing.T, b []byte) {
+	t.Helper()
+	sheet, err := UnmarshalStylesheet(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := 0
+	for _, line := range strings.Split(string(b), "\n") {
+		if strings.HasSuffix(line, " {") && !strings.HasPrefix(line, "@") {
+			want++
+		}
+	}
+	if len(sheet.Rules) != want {
+		t.Fatalf("got %d rules, want %d:\n%s", len(sheet.Rules), want, b)
+	}
+
+	var buf bytes.Buffer
+	for _, r := range sheet.Rules {
+		for _, at := range r.AtRules {
+			buf.WriteString(string(at) + " {\n")
+		}
+		buf.WriteString(string(r.Selector) + " {\n")
+		for _, d := range r.Declarations {
+			buf.WriteString("\t" + d.String() + ";\n")
+		}
+		buf.WriteString("}\n" + strings.Repeat("}\n", len(r.AtRules)))
+	}
+	again, err := UnmarshalStylesheet(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(RuleHashes(sheet), RuleHashes(again)) {
+		t.Fatalf("rules changed after a round trip:\n%s\n%s", b, buf.Bytes())
+	}
+	for i := range sheet.Rules {
+		if !reflect.DeepEqual(sheet.Rules[i].AtRules, again.Rules[i].AtRules) {
+			t.Fatalf("at-rules of %s changed after a round trip", sheet.Rules[i].Selector)
+		}
+	}
+}
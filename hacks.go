@@ -0,0 +1,176 @@
+package css
+
+import (
+	"bytes"
+	"regexp"
+	"sort"
+	"strings"
+	"text/scanner"
+)
+
+// HackKind is the kind of a legacy browser hack.
+type HackKind string
+
+// Kinds of legacy browser hacks.
+const (
+	// HackStarProperty is a property prefixed with "*", read by IE 7 and
+	// older: *zoom: 1.
+	HackStarProperty HackKind = "star property"
+	// HackUnderscoreProperty is a property prefixed with "_", read by IE 6
+	// and older: _height: 1px.
+	HackUnderscoreProperty HackKind = "underscore property"
+	// HackBackslash9 is a value suffixed with \9 or \0, read by IE 8 to 10:
+	// color: red\9.
+	HackBackslash9 HackKind = "backslash 9"
+	// HackImportantIE is a value suffixed with !ie.
+	HackImportantIE HackKind = "!ie"
+	// HackEmptyComment is an empty comment in a selector or declaration,
+	// hiding it from some IE versions: html>/**/body.
+	HackEmptyComment HackKind = "empty comment"
+	// HackStarHTML is a selector starting with "* html" or "*+html", only
+	// matching in IE 6 and 7.
+	HackStarHTML HackKind = "star html"
+)
+
+var (
+	rBackslash9 = regexp.MustCompile(`\\(9|0/?)\s*(!\s*important)?\s*$`)
+	rImportIE   = regexp.MustCompile(`(?i)!\s*ie\s*$`)
+	rStarHTML   = regexp.MustCompile(`(^|,)\s*\*\s*(\+|:first-child\s*\+)?\s*html\b`)
+)
+
+// Hack is a legacy browser hack found in a stylesheet.
+type Hack struct {
+	Kind HackKind
+	// Text is the declaration using the hack, or the selector of the rule
+	// using it.
+	Text string
+	Pos  scanner.Position
+	// start and end are the byte range of the declaration or rule to
+	// remove when stripping the hack.
+	start, end int
+}
+
+// FindHacks returns the legacy browser hacks used in the css, in document
+// order.
+func FindHacks(b []byte) []Hack {
+	var (
+		hacks = []Hack{}
+		item  = []TokenEntry{}
+		// rules holds, for every open block, the index of the selector
+		// hack of the rule or -1.
+		rules = []int{}
+		end   = 0
+	)
+	add := func(kind HackKind, start, stop int) {
+		hacks = append(hacks, Hack{
+			Kind:  kind,
+			Text:  strings.TrimSpace(string(b[start:stop])),
+			Pos:   offsetPosition(b, start),
+			start: start,
+			end:   stop,
+		})
+	}
+
+	e := Tokenize(b).Front()
+	for e != nil {
+		tok := e.Value.(TokenEntry)
+
+		switch tok.typ() {
+		case tokenBlockStart:
+			start := tok.pos.Offset
+			if len(item) > 0 {
+				start = item[0].pos.Offset
+			}
+			selector := string(b[start:tok.pos.Offset])
+			switch {
+			case rStarHTML.MatchString(selector):
+				rules = append(rules, len(hacks))
+				add(HackStarHTML, start, tok.pos.Offset)
+			case strings.Contains(selector, "/**/"):
+				rules = append(rules, len(hacks))
+				add(HackEmptyComment, start, tok.pos.Offset)
+			default:
+				rules = append(rules, -1)
+			}
+			item = nil
+		case tokenStatementEnd, tokenBlockEnd:
+			if len(item) > 0 && len(rules) > 0 {
+				stop := end
+				if tok.typ() == tokenStatementEnd {
+					stop = tok.pos.Offset + 1
+				}
+				if kind, ok := declarationHack(item, string(b[item[0].pos.Offset:stop])); ok {
+					add(kind, item[0].pos.Offset, stop)
+				}
+			}
+			if tok.typ() == tokenBlockEnd && len(rules) > 0 {
+				if i := rules[len(rules)-1]; i >= 0 {
+					hacks[i].end = tok.pos.Offset + 1
+				}
+				rules = rules[:len(rules)-1]
+			}
+			item = nil
+		default:
+			item = append(item, tok)
+		}
+
+		end = tok.pos.Offset + len(tok.value)
+		e = e.Next()
+	}
+
+	return hacks
+}
+
+// declarationHack returns the hack used by the declaration with the tokens
+// and the raw text.
+func declarationHack(item []TokenEntry, raw string) (HackKind, bool) {
+	property := item[0].value
+	switch {
+	case strings.HasPrefix(property, "*"):
+		return HackStarProperty, true
+	case strings.HasPrefix(property, "_"):
+		return HackUnderscoreProperty, true
+	case strings.Contains(raw, "/**/"):
+		return HackEmptyComment, true
+	}
+	value := strings.TrimSuffix(strings.TrimSpace(raw), ";")
+	switch {
+	case rBackslash9.MatchString(value):
+		return HackBackslash9, true
+	case rImportIE.MatchString(value):
+		return HackImportantIE, true
+	}
+	return "", false
+}
+
+// StripHacks removes the declarations and rules using legacy browser
+// hacks from the css and returns the result with the removed hacks.
+func StripHacks(b []byte) ([]byte, []Hack) {
+	hacks := FindHacks(b)
+	spans := make([][2]int, len(hacks))
+	for i, h := range hacks {
+		spans[i] = [2]int{h.start, h.end}
+	}
+	return removeSpans(b, spans), hacks
+}
+
+// removeSpans returns b without the byte ranges in spans. Ranges inside
+// an earlier removed range are ignored.
+func removeSpans(b []byte, spans [][2]int) []byte {
+	sort.Slice(spans, func(i, j int) bool { return spans[i][0] < spans[j][0] })
+
+	var (
+		buf  bytes.Buffer
+		last = 0
+	)
+	for _, span := range spans {
+		if span[0] < last {
+			// already removed with the rule containing it
+			continue
+		}
+		buf.Write(b[last:span[0]])
+		last = span[1]
+	}
+	buf.Write(b[last:])
+	return buf.Bytes()
+}
@@ -0,0 +1,78 @@
+package css
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFindHacks(t *testing.T) {
+	ex := `.a {
+	*zoom: 1;
+	_height: 1px;
+	color: red\9;
+	width: 10px !ie;
+	margin: 0;
+}
+* html .b {
+	color: blue;
+}
+html>/**/body .c {
+	color: green;
+}
+`
+	hacks := FindHacks([]byte(ex))
+	want := []struct {
+		kind HackKind
+		text string
+		line int
+	}{
+		{HackStarProperty, "*zoom: 1;", 2},
+		{HackUnderscoreProperty, "_height: 1px;", 3},
+		{HackBackslash9, `color: red\9;`, 4},
+		{HackImportantIE, "width: 10px !ie;", 5},
+		{HackStarHTML, "* html .b", 8},
+		{HackEmptyComment, "html>/**/body .c", 11},
+	}
+	if len(hacks) != len(want) {
+		t.Fatalf("got %d hacks %v, want %d", len(hacks), hacks, len(want))
+	}
+	for i, w := range want {
+		h := hacks[i]
+		if h.Kind != w.kind || h.Text != w.text || h.Pos.Line != w.line {
+			t.Errorf("hack %d: got %q %q line %d, want %q %q line %d",
+				i, h.Kind, h.Text, h.Pos.Line, w.kind, w.text, w.line)
+		}
+	}
+}
+
+func TestStripHacks(t *testing.T) {
+	ex := `.a {
+	*zoom: 1;
+	color: red;
+	color: blue\9;
+}
+*+html .b {
+	*display: inline;
+}
+.c {
+	top: 0
+}
+`
+	out, hacks := StripHacks([]byte(ex))
+	if len(hacks) != 4 {
+		t.Fatalf("got %d hacks, want 4", len(hacks))
+	}
+	css, err := Unmarshal(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(css) != 2 || css[".a"]["color"] != "red" || css[".c"]["top"] != "0" {
+		t.Fatalf("unexpected result %v:\n%s", css, out)
+	}
+	if _, ok := css[".a"]["*zoom"]; ok {
+		t.Fatal("star hack was not stripped")
+	}
+	if strings.Contains(string(out), "html") {
+		t.Fatalf("star html rule was not stripped:\n%s", out)
+	}
+}
@@ -0,0 +1,34 @@
+package css
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// Hash returns a stable hash of the rule's selector and declarations. It
+// doesn't depend on the position of the rule or on whitespace, so it only
+// changes when the rule itself changes.
+func (r *StyleRule) Hash() string {
+	h := sha256.New()
+	h.Write([]byte(strings.Join(strings.Fields(string(r.Selector)), " ")))
+	for _, d := range r.Declarations {
+		// NUL can't appear in css, so it separates the fields unambiguously
+		h.Write([]byte{0})
+		h.Write([]byte(strings.ToLower(d.Property)))
+		h.Write([]byte{0})
+		h.Write([]byte(normalizeValue(d.valueString())))
+	}
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
+
+// RuleHashes returns the hash of every rule of the stylesheet, in the
+// order of sheet.Rules. Comparing the hashes of two builds tells which
+// rules changed, e.g. to only reload those in a browser.
+func RuleHashes(sheet *Stylesheet) []string {
+	hashes := make([]string, len(sheet.Rules))
+	for i, r := range sheet.Rules {
+		hashes[i] = r.Hash()
+	}
+	return hashes
+}
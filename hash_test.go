@@ -0,0 +1,30 @@
+package css
+
+import "testing"
+
+func TestRuleHashes(t *testing.T) {
+	before, _ := UnmarshalStylesheet([]byte(".a {\n\tcolor: red;\n}\n.b {\n\ttop: 0;\n}\n"))
+	after, _ := UnmarshalStylesheet([]byte("\n\n.a {\n\tcolor:   red;\n}\n.b {\n\ttop: 1px;\n}\n"))
+
+	h1, h2 := RuleHashes(before), RuleHashes(after)
+	if len(h1) != 2 || len(h2) != 2 {
+		t.Fatalf("unexpected hashes %v %v", h1, h2)
+	}
+	if h1[0] != h2[0] {
+		t.Error("hash changed for an unchanged rule")
+	}
+	if h1[1] == h2[1] {
+		t.Error("hash didn't change for a changed rule")
+	}
+	if h1[0] == h1[1] {
+		t.Error("different rules have the same hash")
+	}
+}
+
+func TestRuleHashImportant(t *testing.T) {
+	a := &StyleRule{Selector: ".a", Declarations: []Declaration{NewDeclaration("color", "red")}}
+	b := &StyleRule{Selector: ".a", Declarations: []Declaration{NewDeclaration("color", "red !important")}}
+	if a.Hash() == b.Hash() {
+		t.Fatal("!important doesn't change the hash")
+	}
+}
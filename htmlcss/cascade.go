@@ -0,0 +1,44 @@
+package htmlcss
+
+import (
+	"github.com/itskass/go-css"
+	"golang.org/x/net/html"
+)
+
+// Cascade returns a cascade of the stylesheets of the document in
+// document order, so rules of later <style> and <link> elements win over
+// earlier ones of the same specificity. load returns the css of linked
+// stylesheets from their href; when nil they are left out. The media
+// attributes of the elements are applied as @media rules.
+func Cascade(doc *html.Node, load func(href string) ([]byte, error)) (*css.Cascade, error) {
+	sheets := []*css.Stylesheet{}
+	for _, s := range Stylesheets(doc) {
+		if s.Href != "" {
+			if load == nil {
+				continue
+			}
+			data, err := load(s.Href)
+			if err != nil {
+				return nil, err
+			}
+			s.Data = data
+		}
+		sheet, err := css.UnmarshalStylesheet(s.CSS())
+		if err != nil {
+			return nil, err
+		}
+		sheets = append(sheets, sheet)
+	}
+	return css.NewCascade(sheets...), nil
+}
+
+// ComputedStyle returns the computed style of the element node from the
+// cascade, including the declarations of its style attribute. parent is
+// the computed style of the parent element, or nil for the root element.
+func ComputedStyle(c *css.Cascade, n *html.Node, parent css.ComputedStyle) (css.ComputedStyle, error) {
+	inline, err := css.UnmarshalStyle([]byte(attr(n, "style")))
+	if err != nil {
+		return nil, err
+	}
+	return c.ComputedStyle(element{n}, inline, parent), nil
+}
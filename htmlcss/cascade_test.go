@@ -0,0 +1,61 @@
+package htmlcss
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+func TestCascade(t *testing.T) {
+	doc, err := html.Parse(strings.NewReader(`<html><head>
+<style>p { color: red; }</style>
+<link rel="stylesheet" href="theme.css">
+<style>p.note { font-size: 20px; }</style>
+<link rel="stylesheet" href="print.css" media="print">
+</head><body><p class="note" style="margin-top: 3px">A</p></body></html>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"theme.css": "p {\n\tcolor: blue;\n\tfont-size: 10px;\n}\n",
+		"print.css": "p {\n\tcolor: black;\n}\n",
+	}
+	load := func(href string) ([]byte, error) {
+		return []byte(files[href]), nil
+	}
+
+	c, err := Cascade(doc, load)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var p *html.Node
+	for _, n := range elements(doc) {
+		if n.Data == "p" {
+			p = n
+		}
+	}
+	style, err := ComputedStyle(c, p, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"color": "blue", "font-size": "20px", "margin-top": "3px", "display": "block"}
+	for property, value := range want {
+		if style[property] != value {
+			t.Errorf("%s: got %q, want %q", property, style[property], value)
+		}
+	}
+
+	boom := errors.New("boom")
+	if _, err := Cascade(doc, func(string) ([]byte, error) { return nil, boom }); err != boom {
+		t.Errorf("got %v", err)
+	}
+	c, err = Cascade(doc, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if style, _ := ComputedStyle(c, p, nil); style["color"] != "red" {
+		t.Errorf("without linked stylesheets: got color %q", style["color"])
+	}
+}
@@ -0,0 +1,81 @@
+package htmlcss
+
+import (
+	"github.com/itskass/go-css"
+	"golang.org/x/net/html"
+)
+
+// element adapts an *html.Node to css.Element.
+type element struct {
+	n *html.Node
+}
+
+// newElement returns n as a css.Element, or a nil interface when n is nil.
+func newElement(n *html.Node) css.Element {
+	if n == nil || n.Type != html.ElementNode {
+		return nil
+	}
+	return element{n}
+}
+
+func (e element) TagName() string {
+	return e.n.Data
+}
+
+func (e element) Attr(name string) (string, bool) {
+	for _, a := range e.n.Attr {
+		if a.Namespace == "" && a.Key == name {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+func (e element) Parent() css.Element {
+	return newElement(e.n.Parent)
+}
+
+func (e element) PrevSibling() css.Element {
+	for s := e.n.PrevSibling; s != nil; s = s.PrevSibling {
+		if s.Type == html.ElementNode {
+			return element{s}
+		}
+	}
+	return nil
+}
+
+func (e element) NextSibling() css.Element {
+	for s := e.n.NextSibling; s != nil; s = s.NextSibling {
+		if s.Type == html.ElementNode {
+			return element{s}
+		}
+	}
+	return nil
+}
+
+// elements returns the element nodes under n, including n, in document
+// order.
+func elements(n *html.Node) []*html.Node {
+	nodes := []*html.Node{}
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			nodes = append(nodes, n)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return nodes
+}
+
+func (e element) Namespace() string {
+	switch e.n.Namespace {
+	case "svg":
+		return css.SVGNamespace
+	case "math":
+		return css.MathMLNamespace
+	}
+	return css.HTMLNamespace
+}
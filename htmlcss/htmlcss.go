@@ -0,0 +1,83 @@
+// Package htmlcss reads the stylesheets of HTML documents parsed with
+// golang.org/x/net/html.
+package htmlcss
+
+import (
+	"strings"
+
+	"github.com/itskass/go-css"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Source is a stylesheet embedded in or linked from an HTML document.
+type Source struct {
+	// Node is the <style> or <link> element.
+	Node *html.Node
+	// Href is the url of a linked stylesheet. It is empty for <style>
+	// elements.
+	Href string
+	// Media is the media attribute of the element, empty when not set.
+	Media string
+	// Data is the content of a <style> element. Callers loading a linked
+	// stylesheet set it to the loaded css.
+	Data []byte
+}
+
+// Stylesheets returns the <style> and <link rel="stylesheet"> elements of
+// the document in document order.
+func Stylesheets(doc *html.Node) []Source {
+	sources := []Source{}
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			switch n.DataAtom {
+			case atom.Style:
+				s := Source{Node: n, Media: attr(n, "media")}
+				for c := n.FirstChild; c != nil; c = c.NextSibling {
+					if c.Type == html.TextNode {
+						s.Data = append(s.Data, c.Data...)
+					}
+				}
+				sources = append(sources, s)
+			case atom.Link:
+				if isStylesheetLink(n) {
+					sources = append(sources, Source{
+						Node:  n,
+						Href:  attr(n, "href"),
+						Media: attr(n, "media"),
+					})
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+	return sources
+}
+
+// CSS returns the stylesheet of the source, wrapped in an @media rule
+// when the element has a media attribute.
+func (s Source) CSS() []byte {
+	return css.WrapMedia(s.Media, s.Data)
+}
+
+func isStylesheetLink(n *html.Node) bool {
+	for _, rel := range strings.Fields(attr(n, "rel")) {
+		if strings.EqualFold(rel, "stylesheet") {
+			return true
+		}
+	}
+	return false
+}
+
+func attr(n *html.Node, name string) string {
+	for _, a := range n.Attr {
+		if a.Namespace == "" && a.Key == name {
+			return a.Val
+		}
+	}
+	return ""
+}
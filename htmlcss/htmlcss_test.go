@@ -0,0 +1,41 @@
+package htmlcss
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+func TestStylesheets(t *testing.T) {
+	doc, err := html.Parse(strings.NewReader(`<html><head>
+<link rel="stylesheet" href="print.css" media="print">
+<style media="screen and (max-width: 600px)">@import url(a.css);
+body {
+	color: red;
+}</style>
+<style>p {
+	color: blue;
+}</style>
+</head></html>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sources := Stylesheets(doc)
+	if len(sources) != 3 {
+		t.Fatalf("expected 3 stylesheets, got %d", len(sources))
+	}
+	if sources[0].Href != "print.css" || sources[0].Media != "print" {
+		t.Fatalf("unexpected link source %+v", sources[0])
+	}
+
+	expected := "@import url(a.css) screen and (max-width: 600px);\n" +
+		"@media screen and (max-width: 600px) {\nbody {\n\tcolor: red;\n}\n}\n"
+	if s := string(sources[1].CSS()); s != expected {
+		t.Fatalf("got %q, expected %q", s, expected)
+	}
+	if s := string(sources[2].CSS()); s != "p {\n\tcolor: blue;\n}" {
+		t.Fatalf("stylesheet without media should not be wrapped, got %q", s)
+	}
+}
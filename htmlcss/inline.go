@@ -0,0 +1,113 @@
+package htmlcss
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/itskass/go-css"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// dynamicPseudoClasses are the pseudo-classes depending on user
+// interaction, which style attributes can not express.
+var dynamicPseudoClasses = []string{":hover", ":focus", ":active", ":visited", ":target"}
+
+// Inline moves the rules of the stylesheet into the style attributes of
+// the elements of the document they match, as HTML emails need. The
+// winning declarations are chosen by the cascade: !important, specificity
+// and source order, with the existing style attributes winning over
+// normal declarations. Rules that can not be inlined, those nested in
+// at-rules or using pseudo-elements or dynamic pseudo-classes such as
+// :hover, are kept in a <style> element added to the head, along with
+// the statements such as @import.
+func Inline(document, stylesheet []byte) ([]byte, error) {
+	doc, err := html.Parse(bytes.NewReader(document))
+	if err != nil {
+		return nil, err
+	}
+	sheet, err := css.UnmarshalStylesheet(stylesheet)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		inlined = &css.Stylesheet{}
+		kept    bytes.Buffer
+	)
+	for _, s := range sheet.Statements {
+		kept.WriteString(string(s.Prelude) + ";\n")
+	}
+	for _, r := range sheet.Rules {
+		if inlinable(r) {
+			inlined.Rules = append(inlined.Rules, r)
+			continue
+		}
+		text := r.Source.Text(stylesheet)
+		for i := len(r.AtRules) - 1; i >= 0; i-- {
+			text = []byte(string(r.AtRules[i]) + " {\n" + string(text) + "\n}")
+		}
+		kept.Write(text)
+		kept.WriteByte('\n')
+	}
+
+	c := &css.Cascade{}
+	c.AddStylesheet(inlined, css.OriginAuthor)
+	var head *html.Node
+	for _, n := range elements(doc) {
+		if n.DataAtom == atom.Head && head == nil {
+			head = n
+		}
+		style, err := css.UnmarshalStyle([]byte(attr(n, "style")))
+		if err != nil {
+			// leave the invalid style attribute as it is
+			continue
+		}
+		if decls := c.Resolve(element{n}, style); len(decls) > 0 {
+			setAttr(n, "style", decls.String())
+		}
+	}
+
+	if kept.Len() > 0 && head != nil {
+		s := &html.Node{Type: html.ElementNode, DataAtom: atom.Style, Data: "style"}
+		s.AppendChild(&html.Node{Type: html.TextNode, Data: kept.String()})
+		head.AppendChild(s)
+	}
+
+	var out bytes.Buffer
+	if err := html.Render(&out, doc); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// inlinable reports whether the declarations of the rule can be moved to
+// style attributes.
+func inlinable(r *css.StyleRule) bool {
+	if len(r.AtRules) > 0 || strings.HasPrefix(string(r.Selector), "@") {
+		return false
+	}
+	for _, part := range r.Selector.Group() {
+		if len(part.PseudoElements()) > 0 {
+			return false
+		}
+		lower := strings.ToLower(string(part))
+		for _, p := range dynamicPseudoClasses {
+			if strings.Contains(lower, p) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// setAttr sets the attribute of n, adding it when missing.
+func setAttr(n *html.Node, name, value string) {
+	for i, a := range n.Attr {
+		if a.Namespace == "" && a.Key == name {
+			n.Attr[i].Val = value
+			return
+		}
+	}
+	n.Attr = append(n.Attr, html.Attribute{Key: name, Val: value})
+}
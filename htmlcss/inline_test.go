@@ -0,0 +1,45 @@
+package htmlcss
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInline(t *testing.T) {
+	document := `<html><head></head><body><p class="intro" style="margin: 0">Hi <a href="#">there</a></p><p>Bye</p></body></html>`
+	stylesheet := `p {
+	color: black;
+	margin: 4px;
+}
+p.intro {
+	color: blue;
+}
+a {
+	color: red !important;
+}
+a:hover
+{
+	color: green;
+}
+@media (max-width: 600px) {
+	p {
+		color: gray;
+	}
+}
+`
+	out, err := Inline([]byte(document), []byte(stylesheet))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(out)
+	for _, want := range []string{
+		`<p class="intro" style="color: blue; margin: 0">`,
+		`<a href="#" style="color: red !important">`,
+		`<p style="color: black; margin: 4px">Bye</p>`,
+		"<style>a:hover\n{\n\tcolor: green;\n}\n@media (max-width: 600px) {\np {\n\t\tcolor: gray;\n\t}\n}\n</style></head>",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in\n%s", want, got)
+		}
+	}
+}
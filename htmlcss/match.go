@@ -0,0 +1,35 @@
+package htmlcss
+
+import (
+	"github.com/itskass/go-css"
+	"golang.org/x/net/html"
+)
+
+// NodeRules is an element of a document with the rules matching it.
+type NodeRules struct {
+	Node *html.Node
+	// Rules are the matching rules in document order.
+	Rules []*css.StyleRule
+}
+
+// Match reports whether the element node matches the selector. Nodes
+// that are not elements never match.
+func Match(selector css.Rule, node *html.Node) bool {
+	el := newElement(node)
+	return el != nil && selector.Matches(el)
+}
+
+// QueryAll returns the elements of the document matched by at least one
+// rule of the stylesheet, with the matching rules, in document order.
+func QueryAll(sheet *css.Stylesheet, doc *html.Node) []NodeRules {
+	var (
+		idx     = css.NewRuleIndex(sheet)
+		results = []NodeRules{}
+	)
+	for _, n := range elements(doc) {
+		if rules := idx.MatchingRules(element{n}); len(rules) > 0 {
+			results = append(results, NodeRules{n, rules})
+		}
+	}
+	return results
+}
@@ -0,0 +1,60 @@
+package htmlcss
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/itskass/go-css"
+	"golang.org/x/net/html"
+)
+
+func TestMatch(t *testing.T) {
+	doc, err := html.Parse(strings.NewReader(`<ul id="list"><li class="a">A</li><li>B</li></ul>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var li []*html.Node
+	for _, n := range elements(doc) {
+		if n.Data == "li" {
+			li = append(li, n)
+		}
+	}
+
+	tests := []struct {
+		selector css.Rule
+		node     *html.Node
+		want     bool
+	}{
+		{"#list > li.a", li[0], true},
+		{"li + li", li[1], true},
+		{"li + li", li[0], false},
+		{"ul li", li[0].FirstChild, false},
+	}
+	for _, test := range tests {
+		if got := Match(test.selector, test.node); got != test.want {
+			t.Errorf("%s: got %v, want %v", test.selector, got, test.want)
+		}
+	}
+}
+
+func TestQueryAll(t *testing.T) {
+	doc, err := html.Parse(strings.NewReader(`<div><p class="x">A</p><p>B</p><span>C</span></div>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sheet, err := css.UnmarshalStylesheet([]byte("p {\n\tcolor: red;\n}\np.x {\n\tcolor: blue;\n}\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results := QueryAll(sheet, doc)
+	if len(results) != 2 {
+		t.Fatalf("got %d nodes, want 2", len(results))
+	}
+	if n := results[0]; n.Node.FirstChild.Data != "A" || len(n.Rules) != 2 || n.Rules[0].Selector != "p" {
+		t.Errorf("first node: got %q with %d rules", n.Node.FirstChild.Data, len(n.Rules))
+	}
+	if n := results[1]; n.Node.FirstChild.Data != "B" || len(n.Rules) != 1 {
+		t.Errorf("second node: got %q with %d rules", n.Node.FirstChild.Data, len(n.Rules))
+	}
+}
@@ -0,0 +1,102 @@
+package htmlcss
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/itskass/go-css"
+	"golang.org/x/net/html"
+)
+
+// SuggestSelector returns a selector matching n and no other element of
+// its document. It prefers the most specific selector of the stylesheet
+// that does so, reporting existing as true. Otherwise it builds a new
+// minimal selector from the id, classes and tag of n, falling back to a
+// path of :nth-child() selectors from the closest ancestor that can be
+// selected on its own. sheet may be nil.
+func SuggestSelector(n *html.Node, sheet *css.Stylesheet) (selector css.Rule, existing bool) {
+	root := n
+	for root.Parent != nil {
+		root = root.Parent
+	}
+	all := elements(root)
+
+	if sheet != nil {
+		best, a, b, c := css.Rule(""), -1, -1, -1
+		for _, r := range sheet.Rules {
+			for _, part := range strings.Split(string(r.Selector), ",") {
+				rule := css.Rule(strings.TrimSpace(part))
+				if len(rule.PseudoElements()) > 0 || !unique(rule, n, all) {
+					continue
+				}
+				ra, rb, rc := rule.Specificity()
+				if ra > a || (ra == a && (rb > b || (rb == b && rc > c))) {
+					best, a, b, c = rule, ra, rb, rc
+				}
+			}
+		}
+		if best != "" {
+			return best, true
+		}
+	}
+	return newSelector(n, all), false
+}
+
+// newSelector returns a minimal selector matching only n among all.
+func newSelector(n *html.Node, all []*html.Node) css.Rule {
+	el := element{n}
+	candidates := []string{}
+	if id, ok := el.Attr("id"); ok && isIdent(id) {
+		candidates = append(candidates, "#"+id)
+	}
+	classes, _ := el.Attr("class")
+	for _, class := range strings.Fields(classes) {
+		if isIdent(class) {
+			candidates = append(candidates, "."+class, n.Data+"."+class)
+		}
+	}
+	candidates = append(candidates, n.Data)
+	for _, candidate := range candidates {
+		if unique(css.Rule(candidate), n, all) {
+			return css.Rule(candidate)
+		}
+	}
+
+	index := 1
+	for s := el.PrevSibling(); s != nil; s = s.PrevSibling() {
+		index++
+	}
+	step := css.Rule(n.Data + ":nth-child(" + strconv.Itoa(index) + ")")
+	if unique(step, n, all) || newElement(n.Parent) == nil {
+		return step
+	}
+	return newSelector(n.Parent, all) + " > " + step
+}
+
+// unique reports whether n is the only node of all matching the rule.
+func unique(rule css.Rule, n *html.Node, all []*html.Node) bool {
+	if !rule.Matches(element{n}) {
+		return false
+	}
+	for _, other := range all {
+		if other != n && rule.Matches(element{other}) {
+			return false
+		}
+	}
+	return true
+}
+
+// isIdent reports whether s can be used in a selector without escaping.
+func isIdent(s string) bool {
+	if s == "" || (s[0] >= '0' && s[0] <= '9') {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !(c == '-' || c == '_' || c >= 0x80 || (c >= 'a' && c <= 'z') ||
+			(c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,61 @@
+package htmlcss
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/itskass/go-css"
+	"golang.org/x/net/html"
+)
+
+func TestSuggestSelector(t *testing.T) {
+	doc, err := html.Parse(strings.NewReader(`<html><body>
+<div class="card"><p class="title">A</p><p>B</p></div>
+<div class="card featured"><p class="title">C</p><p>D</p></div>
+<footer id="end"><span>E</span></footer>
+</body></html>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	find := func(text string) *html.Node {
+		for _, n := range elements(doc) {
+			if n.FirstChild != nil && n.FirstChild.Type == html.TextNode && n.FirstChild.Data == text {
+				return n
+			}
+		}
+		t.Fatalf("no element with text %q", text)
+		return nil
+	}
+
+	sheet, err := css.UnmarshalStylesheet([]byte(`.title {
+	color: red;
+}
+div.featured p {
+	color: blue;
+}
+div.featured p.title {
+	color: green;
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		text     string
+		sheet    *css.Stylesheet
+		want     css.Rule
+		existing bool
+	}{
+		{"C", sheet, "div.featured p.title", true},
+		{"A", sheet, "div:nth-child(1) > p:nth-child(1)", false},
+		{"D", nil, ".featured > p:nth-child(2)", false},
+		{"E", nil, "span", false},
+	}
+	for _, test := range tests {
+		got, existing := SuggestSelector(find(test.text), test.sheet)
+		if got != test.want || existing != test.existing {
+			t.Errorf("%s: got %q %v, want %q %v", test.text, got, existing, test.want, test.existing)
+		}
+	}
+}
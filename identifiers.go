@@ -0,0 +1,146 @@
+package css
+
+import (
+	"container/list"
+	"strings"
+	"text/scanner"
+)
+
+// Identifier is a class, id, element, attribute or pseudo identifier
+// found in the selectors, with every position it occurs at.
+type Identifier struct {
+	Name      string
+	Count     int
+	Positions []scanner.Position
+}
+
+// IdentifierOptions configures IdentifiersWith.
+type IdentifierOptions struct {
+	// Dedup merges the occurrences of the same identifier into a single
+	// Identifier, in order of first appearance.
+	Dedup bool
+}
+
+// Identifiers returns all class, id, element, attribute and pseudo
+// identifiers including duplicants
+func Identifiers(tokens *list.List) []string {
+	names := []string{}
+	for _, id := range IdentifiersWith(tokens, IdentifierOptions{}) {
+		names = append(names, id.Name)
+	}
+	return names
+}
+
+// IdentifiersWith returns the identifiers of all selectors with their
+// positions. Classes and ids keep their "." and "#" prefix, attribute
+// selectors their brackets and pseudo classes and elements their colons
+// and arguments.
+func IdentifiersWith(tokens *list.List, opts IdentifierOptions) []Identifier {
+	var (
+		ids   = []Identifier{}
+		index = map[string]int{}
+	)
+
+	for _, p := range preludes(tokens) {
+		if strings.HasPrefix(p.text, "@") {
+			continue
+		}
+		for _, span := range selectorIdentifiers(p.text) {
+			name := p.text[span[0]:span[1]]
+			pos := p.position(span[0])
+			if i, ok := index[name]; ok && opts.Dedup {
+				ids[i].Count++
+				ids[i].Positions = append(ids[i].Positions, pos)
+				continue
+			}
+			index[name] = len(ids)
+			ids = append(ids, Identifier{
+				Name:      name,
+				Count:     1,
+				Positions: []scanner.Position{pos},
+			})
+		}
+	}
+
+	return ids
+}
+
+// selectorIdentifiers returns the start and end index of every identifier
+// in the selector text.
+func selectorIdentifiers(text string) [][2]int {
+	spans := [][2]int{}
+	i := 0
+	for i < len(text) {
+		c := text[i]
+		switch {
+		case c == '.' || c == '#':
+			j := scanName(text, i+1)
+			if j > i+1 {
+				spans = append(spans, [2]int{i, j})
+			}
+			i = j
+		case c == '[':
+			j := strings.IndexByte(text[i:], ']')
+			if j < 0 {
+				return spans
+			}
+			spans = append(spans, [2]int{i, i + j + 1})
+			i += j + 1
+		case c == ':':
+			j := i + 1
+			if j < len(text) && text[j] == ':' {
+				j++
+			}
+			j = scanName(text, j)
+			if j < len(text) && text[j] == '(' {
+				j = scanParens(text, j)
+			}
+			spans = append(spans, [2]int{i, j})
+			i = j
+		case isNameByte(c) && (i == 0 || strings.IndexByte(" >+~,(", text[i-1]) >= 0):
+			j := scanName(text, i)
+			spans = append(spans, [2]int{i, j})
+			i = j
+		default:
+			i++
+		}
+	}
+	return spans
+}
+
+// scanName returns the index after the name starting at i.
+func scanName(text string, i int) int {
+	for i < len(text) {
+		if text[i] == '\\' && i+1 < len(text) {
+			i += 2
+			continue
+		}
+		if !isNameByte(text[i]) {
+			break
+		}
+		i++
+	}
+	return i
+}
+
+// scanParens returns the index after the parenthesis opened at i.
+func scanParens(text string, i int) int {
+	depth := 0
+	for ; i < len(text); i++ {
+		switch text[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return i
+}
+
+func isNameByte(c byte) bool {
+	return c == '-' || c == '_' || c >= 0x80 ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
@@ -0,0 +1,46 @@
+package css
+
+import "testing"
+
+func TestIdentifiers(t *testing.T) {
+	ex1 := `div.box #main {
+	style1: value1;
+}
+input[type="text"] .box {
+	style2: value2;
+}
+li:nth-child(2n+1)
+{
+	style3: value3;
+}`
+
+	t.Run("All", func(t *testing.T) {
+		expected := []string{"div", ".box", "#main", "input", `[type="text"]`, ".box", "li", ":nth-child(2n+1)"}
+		names := Identifiers(Tokenize([]byte(ex1)))
+		if len(names) != len(expected) {
+			t.Fatalf("expected %q, got %q", expected, names)
+		}
+		for i := range expected {
+			if names[i] != expected[i] {
+				t.Fatalf("expected %q, got %q", expected[i], names[i])
+			}
+		}
+	})
+
+	t.Run("Dedup", func(t *testing.T) {
+		ids := IdentifiersWith(Tokenize([]byte(ex1)), IdentifierOptions{Dedup: true})
+		if len(ids) != 7 {
+			t.Fatalf("expected 7 identifiers, got %d", len(ids))
+		}
+		box := ids[1]
+		if box.Name != ".box" || box.Count != 2 {
+			t.Fatalf("expected '.box' twice, got %q %d times", box.Name, box.Count)
+		}
+		if box.Positions[0].Line != 1 || box.Positions[0].Column != 4 {
+			t.Fatalf("wrong position for first '.box': %v", box.Positions[0])
+		}
+		if box.Positions[1].Line != 4 || box.Positions[1].Column != 20 {
+			t.Fatalf("wrong position for second '.box': %v", box.Positions[1])
+		}
+	})
+}
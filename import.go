@@ -0,0 +1,330 @@
+package css
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"text/scanner"
+	"time"
+)
+
+// Import is an @import statement such as
+// @import url("a.css") layer(base) supports(display: grid) screen;
+type Import struct {
+	// URL is the url of the imported stylesheet, unquoted.
+	URL string
+	// Layered is set when the stylesheet is imported into a cascade layer,
+	// named by Layer or anonymous when Layer is empty.
+	Layered bool
+	Layer   string
+	// Supports is the condition of supports(), without the function.
+	Supports string
+	// Media is the media query list, empty when not set.
+	Media  string
+	Pos    scanner.Position
+	Source SourceRange
+}
+
+// ParseImport parses the prelude of an @import statement, with or without
+// the trailing semicolon. It returns false if the prelude is not a valid
+// @import.
+func ParseImport(prelude string) (Import, bool) {
+	imp := Import{}
+	if atRuleName(prelude) != "import" {
+		return imp, false
+	}
+	rest := strings.TrimSuffix(strings.TrimSpace(prelude), ";")
+	rest = strings.TrimSpace(rest[len("@import"):])
+	switch {
+	case strings.HasPrefix(rest, `"`) || strings.HasPrefix(rest, "'"):
+		end := scanString(rest, 0)
+		imp.URL, rest = unquote(rest[:end]), rest[end:]
+	case strings.HasPrefix(strings.ToLower(rest), "url("):
+		end := scanFunction(rest, 3)
+		if end == len(rest) {
+			return imp, false
+		}
+		imp.URL = strings.TrimSpace(rest[4:end])
+		if strings.HasPrefix(imp.URL, `"`) || strings.HasPrefix(imp.URL, "'") {
+			imp.URL = unquote(imp.URL)
+		}
+		rest = rest[end+1:]
+	default:
+		return imp, false
+	}
+
+	rest = strings.TrimSpace(rest)
+	lower := strings.ToLower(rest)
+	switch {
+	case strings.HasPrefix(lower, "layer("):
+		end := scanFunction(rest, 5)
+		imp.Layered, imp.Layer = true, strings.TrimSpace(rest[6:end])
+		rest = rest[min(end+1, len(rest)):]
+	case lower == "layer" || (strings.HasPrefix(lower, "layer") && !isNameByte(lower[5])):
+		imp.Layered = true
+		rest = rest[5:]
+	}
+
+	rest = strings.TrimSpace(rest)
+	if strings.HasPrefix(strings.ToLower(rest), "supports(") {
+		end := scanFunction(rest, 8)
+		imp.Supports = strings.TrimSpace(rest[9:end])
+		rest = rest[min(end+1, len(rest)):]
+	}
+	imp.Media = strings.TrimSpace(rest)
+	return imp, true
+}
+
+// Imports returns the @import statements of the stylesheet in b.
+func Imports(b []byte) []Import {
+	imports := []Import{}
+	for _, s := range RuleSources(b) {
+		if !strings.HasSuffix(string(s.Text(b)), ";") {
+			continue
+		}
+		if imp, ok := ParseImport(string(s.Prelude)); ok {
+			imp.Pos = offsetPosition(b, s.Start)
+			imp.Source = s.SourceRange
+			imports = append(imports, imp)
+		}
+	}
+	return imports
+}
+
+// Imports returns the @import statements of a stylesheet returned by
+// ParseStylesheet.
+func (sheet *Stylesheet) Imports() []Import {
+	imports := []Import{}
+	for _, s := range sheet.Statements {
+		if imp, ok := ParseImport(string(s.Prelude)); ok {
+			imp.Pos = s.Pos
+			imp.Source = s.Source
+			imports = append(imports, imp)
+		}
+	}
+	return imports
+}
+
+var (
+	// ErrNotResolved is returned by resolvers for the imports they leave
+	// to the browser, such as remote urls for a file system resolver.
+	ErrNotResolved = errors.New("css: import not resolved")
+	// ErrImportCycle is returned by ResolveImports when a stylesheet
+	// imports itself, directly or not.
+	ErrImportCycle = errors.New("css: import cycle")
+)
+
+// Resolver loads the stylesheets imported with @import.
+type Resolver interface {
+	// Resolve returns the name and content of the stylesheet imported
+	// with the url from the stylesheet named base. The name identifies
+	// the stylesheet to detect cycles and is the base of its own imports.
+	Resolve(base, url string) (name string, data []byte, err error)
+}
+
+// ResolverFunc is a function implementing Resolver.
+type ResolverFunc func(base, url string) (string, []byte, error)
+
+func (f ResolverFunc) Resolve(base, url string) (string, []byte, error) {
+	return f(base, url)
+}
+
+// FSResolver resolves imports from a file system. Relative urls are
+// relative to the importing file, absolute ones to the root of the file
+// system. Urls with a scheme, such as https: or data:, are not resolved.
+type FSResolver struct {
+	FS fs.FS
+}
+
+func (r FSResolver) Resolve(base, u string) (string, []byte, error) {
+	if strings.Contains(u, ":") || strings.HasPrefix(u, "//") {
+		return "", nil, ErrNotResolved
+	}
+	name := u
+	if strings.HasPrefix(u, "/") {
+		name = strings.TrimPrefix(path.Clean(u), "/")
+	} else {
+		name = path.Join(path.Dir(base), u)
+	}
+	data, err := fs.ReadFile(r.FS, name)
+	return name, data, err
+}
+
+// HTTPResolver resolves imports over HTTP. Relative urls are resolved
+// against the url of the importing stylesheet, which must be absolute.
+type HTTPResolver struct {
+	// Client sends the requests, http.DefaultClient when nil.
+	Client *http.Client
+	// Cache stores the downloaded stylesheets when set. Fresh entries,
+	// per Cache-Control max-age or Expires, are used without a request;
+	// stale ones are revalidated with their ETag and Last-Modified.
+	Cache ImportCache
+}
+
+func (r HTTPResolver) Resolve(base, u string) (string, []byte, error) {
+	target, err := resolveURL(base, u)
+	if err != nil {
+		return "", nil, err
+	}
+	if target.Scheme != "http" && target.Scheme != "https" {
+		return "", nil, ErrNotResolved
+	}
+	name := target.String()
+
+	var (
+		cached CachedImport
+		ok     bool
+		now    = time.Now()
+	)
+	if r.Cache != nil {
+		if cached, ok = r.Cache.Get(name); ok && cached.fresh(now) {
+			return name, cached.Data, nil
+		}
+	}
+
+	req, err := http.NewRequest(http.MethodGet, name, nil)
+	if err != nil {
+		return "", nil, err
+	}
+	if ok {
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+	client := r.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", nil, err
+	}
+	defer resp.Body.Close()
+
+	data := cached.Data
+	switch {
+	case resp.StatusCode == http.StatusNotModified && ok:
+		// the headers of a 304 update the freshness of the entry
+		if resp.Header.Get("ETag") == "" {
+			resp.Header.Set("ETag", cached.ETag)
+		}
+		if resp.Header.Get("Last-Modified") == "" {
+			resp.Header.Set("Last-Modified", cached.LastModified)
+		}
+	case resp.StatusCode/100 == 2:
+		if data, err = io.ReadAll(resp.Body); err != nil {
+			return "", nil, err
+		}
+	default:
+		return "", nil, fmt.Errorf("css: import %s: %s", name, resp.Status)
+	}
+
+	if r.Cache != nil {
+		if entry, store := cacheEntry(resp.Header, data, now); store {
+			if err := r.Cache.Put(name, entry); err != nil {
+				return "", nil, err
+			}
+		}
+	}
+	return name, data, nil
+}
+
+// resolveURL resolves the url against the base url.
+func resolveURL(base, u string) (*url.URL, error) {
+	ref, err := url.Parse(u)
+	if err != nil {
+		return nil, err
+	}
+	b, err := url.Parse(base)
+	if err != nil {
+		return nil, err
+	}
+	return b.ResolveReference(ref), nil
+}
+
+// ResolveImports returns the stylesheet named name with its @import
+// statements replaced by the imported stylesheets, recursively. The
+// imported stylesheets are wrapped in @layer, @supports and @media rules
+// for the conditions of their imports, and their @charset statements are
+// dropped. Imports the resolver returns ErrNotResolved for are kept, but
+// browsers ignore them unless they stay at the start of the top level
+// stylesheet. An import cycle fails with ErrImportCycle.
+func ResolveImports(name string, b []byte, r Resolver) ([]byte, error) {
+	return resolveImports(name, b, r, []string{name})
+}
+
+func resolveImports(name string, b []byte, r Resolver, stack []string) ([]byte, error) {
+	var (
+		out  bytes.Buffer
+		last = 0
+	)
+	for _, imp := range Imports(b) {
+		target, data, err := r.Resolve(name, imp.URL)
+		if errors.Is(err, ErrNotResolved) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d:%d: %w", name, imp.Pos.Line, imp.Pos.Column, err)
+		}
+		if containsString(stack, target) {
+			return nil, fmt.Errorf("%w: %s", ErrImportCycle, strings.Join(append(stack, target), " -> "))
+		}
+		data, err = resolveImports(target, data, r, append(stack[:len(stack):len(stack)], target))
+		if err != nil {
+			return nil, err
+		}
+
+		out.Write(b[last:imp.Source.Start])
+		out.Write(imp.wrap(dropCharset(data)))
+		last = imp.Source.End
+	}
+	out.Write(b[last:])
+	return out.Bytes(), nil
+}
+
+// wrap wraps the imported stylesheet in the at-rules of the conditions
+// of the import.
+func (imp Import) wrap(b []byte) []byte {
+	b = bytes.TrimSpace(WrapMedia(imp.Media, b))
+	if condition := imp.Supports; condition != "" {
+		// supports(display: grid) holds a declaration, which needs
+		// parentheses in @supports
+		if !strings.HasPrefix(condition, "(") && strings.Contains(condition, ":") {
+			condition = "(" + condition + ")"
+		}
+		b = []byte("@supports " + condition + " {\n" + string(b) + "\n}")
+	}
+	if imp.Layered {
+		prelude := "@layer "
+		if imp.Layer != "" {
+			prelude += imp.Layer + " "
+		}
+		b = []byte(prelude + "{\n" + string(b) + "\n}")
+	}
+	return b
+}
+
+// dropCharset removes the @charset statements of the stylesheet.
+func dropCharset(b []byte) []byte {
+	var (
+		out  bytes.Buffer
+		last = 0
+	)
+	for _, s := range RuleSources(b) {
+		if atRuleName(string(s.Prelude)) == "charset" {
+			out.Write(b[last:s.Start])
+			last = s.End
+		}
+	}
+	out.Write(b[last:])
+	return out.Bytes()
+}
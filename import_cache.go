@@ -0,0 +1,147 @@
+package css
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ImportCache stores the stylesheets downloaded by HTTPResolver, so
+// repeated runs don't download unchanged stylesheets again.
+type ImportCache interface {
+	// Get returns the entry of the url, false if there is none.
+	Get(url string) (CachedImport, bool)
+	Put(url string, entry CachedImport) error
+}
+
+// CachedImport is a downloaded stylesheet with the headers to revalidate
+// it.
+type CachedImport struct {
+	Data         []byte `json:"data"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+	// Expires is the end of the freshness lifetime given by the
+	// Cache-Control max-age directive or the Expires header. Entries are
+	// revalidated with the server after it, or always when it is zero.
+	Expires time.Time `json:"expires,omitempty"`
+}
+
+// fresh reports whether the entry can be used without revalidation.
+func (e CachedImport) fresh(now time.Time) bool {
+	return !e.Expires.IsZero() && now.Before(e.Expires)
+}
+
+// MemoryImportCache is an ImportCache in memory, for the lifetime of a
+// dev server. The zero value is an empty cache.
+type MemoryImportCache struct {
+	mu      sync.Mutex
+	entries map[string]CachedImport
+}
+
+func (c *MemoryImportCache) Get(url string) (CachedImport, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[url]
+	return e, ok
+}
+
+func (c *MemoryImportCache) Put(url string, entry CachedImport) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = map[string]CachedImport{}
+	}
+	c.entries[url] = entry
+	return nil
+}
+
+// DirImportCache is an ImportCache storing one file per url in a
+// directory, so the entries survive across runs such as CI builds.
+type DirImportCache string
+
+// path returns the file of the entry of the url.
+func (dir DirImportCache) path(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(string(dir), hex.EncodeToString(sum[:])+".json")
+}
+
+func (dir DirImportCache) Get(url string) (CachedImport, bool) {
+	b, err := os.ReadFile(dir.path(url))
+	if err != nil {
+		return CachedImport{}, false
+	}
+	var e CachedImport
+	if err := json.Unmarshal(b, &e); err != nil {
+		return CachedImport{}, false
+	}
+	return e, true
+}
+
+func (dir DirImportCache) Put(url string, entry CachedImport) error {
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(string(dir), 0o755); err != nil {
+		return err
+	}
+	// write to a temporary file first so concurrent runs never read a
+	// partial entry
+	tmp, err := os.CreateTemp(string(dir), "tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), dir.path(url))
+}
+
+// cacheEntry returns the cache entry of the response at the time it was
+// received, false if the response must not be stored.
+func cacheEntry(header http.Header, data []byte, now time.Time) (CachedImport, bool) {
+	e := CachedImport{
+		Data:         data,
+		ETag:         header.Get("ETag"),
+		LastModified: header.Get("Last-Modified"),
+	}
+	maxAge, hasMaxAge := -1, false
+	for _, directive := range strings.Split(header.Get("Cache-Control"), ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		switch strings.ToLower(name) {
+		case "no-store":
+			return e, false
+		case "no-cache":
+			// store but always revalidate
+			return e, true
+		case "max-age":
+			if n, err := strconv.Atoi(strings.Trim(value, `"`)); err == nil {
+				maxAge, hasMaxAge = n, true
+			}
+		}
+	}
+	switch {
+	case hasMaxAge:
+		if maxAge > 0 {
+			e.Expires = now.Add(time.Duration(maxAge) * time.Second)
+		}
+	case header.Get("Expires") != "":
+		if t, err := http.ParseTime(header.Get("Expires")); err == nil && t.After(now) {
+			e.Expires = t
+		}
+	}
+	return e, true
+}
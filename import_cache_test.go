@@ -0,0 +1,74 @@
+package css
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHTTPResolverCache(t *testing.T) {
+	requests, downloads := 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch r.URL.Path {
+		case "/fresh.css":
+			w.Header().Set("Cache-Control", "public, max-age=3600")
+		case "/etag.css":
+			w.Header().Set("Cache-Control", "no-cache")
+			w.Header().Set("ETag", `"v1"`)
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		case "/private.css":
+			w.Header().Set("Cache-Control", "no-store")
+		}
+		downloads++
+		w.Write([]byte("p {\n\tcolor: red;\n}\n"))
+	}))
+	defer server.Close()
+
+	for name, cache := range map[string]ImportCache{
+		"memory": &MemoryImportCache{},
+		"dir":    DirImportCache(t.TempDir()),
+	} {
+		r := HTTPResolver{Cache: cache}
+		for _, test := range []struct {
+			path                string
+			requests, downloads int
+		}{
+			{"/fresh.css", 1, 1},
+			{"/etag.css", 2, 1},
+			{"/private.css", 2, 2},
+		} {
+			requests, downloads = 0, 0
+			for i := 0; i < 2; i++ {
+				_, data, err := r.Resolve("", server.URL+test.path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(data) != "p {\n\tcolor: red;\n}\n" {
+					t.Fatalf("%s %s: got %q", name, test.path, data)
+				}
+			}
+			if requests != test.requests || downloads != test.downloads {
+				t.Errorf("%s %s: got %d requests and %d downloads, want %d and %d",
+					name, test.path, requests, downloads, test.requests, test.downloads)
+			}
+		}
+	}
+}
+
+func TestCacheEntry(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	header := http.Header{}
+	header.Set("Expires", now.Add(time.Hour).Format(http.TimeFormat))
+	if e, ok := cacheEntry(header, nil, now); !ok || !e.Expires.Equal(now.Add(time.Hour)) {
+		t.Errorf("Expires: got %v %v", e.Expires, ok)
+	}
+	header.Set("Cache-Control", "max-age=0")
+	if e, ok := cacheEntry(header, nil, now); !ok || !e.Expires.IsZero() {
+		t.Errorf("max-age=0: got %v %v", e.Expires, ok)
+	}
+}
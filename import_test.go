@@ -0,0 +1,128 @@
+package css
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestParseImport(t *testing.T) {
+	tests := []struct {
+		prelude string
+		want    Import
+	}{
+		{`@import "a.css"`, Import{URL: "a.css"}},
+		{`@import url("a.css") screen;`, Import{URL: "a.css", Media: "screen"}},
+		{`@import url(a.css) layer`, Import{URL: "a.css", Layered: true}},
+		{`@import 'a.css' layer(base.theme) supports(display: grid) print and (min-width: 10px);`,
+			Import{URL: "a.css", Layered: true, Layer: "base.theme", Supports: "display: grid", Media: "print and (min-width: 10px)"}},
+		{`@import url(a.css) layers`, Import{URL: "a.css", Media: "layers"}},
+	}
+	for _, test := range tests {
+		got, ok := ParseImport(test.prelude)
+		if !ok || !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %+v %v, want %+v", test.prelude, got, ok, test.want)
+		}
+	}
+	for _, prelude := range []string{"@media print", "@import", "@import url(a.css"} {
+		if _, ok := ParseImport(prelude); ok {
+			t.Errorf("%s: parsed", prelude)
+		}
+	}
+}
+
+func TestImports(t *testing.T) {
+	b := []byte("@charset \"utf-8\";\n@import url(a.css);\n@import \"b.css\" print;\np {\n\tcolor: red;\n}\n")
+	imports := Imports(b)
+	if len(imports) != 2 || imports[0].URL != "a.css" || imports[1].Media != "print" {
+		t.Fatalf("got %+v", imports)
+	}
+	if imports[1].Pos.Line != 3 || string(imports[1].Source.Text(b)) != `@import "b.css" print;` {
+		t.Errorf("wrong location %+v", imports[1])
+	}
+
+	sheet, err := ParseStylesheet(strings.NewReader(string(b)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := sheet.Imports(); !reflect.DeepEqual(got, imports) {
+		t.Errorf("got %+v, want %+v", got, imports)
+	}
+}
+
+func TestResolveImports(t *testing.T) {
+	fsys := fstest.MapFS{
+		"main.css":        {Data: []byte("@import url(https://fonts.example.com/a.css);\n@import \"parts/base.css\" layer(base);\np {\n\tcolor: red;\n}\n")},
+		"parts/base.css":  {Data: []byte("@charset \"utf-8\";\n@import \"print.css\" print;\nbody {\n\tmargin: 0;\n}\n")},
+		"parts/print.css": {Data: []byte("a {\n\tcolor: black;\n}\n")},
+		"loop/a.css":      {Data: []byte("@import \"b.css\";\n")},
+		"loop/b.css":      {Data: []byte("@import \"/loop/a.css\";\n")},
+	}
+	r := FSResolver{fsys}
+
+	got, err := ResolveImports("main.css", fsys["main.css"].Data, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `@import url(https://fonts.example.com/a.css);
+@layer base {
+@media print {
+a {
+	color: black;
+}
+}
+body {
+	margin: 0;
+}
+}
+p {
+	color: red;
+}
+`
+	if string(got) != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	_, err = ResolveImports("loop/a.css", fsys["loop/a.css"].Data, r)
+	if !errors.Is(err, ErrImportCycle) || !strings.Contains(err.Error(), "loop/a.css -> loop/b.css -> loop/a.css") {
+		t.Errorf("got %v", err)
+	}
+
+	_, err = ResolveImports("main.css", []byte("@import \"missing.css\";\n"), r)
+	if err == nil || !strings.HasPrefix(err.Error(), "main.css:1:1: ") {
+		t.Errorf("got %v", err)
+	}
+}
+
+func TestUnmarshalWithResolver(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/a.css":
+			w.Write([]byte("@import \"b.css\" supports(display: grid);\n"))
+		case "/b.css":
+			w.Write([]byte("div {\n\tdisplay: grid;\n}\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	opts := ParseOptions{Resolver: HTTPResolver{}}
+	sheet, err := UnmarshalStylesheetWithOptions([]byte("@import url("+server.URL+"/a.css);\n"), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sheet.Rules) != 1 || sheet.Rules[0].Selector != "div" ||
+		!reflect.DeepEqual(sheet.Rules[0].AtRules, []Rule{"@supports (display: grid)"}) {
+		t.Fatalf("got %+v", sheet.Rules)
+	}
+
+	_, err = UnmarshalWithOptions([]byte("@import url("+server.URL+"/missing.css);\n"), opts)
+	if err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("got %v", err)
+	}
+}
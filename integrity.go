@@ -0,0 +1,137 @@
+package css
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"sort"
+	"strings"
+)
+
+// SRIOptions configures the subresource integrity hashes computed by
+// ComputeSRI, MinifyWithSRI and BundleWithSRI.
+type SRIOptions struct {
+	// Algorithms are the hash algorithms, "sha256", "sha384" or "sha512".
+	// Every algorithm adds a hash to the integrity values. The default is
+	// sha384.
+	Algorithms []string
+	// Asset returns the content of an asset referenced by the css. When
+	// it is nil, only the css itself is hashed.
+	Asset func(url string) ([]byte, error)
+}
+
+// SRI is the integrity of emitted css and of the assets it references,
+// for templates rendering integrity attributes.
+type SRI struct {
+	// Integrity is the value of the integrity attribute of the css, such
+	// as "sha384-...".
+	Integrity string
+	// Assets is the integrity of every asset, by url: the imported
+	// stylesheets, the fonts and the images. Data urls are not listed.
+	Assets map[string]string
+}
+
+// sriHashes are the hash algorithms allowed in integrity attributes.
+var sriHashes = map[string]func() hash.Hash{
+	"sha256": sha256.New,
+	"sha384": sha512.New384,
+	"sha512": sha512.New,
+}
+
+// Integrity returns the integrity value of b with the given algorithms,
+// separated by spaces, or sha384 when no algorithm is given.
+func Integrity(b []byte, algorithms ...string) (string, error) {
+	if len(algorithms) == 0 {
+		algorithms = []string{"sha384"}
+	}
+	hashes := make([]string, len(algorithms))
+	for i, alg := range algorithms {
+		newHash, ok := sriHashes[strings.ToLower(alg)]
+		if !ok {
+			return "", fmt.Errorf("unknown integrity algorithm %q", alg)
+		}
+		h := newHash()
+		h.Write(b)
+		hashes[i] = strings.ToLower(alg) + "-" + base64.StdEncoding.EncodeToString(h.Sum(nil))
+	}
+	return strings.Join(hashes, " "), nil
+}
+
+// ComputeSRI returns the integrity of the css in b and, if opts.Asset is
+// set, of the assets it references.
+func ComputeSRI(b []byte, opts SRIOptions) (*SRI, error) {
+	integrity, err := Integrity(b, opts.Algorithms...)
+	if err != nil {
+		return nil, err
+	}
+	sri := &SRI{Integrity: integrity, Assets: map[string]string{}}
+	if opts.Asset == nil {
+		return sri, nil
+	}
+	for _, url := range assetURLs(b) {
+		data, err := opts.Asset(url)
+		if err != nil {
+			return nil, fmt.Errorf("asset %q: %v", url, err)
+		}
+		if sri.Assets[url], err = Integrity(data, opts.Algorithms...); err != nil {
+			return nil, err
+		}
+	}
+	return sri, nil
+}
+
+// MinifyWithSRI is like Minify, also returning the integrity of the
+// output and of its assets.
+func MinifyWithSRI(b []byte, level MinifyLevel, opts SRIOptions) ([]byte, *SRI, error) {
+	out, err := Minify(b, level)
+	if err != nil {
+		return nil, nil, err
+	}
+	sri, err := ComputeSRI(out, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return out, sri, nil
+}
+
+// BundleWithSRI is like Bundle, also returning the integrity of the
+// bundle and of its assets.
+func BundleWithSRI(opts SRIOptions, files ...SourceFile) ([]byte, *Stylesheet, *SRI, error) {
+	out, sheet, err := Bundle(files...)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	sri, err := ComputeSRI(out, opts)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return out, sheet, sri, nil
+}
+
+// assetURLs returns the sorted urls of the imports and of the url()
+// functions of the css, without duplicates.
+func assetURLs(b []byte) []string {
+	seen := map[string]bool{}
+	for _, s := range RuleSources(b) {
+		if url := importURL(string(s.Prelude)); url != "" {
+			seen[url] = true
+		}
+	}
+	sheet, _ := UnmarshalStylesheet(b)
+	for _, r := range sheet.Rules {
+		for _, d := range r.Declarations {
+			for _, url := range componentURLs(d.TypedComponents()) {
+				seen[url] = true
+			}
+		}
+	}
+
+	urls := make([]string, 0, len(seen))
+	for url := range seen {
+		urls = append(urls, url)
+	}
+	sort.Strings(urls)
+	return urls
+}
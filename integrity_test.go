@@ -0,0 +1,76 @@
+package css
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestIntegrity(t *testing.T) {
+	got, err := Integrity([]byte(""), "sha256")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "sha256-47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU="; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	got, _ = Integrity([]byte("a{}"))
+	if !strings.HasPrefix(got, "sha384-") {
+		t.Errorf("default algorithm: got %q", got)
+	}
+	got, _ = Integrity([]byte("a{}"), "sha256", "sha512")
+	if parts := strings.Fields(got); len(parts) != 2 || !strings.HasPrefix(parts[1], "sha512-") {
+		t.Errorf("several algorithms: got %q", got)
+	}
+
+	if _, err := Integrity(nil, "md5"); err == nil {
+		t.Error("expected an error for md5")
+	}
+}
+
+func TestMinifyWithSRI(t *testing.T) {
+	src := `@import "base.css";
+@font-face { font-family: X; src: url(x.woff2) format("woff2"); }
+.a { background: url(a.png), url("data:image/png;base64,AAAA"); }
+.b { background-image: url(a.png); }
+`
+	var loaded []string
+	opts := SRIOptions{
+		Algorithms: []string{"sha256"},
+		Asset: func(url string) ([]byte, error) {
+			loaded = append(loaded, url)
+			return []byte(url), nil
+		},
+	}
+	out, sri, err := MinifyWithSRI([]byte(src), MinifySafe, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, _ := Integrity(out, "sha256"); sri.Integrity != want {
+		t.Errorf("got integrity %q, want %q", sri.Integrity, want)
+	}
+	if strings.Join(loaded, " ") != "a.png base.css x.woff2" {
+		t.Errorf("loaded %v", loaded)
+	}
+	if want, _ := Integrity([]byte("a.png"), "sha256"); sri.Assets["a.png"] != want {
+		t.Errorf("got asset integrity %q, want %q", sri.Assets["a.png"], want)
+	}
+
+	opts.Asset = func(url string) ([]byte, error) { return nil, errors.New("not found") }
+	if _, _, err := MinifyWithSRI([]byte(src), MinifySafe, opts); err == nil {
+		t.Error("expected an error for a missing asset")
+	}
+}
+
+func TestComputeSRIImports(t *testing.T) {
+	sri, err := ComputeSRI([]byte(`@import url("base.css") screen;`), SRIOptions{
+		Asset: func(url string) ([]byte, error) { return []byte(url), nil },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, _ := Integrity([]byte("base.css")); len(sri.Assets) != 1 || sri.Assets["base.css"] != want {
+		t.Errorf("got assets %v", sri.Assets)
+	}
+}
@@ -0,0 +1,48 @@
+package css
+
+import "strings"
+
+// maxInternLength is the length of the longest value interned. Longer
+// values are rarely repeated.
+const maxInternLength = 32
+
+// interner deduplicates the strings of a parse. A nil interner returns
+// every string unchanged.
+type interner map[string]string
+
+func newInterner(enabled bool) interner {
+	if !enabled {
+		return nil
+	}
+	return interner{}
+}
+
+// intern returns the stored string equal to s, storing s if there is
+// none.
+func (in interner) intern(s string) string {
+	if in == nil {
+		return s
+	}
+	if stored, ok := in[s]; ok {
+		return stored
+	}
+	in[s] = s
+	return s
+}
+
+// declaration returns d with its property and, for single keywords and
+// short values, its value interned.
+func (in interner) declaration(d Declaration) Declaration {
+	if in == nil {
+		return d
+	}
+	d.Property = in.intern(d.Property)
+	if len(d.Value) <= maxInternLength && !strings.ContainsAny(d.Value, " \t\n") {
+		raw := d.Raw == d.Value
+		d.Value = in.intern(d.Value)
+		if raw {
+			d.Raw = d.Value
+		}
+	}
+	return d
+}
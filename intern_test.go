@@ -0,0 +1,76 @@
+package css
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"unsafe"
+)
+
+func TestParseIntern(t *testing.T) {
+	ex := []byte(".a {\n\tcolor: red;\n\tmargin: 0 auto;\n}\n.b {\n\tcolor: red;\n\tmargin: 0 auto;\n}\n")
+	blocks, _ := (ParseOptions{Intern: true}).unmarshal(ex)
+	if len(blocks) != 2 {
+		t.Fatalf("expected 2 blocks, got %d", len(blocks))
+	}
+	a, b := blocks[0].styles[0], blocks[1].styles[0]
+	if unsafe.StringData(a.Property) != unsafe.StringData(b.Property) {
+		t.Error("property names are not shared")
+	}
+	if unsafe.StringData(a.Value) != unsafe.StringData(b.Value) {
+		t.Error("keyword values are not shared")
+	}
+	if a.Raw != "red" || a.Value != "red" {
+		t.Errorf("unexpected declaration %#v", a)
+	}
+
+	plain, _ := (ParseOptions{}).unmarshal(ex)
+	if unsafe.StringData(plain[0].styles[0].Property) == unsafe.StringData(plain[1].styles[0].Property) {
+		t.Error("property names are shared without Intern")
+	}
+}
+
+// bundle returns a stylesheet with many rules repeating the same
+// properties and keywords.
+func bundle(rules int) []byte {
+	var b strings.Builder
+	for i := 0; i < rules; i++ {
+		fmt.Fprintf(&b, ".rule%d {\n\tcolor: red;\n\tdisplay: block;\n\tmargin: 0;\n\tposition: relative;\n}\n", i)
+	}
+	return []byte(b.String())
+}
+
+func BenchmarkUnmarshalStylesheet(b *testing.B) {
+	for _, intern := range []bool{false, true} {
+		b.Run(fmt.Sprintf("Intern=%v", intern), func(b *testing.B) {
+			src := bundle(1000)
+			opts := ParseOptions{Intern: intern}
+			b.ReportAllocs()
+			b.ResetTimer()
+			var sheet *Stylesheet
+			for i := 0; i < b.N; i++ {
+				blocks, _ := opts.unmarshal(src)
+				sheet = newStylesheet(blocks)
+			}
+			b.ReportMetric(float64(declarationStorage(sheet)), "decl-bytes")
+		})
+	}
+}
+
+// declarationStorage returns the bytes of string data held by the
+// declarations of the stylesheet, counting shared strings once.
+func declarationStorage(sheet *Stylesheet) int {
+	seen := map[*byte]bool{}
+	size := 0
+	for _, r := range sheet.Rules {
+		for _, d := range r.Declarations {
+			for _, s := range []string{d.Property, d.Value, d.Raw} {
+				if p := unsafe.StringData(s); p != nil && !seen[p] {
+					seen[p] = true
+					size += len(s)
+				}
+			}
+		}
+	}
+	return size
+}
@@ -0,0 +1,248 @@
+package css
+
+import (
+	"strconv"
+	"strings"
+	"text/scanner"
+)
+
+// Keyframes is an @keyframes rule.
+type Keyframes struct {
+	// Name is the animation name, unquoted.
+	Name string
+	// Vendor is the vendor prefix of the at-rule, such as "-webkit-",
+	// and empty for @keyframes.
+	Vendor string
+	Frames []Keyframe
+	// Pos is the position of the first keyframe, or of the rule when it
+	// has none.
+	Pos scanner.Position
+	// AtRules are the at-rules the rule is nested in, such as @media.
+	AtRules []Rule
+}
+
+// Keyframe is a keyframe block of an @keyframes rule.
+type Keyframe struct {
+	// Selector is the keyframe selector as written, such as "from" or
+	// "0%, 100%".
+	Selector Rule
+	// Offsets are the positions of the keyframe between 0 and 1, in the
+	// order of the selector: from is 0, to is 1 and 50% is 0.5. Invalid
+	// selectors have none.
+	Offsets      []float64
+	Declarations Declarations
+	Pos          scanner.Position
+}
+
+// Keyframes returns the @keyframes rules of the stylesheet, including the
+// prefixed ones, in document order. Their keyframes are not part of the
+// map returned by ToLegacyMap.
+func (sheet *Stylesheet) Keyframes() []Keyframes {
+	all := []Keyframes{}
+	var last Rule
+	for _, r := range sheet.Rules {
+		prelude, outer := r.keyframes()
+		frame := true
+		if prelude == "" && isKeyframesPrelude(r.Selector) {
+			// @keyframes without keyframes
+			prelude, outer, frame = r.Selector, r.AtRules, false
+		}
+		if prelude == "" {
+			continue
+		}
+
+		// keyframes of the same rule are consecutive
+		if !frame || len(all) == 0 || last != prelude {
+			name := atRuleName(string(prelude))
+			k := Keyframes{
+				Name:    strings.TrimSpace(strings.TrimSpace(string(prelude))[len(name)+1:]),
+				Vendor:  strings.TrimSuffix(name, "keyframes"),
+				Frames:  []Keyframe{},
+				Pos:     r.Pos,
+				AtRules: outer,
+			}
+			if strings.HasPrefix(k.Name, `"`) || strings.HasPrefix(k.Name, "'") {
+				k.Name = unquote(k.Name)
+			}
+			all = append(all, k)
+			last = prelude
+		}
+		if frame {
+			k := &all[len(all)-1]
+			k.Frames = append(k.Frames, Keyframe{
+				Selector:     r.Selector,
+				Offsets:      keyframeOffsets(string(r.Selector)),
+				Declarations: r.Declarations,
+				Pos:          r.Pos,
+			})
+		}
+	}
+	return all
+}
+
+// keyframes returns the innermost @keyframes rule the rule is nested in
+// with the at-rules around it, or an empty prelude.
+func (r *StyleRule) keyframes() (Rule, []Rule) {
+	for i := len(r.AtRules) - 1; i >= 0; i-- {
+		if isKeyframesPrelude(r.AtRules[i]) {
+			return r.AtRules[i], r.AtRules[:i]
+		}
+	}
+	return "", nil
+}
+
+func isKeyframesPrelude(prelude Rule) bool {
+	return strings.HasSuffix(atRuleName(string(prelude)), "keyframes")
+}
+
+// keyframeOffsets returns the offsets of a keyframe selector, or nil if
+// it is invalid.
+func keyframeOffsets(selector string) []float64 {
+	offsets := []float64{}
+	for _, part := range strings.Split(selector, ",") {
+		switch part = strings.ToLower(strings.TrimSpace(part)); part {
+		case "from":
+			offsets = append(offsets, 0)
+		case "to":
+			offsets = append(offsets, 1)
+		default:
+			n, err := strconv.ParseFloat(strings.TrimSuffix(part, "%"), 64)
+			if !strings.HasSuffix(part, "%") || err != nil || n < 0 || n > 100 {
+				return nil
+			}
+			offsets = append(offsets, n/100)
+		}
+	}
+	return offsets
+}
+
+// animationKeywords are the keywords of the animation shorthand that are
+// not animation names.
+var animationKeywords = []string{
+	"none", "linear", "ease", "ease-in", "ease-out", "ease-in-out", "step-start", "step-end",
+	"infinite", "normal", "reverse", "alternate", "alternate-reverse",
+	"forwards", "backwards", "both", "running", "paused",
+	"initial", "inherit", "unset", "revert", "revert-layer",
+}
+
+// LintKeyframes cross-checks the animations of the stylesheet against its
+// @keyframes rules, reporting animation names without keyframes and
+// keyframes no animation uses.
+func LintKeyframes(b []byte) []Issue {
+	return lintKeyframes([]SourceFile{{Data: b}})
+}
+
+// LintKeyframes is like the LintKeyframes function for all files of the
+// project together, with Pos.Filename of the issues set.
+func (p *Project) LintKeyframes() []Issue {
+	files := make([]SourceFile, len(p.files))
+	for i, f := range p.files {
+		files[i] = SourceFile{f.Name, f.Data}
+	}
+	return lintKeyframes(files)
+}
+
+func lintKeyframes(files []SourceFile) []Issue {
+	type keyframes struct {
+		name    string
+		prelude Rule
+		pos     scanner.Position
+	}
+	var (
+		defined = []keyframes{}
+		names   = map[string]bool{}
+		used    = map[string]bool{}
+		issues  = []Issue{}
+	)
+	for _, f := range files {
+		for _, s := range RuleSources(f.Data) {
+			prelude := strings.TrimSpace(string(s.Prelude))
+			if name := atRuleName(prelude); strings.HasSuffix(name, "keyframes") {
+				k := keyframes{strings.TrimSpace(prelude[len(name)+1:]), s.Prelude, offsetPosition(f.Data, s.Start)}
+				if strings.HasPrefix(k.name, `"`) || strings.HasPrefix(k.name, "'") {
+					k.name = unquote(k.name)
+				}
+				k.pos.Filename = f.Name
+				defined = append(defined, k)
+				names[k.name] = true
+			}
+		}
+	}
+
+	for _, f := range files {
+		sheet, err := UnmarshalStylesheet(f.Data)
+		if err != nil {
+			continue
+		}
+		for _, r := range sheet.Rules {
+			for _, d := range r.Declarations {
+				for _, name := range animationNames(d) {
+					used[name] = true
+					if names[name] {
+						continue
+					}
+					pos := d.Pos
+					pos.Filename = f.Name
+					issues = append(issues, Issue{
+						Lint:     "keyframes",
+						Message:  "animation " + name + " has no @keyframes",
+						Selector: r.Selector,
+						Property: d.Property,
+						Pos:      pos,
+					})
+				}
+			}
+		}
+	}
+
+	for _, k := range defined {
+		if !used[k.name] {
+			issues = append(issues, Issue{
+				Lint:     "keyframes",
+				Message:  "@keyframes " + k.name + " is never used",
+				Selector: k.prelude,
+				Pos:      k.pos,
+			})
+		}
+	}
+	return issues
+}
+
+// animationNames returns the keyframes names used by an animation or
+// animation-name declaration, including the vendor prefixed ones.
+func animationNames(d Declaration) []string {
+	property := unprefixed(strings.ToLower(d.Property))
+	if property != "animation" && property != "animation-name" {
+		return nil
+	}
+
+	names := []string{}
+	for _, animation := range splitTopLevel(d.Value, ',') {
+		for _, c := range parseComponents(animation) {
+			name := c.Value
+			switch {
+			case c.Type == ComponentString:
+			case c.Type == ComponentIdent && !containsString(animationKeywords, strings.ToLower(name)):
+			default:
+				continue
+			}
+			if !containsString(names, name) {
+				names = append(names, name)
+			}
+			// an animation has a single name, the other idents are keywords
+			break
+		}
+	}
+	return names
+}
+
+// unprefixed returns the property without its vendor prefix, such as
+// animation for -webkit-animation.
+func unprefixed(property string) string {
+	if strings.HasPrefix(property, "-") && !strings.HasPrefix(property, "--") {
+		if i := strings.IndexByte(property[1:], '-'); i >= 0 {
+			return property[i+2:]
+		}
+	}
+	return property
+}
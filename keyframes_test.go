@@ -0,0 +1,119 @@
+package css
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLintKeyframes(t *testing.T) {
+	issues := LintKeyframes([]byte(`@keyframes fade {
+	from {
+		opacity: 0;
+	}
+}
+@-webkit-keyframes "spin" {
+	to {
+		transform: rotate(1turn);
+	}
+}
+@keyframes unused {
+	to {
+		top: 0;
+	}
+}
+div.modal {
+	animation: 0.3s ease-in fade, 1s infinite linear slide;
+	-webkit-animation-name: spin;
+}
+div.toast {
+	animation-name: none;
+	animation: var(--enter);
+}
+div.badge {
+	animation: pulse 2s;
+}
+`))
+	expected := []struct {
+		message string
+		line    int
+	}{
+		{"animation slide has no @keyframes", 17},
+		{"animation pulse has no @keyframes", 25},
+		{"@keyframes unused is never used", 11},
+	}
+	if len(issues) != len(expected) {
+		t.Fatalf("expected %d issues, got %v", len(expected), issues)
+	}
+	for i, e := range expected {
+		if issues[i].Message != e.message || issues[i].Pos.Line != e.line {
+			t.Errorf("expected %s at line %d, got %v", e.message, e.line, issues[i])
+		}
+	}
+}
+
+func TestProjectLintKeyframes(t *testing.T) {
+	p := NewProject()
+	p.Add("animations.css", []byte("@keyframes fade {\n\tto {\n\t\topacity: 1;\n\t}\n}\n"))
+	p.Add("modal.css", []byte("div.modal {\n\tanimation: fade 1s;\n}\ndiv.toast {\n\tanimation: slide 1s;\n}\n"))
+
+	issues := p.LintKeyframes()
+	if len(issues) != 1 || issues[0].Message != "animation slide has no @keyframes" || issues[0].Pos.Filename != "modal.css" {
+		t.Errorf("unexpected issues %v", issues)
+	}
+}
+
+func TestStylesheetKeyframes(t *testing.T) {
+	src := `@keyframes spin { from { transform: rotate(0deg); } 50% { opacity: .5 } to { transform: rotate(360deg); } }
+@media screen {
+	@-webkit-keyframes "fade" {
+		0%, 100% {
+			opacity: 0;
+		}
+		50.5% {
+			opacity: 1;
+		}
+	}
+}
+@keyframes empty {
+}
+p {
+	animation: spin 1s;
+}
+`
+	sheet, err := UnmarshalStylesheet([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	all := sheet.Keyframes()
+	if len(all) != 3 {
+		t.Fatalf("got %d keyframes, want 3", len(all))
+	}
+
+	spin := all[0]
+	if spin.Name != "spin" || spin.Vendor != "" || len(spin.Frames) != 3 {
+		t.Fatalf("got %+v", spin)
+	}
+	if f := spin.Frames[1]; f.Selector != "50%" || !reflect.DeepEqual(f.Offsets, []float64{0.5}) || f.Declarations[0].Value != ".5" {
+		t.Errorf("got frame %+v", f)
+	}
+
+	fade := all[1]
+	if fade.Name != "fade" || fade.Vendor != "-webkit-" || !reflect.DeepEqual(fade.AtRules, []Rule{"@media screen"}) {
+		t.Errorf("got %+v", fade)
+	}
+	if len(fade.Frames) != 2 || !reflect.DeepEqual(fade.Frames[0].Offsets, []float64{0, 1}) ||
+		fade.Frames[1].Selector != "50.5%" || !reflect.DeepEqual(fade.Frames[1].Offsets, []float64{0.505}) {
+		t.Errorf("got frames %+v", fade.Frames)
+	}
+
+	if all[2].Name != "empty" || len(all[2].Frames) != 0 {
+		t.Errorf("got %+v", all[2])
+	}
+
+	css := sheet.ToLegacyMap()
+	for _, selector := range []Rule{"from", "to", "50%"} {
+		if _, ok := css[selector]; ok {
+			t.Errorf("keyframe %s in the legacy map", selector)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package css
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var errLength = errors.New("invalid length")
+
+// Length is a length or a percentage, such as 12px, 1.5em or 75%.
+type Length struct {
+	Value float64
+	// Unit is the lower case unit, "%" for percentages. It is empty for
+	// zero, the only length allowed without a unit.
+	Unit string
+}
+
+// LengthContext gives the values relative lengths are resolved against.
+// Its lengths are in css pixels.
+type LengthContext struct {
+	// FontSize is the font size of the element in px, and RootFontSize
+	// the one of the root element. Both are 16 if zero.
+	FontSize, RootFontSize float64
+	// ViewportWidth and ViewportHeight are the size of the viewport in px.
+	ViewportWidth, ViewportHeight float64
+	// PercentBase is the length in px percentages are relative to, such as
+	// the width of the containing block.
+	PercentBase float64
+	// DPI is the number of pixels per inch of the output, 96 if zero. A
+	// css pixel is 1/96 inch, so other values scale all the lengths, such
+	// as 192 for images rendered at twice the css size.
+	DPI float64
+}
+
+// absoluteUnits are the size of the absolute units in px.
+var absoluteUnits = map[string]float64{
+	"px": 1,
+	"in": 96,
+	"cm": 96 / 2.54,
+	"mm": 96 / 25.4,
+	"q":  96 / 101.6,
+	"pt": 96.0 / 72,
+	"pc": 16,
+}
+
+// ParseLength parses a length such as 12px, 1.5em, 75% or 0.
+func ParseLength(value string) (Length, error) {
+	components := parseComponents(strings.TrimSpace(value))
+	if len(components) != 1 || components[0].Type != ComponentDimension {
+		return Length{}, errLength
+	}
+	c := components[0]
+	l := Length{Value: c.Number, Unit: strings.ToLower(c.Unit)}
+	switch l.Unit {
+	case "":
+		if l.Value != 0 {
+			return Length{}, errLength
+		}
+	case "%", "em", "rem", "ex", "ch", "vw", "vh", "vmin", "vmax":
+	default:
+		if _, ok := absoluteUnits[l.Unit]; !ok {
+			return Length{}, errLength
+		}
+	}
+	return l, nil
+}
+
+// String returns the length as css.
+func (l Length) String() string {
+	return strconv.FormatFloat(l.Value, 'f', -1, 64) + l.Unit
+}
+
+// IsRelative reports whether the length depends on the context: font
+// relative and viewport lengths and percentages.
+func (l Length) IsRelative() bool {
+	_, ok := absoluteUnits[l.Unit]
+	return !ok && l.Unit != ""
+}
+
+// ToPixels returns the length in pixels of the output, resolving
+// relative lengths against the context.
+func (l Length) ToPixels(ctx LengthContext) float64 {
+	fontSize := ctx.FontSize
+	if fontSize == 0 {
+		fontSize = 16
+	}
+	rootFontSize := ctx.RootFontSize
+	if rootFontSize == 0 {
+		rootFontSize = 16
+	}
+
+	var px float64
+	switch l.Unit {
+	case "":
+		return 0
+	case "%":
+		px = l.Value * ctx.PercentBase / 100
+	case "em":
+		px = l.Value * fontSize
+	case "rem":
+		px = l.Value * rootFontSize
+	case "ex", "ch":
+		// the usual approximation without font metrics
+		px = l.Value * fontSize / 2
+	case "vw":
+		px = l.Value * ctx.ViewportWidth / 100
+	case "vh":
+		px = l.Value * ctx.ViewportHeight / 100
+	case "vmin":
+		px = l.Value * math.Min(ctx.ViewportWidth, ctx.ViewportHeight) / 100
+	case "vmax":
+		px = l.Value * math.Max(ctx.ViewportWidth, ctx.ViewportHeight) / 100
+	default:
+		px = l.Value * absoluteUnits[l.Unit]
+	}
+	if ctx.DPI != 0 {
+		px *= ctx.DPI / 96
+	}
+	return px
+}
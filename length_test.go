@@ -0,0 +1,74 @@
+package css
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseLength(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Length
+	}{
+		{"12px", Length{12, "px"}},
+		{" 1.5EM ", Length{1.5, "em"}},
+		{"75%", Length{75, "%"}},
+		{"-2rem", Length{-2, "rem"}},
+		{"0", Length{0, ""}},
+		{".5in", Length{0.5, "in"}},
+	}
+	for _, test := range tests {
+		got, err := ParseLength(test.in)
+		if err != nil {
+			t.Errorf("%s: %v", test.in, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("%s: got %v, want %v", test.in, got, test.want)
+		}
+	}
+	for _, in := range []string{"", "12", "12px 1em", "auto", "10deg", "calc(1px)"} {
+		if _, err := ParseLength(in); err == nil {
+			t.Errorf("%s: expected an error", in)
+		}
+	}
+	if s := (Length{1.5, "em"}).String(); s != "1.5em" {
+		t.Errorf("got %q", s)
+	}
+}
+
+func TestLengthToPixels(t *testing.T) {
+	ctx := LengthContext{FontSize: 20, ViewportWidth: 800, ViewportHeight: 600, PercentBase: 300}
+	tests := []struct {
+		in   string
+		want float64
+	}{
+		{"12px", 12},
+		{"1.5em", 30},
+		{"2rem", 32},
+		{"75%", 225},
+		{"10vw", 80},
+		{"10vmin", 60},
+		{"1in", 96},
+		{"12pt", 16},
+		{"2.54cm", 96},
+		{"0", 0},
+	}
+	for _, test := range tests {
+		l, _ := ParseLength(test.in)
+		if got := l.ToPixels(ctx); math.Abs(got-test.want) > 1e-9 {
+			t.Errorf("%s: got %g, want %g", test.in, got, test.want)
+		}
+	}
+
+	ctx.DPI = 300
+	if l, _ := ParseLength("1in"); l.ToPixels(ctx) != 300 {
+		t.Errorf("1in at 300dpi: got %g", l.ToPixels(ctx))
+	}
+	if l, _ := ParseLength("96px"); l.ToPixels(ctx) != 300 {
+		t.Errorf("96px at 300dpi: got %g", l.ToPixels(ctx))
+	}
+	if (Length{1, "px"}).IsRelative() || !(Length{1, "%"}).IsRelative() {
+		t.Error("wrong IsRelative")
+	}
+}
@@ -0,0 +1,56 @@
+package css
+
+import (
+	"bytes"
+	"strings"
+)
+
+// SourceFile is a stylesheet together with the name it was loaded from.
+type SourceFile struct {
+	Name string
+	Data []byte
+}
+
+// LicenseBanner is a license comment and the files it was found in.
+type LicenseBanner struct {
+	Text  string
+	Files []string
+}
+
+// CollectLicenses returns the license comments of all files, in order of
+// first appearance. Banners that only differ in whitespace are merged and
+// list every file they were found in.
+func CollectLicenses(files ...SourceFile) []LicenseBanner {
+	var (
+		banners = []LicenseBanner{}
+		index   = map[string]int{}
+	)
+	for _, f := range files {
+		for _, text := range Licenses(f.Data) {
+			key := strings.Join(strings.Fields(text), " ")
+			i, ok := index[key]
+			if !ok {
+				index[key] = len(banners)
+				banners = append(banners, LicenseBanner{Text: text})
+				i = len(banners) - 1
+			}
+			if n := len(banners[i].Files); n == 0 || banners[i].Files[n-1] != f.Name {
+				banners[i].Files = append(banners[i].Files, f.Name)
+			}
+		}
+	}
+	return banners
+}
+
+// LicenseHeader renders the banners as a header for bundled output. Every
+// banner is preceded by a license comment naming the files it came from,
+// so the attribution survives minification.
+func LicenseHeader(banners []LicenseBanner) []byte {
+	var buf bytes.Buffer
+	for _, b := range banners {
+		buf.WriteString("/*! " + strings.Join(b.Files, ", ") + " */\n")
+		buf.WriteString(b.Text)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
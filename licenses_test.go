@@ -0,0 +1,23 @@
+package css
+
+import "testing"
+
+func TestCollectLicenses(t *testing.T) {
+	files := []SourceFile{
+		{"a.css", []byte("/*! MIT */\n/*! Apache-2.0 */\na {\n\tcolor: red;\n}")},
+		{"b.css", []byte("/*!  MIT  */\nb {\n\tcolor: blue;\n}")},
+	}
+
+	banners := CollectLicenses(files...)
+	if len(banners) != 2 {
+		t.Fatalf("expected 2 banners, got %d", len(banners))
+	}
+	if banners[0].Text != "/*! MIT */" || len(banners[0].Files) != 2 {
+		t.Fatalf("expected MIT banner from both files, got %+v", banners[0])
+	}
+
+	expected := "/*! a.css, b.css */\n/*! MIT */\n/*! a.css */\n/*! Apache-2.0 */\n"
+	if header := string(LicenseHeader(banners)); header != expected {
+		t.Fatalf("got %q, expected %q", header, expected)
+	}
+}
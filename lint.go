@@ -0,0 +1,120 @@
+package css
+
+import (
+	"strings"
+	"text/scanner"
+)
+
+// Issue is a problem found by a lint.
+type Issue struct {
+	// Lint is the name of the lint reporting the issue.
+	Lint    string
+	Message string
+	// Selector and Property locate the issue. Property is empty for issues
+	// about a whole rule.
+	Selector Rule
+	Property string
+	Pos      scanner.Position
+	// Severity ranks the issues of a lint, higher is worse. It is zero for
+	// lints that don't rank their issues.
+	Severity int
+}
+
+func (i Issue) String() string {
+	return Warning{Message: i.Message, Token: string(i.Selector), Pos: i.Pos}.String()
+}
+
+// motionFamily returns "animation" or "transition" for the properties
+// starting animations and transitions, and an empty string otherwise.
+func motionFamily(d Declaration) string {
+	property := strings.ToLower(d.Property)
+	value := strings.ToLower(strings.TrimSpace(d.Value))
+	switch property {
+	case "animation", "animation-name":
+		if value != "none" {
+			return "animation"
+		}
+	case "transition", "transition-property":
+		if value != "none" {
+			return "transition"
+		}
+	}
+	return ""
+}
+
+// LintReducedMotion reports the animations and transitions that are not
+// overridden for users preferring reduced motion. A declaration is
+// guarded when a rule with the same selector, or the universal selector,
+// sets the same kind of property in a
+// @media (prefers-reduced-motion: reduce) rule of any of the stylesheets,
+// or when the declaration itself is in a
+// @media (prefers-reduced-motion: no-preference) rule.
+func LintReducedMotion(sheets ...*Stylesheet) []Issue {
+	guarded := map[string]bool{}
+	for _, sheet := range sheets {
+		for _, r := range sheet.Rules {
+			if reducedMotion(r) != "reduce" {
+				continue
+			}
+			for _, d := range r.Declarations {
+				family := strings.SplitN(strings.ToLower(d.Property), "-", 2)[0]
+				if family != "animation" && family != "transition" {
+					continue
+				}
+				for _, part := range splitTopLevel(string(r.Selector), ',') {
+					guarded[family+" "+Rule(strings.TrimSpace(part)).String()] = true
+				}
+			}
+		}
+	}
+
+	issues := []Issue{}
+	for _, sheet := range sheets {
+		for _, r := range sheet.Rules {
+			if reducedMotion(r) != "" {
+				continue
+			}
+			for _, d := range r.Declarations {
+				family := motionFamily(d)
+				if family == "" || guarded[family+" *"] {
+					continue
+				}
+				unguarded := false
+				for _, part := range splitTopLevel(string(r.Selector), ',') {
+					if !guarded[family+" "+Rule(strings.TrimSpace(part)).String()] {
+						unguarded = true
+					}
+				}
+				if unguarded {
+					issues = append(issues, Issue{
+						Lint:     "reduced-motion",
+						Message:  family + " without a prefers-reduced-motion override",
+						Selector: r.Selector,
+						Property: d.Property,
+						Pos:      d.Pos,
+					})
+				}
+			}
+		}
+	}
+	return issues
+}
+
+// reducedMotion returns the prefers-reduced-motion value of the @media
+// rule the rule is nested in, such as "reduce" or "no-preference", or an
+// empty string.
+func reducedMotion(r *StyleRule) string {
+	for _, at := range r.AtRules {
+		media := strings.ToLower(string(at))
+		i := strings.Index(media, "prefers-reduced-motion")
+		if !strings.HasPrefix(media, "@media") || i < 0 {
+			continue
+		}
+		value := strings.TrimLeft(media[i+len("prefers-reduced-motion"):], ": ")
+		if strings.HasPrefix(value, "no-preference") {
+			return "no-preference"
+		}
+		return "reduce"
+	}
+	return ""
+}
@@ -0,0 +1,66 @@
+package css
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// NamingConvention is a naming policy for the classes and ids of
+// selectors, used by LintNaming. A nil pattern isn't checked.
+type NamingConvention struct {
+	Name  string
+	Class *regexp.Regexp
+	ID    *regexp.Regexp
+}
+
+var (
+	rKebabCase = regexp.MustCompile(`^[a-z][a-z0-9]*(-[a-z0-9]+)*$`)
+
+	// NamingBEM is the Block__Element--Modifier convention with lower
+	// case, hyphenated names, such as "card__title--large". Ids are kebab
+	// case.
+	NamingBEM = NamingConvention{
+		Name:  "BEM",
+		Class: regexp.MustCompile(`^[a-z][a-z0-9]*(-[a-z0-9]+)*(__[a-z0-9]+(-[a-z0-9]+)*)?(--[a-z0-9]+(-[a-z0-9]+)*){0,2}$`),
+		ID:    rKebabCase,
+	}
+	// NamingSUIT is the SUIT CSS convention: components such as
+	// "ns-Button-icon--large", utilities such as "u-textCenter" and
+	// states such as "is-active". Ids are kebab case.
+	NamingSUIT = NamingConvention{
+		Name: "SUIT",
+		Class: regexp.MustCompile(`^(([a-z][a-zA-Z0-9]*-)?[A-Z][a-zA-Z0-9]*(-[a-z][a-zA-Z0-9]*)?(--[a-z][a-zA-Z0-9]*)*` +
+			`|u-([a-z][a-zA-Z0-9]*-)?[a-z][a-zA-Z0-9]*|is-[a-z][a-zA-Z0-9]*)$`),
+		ID: rKebabCase,
+	}
+	// NamingKebabCase requires lower case, hyphenated classes and ids.
+	NamingKebabCase = NamingConvention{Name: "kebab-case", Class: rKebabCase, ID: rKebabCase}
+)
+
+// LintNaming reports the classes and ids of the selectors in b that don't
+// follow the naming convention, including the ones in pseudo class
+// arguments such as :not(.x). Escaped names are checked unescaped.
+func LintNaming(b []byte, convention NamingConvention) []Issue {
+	issues := []Issue{}
+	for _, span := range selectorSpans(b) {
+		selector := string(b[span[0]:span[1]])
+		for _, ref := range selectorReferences(selector) {
+			kind, pattern := "class", convention.Class
+			if selector[ref[0]] == '#' {
+				kind, pattern = "id", convention.ID
+			}
+			name := unescapeIdent(selector[ref[0]+1 : ref[1]])
+			if pattern == nil || pattern.MatchString(name) {
+				continue
+			}
+			issues = append(issues, Issue{
+				Lint:     "naming",
+				Message:  fmt.Sprintf("%s %q does not follow the %s naming convention", kind, name, convention.Name),
+				Selector: Rule(strings.TrimSpace(selector)),
+				Pos:      offsetPosition(b, span[0]+ref[0]),
+			})
+		}
+	}
+	return issues
+}
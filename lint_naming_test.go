@@ -0,0 +1,58 @@
+package css
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestLintNaming(t *testing.T) {
+	src := []byte(`div.card__title--large, div.card_title {
+	top: 0;
+}
+#MainNav, div.Button-icon--small:not(.is-active)
+{
+	top: 0;
+}
+@keyframes Fade {
+	from {
+		opacity: 0;
+	}
+}
+div.u-textCenter[data-x=".Bad"] {
+	top: 0;
+}
+`)
+	tests := []struct {
+		convention NamingConvention
+		expected   []string
+	}{
+		{NamingBEM, []string{"card_title", "MainNav", "Button-icon--small", "u-textCenter"}},
+		{NamingSUIT, []string{"card__title--large", "card_title", "MainNav"}},
+		{NamingKebabCase, []string{"card__title--large", "card_title", "MainNav", "Button-icon--small", "u-textCenter"}},
+		{NamingConvention{Name: "custom", Class: regexp.MustCompile(`^[a-z_-]+$`)}, []string{"Button-icon--small", "u-textCenter"}},
+	}
+	for _, test := range tests {
+		issues := LintNaming(src, test.convention)
+		names := []string{}
+		for _, issue := range issues {
+			names = append(names, issue.Message)
+		}
+		if len(issues) != len(test.expected) {
+			t.Errorf("%s: expected %v, got %v", test.convention.Name, test.expected, names)
+			continue
+		}
+		for i, name := range test.expected {
+			if !regexp.MustCompile(`"` + regexp.QuoteMeta(name) + `"`).MatchString(issues[i].Message) {
+				t.Errorf("%s: expected %s, got %s", test.convention.Name, name, issues[i].Message)
+			}
+		}
+	}
+
+	issues := LintNaming(src, NamingBEM)
+	if pos := issues[1].Pos; pos.Line != 4 || pos.Column != 1 || issues[1].Selector != "#MainNav, div.Button-icon--small:not(.is-active)" {
+		t.Errorf("unexpected issue %+v", issues[1])
+	}
+	if pos := issues[0].Pos; pos.Line != 1 || pos.Column != 28 {
+		t.Errorf("unexpected position %v", pos)
+	}
+}
@@ -0,0 +1,119 @@
+package css
+
+import "strings"
+
+// maxDescendantChain is the longest chain of descendant combinators not
+// reported by LintSelectors.
+const maxDescendantChain = 3
+
+// LintSelectors reports the selectors that are slow to match on large
+// documents: selectors whose key selector, the rightmost compound that
+// browsers match first, is universal or only has attribute conditions,
+// long descendant chains, and :has() with arguments matching broadly.
+//
+// The severity of an issue is the cost of the pattern multiplied by one
+// plus the number of hundreds of rules in the stylesheet, since the same
+// selector costs more in a large stylesheet than in a small one.
+func LintSelectors(sheet *Stylesheet) []Issue {
+	var (
+		issues = []Issue{}
+		weight = 1 + len(sheet.Rules)/100
+	)
+	for _, r := range sheet.Rules {
+		if strings.HasPrefix(string(r.Selector), "@") {
+			continue
+		}
+		group, err := parseSelectorGroup(string(r.Selector))
+		if err != nil {
+			continue
+		}
+		for _, sel := range group {
+			for _, p := range expensivePatterns(sel) {
+				issues = append(issues, Issue{
+					Lint:     "expensive-selector",
+					Message:  p.message,
+					Selector: r.Selector,
+					Pos:      r.Pos,
+					Severity: p.cost * weight,
+				})
+			}
+		}
+	}
+	return issues
+}
+
+// expensivePattern is a slow pattern found in a selector with its
+// relative cost.
+type expensivePattern struct {
+	message string
+	cost    int
+}
+
+// expensivePatterns returns the slow patterns of the selector.
+func expensivePatterns(sel complexSelector) []expensivePattern {
+	patterns := []expensivePattern{}
+	key := sel.compounds[len(sel.compounds)-1]
+	switch {
+	case isUniversal(key) && len(sel.compounds) > 1:
+		// a lone * only matches once per element and is cheap
+		patterns = append(patterns, expensivePattern{"universal key selector", 3})
+	case len(key.attrs) > 0 && key.tag == "" && len(key.ids) == 0 && len(key.classes) == 0:
+		patterns = append(patterns, expensivePattern{"attribute-only key selector", 2})
+	}
+
+	chain := 0
+	for _, c := range sel.combinators {
+		if c == ' ' {
+			chain++
+		}
+	}
+	if chain > maxDescendantChain {
+		patterns = append(patterns, expensivePattern{"deep descendant chain", chain - maxDescendantChain})
+	}
+
+	for _, compound := range sel.compounds {
+		for _, p := range compound.pseudos {
+			if p.name == "has" && broadHas(p.arg) {
+				patterns = append(patterns, expensivePattern{":has() with a broad argument", 3})
+			}
+		}
+	}
+	return patterns
+}
+
+// isUniversal reports whether the compound selector matches any element
+// or only restricts it with pseudo-classes.
+func isUniversal(compound compoundSelector) bool {
+	return (compound.tag == "" || compound.tag == "*") &&
+		len(compound.ids) == 0 && len(compound.classes) == 0 && len(compound.attrs) == 0
+}
+
+// broadHas reports whether the relative selectors of a :has() argument
+// search the whole subtree for elements without an id or class, such as
+// :has(img) or :has(*).
+func broadHas(arg string) bool {
+	for _, part := range splitTopLevel(arg, ',') {
+		part = strings.TrimSpace(part)
+		// relative selectors starting with a combinator only look at the
+		// children or the siblings, unless followed by a descendant one
+		subtree := true
+		if part != "" && strings.IndexByte(">+~", part[0]) >= 0 {
+			subtree = false
+			part = part[1:]
+		}
+		sel, err := parseComplexSelector(part)
+		if err != nil {
+			continue
+		}
+		for _, c := range sel.combinators {
+			if c == ' ' {
+				subtree = true
+			}
+		}
+		key := sel.compounds[len(sel.compounds)-1]
+		if subtree && len(key.ids) == 0 && len(key.classes) == 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package css
+
+import "testing"
+
+func TestLintSelectors(t *testing.T) {
+	sheet, err := UnmarshalStylesheet([]byte(`.nav * {
+	margin: 0;
+}
+[data-role] {
+	color: red;
+}
+html body div ul li a {
+	color: blue;
+}
+.card:has(img)
+{
+	padding: 0;
+}
+.card:has(> .title)
+{
+	padding: 1px;
+}
+* {
+	box-sizing: border-box;
+}
+a[href] {
+	color: green;
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	issues := LintSelectors(sheet)
+	expected := []struct {
+		selector Rule
+		message  string
+		severity int
+	}{
+		{".nav *", "universal key selector", 3},
+		{"[data-role]", "attribute-only key selector", 2},
+		{"html body div ul li a", "deep descendant chain", 2},
+		{".card:has(img)", ":has() with a broad argument", 3},
+	}
+	if len(issues) != len(expected) {
+		t.Fatalf("got %d issues %v, expected %d", len(issues), issues, len(expected))
+	}
+	for i, e := range expected {
+		if issues[i].Selector != e.selector || issues[i].Message != e.message || issues[i].Severity != e.severity {
+			t.Errorf("issue %d: got %q %q %d, expected %q %q %d", i,
+				issues[i].Selector, issues[i].Message, issues[i].Severity, e.selector, e.message, e.severity)
+		}
+	}
+}
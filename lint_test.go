@@ -0,0 +1,53 @@
+package css
+
+import "testing"
+
+func TestLintReducedMotion(t *testing.T) {
+	base, _ := UnmarshalStylesheet([]byte(`.spinner {
+	animation: spin 1s infinite;
+}
+.fade {
+	transition: opacity 0.3s;
+	animation: none;
+}
+.slide {
+	transition: transform 0.3s;
+}
+@media (prefers-reduced-motion: no-preference) {
+	.bounce {
+		animation: bounce 1s;
+	}
+}
+`))
+	overrides, _ := UnmarshalStylesheet([]byte(`@media (prefers-reduced-motion: reduce) {
+	.spinner {
+		animation-duration: 0s;
+	}
+	.slide {
+		animation: none;
+	}
+}
+`))
+
+	issues := LintReducedMotion(base, overrides)
+	if len(issues) != 2 {
+		t.Fatalf("expected 2 issues, got %v", issues)
+	}
+	if issues[0].Selector != ".fade" || issues[0].Property != "transition" || issues[0].Pos.Line != 5 {
+		t.Errorf("unexpected issue %+v", issues[0])
+	}
+	if issues[1].Selector != ".slide" || issues[1].Property != "transition" {
+		t.Errorf("unexpected issue %+v", issues[1])
+	}
+
+	universal, _ := UnmarshalStylesheet([]byte(`@media (prefers-reduced-motion: reduce) {
+	* {
+		transition: none !important;
+		animation: none !important;
+	}
+}
+`))
+	if issues := LintReducedMotion(base, universal); len(issues) != 0 {
+		t.Fatalf("universal override should guard everything, got %v", issues)
+	}
+}
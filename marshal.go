@@ -0,0 +1,122 @@
+package css
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Marshal returns the rules map as css, the inverse of Unmarshal. Rules
+// are sorted by selector and declarations by property, so the output is
+// deterministic. Selectors, properties and values that would make the
+// output invalid, such as a value containing "}", are an error.
+func Marshal(css map[Rule]map[string]string) ([]byte, error) {
+	var buf bytes.Buffer
+	for i, r := range FromLegacyMap(css).Rules {
+		if i > 0 {
+			buf.WriteByte('\n')
+		}
+		if err := writeRule(&buf, r, ""); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// MarshalStylesheet returns the stylesheet as css, with its statements
+// first and its rules in order. Consecutive rules nested in the same
+// at-rules share the at-rule blocks. Invalid selectors, properties and
+// values are an error, as for Marshal.
+func MarshalStylesheet(sheet *Stylesheet) ([]byte, error) {
+	var (
+		buf  bytes.Buffer
+		open []Rule
+	)
+	for _, s := range sheet.Statements {
+		buf.WriteString(strings.TrimSpace(string(s.Prelude)) + ";\n")
+	}
+	for i, r := range sheet.Rules {
+		common := 0
+		for common < len(open) && common < len(r.AtRules) && open[common] == r.AtRules[common] {
+			common++
+		}
+		for len(open) > common {
+			open = open[:len(open)-1]
+			buf.WriteString(strings.Repeat("\t", len(open)) + "}\n")
+		}
+		if i > 0 || len(sheet.Statements) > 0 {
+			buf.WriteByte('\n')
+		}
+		for _, at := range r.AtRules[common:] {
+			if strings.ContainsAny(string(at), "{};") {
+				return nil, fmt.Errorf("invalid at-rule %q", at)
+			}
+			buf.WriteString(strings.Repeat("\t", len(open)) + strings.TrimSpace(string(at)) + " {\n")
+			open = append(open, at)
+		}
+		if err := writeRule(&buf, r, strings.Repeat("\t", len(open))); err != nil {
+			return nil, err
+		}
+	}
+	for len(open) > 0 {
+		open = open[:len(open)-1]
+		buf.WriteString(strings.Repeat("\t", len(open)) + "}\n")
+	}
+	return buf.Bytes(), nil
+}
+
+// writeRule writes the rule with its declarations, indented with indent.
+func writeRule(buf *bytes.Buffer, r *StyleRule, indent string) error {
+	selector := strings.TrimSpace(string(r.Selector))
+	if selector == "" || strings.ContainsAny(selector, "{};") {
+		return fmt.Errorf("invalid selector %q", r.Selector)
+	}
+	buf.WriteString(indent + selector + " {\n")
+	for _, d := range r.Declarations {
+		if d.Property == "" || strings.ContainsAny(d.Property, ":;{} \t\n") {
+			return fmt.Errorf("invalid property %q in %s", d.Property, selector)
+		}
+		if !validValue(d.Value) {
+			return fmt.Errorf("invalid value %q of %s in %s", d.Value, d.Property, selector)
+		}
+		buf.WriteString(indent + "\t" + d.String() + ";\n")
+	}
+	buf.WriteString(indent + "}\n")
+	return nil
+}
+
+// validValue reports whether the value can be written in a declaration:
+// it isn't empty, its strings, comments and parentheses are closed, and it
+// has no semicolons or braces outside of strings.
+func validValue(value string) bool {
+	depth := 0
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; c {
+		case '"', '\'':
+			end := scanString(value, i)
+			if end == len(value) && (end-i < 2 || value[end-1] != c) {
+				return false
+			}
+			i = end - 1
+		case '/':
+			if i+1 < len(value) && value[i+1] == '*' {
+				end := strings.Index(value[i+2:], "*/")
+				if end < 0 {
+					return false
+				}
+				i += end + 3
+			}
+		case '\\':
+			i++
+		case '(':
+			depth++
+		case ')':
+			if depth--; depth < 0 {
+				return false
+			}
+		case ';', '{', '}':
+			return false
+		}
+	}
+	return depth == 0 && strings.TrimSpace(value) != ""
+}
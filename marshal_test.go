@@ -0,0 +1,103 @@
+package css
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMarshal(t *testing.T) {
+	css := map[Rule]map[string]string{
+		"div p": {"margin": "0", "color": "red !important"},
+		"a":     {"content": `"a, b"`, "background": "url(a.png)"},
+	}
+	out, err := Marshal(css)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `a {
+	background: url(a.png);
+	content: "a, b";
+}
+
+div p {
+	color: red !important;
+	margin: 0;
+}
+`
+	if string(out) != expected {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+
+	back, err := Unmarshal(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(back, css) {
+		t.Errorf("round trip changed the rules: %v", back)
+	}
+
+	invalid := []map[Rule]map[string]string{
+		{"": {"color": "red"}},
+		{"a {": {"color": "red"}},
+		{"a": {"": "red"}},
+		{"a": {"co lor": "red"}},
+		{"a": {"color": ""}},
+		{"a": {"color": "red; } body { color: blue"}},
+		{"a": {"content": `"unclosed`}},
+		{"a": {"width": "calc(1px"}},
+		{"a": {"color": "red /* open"}},
+	}
+	for _, css := range invalid {
+		if _, err := Marshal(css); err == nil {
+			t.Errorf("expected an error for %v", css)
+		}
+	}
+}
+
+func TestMarshalStylesheet(t *testing.T) {
+	sheet, err := ParseStylesheet(strings.NewReader(`@charset "utf-8";
+@import url(a.css);
+a { color: red !important; }
+@media print { a { color: black; } @supports (display: grid) { b { display: grid; } } }
+@media print { c { top: 0; } }
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := MarshalStylesheet(sheet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `@charset "utf-8";
+@import url(a.css);
+
+a {
+	color: red !important;
+}
+
+@media print {
+	a {
+		color: black;
+	}
+
+	@supports (display: grid) {
+		b {
+			display: grid;
+		}
+	}
+
+	c {
+		top: 0;
+	}
+}
+`
+	if string(b) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", b, want)
+	}
+
+	sheet.Rules[0].Declarations[0].Value = "}"
+	if _, err := MarshalStylesheet(sheet); err == nil {
+		t.Error("expected an error for an invalid value")
+	}
+}
@@ -0,0 +1,81 @@
+package css
+
+// MatchStrategy is an algorithm matching selectors against elements.
+type MatchStrategy int
+
+const (
+	// MatchRightToLeft matches the key selector first and walks up the
+	// ancestors and back through the siblings, as browsers do, giving up
+	// as soon as no remaining element can match. It is used by default.
+	MatchRightToLeft MatchStrategy = iota
+	// MatchBacktracking tries every ancestor or sibling for every
+	// combinator, which is exponential in the number of descendant
+	// combinators in the worst case.
+	MatchBacktracking
+)
+
+// matchResult is the outcome of matching a part of a selector. Besides
+// success, it tells the combinator loops whether trying other elements
+// can still succeed.
+type matchResult int
+
+const (
+	matched matchResult = iota
+	// failsLocally means another ancestor or sibling may match.
+	failsLocally
+	// failsAllSiblings means no previous sibling can match, but an
+	// ancestor may.
+	failsAllSiblings
+	// failsCompletely means neither an ancestor nor a sibling can match.
+	failsCompletely
+)
+
+// matches reports whether el matches the selector using the strategy.
+func (sel complexSelector) matches(el Element, strategy MatchStrategy) bool {
+	if strategy == MatchBacktracking {
+		return sel.matchBacktracking(len(sel.compounds)-1, el)
+	}
+	return sel.matchRightToLeft(len(sel.compounds)-1, el) == matched
+}
+
+// matchRightToLeft matches el against the selector ending with compound
+// i. When a descendant combinator runs out of ancestors, any higher
+// ancestor for the compounds on its right has even fewer ancestors, so
+// the whole match fails instead of backtracking; the same applies to
+// sibling combinators running out of siblings.
+func (sel complexSelector) matchRightToLeft(i int, el Element) matchResult {
+	if !sel.compounds[i].match(el) {
+		return failsLocally
+	}
+	if i == 0 {
+		return matched
+	}
+	switch sel.combinators[i-1] {
+	case '>':
+		p := el.Parent()
+		if p == nil {
+			return failsCompletely
+		}
+		return sel.matchRightToLeft(i-1, p)
+	case '+':
+		s := el.PrevSibling()
+		if s == nil {
+			return failsAllSiblings
+		}
+		return sel.matchRightToLeft(i-1, s)
+	case '~':
+		for s := el.PrevSibling(); s != nil; s = s.PrevSibling() {
+			if r := sel.matchRightToLeft(i-1, s); r != failsLocally {
+				return r
+			}
+		}
+		return failsAllSiblings
+	default:
+		for p := el.Parent(); p != nil; p = p.Parent() {
+			if r := sel.matchRightToLeft(i-1, p); r == matched || r == failsCompletely {
+				return r
+			}
+		}
+		return failsCompletely
+	}
+}
@@ -0,0 +1,65 @@
+package css
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMatchStrategies(t *testing.T) {
+	root := &testElement{tag: "html"}
+	body := root.append("body", nil)
+	section := body.append("section", map[string]string{"class": "a"})
+	h1 := section.append("h1", nil)
+	div := section.append("div", nil)
+	p := div.append("p", map[string]string{"class": "x"})
+	span := p.append("span", nil)
+	elements := []*testElement{root, body, section, h1, div, p, span}
+
+	selectors := []Rule{
+		"span", "p span", "section p > span", "body > section span", "html > section span",
+		"h1 + div p", "h1 ~ div > p.x", "section > div ~ h1", "h1 + p", ".a div p span",
+		"body .a > div > p > span", "div div span", "html body section div p span",
+		"section:not(.b) h1 ~ div span",
+	}
+	for _, rule := range selectors {
+		s, err := CompileSelector(rule)
+		if err != nil {
+			t.Fatalf("%q: %v", rule, err)
+		}
+		for _, el := range elements {
+			rtl, bt := s.MatchesWith(el, MatchRightToLeft), s.MatchesWith(el, MatchBacktracking)
+			if rtl != bt {
+				t.Errorf("%q on %s: right-to-left %v, backtracking %v", rule, el.tag, rtl, bt)
+			}
+		}
+	}
+	if s, _ := CompileSelector("h1 ~ div > p.x span"); !s.Matches(span) {
+		t.Error("expected a match")
+	}
+}
+
+// deepDocument returns the innermost span of a chain of nested divs.
+func deepDocument(depth int) *testElement {
+	el := &testElement{tag: "html"}
+	for i := 0; i < depth; i++ {
+		el = el.append("div", nil)
+	}
+	return el.append("span", nil)
+}
+
+func BenchmarkMatchStrategies(b *testing.B) {
+	el := deepDocument(40)
+	// there is no section, so every combination of divs is tried by the
+	// backtracking strategy
+	s, _ := CompileSelector(Rule("section " + strings.Repeat("div ", 4) + "span"))
+	for _, bench := range []struct {
+		name     string
+		strategy MatchStrategy
+	}{{"RightToLeft", MatchRightToLeft}, {"Backtracking", MatchBacktracking}} {
+		b.Run(bench.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				s.MatchesWith(el, bench.strategy)
+			}
+		})
+	}
+}
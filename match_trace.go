@@ -0,0 +1,200 @@
+package css
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MatchStep is a compound selector tried against an element while
+// matching a selector.
+type MatchStep struct {
+	// Compound is the index of the compound selector in the selector.
+	Compound int
+	// Selector is the compound selector, such as "li.item".
+	Selector string
+	// Combinator is the combinator that led to the element, the one on the
+	// right of the compound, and zero for the subject of the selector.
+	Combinator Combinator
+	// Element is the element tried. It is nil when the combinator ran out
+	// of parents or siblings.
+	Element Element
+	Matched bool
+	// Failed is the simple selector the element doesn't match, such as
+	// ".item", or a description of why no element was found.
+	Failed string
+}
+
+// MatchTrace is the step by step trace of matching one selector of a
+// group against an element, in the order the right-to-left matcher tried
+// the elements.
+type MatchTrace struct {
+	Selector Rule
+	Matched  bool
+	Steps    []MatchStep
+}
+
+// ExplainMatch matches every selector of the group against the element
+// and returns how each of them matched or why it failed.
+func ExplainMatch(selector Rule, el Element) ([]MatchTrace, error) {
+	group, err := parseSelectorGroup(string(selector))
+	if err != nil {
+		return nil, err
+	}
+	traces := make([]MatchTrace, len(group))
+	for i, sel := range group {
+		traces[i] = sel.explain(el)
+	}
+	return traces, nil
+}
+
+// Explain matches the element against the selector and returns how it
+// matched or why it failed.
+func (s Selector) Explain(el Element) MatchTrace {
+	return s.sel.explain(el)
+}
+
+func (sel complexSelector) explain(el Element) MatchTrace {
+	t := MatchTrace{Selector: Rule(sel.String())}
+	t.Matched = sel.traceRightToLeft(len(sel.compounds)-1, el, 0, &t) == matched
+	return t
+}
+
+// traceRightToLeft is matchRightToLeft recording every step in t.
+func (sel complexSelector) traceRightToLeft(i int, el Element, via Combinator, t *MatchTrace) matchResult {
+	step := MatchStep{Compound: i, Selector: sel.compounds[i].String(), Combinator: via, Element: el}
+	step.Failed = sel.compounds[i].failure(el)
+	step.Matched = step.Failed == ""
+	t.Steps = append(t.Steps, step)
+	if !step.Matched {
+		return failsLocally
+	}
+	if i == 0 {
+		return matched
+	}
+
+	c := Combinator(sel.combinators[i-1])
+	missing := func(what string) {
+		t.Steps = append(t.Steps, MatchStep{
+			Compound:   i - 1,
+			Selector:   sel.compounds[i-1].String(),
+			Combinator: c,
+			Failed:     what,
+		})
+	}
+	switch c {
+	case Child:
+		p := el.Parent()
+		if p == nil {
+			missing("no parent")
+			return failsCompletely
+		}
+		return sel.traceRightToLeft(i-1, p, c, t)
+	case NextSibling:
+		s := el.PrevSibling()
+		if s == nil {
+			missing("no previous sibling")
+			return failsAllSiblings
+		}
+		return sel.traceRightToLeft(i-1, s, c, t)
+	case SubsequentSibling:
+		for s := el.PrevSibling(); s != nil; s = s.PrevSibling() {
+			if r := sel.traceRightToLeft(i-1, s, c, t); r != failsLocally {
+				return r
+			}
+		}
+		missing("no matching previous sibling")
+		return failsAllSiblings
+	default:
+		for p := el.Parent(); p != nil; p = p.Parent() {
+			if r := sel.traceRightToLeft(i-1, p, c, t); r == matched || r == failsCompletely {
+				return r
+			}
+		}
+		missing("no matching ancestor")
+		return failsCompletely
+	}
+}
+
+// failure returns the first simple selector of the compound the element
+// doesn't match, or an empty string if it matches.
+func (compound compoundSelector) failure(el Element) string {
+	if compound.tag != "" && compound.tag != "*" && !strings.EqualFold(compound.tag, el.TagName()) {
+		return compound.tag
+	}
+	switch {
+	case compound.namespace != nil:
+		if elementNamespace(el) != *compound.namespace {
+			return compound.prefix + "|"
+		}
+	case compound.prefixed && compound.prefix != "*":
+		return compound.prefix + "| (undeclared prefix)"
+	}
+	for _, id := range compound.ids {
+		if v, ok := el.Attr("id"); !ok || v != id {
+			return "#" + id
+		}
+	}
+	if len(compound.classes) > 0 {
+		v, _ := el.Attr("class")
+		classes := strings.Fields(v)
+		for _, class := range compound.classes {
+			if !containsString(classes, class) {
+				return "." + class
+			}
+		}
+	}
+	for _, attr := range compound.attrs {
+		if !attr.match(el) {
+			return attr.String()
+		}
+	}
+	for _, p := range compound.pseudos {
+		if !p.match(el) {
+			return p.String()
+		}
+	}
+	return ""
+}
+
+// String returns the trace with a line per step, such as:
+//
+//	ul > li.item: no match
+//	  li.item on <li class="first">: fails .item
+func (t MatchTrace) String() string {
+	var b strings.Builder
+	b.WriteString(string(t.Selector))
+	if t.Matched {
+		b.WriteString(": match\n")
+	} else {
+		b.WriteString(": no match\n")
+	}
+	for _, s := range t.Steps {
+		b.WriteString("  ")
+		if s.Combinator != 0 {
+			b.WriteString(s.Combinator.String() + " ")
+		}
+		b.WriteString(s.Selector)
+		switch {
+		case s.Element == nil:
+			b.WriteString(": " + s.Failed)
+		case s.Matched:
+			b.WriteString(" on " + describeElement(s.Element) + ": matches")
+		default:
+			b.WriteString(" on " + describeElement(s.Element) + ": fails " + s.Failed)
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// describeElement returns the start tag of the element with its id and
+// class attributes.
+func describeElement(el Element) string {
+	s := "<" + el.TagName()
+	for _, name := range []string{"id", "class"} {
+		if v, ok := el.Attr(name); ok {
+			s += fmt.Sprintf(" %s=%q", name, v)
+		}
+	}
+	return s + ">"
+}
@@ -0,0 +1,56 @@
+package css
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExplainMatch(t *testing.T) {
+	ul := &SimpleElement{Tag: "ul", Classes: []string{"nav"}}
+	li := &SimpleElement{Tag: "li", Classes: []string{"first"}, ParentElement: ul}
+	a := &SimpleElement{Tag: "a", ID: "home", ParentElement: li}
+
+	traces, err := ExplainMatch("ul.menu li > a, ul li > a#home", a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(traces) != 2 {
+		t.Fatalf("got %d traces", len(traces))
+	}
+
+	failed := traces[0]
+	if failed.Matched {
+		t.Fatal("ul.menu li > a matched")
+	}
+	var last MatchStep
+	for _, s := range failed.Steps {
+		if s.Element != nil && !s.Matched {
+			last = s
+		}
+	}
+	if last.Selector != "ul.menu" || last.Failed != ".menu" || last.Combinator != Descendant {
+		t.Errorf("got failing step %+v", last)
+	}
+	if s := failed.Steps[len(failed.Steps)-1]; s.Element != nil || s.Failed != "no matching ancestor" {
+		t.Errorf("got last step %+v", s)
+	}
+
+	if !traces[1].Matched {
+		t.Errorf("ul li > a#home didn't match:\n%s", traces[1])
+	}
+	if got := traces[1].String(); !strings.Contains(got, `child li on <li class="first">: matches`) {
+		t.Errorf("got trace:\n%s", got)
+	}
+
+	if _, err := ExplainMatch("a[", a); err == nil {
+		t.Error("expected an error for an invalid selector")
+	}
+}
+
+func TestExplainMatchChild(t *testing.T) {
+	sels, _ := ParseSelector("div > p")
+	trace := sels[0].Explain(&SimpleElement{Tag: "p"})
+	if trace.Matched || len(trace.Steps) != 2 || trace.Steps[1].Failed != "no parent" {
+		t.Errorf("got trace:\n%s", trace)
+	}
+}
@@ -0,0 +1,124 @@
+package css
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+)
+
+var rLeadingStatement = regexp.MustCompile(`(?i)^\s*@(charset|import)\b[^;]*;`)
+
+// WrapMedia wraps the stylesheet in an @media rule with the media query
+// list, the same way a media attribute on a <style> or <link> element
+// applies to the whole stylesheet. Leading @charset and @import
+// statements stay in front of the @media rule, where they are still
+// valid. The stylesheet is returned unchanged when media is empty or
+// "all".
+func WrapMedia(media string, b []byte) []byte {
+	media = strings.TrimSpace(media)
+	if media == "" || strings.EqualFold(media, "all") {
+		return b
+	}
+
+	var buf bytes.Buffer
+	rest := blankComments(b)
+	start := 0
+	for {
+		loc := rLeadingStatement.FindIndex(rest[start:])
+		if loc == nil {
+			break
+		}
+		statement := strings.TrimSpace(string(b[start+loc[0] : start+loc[1]]))
+		if strings.HasPrefix(strings.ToLower(statement), "@import") && !hasImportMedia(statement) {
+			statement = strings.TrimSuffix(statement, ";") + " " + media + ";"
+		}
+		buf.WriteString(statement + "\n")
+		start += loc[1]
+	}
+
+	buf.WriteString("@media " + media + " {\n")
+	buf.Write(bytes.TrimSpace(b[start:]))
+	buf.WriteString("\n}\n")
+	return buf.Bytes()
+}
+
+// hasImportMedia reports whether the @import statement has conditions
+// after its url.
+func hasImportMedia(statement string) bool {
+	components := parseComponents(strings.TrimSuffix(statement[len("@import"):], ";"))
+	return len(components) > 1
+}
+
+// Media is an @media rule with the rules it holds, in the format returned
+// by Unmarshal.
+type Media struct {
+	// Condition is the media query list, such as "print" or
+	// "screen and (min-width: 600px)".
+	Condition string
+	Rules     map[Rule]map[string]string
+	// Media are the @media rules nested in this one.
+	Media []Media
+}
+
+// UnmarshalWithMedia is like Unmarshal, also returning the @media rules
+// of the stylesheet in document order. The rules nested in @media rules
+// are only part of the returned Media, not of the rules map. @media rules
+// with the same condition are merged like rules with the same selector.
+func UnmarshalWithMedia(b []byte) (map[Rule]map[string]string, []Media, error) {
+	sheet, err := UnmarshalStylesheet(b)
+	if err != nil {
+		return nil, nil, err
+	}
+	return sheet.ToLegacyMap(), sheet.Media(), nil
+}
+
+// Media returns the @media rules of the stylesheet in document order,
+// with the rules they hold in the format returned by Unmarshal.
+func (sheet *Stylesheet) Media() []Media {
+	media := []Media{}
+	for _, r := range sheet.Rules {
+		conditions := r.mediaConditions()
+		if len(conditions) == 0 {
+			continue
+		}
+		list := &media
+		var m *Media
+		for _, condition := range conditions {
+			m = nil
+			for i := range *list {
+				if (*list)[i].Condition == condition {
+					m = &(*list)[i]
+					break
+				}
+			}
+			if m == nil {
+				*list = append(*list, Media{Condition: condition, Rules: map[Rule]map[string]string{}})
+				m = &(*list)[len(*list)-1]
+			}
+			list = &m.Media
+		}
+
+		styles, ok := m.Rules[r.Selector]
+		if !ok {
+			styles = map[string]string{}
+			m.Rules[r.Selector] = styles
+		}
+		for _, d := range r.Declarations {
+			styles[d.Property] = d.valueString()
+		}
+	}
+	return media
+}
+
+// mediaConditions returns the media query lists of the @media rules the
+// rule is nested in, outermost first, with whitespace normalized.
+func (r *StyleRule) mediaConditions() []string {
+	var conditions []string
+	for _, at := range r.AtRules {
+		if atRuleName(string(at)) == "media" {
+			condition := strings.TrimSpace(string(at))[len("@media"):]
+			conditions = append(conditions, strings.Join(strings.Fields(condition), " "))
+		}
+	}
+	return conditions
+}
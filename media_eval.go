@@ -0,0 +1,445 @@
+package css
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// MediaEnvironment is the device media queries are evaluated for.
+type MediaEnvironment struct {
+	// Type is the media type, screen if empty.
+	Type string
+	// Width and Height are the size of the viewport in css pixels.
+	Width, Height float64
+	// Resolution is the number of device pixels per css pixel, 1 if zero.
+	Resolution float64
+	// Features are the values of the other media features, such as
+	// "prefers-color-scheme": "dark" or "hover": "none". Features that are
+	// not set have the value of a desktop browser with default settings.
+	Features map[string]string
+}
+
+var defaultMediaFeatures = map[string]string{
+	"any-hover":              "hover",
+	"any-pointer":            "fine",
+	"color":                  "8",
+	"color-gamut":            "srgb",
+	"forced-colors":          "none",
+	"hover":                  "hover",
+	"pointer":                "fine",
+	"prefers-color-scheme":   "light",
+	"prefers-contrast":       "no-preference",
+	"prefers-reduced-motion": "no-preference",
+	"scan":                   "progressive",
+}
+
+// fingerprint returns a key identifying the environment. fmt prints maps
+// sorted by key, so equal environments have equal fingerprints.
+func (env MediaEnvironment) fingerprint() string {
+	return fmt.Sprintf("%s|%g|%g|%g|%v", env.Type, env.Width, env.Height, env.Resolution, env.Features)
+}
+
+// feature returns the value of the media feature in the environment.
+func (env MediaEnvironment) feature(name string) (mediaValue, bool) {
+	switch name {
+	case "width", "device-width":
+		return mediaValue{number: env.Width, numeric: true}, true
+	case "height", "device-height":
+		return mediaValue{number: env.Height, numeric: true}, true
+	case "aspect-ratio", "device-aspect-ratio":
+		if env.Height == 0 {
+			return mediaValue{}, false
+		}
+		return mediaValue{number: env.Width / env.Height, numeric: true}, true
+	case "resolution":
+		if env.Resolution == 0 {
+			return mediaValue{number: 1, numeric: true}, true
+		}
+		return mediaValue{number: env.Resolution, numeric: true}, true
+	case "orientation":
+		if env.Height >= env.Width {
+			return mediaValue{ident: "portrait"}, true
+		}
+		return mediaValue{ident: "landscape"}, true
+	}
+	value, ok := env.Features[name]
+	if !ok {
+		value, ok = defaultMediaFeatures[name]
+	}
+	if !ok {
+		return mediaValue{}, false
+	}
+	return parseMediaValue(value), true
+}
+
+// MediaEvaluator evaluates media query lists for environments. Query
+// lists are parsed once and the results are cached per environment, so
+// evaluating the same stylesheet for many viewport profiles only costs a
+// map lookup per query after the first time. It is safe for concurrent
+// use.
+type MediaEvaluator struct {
+	mu      sync.Mutex
+	queries map[string][]compiledMediaQuery
+	results map[string]bool
+}
+
+// NewMediaEvaluator returns an evaluator with empty caches.
+func NewMediaEvaluator() *MediaEvaluator {
+	return &MediaEvaluator{queries: map[string][]compiledMediaQuery{}, results: map[string]bool{}}
+}
+
+// Matches reports whether the comma separated media query list, such as
+// "screen and (min-width: 600px), print", matches the environment. An
+// empty list matches every environment.
+func (e *MediaEvaluator) Matches(list string, env MediaEnvironment) bool {
+	return e.matches(list, env, env.fingerprint())
+}
+
+func (e *MediaEvaluator) matches(list string, env MediaEnvironment, fingerprint string) bool {
+	key := fingerprint + "\x00" + list
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if result, ok := e.results[key]; ok {
+		return result
+	}
+	queries, ok := e.queries[list]
+	if !ok {
+		queries = compileMediaList(list)
+		e.queries[list] = queries
+	}
+	result := len(queries) == 0
+	for _, q := range queries {
+		if q.eval(env) {
+			result = true
+			break
+		}
+	}
+	e.results[key] = result
+	return result
+}
+
+// ActiveRules returns the rules of the stylesheet that apply in the
+// environment: the rules that are not nested in an @media rule, and the
+// rules of which all enclosing @media rules match.
+func (e *MediaEvaluator) ActiveRules(sheet *Stylesheet, env MediaEnvironment) []*StyleRule {
+	var (
+		rules       = []*StyleRule{}
+		fingerprint = env.fingerprint()
+	)
+	for _, r := range sheet.Rules {
+		active := true
+		for _, at := range r.AtRules {
+			if atRuleName(string(at)) == "media" &&
+				!e.matches(strings.TrimSpace(string(at))[len("@media"):], env, fingerprint) {
+				active = false
+				break
+			}
+		}
+		if active {
+			rules = append(rules, r)
+		}
+	}
+	return rules
+}
+
+// compiledMediaQuery is a media query such as "not screen and
+// (color)".
+type compiledMediaQuery struct {
+	not       bool
+	typ       string
+	condition *mediaCondition
+}
+
+// mediaCondition is a media feature or a combination of conditions.
+type mediaCondition struct {
+	negate  bool
+	or      bool
+	terms   []mediaCondition
+	feature *mediaFeature
+	// invalid conditions never match.
+	invalid bool
+}
+
+// mediaFeature is a test of a feature such as "(min-width: 600px)". Range
+// tests like "(400px < width < 800px)" are compiled to an and of two
+// features.
+type mediaFeature struct {
+	name  string
+	op    string
+	value mediaValue
+	// boolean features such as "(hover)" have no value.
+	boolean bool
+}
+
+// mediaValue is the value of a media feature. Lengths are in px and
+// resolutions in dppx.
+type mediaValue struct {
+	number  float64
+	ident   string
+	numeric bool
+	// viewport is set to vw or vh for values relative to the viewport,
+	// which are resolved when the environment is known.
+	viewport string
+}
+
+// resolve returns the number of the value in the environment.
+func (v mediaValue) resolve(env MediaEnvironment) float64 {
+	switch v.viewport {
+	case "vw":
+		return v.number * env.Width / 100
+	case "vh":
+		return v.number * env.Height / 100
+	}
+	return v.number
+}
+
+// compileMediaList parses the comma separated media query list.
+func compileMediaList(list string) []compiledMediaQuery {
+	queries := []compiledMediaQuery{}
+	for _, query := range splitTopLevel(list, ',') {
+		if query = strings.ToLower(strings.TrimSpace(query)); query != "" {
+			queries = append(queries, compileMediaQuery(query))
+		}
+	}
+	return queries
+}
+
+// compileMediaQuery parses a lower case media query.
+func compileMediaQuery(query string) compiledMediaQuery {
+	q := compiledMediaQuery{typ: "all"}
+	if !strings.HasPrefix(query, "(") && !strings.HasPrefix(query, "not (") {
+		words := strings.SplitN(query, " ", 2)
+		if words[0] == "not" || words[0] == "only" {
+			q.not = words[0] == "not"
+			query = ""
+			if len(words) == 2 {
+				query = strings.TrimSpace(words[1])
+			}
+			words = strings.SplitN(query, " ", 2)
+		}
+		q.typ = words[0]
+		query = ""
+		if len(words) == 2 {
+			query = strings.TrimSpace(words[1])
+			if !strings.HasPrefix(query, "and ") {
+				return compiledMediaQuery{condition: &mediaCondition{invalid: true}}
+			}
+			query = query[len("and "):]
+		}
+	}
+	if query != "" {
+		c := compileMediaCondition(query)
+		q.condition = &c
+	}
+	return q
+}
+
+// compileMediaCondition parses a condition such as "(hover) and
+// (min-width: 600px)" or "not (color)".
+func compileMediaCondition(s string) mediaCondition {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "not ") || strings.HasPrefix(s, "not(") {
+		return mediaCondition{negate: true, terms: []mediaCondition{compileMediaCondition(s[len("not"):])}}
+	}
+
+	c := mediaCondition{}
+	for i := 0; i < len(s); {
+		switch {
+		case s[i] == ' ':
+			i++
+		case s[i] == '(':
+			end := scanFunction(s, i)
+			part := strings.TrimSpace(s[i+1 : end])
+			if strings.HasPrefix(part, "(") || strings.HasPrefix(part, "not ") || strings.HasPrefix(part, "not(") {
+				c.terms = append(c.terms, compileMediaCondition(part))
+			} else {
+				c.terms = append(c.terms, compileMediaFeature(part))
+			}
+			i = end + 1
+		default:
+			end := strings.IndexAny(s[i:], " (")
+			if end < 0 {
+				end = len(s) - i
+			}
+			switch s[i : i+end] {
+			case "and":
+			case "or":
+				c.or = true
+			default:
+				return mediaCondition{invalid: true}
+			}
+			i += end
+		}
+	}
+	if len(c.terms) == 1 {
+		return c.terms[0]
+	}
+	return c
+}
+
+// compileMediaFeature parses the content of a feature test such as
+// "min-width: 600px", "width >= 600px" or "hover".
+func compileMediaFeature(s string) mediaCondition {
+	if name, value, ok := strings.Cut(s, ":"); ok {
+		f := mediaFeature{name: strings.TrimSpace(name), op: "="}
+		switch {
+		case strings.HasPrefix(f.name, "min-"):
+			f.name, f.op = f.name[len("min-"):], ">="
+		case strings.HasPrefix(f.name, "max-"):
+			f.name, f.op = f.name[len("max-"):], "<="
+		}
+		f.value = parseMediaValue(value)
+		return mediaCondition{feature: &f}
+	}
+
+	parts := rMediaRange.Split(s, -1)
+	ops := rMediaRange.FindAllString(s, -1)
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+	switch len(parts) {
+	case 1:
+		return mediaCondition{feature: &mediaFeature{name: s, boolean: true}}
+	case 2:
+		if isMediaFeatureName(parts[0]) {
+			return mediaCondition{feature: &mediaFeature{parts[0], ops[0], parseMediaValue(parts[1]), false}}
+		}
+		return mediaCondition{feature: &mediaFeature{parts[1], flipRange(ops[0]), parseMediaValue(parts[0]), false}}
+	case 3:
+		return mediaCondition{terms: []mediaCondition{
+			{feature: &mediaFeature{parts[1], flipRange(ops[0]), parseMediaValue(parts[0]), false}},
+			{feature: &mediaFeature{parts[1], ops[1], parseMediaValue(parts[2]), false}},
+		}}
+	}
+	return mediaCondition{invalid: true}
+}
+
+var rMediaRange = regexp.MustCompile(`<=|>=|<|>|=`)
+
+// isMediaFeatureName reports whether the range operand is a feature name
+// rather than a value.
+func isMediaFeatureName(s string) bool {
+	return s != "" && (s[0] >= 'a' && s[0] <= 'z') && !strings.ContainsAny(s, "0123456789/")
+}
+
+// flipRange returns the operator with its operands swapped.
+func flipRange(op string) string {
+	return strings.NewReplacer("<", ">", ">", "<").Replace(op)
+}
+
+var mediaUnits = map[string]float64{
+	"":     1,
+	"px":   1,
+	"em":   16,
+	"rem":  16,
+	"in":   96,
+	"cm":   96 / 2.54,
+	"mm":   96 / 25.4,
+	"q":    96 / 101.6,
+	"pt":   96.0 / 72,
+	"pc":   16,
+	"dppx": 1,
+	"x":    1,
+	"dpi":  1.0 / 96,
+	"dpcm": 2.54 / 96,
+}
+
+// parseMediaValue parses a media feature value such as 600px, 16/9 or
+// dark.
+func parseMediaValue(s string) mediaValue {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if num, den, ok := strings.Cut(s, "/"); ok {
+		n, err1 := strconv.ParseFloat(strings.TrimSpace(num), 64)
+		d, err2 := strconv.ParseFloat(strings.TrimSpace(den), 64)
+		if err1 == nil && err2 == nil && d != 0 {
+			return mediaValue{number: n / d, numeric: true}
+		}
+		return mediaValue{ident: s}
+	}
+	components := parseComponents(s)
+	if len(components) != 1 || components[0].Type != ComponentDimension {
+		return mediaValue{ident: s}
+	}
+	c := components[0]
+	if c.Unit == "vw" || c.Unit == "vh" {
+		return mediaValue{number: c.Number, numeric: true, viewport: c.Unit}
+	}
+	if scale, ok := mediaUnits[c.Unit]; ok {
+		return mediaValue{number: c.Number * scale, numeric: true}
+	}
+	return mediaValue{ident: s}
+}
+
+// eval reports whether the query matches the environment.
+func (q compiledMediaQuery) eval(env MediaEnvironment) bool {
+	typ := env.Type
+	if typ == "" {
+		typ = "screen"
+	}
+	result := q.typ == "all" || q.typ == typ
+	if result && q.condition != nil {
+		result = q.condition.eval(env)
+	}
+	return result != q.not
+}
+
+// eval reports whether the condition matches the environment.
+func (c mediaCondition) eval(env MediaEnvironment) bool {
+	if c.invalid {
+		return false
+	}
+	result := true
+	switch {
+	case c.feature != nil:
+		result = c.feature.eval(env)
+	case c.or:
+		result = false
+		for _, t := range c.terms {
+			if t.eval(env) {
+				result = true
+				break
+			}
+		}
+	default:
+		for _, t := range c.terms {
+			if !t.eval(env) {
+				result = false
+				break
+			}
+		}
+	}
+	return result != c.negate
+}
+
+// eval reports whether the feature test matches the environment.
+func (f mediaFeature) eval(env MediaEnvironment) bool {
+	actual, ok := env.feature(f.name)
+	switch {
+	case !ok:
+		return false
+	case f.boolean:
+		if actual.numeric {
+			return actual.number != 0
+		}
+		return actual.ident != "none" && actual.ident != "no-preference"
+	case actual.numeric && f.value.numeric:
+		a, b := actual.number, f.value.resolve(env)
+		switch f.op {
+		case "<":
+			return a < b
+		case "<=":
+			return a <= b
+		case ">":
+			return a > b
+		case ">=":
+			return a >= b
+		}
+		return math.Abs(a-b) < 1e-9
+	}
+	return f.op == "=" && actual.ident == f.value.ident
+}
@@ -0,0 +1,87 @@
+package css
+
+import "testing"
+
+func TestMediaEvaluator(t *testing.T) {
+	var (
+		phone  = MediaEnvironment{Width: 375, Height: 812, Resolution: 3, Features: map[string]string{"hover": "none", "pointer": "coarse"}}
+		laptop = MediaEnvironment{Width: 1440, Height: 900}
+		dark   = MediaEnvironment{Width: 1440, Height: 900, Features: map[string]string{"prefers-color-scheme": "dark"}}
+		paper  = MediaEnvironment{Type: "print", Width: 794, Height: 1123}
+	)
+	tests := []struct {
+		query    string
+		env      MediaEnvironment
+		expected bool
+	}{
+		{"", phone, true},
+		{"all", phone, true},
+		{"screen", paper, false},
+		{"print", paper, true},
+		{"not print", laptop, true},
+		{"only screen and (min-width: 768px)", laptop, true},
+		{"only screen and (min-width: 768px)", phone, false},
+		{"(max-width: 48em)", phone, true},
+		{"(width >= 600px)", laptop, true},
+		{"(400px <= width < 800px)", paper, true},
+		{"(400px <= width < 800px)", laptop, false},
+		{"(orientation: portrait)", phone, true},
+		{"(min-aspect-ratio: 16/10)", laptop, true},
+		{"(min-resolution: 2dppx)", phone, true},
+		{"(min-resolution: 192dpi)", laptop, false},
+		{"(hover)", phone, false},
+		{"(hover)", laptop, true},
+		{"not (hover)", phone, true},
+		{"(pointer: coarse) or (max-width: 400px)", phone, true},
+		{"(prefers-color-scheme: dark)", dark, true},
+		{"(prefers-color-scheme: dark)", laptop, false},
+		{"(prefers-reduced-motion)", laptop, false},
+		{"(min-width: 50vh)", phone, false},
+		{"print, (max-width: 400px)", phone, true},
+		{"screen and (unknown-feature)", laptop, false},
+		{"screen (color)", laptop, false},
+	}
+	e := NewMediaEvaluator()
+	for _, test := range tests {
+		for i := 0; i < 2; i++ {
+			if actual := e.Matches(test.query, test.env); actual != test.expected {
+				t.Errorf("%q: expected %v, got %v", test.query, test.expected, actual)
+			}
+		}
+	}
+	if len(e.queries) != 22 {
+		t.Errorf("expected every query to be compiled once, got %d", len(e.queries))
+	}
+}
+
+func TestMediaEvaluatorActiveRules(t *testing.T) {
+	sheet, _ := UnmarshalStylesheet([]byte(`body {
+	margin: 0;
+}
+@media (min-width: 768px) {
+	body {
+		margin: 1em;
+	}
+	@media print {
+		body {
+			margin: 0;
+		}
+	}
+}
+@supports (display: grid) {
+	main {
+		display: grid;
+	}
+}
+`))
+	e := NewMediaEvaluator()
+	if rules := e.ActiveRules(sheet, MediaEnvironment{Width: 375, Height: 812}); len(rules) != 2 || rules[1].Selector != "main" {
+		t.Errorf("unexpected rules for phone: %v", rules)
+	}
+	if rules := e.ActiveRules(sheet, MediaEnvironment{Width: 1024, Height: 768}); len(rules) != 3 {
+		t.Errorf("unexpected rules for tablet: %v", rules)
+	}
+	if rules := e.ActiveRules(sheet, MediaEnvironment{Type: "print", Width: 794, Height: 1123}); len(rules) != 4 {
+		t.Errorf("unexpected rules for print: %v", rules)
+	}
+}
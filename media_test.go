@@ -0,0 +1,60 @@
+package css
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUnmarshalWithMedia(t *testing.T) {
+	css, media, err := UnmarshalWithMedia([]byte(`body {
+	margin: 0;
+}
+@media screen and
+		(min-width: 600px) {
+	body {
+		margin: 1em;
+	}
+	@media print {
+		body {
+			color: black;
+		}
+	}
+}
+@media print {
+	nav {
+		display: none;
+	}
+}
+@media screen and (min-width: 600px) {
+	body {
+		padding: 0;
+	}
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[Rule]map[string]string{"body": {"margin": "0"}}
+	if !reflect.DeepEqual(css, expected) {
+		t.Errorf("expected %v, got %v", expected, css)
+	}
+
+	expectedMedia := []Media{
+		{
+			Condition: "screen and (min-width: 600px)",
+			Rules:     map[Rule]map[string]string{"body": {"margin": "1em", "padding": "0"}},
+			Media: []Media{
+				{Condition: "print", Rules: map[Rule]map[string]string{"body": {"color": "black"}}},
+			},
+		},
+		{Condition: "print", Rules: map[Rule]map[string]string{"nav": {"display": "none"}}},
+	}
+	if !reflect.DeepEqual(media, expectedMedia) {
+		t.Errorf("expected %+v, got %+v", expectedMedia, media)
+	}
+
+	if css, _ := Unmarshal([]byte("a {\n\tcolor: red;\n}\n@media print {\n\ta {\n\t\tcolor: black;\n\t}\n}\n")); css["a"]["color"] != "red" {
+		t.Errorf("@media rules changed the rules map: %v", css)
+	}
+}
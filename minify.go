@@ -0,0 +1,215 @@
+package css
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+)
+
+// MinifyLevel selects which passes are run by Minify.
+type MinifyLevel int
+
+const (
+	// MinifySafe removes whitespace, comments and the units of zero
+	// lengths, outside of calc() and the other math functions and of the
+	// flex properties. At-rules and statements such as @import are kept.
+	// The output always behaves like the input.
+	MinifySafe MinifyLevel = iota
+	// MinifyAggressive runs the safe passes and also merges rules with
+	// the same selector, collapses longhands into shorthands and removes
+	// duplicated selectors. These passes can change cascade behavior.
+	MinifyAggressive
+)
+
+var rZeroUnit = regexp.MustCompile(`(^|[\s,(])-?0+(?:\.0+)?(?:px|em|rem|ex|ch|vw|vh|vmin|vmax|cm|mm|in|pt|pc|q)\b`)
+
+// Minify returns the css in b with the passes of the given level applied.
+// License comments are kept at the top of the output.
+func Minify(b []byte, level MinifyLevel) ([]byte, error) {
+	return MinifyWithOptions(b, level, ParseOptions{})
+}
+
+// MinifyWithOptions is like Minify, reporting the rules and declarations
+// it had to skip as configured in opts.
+func MinifyWithOptions(b []byte, level MinifyLevel, opts ParseOptions) ([]byte, error) {
+	blocks, err := opts.unmarshal(b)
+	if err != nil {
+		return nil, err
+	}
+	for i := range blocks {
+		for j, d := range blocks[i].styles {
+			// custom properties can be used in calc()
+			if !strings.HasPrefix(d.Property, "--") && !containsString(keepUnitProperties, strings.ToLower(d.Property)) {
+				blocks[i].styles[j].Value = stripZeroUnits(d.Value)
+			}
+		}
+	}
+
+	if level >= MinifyAggressive {
+		blocks = mergeBlocks(blocks)
+		for i := range blocks {
+			blocks[i].selector = dedupSelectors(blocks[i].selector)
+			blocks[i].styles = CollapseShorthands(blocks[i].styles)
+		}
+	}
+
+	var (
+		buf        bytes.Buffer
+		statements = statementSources(b)
+	)
+	// @charset must come first
+	for _, st := range statements {
+		if atRuleName(string(st.Prelude)) == "charset" {
+			buf.WriteString(compactSelector(string(st.Prelude)) + ";")
+		}
+	}
+	for _, license := range Licenses(b) {
+		buf.WriteString(license)
+		buf.WriteByte('\n')
+	}
+	for _, st := range statements {
+		if atRuleName(string(st.Prelude)) != "charset" {
+			buf.WriteString(compactSelector(string(st.Prelude)) + ";")
+		}
+	}
+	nestBlocks(blocks, func(bl block, depth int) {
+		buf.WriteString(compactSelector(bl.atRules[depth]) + "{")
+	}, func(int) {
+		buf.WriteByte('}')
+	}, func(bl block, _ int) {
+		buf.WriteString(compactSelector(bl.selector))
+		buf.WriteByte('{')
+		for i, d := range bl.styles {
+			if i > 0 {
+				buf.WriteByte(';')
+			}
+			buf.WriteString(d.Property)
+			buf.WriteByte(':')
+			buf.WriteString(d.Value)
+			if d.Important {
+				buf.WriteString("!important")
+			}
+		}
+		buf.WriteByte('}')
+	})
+	return buf.Bytes(), nil
+}
+
+// keepUnitProperties are the properties whose zero lengths keep their
+// unit: in "flex: 1 0px" the 0px is the flex-basis, while "flex: 1 0" is
+// a flex-shrink of 0.
+var keepUnitProperties = []string{"flex", "flex-basis", "-webkit-flex", "-webkit-flex-basis", "-ms-flex"}
+
+// keepUnitFunctions are the functions whose zero lengths keep their unit:
+// "calc(100% - 0)" is invalid, and urls are not lengths.
+var keepUnitFunctions = []string{"calc", "-webkit-calc", "-moz-calc", "min", "max", "clamp", "url", "var", "env"}
+
+// stripZeroUnits removes the unit from zero lengths, "0px" becomes "0",
+// except in strings and in the arguments of keepUnitFunctions.
+func stripZeroUnits(value string) string {
+	var (
+		out   strings.Builder
+		start = 0
+	)
+	for i := 0; i < len(value); {
+		c := value[i]
+		end := i + 1
+		switch {
+		case c == '"' || c == '\'':
+			end = scanString(value, i)
+		case c == '\\':
+			end = min(i+2, len(value))
+		case isNameByte(c) && (i == 0 || !isNameByte(value[i-1])):
+			end = scanName(value, i)
+			if end < len(value) && value[end] == '(' && containsString(keepUnitFunctions, strings.ToLower(value[i:end])) {
+				end = min(scanFunction(value, end)+1, len(value))
+				out.WriteString(rZeroUnit.ReplaceAllString(value[start:i], "${1}0"))
+				out.WriteString(value[i:end])
+				start = end
+			}
+		}
+		if c == '"' || c == '\'' {
+			out.WriteString(rZeroUnit.ReplaceAllString(value[start:i], "${1}0"))
+			out.WriteString(value[i:end])
+			start = end
+		}
+		i = end
+	}
+	out.WriteString(rZeroUnit.ReplaceAllString(value[start:], "${1}0"))
+	return out.String()
+}
+
+// compactSelector removes the whitespace around selector separators and
+// collapses the other whitespace, outside of strings.
+func compactSelector(selector string) string {
+	parts := splitTopLevel(selector, ',')
+	for i := range parts {
+		parts[i] = collapseSpaces(parts[i])
+	}
+	return strings.Join(parts, ",")
+}
+
+// mergeBlocks merges every block into the first block with the same
+// selector nested in the same at-rules. Styles of later blocks override
+// the earlier ones.
+func mergeBlocks(blocks []block) []block {
+	var (
+		merged = []block{}
+		index  = map[string]int{}
+	)
+	for _, b := range blocks {
+		key := strings.Join(append(append([]string{}, b.atRules...), b.selector), "\x00")
+		i, ok := index[key]
+		if !ok {
+			index[key] = len(merged)
+			merged = append(merged, block{selector: b.selector, pos: b.pos, atRules: b.atRules})
+			i = len(merged) - 1
+		}
+		for _, d := range b.styles {
+			merged[i].styles = setDeclaration(merged[i].styles, d)
+		}
+	}
+	return merged
+}
+
+// setDeclaration removes any earlier declaration of the same property
+// and appends d, so that the last value wins.
+func setDeclaration(styles []Declaration, d Declaration) []Declaration {
+	out := styles[:0]
+	for _, s := range styles {
+		if s.Property != d.Property {
+			out = append(out, s)
+		}
+	}
+	return append(out, d)
+}
+
+// dedupSelectors removes repeated selectors from a comma separated list.
+func dedupSelectors(selector string) string {
+	var (
+		seen = map[string]bool{}
+		out  = []string{}
+	)
+	for _, s := range splitTopLevel(selector, ',') {
+		s = collapseSpaces(s)
+		if seen[s] {
+			continue
+		}
+		seen[s] = true
+		out = append(out, s)
+	}
+	return strings.Join(out, ",")
+}
+
+// boxValue returns the shortest form of a top, right, bottom, left value.
+func boxValue(v []string) string {
+	switch {
+	case v[0] == v[1] && v[1] == v[2] && v[2] == v[3]:
+		return v[0]
+	case v[0] == v[2] && v[1] == v[3]:
+		return v[0] + " " + v[1]
+	case v[1] == v[3]:
+		return v[0] + " " + v[1] + " " + v[2]
+	}
+	return strings.Join(v, " ")
+}
@@ -0,0 +1,100 @@
+package css
+
+import "testing"
+
+func TestMinify(t *testing.T) {
+	ex1 := `/*! license */
+.a {
+	margin-top: 0px;
+	margin-right: 10px;
+	margin-bottom: 0em;
+	margin-left: 10px;
+}
+.b, .b {
+	color: red;
+}
+.a {
+	color: blue;
+}`
+
+	t.Run("Safe", func(t *testing.T) {
+		out, err := Minify([]byte(ex1), MinifySafe)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := "/*! license */\n" +
+			".a{margin-top:0;margin-right:10px;margin-bottom:0;margin-left:10px}" +
+			".b,.b{color:red}" +
+			".a{color:blue}"
+		if string(out) != expected {
+			t.Fatalf("got %q, expected %q", out, expected)
+		}
+	})
+
+	t.Run("Aggressive", func(t *testing.T) {
+		out, err := Minify([]byte(ex1), MinifyAggressive)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := "/*! license */\n" +
+			".a{margin:0 10px;color:blue}" +
+			".b{color:red}"
+		if string(out) != expected {
+			t.Fatalf("got %q, expected %q", out, expected)
+		}
+	})
+}
+
+func TestMinifyKeepsBehavior(t *testing.T) {
+	tests := []struct{ in, out string }{
+		{"@media print { a { color: red; } }", "@media print{a{color:red}}"},
+		{"@media print { a { color: red; } b { color: blue; } } c { color: green; }",
+			"@media print{a{color:red}b{color:blue}}c{color:green}"},
+		{"@supports (display: grid) { @media screen { a { margin: 0px; } } }",
+			"@supports (display: grid){@media screen{a{margin:0}}}"},
+		{"a { color: red; }\n@import url(\"a.css\")  screen;\n@charset \"utf-8\";",
+			"@charset \"utf-8\";@import url(\"a.css\") screen;a{color:red}"},
+		{"a { width: calc(100% - 0px); }", "a{width:calc(100% - 0px)}"},
+		{"a { width: max(0px, 1em) ; margin: 0px; }", "a{width:max(0px, 1em);margin:0}"},
+		{"a { content: \"0px\"; }", "a{content:\"0px\"}"},
+		{"a { background: url(0px.png) 0px 0px; }", "a{background:url(0px.png) 0 0}"},
+		{"a { --gap: 0px; }", "a{--gap:0px}"},
+		{"a { flex: 1 0px; flex-basis: 0px; margin: 0px; }", "a{flex:1 0px;flex-basis:0px;margin:0}"},
+		{"a[title=\"x  y\"] ,  b:is(c,  d) { color: red; }", "a[title=\"x  y\"],b:is(c,  d){color:red}"},
+	}
+	for _, test := range tests {
+		out, err := Minify([]byte(test.in), MinifySafe)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(out) != test.out {
+			t.Errorf("%q: got %q, expected %q", test.in, out, test.out)
+		}
+	}
+
+	// shorthands are only collapsed when the values are known
+	out, err := Minify([]byte("a { margin: var(--m); margin-left: 0; } b { margin: 0 calc(1px + 2px); margin-left: 5px; }"), MinifyAggressive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "a{margin:var(--m);margin-left:0}b{margin:0 calc(1px + 2px) 0 5px}"; string(out) != expected {
+		t.Errorf("got %q, expected %q", out, expected)
+	}
+
+	out, err = Minify([]byte(`a[title="x  y"], a[title="x y"], a[title="x  y"] { color: red; }`), MinifyAggressive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `a[title="x  y"],a[title="x y"]{color:red}`; string(out) != expected {
+		t.Errorf("got %q, expected %q", out, expected)
+	}
+
+	// the same selector in different at-rules isn't merged
+	out, err = Minify([]byte("a { color: red; } @media print { a { color: blue; } }"), MinifyAggressive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "a{color:red}@media print{a{color:blue}}"; string(out) != expected {
+		t.Errorf("got %q, expected %q", out, expected)
+	}
+}
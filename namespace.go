@@ -0,0 +1,62 @@
+package css
+
+import "strings"
+
+// Namespaces returns the namespaces declared by the @namespace rules of
+// the stylesheet, mapping their prefix to their URI. The default
+// namespace has the empty prefix.
+func Namespaces(b []byte) map[string]string {
+	namespaces := map[string]string{}
+	for _, item := range topLevelItems(blankComments(b)) {
+		if atRuleName(item) != "namespace" {
+			continue
+		}
+		components := parseComponents(strings.TrimSuffix(strings.TrimSpace(item)[len("@namespace"):], ";"))
+		prefix := ""
+		if len(components) == 2 && components[0].Type == ComponentIdent {
+			prefix = components[0].Value
+			components = components[1:]
+		}
+		if len(components) != 1 {
+			continue
+		}
+		switch c := components[0]; {
+		case c.Type == ComponentString:
+			namespaces[prefix] = c.Value
+		case c.Type == ComponentFunction && strings.EqualFold(c.Value, "url") && len(c.Args) == 1:
+			namespaces[prefix] = c.Args[0].Value
+		}
+	}
+	return namespaces
+}
+
+// CompileSelectorNS is like CompileSelector for stylesheets declaring
+// namespaces, as returned by Namespaces. Selectors with a prefix such as
+// svg|a only match elements in the namespace of the prefix, and the other
+// selectors only match elements in the default namespace, if declared.
+// Undeclared prefixes are an error.
+func CompileSelectorNS(rule Rule, namespaces map[string]string) (*CompiledSelector, error) {
+	s, err := CompileSelector(rule)
+	if err != nil {
+		return nil, err
+	}
+	for i := range s.group {
+		for j := range s.group[i].compounds {
+			c := &s.group[i].compounds[j]
+			switch {
+			case c.prefixed && (c.prefix == "*" || c.prefix == ""):
+			case c.prefixed:
+				uri, ok := namespaces[c.prefix]
+				if !ok {
+					return nil, errInvalidSelector
+				}
+				c.namespace = &uri
+			default:
+				if uri, ok := namespaces[""]; ok {
+					c.namespace = &uri
+				}
+			}
+		}
+	}
+	return s, nil
+}
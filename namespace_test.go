@@ -0,0 +1,74 @@
+package css
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNamespaces(t *testing.T) {
+	namespaces := Namespaces([]byte(`@charset "utf-8";
+@namespace url(http://www.w3.org/1999/xhtml);
+@namespace svg url("http://www.w3.org/2000/svg");
+/* @namespace x "nope"; */
+@namespace m "http://www.w3.org/1998/Math/MathML";
+a {
+	color: red;
+}
+`))
+	expected := map[string]string{"": HTMLNamespace, "svg": SVGNamespace, "m": MathMLNamespace}
+	if !reflect.DeepEqual(namespaces, expected) {
+		t.Errorf("expected %v, got %v", expected, namespaces)
+	}
+}
+
+func TestCompileSelectorNS(t *testing.T) {
+	var (
+		body   = &testElement{tag: "body"}
+		link   = body.append("a", nil)
+		svg    = body.append("svg", nil)
+		svgA   = svg.append("a", nil)
+		plainA = body.append("a", nil)
+	)
+	svg.namespace, svgA.namespace, plainA.namespace = SVGNamespace, SVGNamespace, "urn:none"
+
+	withDefault := map[string]string{"": HTMLNamespace, "svg": SVGNamespace}
+	tests := []struct {
+		rule       Rule
+		namespaces map[string]string
+		matches    []*testElement
+	}{
+		{"a", nil, []*testElement{link, svgA, plainA}},
+		{"a", withDefault, []*testElement{link}},
+		{"svg|a", withDefault, []*testElement{svgA}},
+		{"*|a", withDefault, []*testElement{link, svgA, plainA}},
+		{"svg|* > svg|a", withDefault, []*testElement{svgA}},
+		{"svg|*", withDefault, []*testElement{svg, svgA}},
+		{"body > a, svg|a", withDefault, []*testElement{link, svgA}},
+	}
+	for _, test := range tests {
+		s, err := CompileSelectorNS(test.rule, test.namespaces)
+		if err != nil {
+			t.Errorf("%s: %v", test.rule, err)
+			continue
+		}
+		for _, el := range []*testElement{link, svg, svgA, plainA} {
+			expected := false
+			for _, m := range test.matches {
+				expected = expected || m == el
+			}
+			if s.Matches(el) != expected {
+				t.Errorf("%s: expected %v for %s in %s", test.rule, expected, el.tag, el.Namespace())
+			}
+		}
+	}
+
+	if _, err := CompileSelectorNS("x|a", withDefault); err == nil {
+		t.Error("expected an error for an undeclared prefix")
+	}
+	if Rule("svg|a").Matches(svgA) || !Rule("*|a").Matches(svgA) {
+		t.Error("unexpected match without namespaces")
+	}
+	if s := Rule("svg|a >  *|b, |c").String(); s != "svg|a > *|b, |c" {
+		t.Errorf("unexpected string %q", s)
+	}
+}
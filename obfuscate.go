@@ -0,0 +1,244 @@
+package css
+
+import (
+	"bytes"
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+// Renaming maps the original class, id, @keyframes and @counter-style
+// names to the names generated by Obfuscate or Scope, without the "." and
+// "#" prefix. It can be used to rewrite the html and templates using the
+// stylesheet.
+type Renaming struct {
+	Classes       map[string]string
+	IDs           map[string]string
+	Keyframes     map[string]string
+	CounterStyles map[string]string
+}
+
+// Obfuscate replaces every class and id in the selectors of the css with a
+// short generated name and returns the result with the mapping used. The
+// names only depend on the seed and on the order in which classes and ids
+// first appear, so the same input and seed always give the same output.
+// The names of @keyframes and @counter-style rules and their references
+// are replaced as well.
+func Obfuscate(b []byte, seed int64) ([]byte, Renaming) {
+	alphabet := []byte("abcdefghijklmnopqrstuvwxyz")
+	r := rand.New(rand.NewSource(seed))
+	r.Shuffle(len(alphabet), func(i, j int) { alphabet[i], alphabet[j] = alphabet[j], alphabet[i] })
+
+	return rename(b, func(names map[string]string, _ string) string {
+		return generatedName(alphabet, len(names))
+	})
+}
+
+// Scope prefixes every class and id in the selectors of the css, and the
+// names of the @keyframes and @counter-style rules with their references,
+// so the stylesheet doesn't clash with the other stylesheets of a page.
+func Scope(b []byte, prefix string) ([]byte, Renaming) {
+	return rename(b, func(_ map[string]string, name string) string {
+		return prefix + name
+	})
+}
+
+// renameEdit replaces the [start, end) range of the source with name.
+type renameEdit struct {
+	start, end int
+	name       string
+}
+
+// rename replaces the classes and ids of the selectors in b, the names of
+// the @keyframes and @counter-style rules, and the references to these
+// rules in animation and list-style declarations. newName is called once
+// per name with the names of the same kind renamed so far. References to
+// keyframes and counter styles not defined in b are kept, since they are
+// defined elsewhere.
+func rename(b []byte, newName func(names map[string]string, name string) string) ([]byte, Renaming) {
+	var (
+		renaming = Renaming{
+			Classes:       map[string]string{},
+			IDs:           map[string]string{},
+			Keyframes:     map[string]string{},
+			CounterStyles: map[string]string{},
+		}
+		clean = blankComments(b)
+		edits = []renameEdit{}
+	)
+	define := func(names map[string]string, name string, start, end int) {
+		renamed, ok := names[name]
+		if !ok {
+			renamed = newName(names, name)
+			names[name] = renamed
+		}
+		edits = append(edits, renameEdit{start, end, renamed})
+	}
+
+	for _, span := range selectorSpans(b) {
+		text := string(clean[span[0]:span[1]])
+		for _, ref := range selectorReferences(text) {
+			names := renaming.Classes
+			if text[ref[0]] == '#' {
+				names = renaming.IDs
+			}
+			define(names, text[ref[0]+1:ref[1]], span[0]+ref[0]+1, span[0]+ref[1])
+		}
+	}
+
+	for _, s := range RuleSources(b) {
+		text := string(clean[s.Start:s.End])
+		name := atRuleName(text)
+		names := renaming.Keyframes
+		switch {
+		case strings.HasSuffix(name, "keyframes"):
+		case name == "counter-style":
+			names = renaming.CounterStyles
+		default:
+			continue
+		}
+		i := 1 + len(name)
+		for i < len(text) && (text[i] == ' ' || text[i] == '\t' || text[i] == '\n') {
+			i++
+		}
+		if i < len(text) && (text[i] == '"' || text[i] == '\'') {
+			if j := scanString(text, i); j-1 > i {
+				define(names, text[i+1:j-1], s.Start+i+1, s.Start+j-1)
+			}
+		} else if j := scanName(text, i); j > i {
+			define(names, text[i:j], s.Start+i, s.Start+j)
+		}
+	}
+
+	for _, span := range declarationSpans(b) {
+		names := renaming.Keyframes
+		switch unprefixed(strings.ToLower(span.tokens[0].value)) {
+		case "animation", "animation-name":
+		case "list-style", "list-style-type", "system", "fallback":
+			names = renaming.CounterStyles
+		default:
+			continue
+		}
+		text := string(clean[span.start:span.end])
+		for i := strings.IndexByte(text, ':') + 1; i > 0 && i < len(text); {
+			switch c := text[i]; {
+			case c == '"' || c == '\'':
+				j := scanString(text, i)
+				if j-1 > i {
+					if renamed, ok := names[text[i+1:j-1]]; ok {
+						edits = append(edits, renameEdit{span.start + i + 1, span.start + j - 1, renamed})
+					}
+				}
+				i = j
+			case isNameByte(c):
+				j := scanName(text, i)
+				if renamed, ok := names[text[i:j]]; ok {
+					edits = append(edits, renameEdit{span.start + i, span.start + j, renamed})
+				}
+				i = j
+			default:
+				i++
+			}
+		}
+	}
+
+	sort.Slice(edits, func(i, j int) bool { return edits[i].start < edits[j].start })
+	var (
+		buf  bytes.Buffer
+		last = 0
+	)
+	for _, e := range edits {
+		buf.Write(b[last:e.start])
+		buf.WriteString(e.name)
+		last = e.end
+	}
+	buf.Write(b[last:])
+	return buf.Bytes(), renaming
+}
+
+// generatedName returns the n-th name made of letters of the alphabet:
+// a, b, ..., z, aa, ab and so on for the unshuffled alphabet.
+func generatedName(alphabet []byte, n int) string {
+	name := []byte{}
+	for {
+		name = append([]byte{alphabet[n%len(alphabet)]}, name...)
+		n = n/len(alphabet) - 1
+		if n < 0 {
+			return string(name)
+		}
+	}
+}
+
+// selectorReferences returns the start and end index of every class and
+// id in the selector text, including the ones in pseudo class arguments.
+// Attribute selectors and strings are skipped.
+func selectorReferences(text string) [][2]int {
+	refs := [][2]int{}
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; c {
+		case '"', '\'':
+			i = scanString(text, i) - 1
+		case '[':
+			for i < len(text) && text[i] != ']' {
+				if text[i] == '"' || text[i] == '\'' {
+					i = scanString(text, i)
+					continue
+				}
+				i++
+			}
+		case '.', '#':
+			if i+1 >= len(text) || !isNameByte(text[i+1]) || (c == '.' && text[i+1] >= '0' && text[i+1] <= '9') {
+				continue
+			}
+			j := scanName(text, i+1)
+			refs = append(refs, [2]int{i, j})
+			i = j - 1
+		}
+	}
+	return refs
+}
+
+// selectorSpans returns the byte range of the selector of every style
+// rule in b. The preludes of at-rules and the keyframe selectors are
+// skipped.
+func selectorSpans(b []byte) [][2]int {
+	var (
+		spans = [][2]int{}
+		item  = []TokenEntry{}
+		// stack holds the lower case at-rule name of every open block, or
+		// an empty string for style rules.
+		stack = []string{}
+	)
+
+	e := Tokenize(b).Front()
+	for e != nil {
+		tok := e.Value.(TokenEntry)
+
+		switch tok.typ() {
+		case tokenBlockStart:
+			name := ""
+			if len(item) > 0 {
+				name = atRuleName(item[0].value)
+				inKeyframes := len(stack) > 0 && strings.HasSuffix(stack[len(stack)-1], "keyframes")
+				if name == "" && !inKeyframes {
+					spans = append(spans, [2]int{item[0].pos.Offset, tok.pos.Offset})
+				}
+			}
+			stack = append(stack, name)
+			item = nil
+		case tokenBlockEnd:
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+			item = nil
+		case tokenStatementEnd:
+			item = nil
+		default:
+			item = append(item, tok)
+		}
+
+		e = e.Next()
+	}
+
+	return spans
+}
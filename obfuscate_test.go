@@ -0,0 +1,129 @@
+package css
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestObfuscate(t *testing.T) {
+	ex := []byte(`.header .title {
+	color: #fff;
+}
+#main > .title:not(.hidden)
+{
+	background: url("a.b.png");
+}
+[data-x=".nope"] .header {
+	top: 0
+}
+@keyframes fade {
+	12.5% {
+		opacity: 0.5;
+	}
+}
+`)
+	out, renaming := Obfuscate(ex, 1)
+	if len(renaming.Classes) != 3 || len(renaming.IDs) != 1 {
+		t.Fatalf("unexpected renaming %v", renaming)
+	}
+	header, title := renaming.Classes["header"], renaming.Classes["title"]
+	if header == "" || title == "" || header == title || len(header) != 1 {
+		t.Fatalf("unexpected class names %v", renaming.Classes)
+	}
+
+	id := renaming.IDs["main"]
+	for _, sel := range []string{"." + header + " ." + title + " {", "#" + id + " > ." + title + ":not(." + renaming.Classes["hidden"] + ")"} {
+		if !bytes.Contains(out, []byte(sel)) {
+			t.Errorf("expected selector %q:\n%s", sel, out)
+		}
+	}
+	for _, keep := range []string{`"a.b.png"`, `[data-x=".nope"]`, "12.5%"} {
+		if !bytes.Contains(out, []byte(keep)) {
+			t.Errorf("expected %s to be kept:\n%s", keep, out)
+		}
+	}
+
+	again, _ := Obfuscate(ex, 1)
+	if !bytes.Equal(out, again) {
+		t.Fatal("obfuscation is not deterministic")
+	}
+}
+
+func TestGeneratedName(t *testing.T) {
+	alphabet := []byte("abcdefghijklmnopqrstuvwxyz")
+	for n, want := range map[int]string{0: "a", 25: "z", 26: "aa", 27: "ab", 26 + 26*26: "aaa"} {
+		if got := generatedName(alphabet, n); got != want {
+			t.Errorf("generatedName(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+func TestScope(t *testing.T) {
+	ex := []byte(`div.card {
+	animation: 1s ease-in fade, 2s spin infinite;
+	list-style: stars inside;
+}
+@keyframes fade {
+	from {
+		opacity: 0;
+	}
+}
+@-webkit-keyframes "fade" {
+	from {
+		opacity: 0;
+	}
+}
+@counter-style stars {
+	system: cyclic;
+	symbols: "*";
+}
+@counter-style big-stars {
+	system: extends stars;
+	fallback: stars;
+}
+div#list {
+	-webkit-animation-name: fade;
+	list-style-type: big-stars;
+}
+`)
+	out, renaming := Scope(ex, "w-")
+	expected := `div.w-card {
+	animation: 1s ease-in w-fade, 2s spin infinite;
+	list-style: w-stars inside;
+}
+@keyframes w-fade {
+	from {
+		opacity: 0;
+	}
+}
+@-webkit-keyframes "w-fade" {
+	from {
+		opacity: 0;
+	}
+}
+@counter-style w-stars {
+	system: cyclic;
+	symbols: "*";
+}
+@counter-style w-big-stars {
+	system: extends w-stars;
+	fallback: w-stars;
+}
+div#w-list {
+	-webkit-animation-name: w-fade;
+	list-style-type: w-big-stars;
+}
+`
+	if string(out) != expected {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+	if renaming.Keyframes["fade"] != "w-fade" || len(renaming.Keyframes) != 1 || len(renaming.CounterStyles) != 2 {
+		t.Errorf("unexpected renaming %v", renaming)
+	}
+
+	obfuscated, renaming := Obfuscate(ex, 1)
+	if !bytes.Contains(obfuscated, []byte("@keyframes "+renaming.Keyframes["fade"]+" {")) ||
+		!bytes.Contains(obfuscated, []byte("list-style-type: "+renaming.CounterStyles["big-stars"]+";")) {
+		t.Errorf("unexpected obfuscated output:\n%s", obfuscated)
+	}
+}
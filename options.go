@@ -0,0 +1,221 @@
+package css
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"text/scanner"
+	"time"
+)
+
+// Warning is a non-fatal problem found while parsing or transforming a
+// stylesheet, such as a skipped rule or an unknown at-rule.
+type Warning struct {
+	Message string
+	// Token is the text the warning is about.
+	Token string
+	Pos   scanner.Position
+}
+
+func (w Warning) String() string {
+	return fmt.Sprintf("%d:%d: %s %q", w.Pos.Line, w.Pos.Column, w.Message, w.Token)
+}
+
+// ParseError is the syntax error failing a strict parse.
+type ParseError struct {
+	Pos scanner.Position
+	// Token is the offending token, empty at the end of the source.
+	Token string
+	// Expected describes what was expected instead of the token, such as
+	// "property" or "}".
+	Expected string
+}
+
+func (e *ParseError) Error() string {
+	found := "end of input"
+	if e.Token != "" {
+		found = strconv.Quote(e.Token)
+	}
+	return fmt.Sprintf("%d:%d: unexpected %s, expected %s", e.Pos.Line, e.Pos.Column, found, e.Expected)
+}
+
+// ParseOptions configures parsing. The zero value silently drops every
+// warning.
+type ParseOptions struct {
+	// Logger receives every warning at the warn level.
+	Logger *slog.Logger
+	// OnWarning is called with every warning.
+	OnWarning func(Warning)
+	// Metrics receives the measurements of every parse.
+	Metrics Metrics
+	// Intern makes the declarations of a parse share the storage of equal
+	// property names and keyword values, reducing the memory held by
+	// large stylesheets at a small cost in parse time.
+	Intern bool
+	// Arena allocates the rules and declarations of the parsed
+	// stylesheets in large batches instead of one by one.
+	Arena *Arena
+	// SplitSelectorGroups parses a rule with a selector group such as
+	// "h1, h2 { ... }" as one rule per selector, each with all the
+	// declarations.
+	SplitSelectorGroups bool
+	// Resolver inlines the stylesheets imported with @import before
+	// parsing, see ResolveImports. The parsed stylesheet has an empty
+	// name: FSResolver resolves its relative urls from the root and
+	// HTTPResolver only resolves its absolute urls.
+	Resolver Resolver
+	// Strict fails the parse with a *ParseError at the first syntax error
+	// instead of skipping the offending rule or declaration with a
+	// warning. Unknown at-rules are still only warnings.
+	Strict bool
+}
+
+// Metrics receives measurements of parsing, so services can forward them
+// to Prometheus, OpenTelemetry or any other monitoring system.
+type Metrics interface {
+	ObserveParse(stats ParseStats)
+}
+
+// ParseStats are the measurements of a single parse.
+type ParseStats struct {
+	Duration time.Duration
+	// Bytes is the size of the parsed css. It is zero when parsing an
+	// existing token list.
+	Bytes    int
+	Tokens   int
+	Warnings int
+	// Errors is the number of errors that failed the parse.
+	Errors int
+}
+
+// resolve returns b with its imports inlined when opts.Resolver is set.
+func (opts ParseOptions) resolve(b []byte) ([]byte, error) {
+	if opts.Resolver == nil {
+		return b, nil
+	}
+	return ResolveImports("", b, opts.Resolver)
+}
+
+// unmarshal tokenizes and parses b into blocks.
+func (opts ParseOptions) unmarshal(b []byte) ([]block, error) {
+	start := time.Now()
+	return opts.parse(Tokenize(b), len(b), start)
+}
+
+// parse parses the tokens into blocks, reporting the measurements of the
+// parse started at start to opts.Metrics.
+func (opts ParseOptions) parse(l *list.List, size int, start time.Time) ([]block, error) {
+	if opts.Metrics == nil {
+		blocks, err := parseBlocks(l, opts)
+		if err != nil {
+			return nil, err
+		}
+		return opts.split(blocks), nil
+	}
+
+	stats := ParseStats{Bytes: size, Tokens: l.Len()}
+	onWarning := opts.OnWarning
+	opts.OnWarning = func(w Warning) {
+		stats.Warnings++
+		if onWarning != nil {
+			onWarning(w)
+		}
+	}
+	blocks, err := parseBlocks(l, opts)
+	stats.Duration = time.Since(start)
+	if err != nil {
+		stats.Errors++
+		opts.Metrics.ObserveParse(stats)
+		return nil, err
+	}
+	opts.Metrics.ObserveParse(stats)
+	return opts.split(blocks), nil
+}
+
+// split returns the blocks with one block per selector of their selector
+// groups if opts.SplitSelectorGroups is set.
+func (opts ParseOptions) split(blocks []block) []block {
+	if !opts.SplitSelectorGroups {
+		return blocks
+	}
+	split := make([]block, 0, len(blocks))
+	for _, b := range blocks {
+		if strings.HasPrefix(b.selector, "@") {
+			split = append(split, b)
+			continue
+		}
+		styles := b.styles
+		for i, rule := range Rule(b.selector).Group() {
+			if i > 0 {
+				// rules must not share their declarations
+				b.styles = append([]Declaration(nil), styles...)
+			}
+			b.selector = string(rule)
+			split = append(split, b)
+		}
+	}
+	return split
+}
+
+// knownAtRules are the at-rules that don't cause a warning.
+var knownAtRules = map[string]bool{
+	"charset":             true,
+	"container":           true,
+	"counter-style":       true,
+	"document":            true,
+	"else":                true,
+	"font-face":           true,
+	"font-feature-values": true,
+	"import":              true,
+	"keyframes":           true,
+	"layer":               true,
+	"media":               true,
+	"namespace":           true,
+	"page":                true,
+	"property":            true,
+	"scope":               true,
+	"starting-style":      true,
+	"supports":            true,
+	"viewport":            true,
+	"when":                true,
+}
+
+func (opts ParseOptions) warn(message string, tok TokenEntry) {
+	w := Warning{Message: message, Token: tok.value, Pos: tok.pos}
+	if opts.Logger != nil {
+		opts.Logger.LogAttrs(context.Background(), slog.LevelWarn, w.Message,
+			slog.String("token", w.Token),
+			slog.Int("line", w.Pos.Line),
+			slog.Int("column", w.Pos.Column),
+		)
+	}
+	if opts.OnWarning != nil {
+		opts.OnWarning(w)
+	}
+}
+
+// checkAtRule warns about at-rules this package doesn't know. Vendor
+// prefixed at-rules are checked without their prefix.
+func (opts ParseOptions) checkAtRule(prelude string, tok TokenEntry) {
+	if !strings.HasPrefix(prelude, "@") {
+		return
+	}
+	// the name ends at the first byte that can't be in a name, as in
+	// @media(min-width: 600px)
+	name := strings.ToLower(prelude[1:scanName(prelude, 1)])
+	if name == "" {
+		opts.warn("unnamed at-rule", TokenEntry{value: "@", pos: tok.pos})
+		return
+	}
+	if strings.HasPrefix(name, "-") {
+		if i := strings.Index(name[1:], "-"); i >= 0 {
+			name = name[i+2:]
+		}
+	}
+	if !knownAtRules[name] {
+		opts.warn("unknown at-rule", TokenEntry{value: "@" + name, pos: tok.pos})
+	}
+}
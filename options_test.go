@@ -0,0 +1,216 @@
+package css
+
+import (
+	"bytes"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+	"text/scanner"
+)
+
+func TestParseWarnings(t *testing.T) {
+	ex1 := `@import url(a.css);
+@unknown foo;
+}
+rule {
+	style1: value1;
+	style2:;
+	: value3;
+}
+@-webkit-keyframes spin {
+	from {
+		style1: value1;
+	}
+}
+rule2 {
+	style1: value1;`
+
+	warnings := []Warning{}
+	var logs bytes.Buffer
+	css, err := UnmarshalWithOptions([]byte(ex1), ParseOptions{
+		Logger:    slog.New(slog.NewTextHandler(&logs, nil)),
+		OnWarning: func(w Warning) { warnings = append(warnings, w) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(css["rule"]) != 1 {
+		t.Fatalf("expected only 'style1' in 'rule', got %v", css["rule"])
+	}
+
+	expected := []string{
+		"2:1: unknown at-rule \"@unknown\"",
+		"3:1: skipped unexpected block end \"}\"",
+		"6:2: skipped declaration without value \"style2\"",
+		"7:2: skipped declaration without property \":\"",
+		"14:1: skipped unclosed block \"rule2\"",
+	}
+	if len(warnings) != len(expected) {
+		t.Fatalf("expected %d warnings, got %d: %v", len(expected), len(warnings), warnings)
+	}
+	for i := range expected {
+		if warnings[i].String() != expected[i] {
+			t.Fatalf("expected warning %q, got %q", expected[i], warnings[i])
+		}
+	}
+	if n := strings.Count(logs.String(), "level=WARN"); n != len(expected) {
+		t.Fatalf("expected %d logged warnings, got %d", len(expected), n)
+	}
+}
+
+func TestUnnamedAtRule(t *testing.T) {
+	for _, src := range []string{"@;", "@ {}", "@\n{ a { color: red; } }"} {
+		warnings := []Warning{}
+		opts := ParseOptions{OnWarning: func(w Warning) { warnings = append(warnings, w) }}
+		if _, err := UnmarshalWithOptions([]byte(src), opts); err != nil {
+			t.Fatalf("%q: %v", src, err)
+		}
+		if len(warnings) == 0 || warnings[0].Message != "unnamed at-rule" {
+			t.Fatalf("%q: expected an unnamed at-rule warning, got %v", src, warnings)
+		}
+		ParseTolerant([]byte(src))
+		UnmarshalWithOptions([]byte(src), ParseOptions{Strict: true})
+		ParseStylesheet(strings.NewReader(src))
+		Minify([]byte(src), MinifyAggressive)
+		Format([]byte(src), FormatOptions{})
+		Bundle(SourceFile{Name: "a.css", Data: []byte(src)})
+		d := NewDecoder(strings.NewReader(src))
+		for _, _, err := d.Next(); err == nil; _, _, err = d.Next() {
+		}
+	}
+}
+
+func TestAtRuleNames(t *testing.T) {
+	tests := []struct{ src, warning string }{
+		{"@media(min-width: 600px) { a { color: red; } }", ""},
+		{"@supports(display: grid) { a { color: red; } }", ""},
+		{"@-webkit-keyframes x { from { top: 0; } }", ""},
+		{"@foo(x) { a { color: red; } }", "@foo"},
+	}
+	for _, test := range tests {
+		warnings := []Warning{}
+		opts := ParseOptions{OnWarning: func(w Warning) { warnings = append(warnings, w) }}
+		if _, err := UnmarshalWithOptions([]byte(test.src), opts); err != nil {
+			t.Fatalf("%q: %v", test.src, err)
+		}
+		switch {
+		case test.warning == "" && len(warnings) != 0:
+			t.Errorf("%q: unexpected warnings %v", test.src, warnings)
+		case test.warning != "" && (len(warnings) != 1 || warnings[0].Message != "unknown at-rule" || warnings[0].Token != test.warning):
+			t.Errorf("%q: expected an unknown at-rule warning for %s, got %v", test.src, test.warning, warnings)
+		}
+	}
+}
+
+type testMetrics []ParseStats
+
+func (m *testMetrics) ObserveParse(stats ParseStats) {
+	*m = append(*m, stats)
+}
+
+func TestParseMetrics(t *testing.T) {
+	ex1 := []byte(`rule {
+	style1: value1;
+	style2:;
+}`)
+
+	metrics := &testMetrics{}
+	if _, err := UnmarshalWithOptions(ex1, ParseOptions{Metrics: metrics}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := MinifyWithOptions(ex1, MinifySafe, ParseOptions{Metrics: metrics}); err != nil {
+		t.Fatal(err)
+	}
+	if len(*metrics) != 2 {
+		t.Fatalf("expected 2 observed parses, got %d", len(*metrics))
+	}
+	stats := (*metrics)[0]
+	if stats.Bytes != len(ex1) || stats.Tokens != 10 || stats.Warnings != 1 || stats.Errors != 0 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+	if stats.Duration <= 0 {
+		t.Fatal("parse duration should be measured")
+	}
+}
+
+func TestStrictParse(t *testing.T) {
+	cases := []struct {
+		src  string
+		want ParseError
+	}{
+		{"a {\n\tcolor: red;\n\tmargin:;\n}", ParseError{Pos: scanner.Position{Line: 3, Column: 2}, Token: "margin", Expected: "value"}},
+		{"color: red;\na { color: red; }", ParseError{Pos: scanner.Position{Line: 1, Column: 1}, Token: "color", Expected: "{"}},
+		{"a { color: red; }\n}", ParseError{Pos: scanner.Position{Line: 2, Column: 1}, Token: "}", Expected: "selector or at-rule"}},
+		{"a { color: red;", ParseError{Pos: scanner.Position{Line: 1, Column: 16}, Expected: "}"}},
+	}
+	for _, c := range cases {
+		_, err := UnmarshalWithOptions([]byte(c.src), ParseOptions{Strict: true})
+		perr, ok := err.(*ParseError)
+		if !ok {
+			t.Fatalf("%q: expected a *ParseError, got %v", c.src, err)
+		}
+		if perr.Pos.Line != c.want.Pos.Line || perr.Pos.Column != c.want.Pos.Column ||
+			perr.Token != c.want.Token || perr.Expected != c.want.Expected {
+			t.Fatalf("%q: expected %v, got %v", c.src, &c.want, perr)
+		}
+		if _, err := UnmarshalWithOptions([]byte(c.src), ParseOptions{}); err != nil {
+			t.Fatalf("%q: lenient parse failed: %v", c.src, err)
+		}
+	}
+
+	if _, err := UnmarshalWithOptions([]byte("@custom-rule x { a { color: red; } }"), ParseOptions{Strict: true}); err != nil {
+		t.Fatalf("unknown at-rules should not fail a strict parse: %v", err)
+	}
+	err := &ParseError{Pos: scanner.Position{Line: 3, Column: 2}, Token: "margin", Expected: "value"}
+	if got := err.Error(); got != `3:2: unexpected "margin", expected value` {
+		t.Fatalf("unexpected message %q", got)
+	}
+}
+
+func TestStrictDecoder(t *testing.T) {
+	d := NewDecoderWithOptions(strings.NewReader("a { color: red; }\nb { margin:; }\nc { color: blue; }"), ParseOptions{Strict: true})
+	if rule, _, err := d.Next(); err != nil || rule != "a" {
+		t.Fatalf("expected rule a, got %q %v", rule, err)
+	}
+	if _, _, err := d.Next(); err == nil {
+		t.Fatal("expected a parse error")
+	} else if _, ok := err.(*ParseError); !ok {
+		t.Fatalf("expected a *ParseError, got %v", err)
+	}
+}
+
+func TestSplitSelectorGroups(t *testing.T) {
+	src := []byte("h1, h2,\nh3 {\n\tcolor: red;\n}\nh2 {\n\tmargin: 0;\n}\n@media print {\n\th1, p {\n\t\tcolor: black;\n\t}\n}\n")
+
+	css, err := UnmarshalWithOptions(src, ParseOptions{SplitSelectorGroups: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[Rule]map[string]string{
+		"h1": {"color": "red"},
+		"h2": {"color": "red", "margin": "0"},
+		"h3": {"color": "red"},
+	}
+	if !reflect.DeepEqual(css, expected) {
+		t.Errorf("expected %v, got %v", expected, css)
+	}
+
+	sheet, _ := UnmarshalStylesheetWithOptions(src, ParseOptions{SplitSelectorGroups: true})
+	if len(sheet.Rules) != 6 || sheet.Rules[4].Selector != "h1" || sheet.Rules[5].AtRules[0] != "@media print" {
+		t.Fatalf("unexpected rules %v", sheet.Rules)
+	}
+	sheet.Rules[0].Declarations[0].Value = "blue"
+	if sheet.Rules[1].Declarations[0].Value != "red" {
+		t.Error("split rules share their declarations")
+	}
+
+	if css, _ := Unmarshal(src); len(css) != 2 {
+		t.Errorf("expected the groups to be kept by default, got %v", css)
+	}
+
+	sheet, _ = UnmarshalStylesheetWithOptions([]byte(`a[title="x  y"], b { color: red; }`), ParseOptions{SplitSelectorGroups: true})
+	if len(sheet.Rules) != 2 || string(sheet.Rules[0].Selector) != `a[title="x  y"]` {
+		t.Fatalf("unexpected rules %v", sheet.Rules)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"regexp"
 	"strings"
 	"text/scanner"
+	"time"
 )
 
 type tokenType int
@@ -25,7 +26,6 @@ const (
 
 var (
 	rComments = regexp.MustCompile(`\/\*[^*]*\*+([^\/*][^*]*\*+)*\/`)
-	rLicenses = regexp.MustCompile(`\/\*![^*]*\*+([^\/*][^*]*\*+)*\/`)
 )
 
 // Rule is a string type that represents a CSS rule.
@@ -34,6 +34,8 @@ type Rule string
 type TokenEntry struct {
 	value string
 	pos   scanner.Position
+	// space is the whitespace between the previous token and this one.
+	space string
 }
 
 type tokenizer struct {
@@ -56,33 +58,84 @@ func (e TokenEntry) typ() tokenType {
 }
 
 func (t *tokenizer) next() (TokenEntry, error) {
+	space := ""
 	token := t.s.Scan()
+	for isSpace(token) {
+		space += string(token)
+		token = t.s.Scan()
+	}
 	if token == scanner.EOF {
 		return TokenEntry{}, errors.New("EOF")
 	}
 	value := t.s.TokenText()
-	pos := t.s.Pos()
+	pos := t.s.Position
 	if newTokenType(value).String() == "STYLE_SEPARATOR" {
-		t.s.IsIdentRune = func(ch rune, i int) bool { // property value can contain spaces
-			if ch == -1 || ch == '\n' || ch == '\t' || ch == ':' || ch == ';' {
-				return false
-			}
-			return true
-		}
+		t.s.IsIdentRune = identRune("\n\t:;{}") // property value can contain spaces
 	} else {
-		t.s.IsIdentRune = func(ch rune, i int) bool { // other tokens can't contain spaces
-			if ch == -1 || ch == '.' || ch == '#' || ch == '\n' || ch == ' ' || ch == '\t' || ch == ':' || ch == ';' {
-				return false
-			}
-			return true
-		}
+		t.s.IsIdentRune = identRune(".#\n \t:;{}") // other tokens can't contain spaces
 	}
 	return TokenEntry{
 		value: value,
 		pos:   pos,
+		space: space,
 	}, nil
 }
 
+// isSpace reports whether the token is a whitespace character, which the
+// tokenizer reads as tokens to keep the spacing of values.
+func isSpace(token rune) bool {
+	switch token {
+	case ' ', '\t', '\n', '\r', '\f':
+		return true
+	}
+	return false
+}
+
+// identRune returns an IsIdentRune function for the scanner that ends the
+// token on any of the stop characters. Anything goes inside strings and
+// brackets, so function arguments, attribute selectors and data URIs stay
+// intact.
+func identRune(stop string) func(ch rune, i int) bool {
+	var (
+		depth   = 0
+		quote   = rune(0)
+		escaped = false
+	)
+	return func(ch rune, i int) bool {
+		if i == 0 {
+			depth, quote, escaped = 0, 0, false
+		}
+		if ch == scanner.EOF || (i == 0 && isSpace(ch)) {
+			return false
+		}
+		if quote != 0 {
+			switch {
+			case escaped:
+				escaped = false
+			case ch == '\\':
+				escaped = true
+			case ch == quote:
+				quote = 0
+			}
+			return true
+		}
+		if depth == 0 && strings.ContainsRune(stop, ch) {
+			return false
+		}
+		switch ch {
+		case '"', '\'':
+			quote = ch
+		case '(', '[':
+			depth++
+		case ')', ']':
+			if depth > 0 {
+				depth--
+			}
+		}
+		return true
+	}
+}
+
 func (t tokenType) String() string {
 	switch t {
 	case tokenBlockStart:
@@ -118,6 +171,8 @@ func newTokenType(typ string) tokenType {
 func newTokenizer(r io.Reader) *tokenizer {
 	s := &scanner.Scanner{}
 	s.Init(r)
+	s.Whitespace = 0
+	s.IsIdentRune = identRune(".#\n \t:;{}")
 	return &tokenizer{
 		s: s,
 	}
@@ -136,112 +191,539 @@ func buildList(r io.Reader) *list.List {
 	return l
 }
 
-func Parse(l *list.List) (map[Rule]map[string]string, error) {
-	var (
-		styles    = map[string]string{}
-		selectors = []string{}
-		blocks    = []map[string]string{}
-
-		prev    = TokenEntry{}
-		e       = l.Front()
-		bufferV = ""
-		bufferK = ""
-		inblock = false
-	)
+// block is a single selector block with its styles in document order.
+type block struct {
+	selector string
+	styles   []Declaration
+	pos      scanner.Position
+	source   SourceRange
+	// atRules are the preludes of the at-rules the block is nested in,
+	// outermost first.
+	atRules []string
+}
 
-	for e != nil {
+// openBlock is a block whose end wasn't reached yet.
+type openBlock struct {
+	prelude string
+	pos     scanner.Position
+	start   int
+	// nested is set when the block contains other blocks.
+	nested bool
+	// dropped is set when the block is discarded by a tolerant parse.
+	dropped bool
+}
+
+// parseBlocks groups the token list into blocks, keeping the order in
+// which selectors and styles appear in the source. Skipped tokens are
+// reported to opts.
+func parseBlocks(l *list.List, opts ParseOptions) ([]block, error) {
+	p := newBlockParser(opts)
+	for e := l.Front(); e != nil && p.err == nil; e = e.Next() {
+		p.feed(e.Value.(TokenEntry))
+	}
+	p.finish()
+	if p.err != nil {
+		return nil, p.err
+	}
+	return p.blocks, nil
+}
+
+// parseDeclarationList parses the tokens as the content of a block, such
+// as a style attribute. Braces are syntax errors, so the list can't close
+// the block and add rules.
+func parseDeclarationList(l *list.List, opts ParseOptions) ([]Declaration, error) {
+	p := newBlockParser(opts)
+	p.inblock = true
+	for e := l.Front(); e != nil && p.err == nil; e = e.Next() {
 		tok := e.Value.(TokenEntry)
-		//fmt.Printf("{\n token: %v %s\n bK: %s\n bV: %s\n}", tok.typ(), tok.value, bufferK, bufferV)
+		if typ := tok.typ(); typ == tokenBlockStart || typ == tokenBlockEnd {
+			p.fail("skipped brace in declaration list", "declaration", tok)
+			continue
+		}
+		p.feed(tok)
+	}
+	if p.err == nil && p.prev.value != "" && p.prev.typ() != tokenStatementEnd {
+		// the last declaration doesn't need a semicolon
+		p.appendStyle()
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	return p.styles, nil
+}
 
-		switch tok.typ() {
-		case tokenSelector:
-			bufferV += tok.value
-		case tokenStyleSeparator:
-			if inblock {
-				bufferV = ""
-				bufferK += prev.value
-				break
+// blockParser groups tokens into blocks as they are fed to it, so blocks
+// can be consumed before the end of the source is reached.
+type blockParser struct {
+	opts ParseOptions
+	// blocks are the blocks completed so far.
+	blocks []block
+
+	styles  []Declaration
+	prev    TokenEntry
+	start   TokenEntry
+	opened  TokenEntry
+	open    []openBlock
+	keyPos  scanner.Position
+	bufferV string
+	bufferK string
+	// bufferS is the text of the statement including the colons, which
+	// is the prelude when a block starts: colons inside blocks may also
+	// be the ones of pseudo-classes in nested selectors such as a:hover.
+	bufferS string
+	inblock bool
+	depth   int
+	intern  interner
+	// err is the first syntax error of a strict parse.
+	err *ParseError
+	// tolerant recovers from syntax errors the way browsers do, recording
+	// them in diagnostics.
+	tolerant    bool
+	diagnostics []*ParseError
+	// drop is set by a tolerant parse when invalid tokens start the
+	// prelude of the next block, which is then discarded.
+	drop bool
+}
+
+func newBlockParser(opts ParseOptions) *blockParser {
+	return &blockParser{
+		opts:   opts,
+		styles: []Declaration{},
+		blocks: []block{},
+		intern: newInterner(opts.Intern),
+	}
+}
+
+// fail reports the syntax error at tok, which fails a strict parse.
+func (p *blockParser) fail(message, expected string, tok TokenEntry) {
+	p.opts.warn(message, tok)
+	if p.tolerant {
+		p.diagnostics = append(p.diagnostics, &ParseError{Pos: tok.pos, Token: tok.value, Expected: expected})
+	}
+	if p.opts.Strict && p.err == nil {
+		p.err = &ParseError{Pos: tok.pos, Token: tok.value, Expected: expected}
+	}
+}
+
+// appendStyle adds the buffered declaration to the block styles
+func (p *blockParser) appendStyle() {
+	switch {
+	case p.bufferK == "":
+		p.fail("skipped declaration without property", "property", p.start)
+	case strings.TrimSpace(p.bufferV) == "":
+		p.fail("skipped declaration without value", "value", p.start)
+	default:
+		p.styles = append(p.styles, p.intern.declaration(newBlockDeclaration(p.bufferK, p.bufferV, p.keyPos)))
+	}
+}
+
+// appendPrelude adds the token to the statement text, separated by a
+// single space where the source has whitespace.
+func (p *blockParser) appendPrelude(tok TokenEntry) {
+	if p.bufferS != "" && tok.space != "" {
+		p.bufferS += " "
+	}
+	p.bufferS += tok.value
+}
+
+// inValue reports whether the parser is in the value of a declaration.
+func (p *blockParser) inValue() bool {
+	return p.inblock && p.bufferK != ""
+}
+
+// feed parses the next token.
+func (p *blockParser) feed(tok TokenEntry) {
+	prev := p.prev
+	if prev.value == "" || prev.typ() == tokenStatementEnd ||
+		prev.typ() == tokenBlockStart || prev.typ() == tokenBlockEnd {
+		p.start = tok
+	}
+
+	switch tok.typ() {
+	case tokenSelector:
+		if p.inValue() && p.bufferV != "" {
+			p.bufferV += tok.space
+		}
+		p.bufferV += tok.value
+		p.appendPrelude(tok)
+	case tokenStyleSeparator:
+		p.appendPrelude(tok)
+		if p.inblock {
+			if p.bufferV != "" {
+				p.bufferK += prev.value
+				p.keyPos = prev.pos
 			}
-			bufferV += tok.value
-		case tokenValue:
-			// this is a work around for supporting media queries
-			tok.value = strings.Replace(tok.value, "{", "", -1)
-			if prev.typ() == tokenValue {
-				bufferV += " "
+			p.bufferV = ""
+			break
+		}
+		p.bufferV += tok.value
+	case tokenValue:
+		// this is a work around for supporting media queries
+		tok.value = strings.Replace(tok.value, "{", "", -1)
+		switch {
+		case p.inValue():
+			// keep the spacing of values such as grid-template
+			if p.bufferV != "" {
+				p.bufferV += tok.space
 			}
-			bufferV += tok.value
-		case tokenStatementEnd:
-			styles[bufferK] = bufferV
-			bufferK = ""
-			bufferV = ""
-		case tokenBlockStart:
-			inblock = true
-			selectors = append(selectors, bufferV)
-			bufferK = ""
-			bufferV = ""
-		case tokenBlockEnd:
-			inblock = false
-			if prev.typ() != tokenStatementEnd && prev.typ() != tokenBlockStart {
-				styles[bufferK] = bufferV
+		case prev.typ() == tokenValue && p.bufferV != "":
+			p.bufferV += " "
+		}
+		p.bufferV += tok.value
+		p.appendPrelude(tok)
+	case tokenStatementEnd:
+		if p.inblock {
+			p.appendStyle()
+		} else if strings.HasPrefix(p.bufferV, "@") {
+			p.opts.checkAtRule(p.bufferV, p.start)
+		} else {
+			p.fail("skipped declaration outside of a block", "{", p.start)
+			// browsers read the statement as the start of the prelude of
+			// the next rule, which is invalid
+			p.drop = p.tolerant
+		}
+		p.bufferK = ""
+		p.bufferV = ""
+		p.bufferS = ""
+	case tokenBlockStart:
+		prelude := strings.TrimSpace(p.bufferS)
+		p.opts.checkAtRule(prelude, p.start)
+		p.opened = p.start
+		dropped := p.drop
+		p.drop = false
+		if len(p.open) > 0 {
+			p.open[len(p.open)-1].nested = true
+			dropped = dropped || p.open[len(p.open)-1].dropped
+		}
+		p.open = append(p.open, openBlock{prelude: prelude, pos: p.start.pos, start: p.start.pos.Offset, dropped: dropped})
+		p.inblock = true
+		p.depth++
+		p.bufferK = ""
+		p.bufferV = ""
+		p.bufferS = ""
+	case tokenBlockEnd:
+		if p.depth == 0 {
+			p.fail("skipped unexpected block end", "selector or at-rule", tok)
+			p.drop = p.tolerant
+			break
+		}
+		p.depth--
+		p.inblock = false
+		if prev.typ() != tokenStatementEnd && prev.typ() != tokenBlockStart &&
+			prev.typ() != tokenBlockEnd {
+			p.appendStyle()
+		}
+		p.bufferK = ""
+		p.bufferV = ""
+		p.bufferS = ""
+		current := p.open[len(p.open)-1]
+		p.open = p.open[:len(p.open)-1]
+		if current.dropped || current.nested && strings.HasPrefix(current.prelude, "@") {
+			// grouping at-rules such as @media only hold other blocks
+			p.styles = []Declaration{}
+			break
+		}
+		b := block{
+			selector: current.prelude,
+			styles:   p.opts.Arena.declarations(p.styles),
+			pos:      current.pos,
+			source:   SourceRange{current.start, tok.pos.Offset + len(tok.value)},
+		}
+		for _, o := range p.open {
+			if strings.HasPrefix(o.prelude, "@") {
+				b.atRules = append(b.atRules, o.prelude)
 			}
-			bufferK = ""
-			bufferV = ""
-			blocks = append(blocks, styles)
-			styles = map[string]string{}
 		}
-		prev = tok
-		e = e.Next()
+		p.blocks = append(p.blocks, b)
+		if p.opts.Arena != nil {
+			// the declarations were copied to the arena
+			p.styles = p.styles[:0]
+		} else {
+			p.styles = []Declaration{}
+		}
 	}
+	p.prev = tok
+}
 
-	// compile blocks and merge duplicates
-	css := make(map[Rule]map[string]string)
-	for i := range selectors {
-		styles = blocks[i]
-		oldRule, ok := css[Rule(selectors[i])]
-		if ok {
-			// merge rules
-			for style, value := range oldRule {
-				if _, ok := styles[style]; !ok {
-					styles[style] = value
-				}
+// finish reports the blocks left open at the end of the source.
+func (p *blockParser) finish() {
+	if p.depth == 0 || p.err != nil {
+		return
+	}
+	end := p.prev.pos
+	end.Offset += len(p.prev.value)
+	end.Column += len(p.prev.value)
+	if p.tolerant {
+		// browsers close the blocks left open at the end of the source
+		p.diagnostics = append(p.diagnostics, &ParseError{Pos: end, Expected: "}"})
+		for p.depth > 0 {
+			n := len(p.blocks)
+			p.feed(TokenEntry{value: "}", pos: end})
+			if len(p.blocks) > n {
+				p.blocks[n].source.End = end.Offset
 			}
 		}
-		//fmt.Println(rule[i], "merging:", ok, styles)
-		css[Rule(selectors[i])] = styles
+		return
+	}
+	p.opts.warn("skipped unclosed block", p.opened)
+	if p.opts.Strict {
+		p.err = &ParseError{Pos: end, Expected: "}"}
 	}
+}
+
+func newBlockDeclaration(property, raw string, pos scanner.Position) Declaration {
+	d := NewDeclaration(property, raw)
+	d.Pos = pos
+	return d
+}
 
+func Parse(l *list.List) (map[Rule]map[string]string, error) {
+	return ParseWithOptions(l, ParseOptions{})
+}
+
+// ParseWithOptions is like Parse, reporting warnings and metrics as
+// configured in opts.
+func ParseWithOptions(l *list.List, opts ParseOptions) (map[Rule]map[string]string, error) {
+	blocks, err := opts.parse(l, 0, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return rulesMap(blocks), nil
+}
+
+// rulesMap compiles the blocks into a rules map, merging duplicates.
+func rulesMap(blocks []block) map[Rule]map[string]string {
+	return newStylesheet(blocks).ToLegacyMap()
+}
+
+// ParseDeclarations returns the declarations of every rule in document
+// order. Declarations of rules with the same selector are appended in the
+// order they appear, so the last declaration of a property wins.
+func ParseDeclarations(l *list.List) (map[Rule][]Declaration, error) {
+	return ParseDeclarationsWithOptions(l, ParseOptions{})
+}
+
+// ParseDeclarationsWithOptions is like ParseDeclarations, reporting
+// warnings and metrics as configured in opts. Like ParseWithOptions, it
+// returns the *ParseError of a strict parse.
+func ParseDeclarationsWithOptions(l *list.List, opts ParseOptions) (map[Rule][]Declaration, error) {
+	blocks, err := opts.parse(l, 0, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	css := make(map[Rule][]Declaration)
+	for _, b := range blocks {
+		css[Rule(b.selector)] = append(css[Rule(b.selector)], b.styles...)
+	}
 	return css, nil
 }
 
+// UnmarshalDeclarations is like Unmarshal but keeps every declaration of
+// a rule, with its !important flag and position.
+func UnmarshalDeclarations(b []byte) (map[Rule][]Declaration, error) {
+	return ParseDeclarations(Tokenize(b))
+}
+
 // Unmarshal will take a byte slice, containing sylesheet rules and return
-// a map of a rules map.
+// a map of a rules map. It is a convenience wrapper losing the order of
+// the rules and declarations; use ParseStylesheet for the full tree.
 func Unmarshal(b []byte) (map[Rule]map[string]string, error) {
 	return Parse(Tokenize(b))
 }
 
+// UnmarshalWithOptions is like Unmarshal, reporting warnings and metrics as
+// configured in opts.
+func UnmarshalWithOptions(b []byte, opts ParseOptions) (map[Rule]map[string]string, error) {
+	b, err := opts.resolve(b)
+	if err != nil {
+		return nil, err
+	}
+	blocks, err := opts.unmarshal(b)
+	if err != nil {
+		return nil, err
+	}
+	return rulesMap(blocks), nil
+}
+
+// OrderedRule is a rule with its declarations, as returned by
+// OrderedRules.
+type OrderedRule struct {
+	Rule
+	Decls Declarations
+}
+
+// OrderedRules returns the rules of the css in b in document order. Unlike
+// Unmarshal, rules with the same selector are not merged, so iterating the
+// result is deterministic.
+func OrderedRules(b []byte) []OrderedRule {
+	rules := []OrderedRule{}
+	blocks, _ := ParseOptions{}.unmarshal(b)
+	for _, bl := range blocks {
+		rules = append(rules, OrderedRule{Rule(bl.selector), bl.styles})
+	}
+	return rules
+}
+
 // CSSStyle returns an error-checked parsed style, or an error if the
-// style is unknown. Most of the styles are not supported yet.
+// style is unknown. The css-wide keywords such as inherit are valid for
+// every style, with the keyword as value.
 func CSSStyle(name string, styles map[string]string) (Style, error) {
 	value := styles[name]
 	styleFn, ok := StylesTable[name]
 	if !ok {
 		return Style{}, errors.New("unknown style")
 	}
+	if isWideKeyword(value) {
+		return Style{Value: strings.ToLower(strings.TrimSpace(value))}, nil
+	}
 	return styleFn(value)
 }
 
 // Tokenize builds a token list from css bytes
 func Tokenize(b []byte) *list.List {
-	return buildList(bytes.NewReader(b))
+	return buildList(bytes.NewReader(blankComments(b)))
 }
 
-// Selectors will return all the selectors, including duplicants.
+// blankComments replaces the comments in b with spaces, keeping line
+// breaks so the token positions still match the source.
+func blankComments(b []byte) []byte {
+	out := append([]byte{}, b...)
+	for _, loc := range commentIndexes(b) {
+		for i := loc[0]; i < loc[1]; i++ {
+			if b[i] != '\n' {
+				out[i] = ' '
+			}
+		}
+	}
+	return out
+}
+
+// commentIndexes returns the start and end of every comment in b, like
+// FindAllIndex. Strings and unquoted urls are skipped, so "/*" in
+// content: "/* x */" or in url(a/*.png) doesn't start a comment.
+func commentIndexes(b []byte) [][]int {
+	indexes := [][]int{}
+	for i := 0; i < len(b); {
+		switch c := b[i]; {
+		case c == '"' || c == '\'':
+			// a string ends at its quote or at a line break
+			for i++; i < len(b) && b[i] != c && b[i] != '\n'; i++ {
+				if b[i] == '\\' {
+					i++
+				}
+			}
+			i++
+		case c == '\\':
+			i += 2
+		case c == '/' && i+1 < len(b) && b[i+1] == '*':
+			end := bytes.Index(b[i+2:], []byte("*/"))
+			if end < 0 {
+				return indexes
+			}
+			indexes = append(indexes, []int{i, end + i + 4})
+			i = end + i + 4
+		case (c == 'u' || c == 'U') && i+4 <= len(b) && bytes.EqualFold(b[i:i+4], []byte("url(")) &&
+			(i == 0 || !isNameByte(b[i-1])):
+			i += 4
+			for i < len(b) && (b[i] == ' ' || b[i] == '\t' || b[i] == '\n' || b[i] == '\r' || b[i] == '\f') {
+				i++
+			}
+			if i < len(b) && b[i] != '"' && b[i] != '\'' {
+				for i < len(b) && b[i] != ')' {
+					if b[i] == '\\' {
+						i++
+					}
+					i++
+				}
+			}
+		default:
+			i++
+		}
+	}
+	return indexes
+}
+
+// Selectors will return all the selectors, including duplicants, and the
+// preludes of the at-rules with a block. The selectors are written as in
+// the source, combinators (">", "+", "~") included, except that they are
+// trimmed and every run of whitespace becomes a single space: "ul  >\n\tli"
+// is returned as "ul > li" while "ul>li" is unchanged. Whitespace inside
+// parentheses, brackets and strings is kept, and comments count as
+// whitespace. Use Rule.String for the CSSOM serialization.
 func Selectors(tokens *list.List) []Rule {
+	rules := []Rule{}
+	for _, p := range preludes(tokens) {
+		rules = append(rules, Rule(collapseSpaces(p.text)))
+	}
+	return rules
+}
+
+// collapseSpaces replaces every run of whitespace in s with a single
+// space and trims s, except inside parentheses, brackets and strings and
+// for escaped whitespace.
+func collapseSpaces(s string) string {
+	var (
+		b     strings.Builder
+		depth = 0
+	)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s):
+			b.WriteString(s[i : i+2])
+			i++
+			continue
+		case c == '"' || c == '\'':
+			end := scanString(s, i)
+			b.WriteString(s[i:end])
+			i = end - 1
+			continue
+		case c == '(' || c == '[':
+			depth++
+		case (c == ')' || c == ']') && depth > 0:
+			depth--
+		case depth == 0 && isSpace(rune(c)):
+			for i+1 < len(s) && isSpace(rune(s[i+1])) {
+				i++
+			}
+			if b.Len() > 0 && i+1 < len(s) {
+				b.WriteByte(' ')
+			}
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// prelude is the text in front of a block, rebuilt from its tokens.
+type prelude struct {
+	text string
+	// starts holds the index in text of every token, and positions the
+	// source position of the same token.
+	starts    []int
+	positions []scanner.Position
+}
+
+// position returns the source position of the byte at index i of the
+// prelude text.
+func (p prelude) position(i int) scanner.Position {
+	j := len(p.starts) - 1
+	for j > 0 && p.starts[j] > i {
+		j--
+	}
+	pos := p.positions[j]
+	pos.Offset += i - p.starts[j]
+	pos.Column += i - p.starts[j]
+	return pos
+}
+
+// preludes returns the prelude of every block in the tokens.
+func preludes(tokens *list.List) []prelude {
 	var (
-		selector  = ""
-		rule      = []string{}
-		rules     = []Rule{}
-		prevToken = tokenType(tokenFirstToken)
+		current = prelude{}
+		all     = []prelude{}
+		end     = 0
 	)
 
 	e := tokens.Front()
@@ -249,36 +731,46 @@ func Selectors(tokens *list.List) []Rule {
 		tok := e.Value.(TokenEntry)
 
 		switch tok.typ() {
-		case tokenValue:
-			switch prevToken {
-			case tokenBlockEnd, tokenFirstToken, tokenValue:
-				rule = append(rule, tok.value)
-			case tokenSelector:
-				rule = append(rule, selector+tok.value)
-			}
-		case tokenSelector:
-			selector = "."
 		case tokenBlockStart:
-			rules = append(rules, Rule(strings.Join(rule, " ")))
-			rule = []string{}
+			all = append(all, current)
+			current = prelude{}
+		case tokenBlockEnd, tokenStatementEnd:
+			current = prelude{}
+		default:
+			if current.text != "" && tok.pos.Offset > end {
+				current.text += " "
+			}
+			current.starts = append(current.starts, len(current.text))
+			current.positions = append(current.positions, tok.pos)
+			current.text += tok.value
 		}
 
-		prevToken = tok.typ()
+		end = tok.pos.Offset + len(tok.value)
 		e = e.Next()
 	}
 
-	return rules
+	return all
 }
 
 // Comments returns all css comments
 func Comments(b []byte) []string {
-	return rComments.FindAllString(string(b), -1)
+	comments := []string{}
+	for _, loc := range commentIndexes(b) {
+		comments = append(comments, string(b[loc[0]:loc[1]]))
+	}
+	return comments
 }
 
 // Licenses returns all css License. Will return empty string if no
 // license exists .
 func Licenses(b []byte) []string {
-	return rLicenses.FindAllString(string(b), -1)
+	licenses := []string{}
+	for _, comment := range Comments(b) {
+		if strings.HasPrefix(comment, "/*!") {
+			licenses = append(licenses, comment)
+		}
+	}
+	return licenses
 }
 
 // BlockCount returns the number code blocks in the css
@@ -297,30 +789,84 @@ func BlockCount(tokens *list.List) int {
 	return count
 }
 
-// Identifiers returns all class, id and element identifiers including
-// duplicants
-func Identifiers(tokens *list.List) []string {
+// StructureReport describes the shape of a stylesheet.
+type StructureReport struct {
+	// Rules is the number of top-level rules.
+	Rules int
+	// AtRules counts the at-rules by name, including the "@".
+	AtRules map[string]int
+	// MaxDepth is the deepest block nesting, 1 for a flat stylesheet.
+	MaxDepth int
+	// Declarations maps a declaration count to the number of rules
+	// having that many declarations.
+	Declarations map[int]int
+}
+
+// Structure returns a StructureReport of the tokens, computed in a
+// single pass.
+func Structure(tokens *list.List) StructureReport {
 	var (
-		names = []string{}
-		prev  = TokenEntry{}
+		report = StructureReport{
+			AtRules:      map[string]int{},
+			Declarations: map[int]int{},
+		}
+		name    = ""
+		started = false
+		pending = false
+		atRule  = []bool{}
+		decls   = []int{}
+		end     = 0
 	)
 
 	e := tokens.Front()
 	for e != nil {
 		tok := e.Value.(TokenEntry)
+		depth := len(decls)
 
-		if prev.typ() == tokenSelector {
-			names = append(names, prev.value+tok.value)
-		} else if prev.typ() == tokenBlockEnd &&
-			tok.typ() != tokenSelector {
-			names = append(names, tok.value)
+		switch tok.typ() {
+		case tokenBlockStart:
+			if name != "" {
+				report.AtRules[name]++
+			} else if depth == 0 {
+				report.Rules++
+			}
+			atRule = append(atRule, name != "")
+			decls = append(decls, 0)
+			if len(decls) > report.MaxDepth {
+				report.MaxDepth = len(decls)
+			}
+			name, started, pending = "", false, false
+		case tokenStatementEnd, tokenBlockEnd:
+			if name != "" {
+				report.AtRules[name]++
+			} else if pending && depth > 0 {
+				decls[depth-1]++
+			}
+			if tok.typ() == tokenBlockEnd && depth > 0 {
+				if !atRule[depth-1] {
+					report.Declarations[decls[depth-1]]++
+				}
+				atRule = atRule[:depth-1]
+				decls = decls[:depth-1]
+			}
+			name, started, pending = "", false, false
+		case tokenStyleSeparator:
+			pending = true
+		default:
+			if !started && strings.HasPrefix(tok.value, "@") {
+				name = tok.value
+			} else if name == "@" && tok.pos.Offset == end {
+				// the scanner splits a leading "@" from the name
+				name += tok.value
+			}
+			started = true
 		}
 
-		prev = tok
+		end = tok.pos.Offset + len(tok.value)
 		e = e.Next()
 	}
 
-	return names
+	return report
 }
 
 // Styles will return all the styles in a css as an arrays
@@ -140,6 +140,52 @@ rule1 {
 			t.Fatalf("value of 'style1' should be 'value3' but got '%v'", css["rule1"]["style1"])
 		}
 	})
+	t.Run("NestedFunctions", func(t *testing.T) {
+		ex1 := `grid {
+	grid-template-columns: repeat(2, minmax(100px, 1fr));
+	width: var(--x,
+		1px, 2px);
+	background-image: url(http://example.com/a.png);
+}`
+		css, err := Unmarshal([]byte(ex1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := map[string]string{
+			"grid-template-columns": "repeat(2, minmax(100px, 1fr))",
+			"width":                 "var(--x,\n\t\t1px, 2px)",
+			"background-image":      "url(http://example.com/a.png)",
+		}
+		for style, value := range expected {
+			if css["grid"][style] != value {
+				t.Fatalf("invalid value for %q, got %q", style, css["grid"][style])
+			}
+		}
+	})
+	t.Run("SemicolonsInValues", func(t *testing.T) {
+		ex1 := `icon {
+	background: url("data:image/svg+xml;charset=utf8,%3Csvg%3E");
+	content: "a;b:c";
+	src: url(a.woff) format("woff;2");
+}`
+		css, err := Unmarshal([]byte(ex1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := map[string]string{
+			"background": `url("data:image/svg+xml;charset=utf8,%3Csvg%3E")`,
+			"content":    `"a;b:c"`,
+			"src":        `url(a.woff) format("woff;2")`,
+		}
+		if len(css["icon"]) != len(expected) {
+			t.Fatalf("expected %d styles, got %v", len(expected), css["icon"])
+		}
+		for style, value := range expected {
+			if css["icon"][style] != value {
+				t.Fatalf("invalid value for %q, got %q", style, css["icon"][style])
+			}
+		}
+	})
 	t.Run("RealWorldCSS", func(t *testing.T) {
 		ex1 := `body {
     background-image: url("gradient_bg.png");
@@ -175,6 +221,54 @@ func TestParseSelectors(t *testing.T) {
 	}
 }
 
+func TestParseDotsAndHashesInStrings(t *testing.T) {
+	ex1 := `[class="a.b"] {
+	content: "#1";
+}
+a[href="#top"] {
+	content: ".";
+}`
+
+	css, err := Unmarshal([]byte(ex1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if css[`[class="a.b"]`]["content"] != `"#1"` {
+		t.Fatalf("missing or invalid '[class=\"a.b\"]' rule: %v", css)
+	}
+	if css[`a[href="#top"]`]["content"] != `"."` {
+		t.Fatalf("missing or invalid 'a[href=\"#top\"]' rule: %v", css)
+	}
+}
+
+func TestCommentsInStrings(t *testing.T) {
+	ex1 := `a {
+	content: "/* x */";
+	background: url(img/*.png) /* comment */;
+}
+b[title='/*'] {
+	color: red;
+}`
+
+	css, err := Unmarshal([]byte(ex1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if css["a"]["content"] != `"/* x */"` {
+		t.Fatalf("invalid content: %v", css)
+	}
+	if css["a"]["background"] != "url(img/*.png)" {
+		t.Fatalf("invalid background: %v", css)
+	}
+	if css[`b[title='/*']`]["color"] != "red" {
+		t.Fatalf("missing or invalid b rule: %v", css)
+	}
+
+	if out := string(blankComments([]byte("/* a\nb */c"))); out != "    \n    c" {
+		t.Fatalf("unexpected blanked comment %q", out)
+	}
+}
+
 func TestParseSelectorGroup(t *testing.T) {
 	ex1 := `.rule1 #rule2 rule3 {
 		style1: value1;
@@ -198,6 +292,128 @@ func TestParseSelectorGroup(t *testing.T) {
 
 }
 
+func TestSelectorsCombinators(t *testing.T) {
+	ex1 := `div > p {
+	style1: value1;
+}
+h1+p {
+	style2: value2;
+}
+ul   li ~ span {
+	style3: value3;
+}
+#id.class {
+	style4: value4;
+}
+h1 ,
+	h2>a:not( .b ) {
+	style5: value5;
+}
+a[title='x  y'] {
+	style6: value6;
+}
+a:hover  >  b {
+	style7: value7;
+}`
+
+	// whitespace runs between tokens are collapsed, nothing else changes
+	expected := []Rule{"div > p", "h1+p", "ul li ~ span", "#id.class", "h1 , h2>a:not( .b )", "a[title='x  y']", "a:hover > b"}
+	rules := Selectors(Tokenize([]byte(ex1)))
+	if len(rules) != len(expected) {
+		t.Fatalf("expected %d selectors, got %d: %q", len(expected), len(rules), rules)
+	}
+	for i := range expected {
+		if rules[i] != expected[i] {
+			t.Fatalf("expected selector %q, got %q", string(expected[i]), string(rules[i]))
+		}
+	}
+}
+
+func TestStructure(t *testing.T) {
+	ex1 := `@import url("a.css");
+rule1 {
+	style1: value1;
+	style2: value2;
+}
+@media print {
+	rule2 {
+		style1: value1;
+	}
+}
+rule3 {
+	style1: value1;
+}`
+
+	report := Structure(Tokenize([]byte(ex1)))
+	if report.Rules != 2 {
+		t.Fatalf("expected 2 top-level rules, got %d", report.Rules)
+	}
+	if report.AtRules["@media"] != 1 || report.AtRules["@import"] != 1 {
+		t.Fatalf("unexpected at-rule counts %v", report.AtRules)
+	}
+	if report.MaxDepth != 2 {
+		t.Fatalf("expected max depth 2, got %d", report.MaxDepth)
+	}
+	if report.Declarations[1] != 2 || report.Declarations[2] != 1 {
+		t.Fatalf("unexpected declaration distribution %v", report.Declarations)
+	}
+}
+
+func TestOrderedRules(t *testing.T) {
+	ex1 := `rule2 {
+	style2: value2;
+	style1: value1;
+}
+rule1 {
+	style3: value3;
+}
+rule2 {
+	style4: value4;
+}`
+
+	rules := OrderedRules([]byte(ex1))
+	expected := []Rule{"rule2", "rule1", "rule2"}
+	if len(rules) != len(expected) {
+		t.Fatalf("expected %d rules, got %d", len(expected), len(rules))
+	}
+	for i := range expected {
+		if rules[i].Rule != expected[i] {
+			t.Fatalf("expected rule %q, got %q", expected[i], rules[i].Rule)
+		}
+	}
+	if rules[0].Decls[0].Property != "style2" || rules[0].Decls[1].Property != "style1" {
+		t.Fatalf("declarations are not in document order: %v", rules[0].Decls)
+	}
+}
+
+func TestValueSpacing(t *testing.T) {
+	ex1 := `div {
+	grid-template:
+		"a a" 40px
+		"b b" 1fr / 1.5fr  auto;
+	transform: translate(10px ,20px)  rotate(1deg);
+	color: red
+		#fff !important;
+	margin:0 auto
+}`
+
+	css, err := Unmarshal([]byte(ex1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{
+		"grid-template": "\"a a\" 40px\n\t\t\"b b\" 1fr / 1.5fr  auto",
+		"transform":     "translate(10px ,20px)  rotate(1deg)",
+		"color":         "red\n\t\t#fff !important",
+		"margin":        "0 auto",
+	}
+	for property, value := range expected {
+		if css["div"][property] != value {
+			t.Errorf("%s: got %q, want %q", property, css["div"][property], value)
+		}
+	}
+}
+
 func BenchmarkParser(b *testing.B) {
 
 	ex1 := ""
@@ -0,0 +1,194 @@
+package css
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PatchOpKind is the kind of a PatchOp.
+type PatchOpKind string
+
+const (
+	// PatchInsert inserts a rule.
+	PatchInsert PatchOpKind = "insert"
+	// PatchDelete deletes a rule.
+	PatchDelete PatchOpKind = "delete"
+	// PatchEdit replaces the declarations of a rule.
+	PatchEdit PatchOpKind = "edit"
+)
+
+// Patch is a list of changes turning a stylesheet into another one. It
+// is meant to be sent as json, so editors and live-preview servers can
+// keep a stylesheet in sync without sending all of it.
+type Patch []PatchOp
+
+// PatchOp is a change of a Patch. Rules are referenced by their RuleID.
+type PatchOp struct {
+	Op PatchOpKind `json:"op"`
+	ID string      `json:"id"`
+	// After is the id of the rule an inserted rule follows, empty to
+	// insert it first.
+	After    string `json:"after,omitempty"`
+	Selector Rule   `json:"selector,omitempty"`
+	AtRules  []Rule `json:"atRules,omitempty"`
+	// Declarations are the declarations of inserted and edited rules, in
+	// the format of a style attribute.
+	Declarations string `json:"declarations,omitempty"`
+}
+
+// RuleIDs returns the id of every rule of the stylesheet, in the order of
+// sheet.Rules. The id of a rule depends on its at-rules, its selector
+// and the number of rules with the same ones before it, so it doesn't
+// change when declarations or unrelated rules are edited.
+func (sheet *Stylesheet) RuleIDs() []string {
+	var (
+		ids   = make([]string, len(sheet.Rules))
+		count = map[string]int{}
+	)
+	for i, r := range sheet.Rules {
+		h := sha256.New()
+		for _, at := range r.AtRules {
+			h.Write([]byte(strings.Join(strings.Fields(string(at)), " ")))
+			h.Write([]byte{0})
+		}
+		h.Write([]byte(strings.Join(strings.Fields(string(r.Selector)), " ")))
+		key := hex.EncodeToString(h.Sum(nil)[:4])
+		ids[i] = key + "-" + strconv.Itoa(count[key])
+		count[key]++
+	}
+	return ids
+}
+
+// MakePatch returns the patch turning the old stylesheet into the new
+// one. The rules are deleted first, then edited, then inserted in the
+// order of the new stylesheet. Rules that moved are deleted and inserted
+// again.
+func MakePatch(old, new *Stylesheet) Patch {
+	var (
+		patch   = Patch{}
+		edits   = Patch{}
+		inserts = Patch{}
+		oldIDs  = old.RuleIDs()
+		newIDs  = new.RuleIDs()
+		index   = make(map[string]int, len(oldIDs))
+		kept    = map[string]bool{}
+		last    = -1
+	)
+	for i, id := range oldIDs {
+		index[id] = i
+	}
+
+	for i, r := range new.Rules {
+		id := newIDs[i]
+		j, ok := index[id]
+		if ok && j > last {
+			kept[id] = true
+			last = j
+			if old.Rules[j].Hash() != r.Hash() {
+				edits = append(edits, PatchOp{Op: PatchEdit, ID: id, Declarations: Declarations(r.Declarations).String()})
+			}
+			continue
+		}
+		op := PatchOp{
+			Op:           PatchInsert,
+			ID:           id,
+			Selector:     r.Selector,
+			AtRules:      r.AtRules,
+			Declarations: Declarations(r.Declarations).String(),
+		}
+		if i > 0 {
+			op.After = newIDs[i-1]
+		}
+		inserts = append(inserts, op)
+	}
+
+	for _, id := range oldIDs {
+		if !kept[id] {
+			patch = append(patch, PatchOp{Op: PatchDelete, ID: id})
+		}
+	}
+	patch = append(patch, edits...)
+	return append(patch, inserts...)
+}
+
+// ApplyPatch applies the patch to the stylesheet. The ids of deleted and
+// edited rules are the ids of the stylesheet before the patch. On error,
+// the stylesheet is left unchanged.
+func ApplyPatch(sheet *Stylesheet, patch Patch) error {
+	var (
+		rules = append([]*StyleRule{}, sheet.Rules...)
+		byID  = map[string]*StyleRule{}
+		edits = map[*StyleRule]Declarations{}
+	)
+	for i, id := range sheet.RuleIDs() {
+		byID[id] = rules[i]
+	}
+
+	for _, op := range patch {
+		switch op.Op {
+		case PatchDelete:
+			r, ok := byID[op.ID]
+			if !ok {
+				return fmt.Errorf("patch: no rule %q to delete", op.ID)
+			}
+			delete(byID, op.ID)
+			rules = removeRule(rules, r)
+		case PatchEdit:
+			r, ok := byID[op.ID]
+			if !ok {
+				return fmt.Errorf("patch: no rule %q to edit", op.ID)
+			}
+			ds, err := UnmarshalStyle([]byte(op.Declarations))
+			if err != nil {
+				return fmt.Errorf("patch: rule %q: %v", op.ID, err)
+			}
+			edits[r] = ds
+		case PatchInsert:
+			if _, ok := byID[op.ID]; ok {
+				return fmt.Errorf("patch: rule %q already exists", op.ID)
+			}
+			at := 0
+			if op.After != "" {
+				prev, ok := byID[op.After]
+				if !ok {
+					return fmt.Errorf("patch: no rule %q to insert %q after", op.After, op.ID)
+				}
+				at = ruleIndex(rules, prev) + 1
+			}
+			ds, err := UnmarshalStyle([]byte(op.Declarations))
+			if err != nil {
+				return fmt.Errorf("patch: rule %q: %v", op.ID, err)
+			}
+			r := &StyleRule{Selector: op.Selector, Declarations: ds, AtRules: op.AtRules}
+			byID[op.ID] = r
+			rules = append(rules[:at], append([]*StyleRule{r}, rules[at:]...)...)
+		default:
+			return fmt.Errorf("patch: unknown operation %q", op.Op)
+		}
+	}
+
+	for r, ds := range edits {
+		r.Declarations = ds
+	}
+	sheet.Rules = rules
+	return nil
+}
+
+func ruleIndex(rules []*StyleRule, r *StyleRule) int {
+	for i := range rules {
+		if rules[i] == r {
+			return i
+		}
+	}
+	return -1
+}
+
+func removeRule(rules []*StyleRule, r *StyleRule) []*StyleRule {
+	if i := ruleIndex(rules, r); i >= 0 {
+		return append(rules[:i], rules[i+1:]...)
+	}
+	return rules
+}
@@ -0,0 +1,79 @@
+package css
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestMakePatch(t *testing.T) {
+	old, _ := UnmarshalStylesheet([]byte(`.a { color: red; }
+.b { top: 0; }
+.c { left: 0; }
+.d { right: 0; }
+@media print { .a { display: none; } }
+`))
+	new, _ := UnmarshalStylesheet([]byte(`.new { margin: 0; }
+.a { color: blue; }
+.d { right: 0; }
+.c { left: 0; }
+@media print { .a { display: none; } }
+.a { color: green; }
+`))
+
+	patch := MakePatch(old, new)
+	b, err := json.Marshal(patch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Patch
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	ops := map[PatchOpKind]int{}
+	for _, op := range decoded {
+		ops[op.Op]++
+	}
+	// .b is deleted, .c moves after .d, .a is edited, .new and the second
+	// .a are inserted
+	if want := map[PatchOpKind]int{PatchDelete: 2, PatchEdit: 1, PatchInsert: 3}; !reflect.DeepEqual(ops, want) {
+		t.Errorf("got operations %v, want %v\n%s", ops, want, b)
+	}
+
+	if err := ApplyPatch(old, decoded); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := RuleHashes(old), RuleHashes(new); !reflect.DeepEqual(got, want) {
+		t.Errorf("got rules %v, want %v", ruleSelectors(old), ruleSelectors(new))
+	}
+	if !reflect.DeepEqual(old.Rules[4].AtRules, []Rule{"@media print"}) {
+		t.Errorf("got at-rules %v", old.Rules[4].AtRules)
+	}
+	if len(MakePatch(old, new)) != 0 {
+		t.Errorf("patch of equal stylesheets: %v", MakePatch(old, new))
+	}
+}
+
+func TestApplyPatchError(t *testing.T) {
+	sheet, _ := UnmarshalStylesheet([]byte(".a { color: red; }"))
+	ids := sheet.RuleIDs()
+	patch := Patch{
+		{Op: PatchEdit, ID: ids[0], Declarations: "color: blue"},
+		{Op: PatchDelete, ID: "missing"},
+	}
+	if err := ApplyPatch(sheet, patch); err == nil {
+		t.Fatal("expected an error for an unknown rule")
+	}
+	if sheet.Rules[0].Declarations[0].Value != "red" {
+		t.Error("stylesheet changed by a failed patch")
+	}
+}
+
+func ruleSelectors(sheet *Stylesheet) []Rule {
+	selectors := make([]Rule, len(sheet.Rules))
+	for i, r := range sheet.Rules {
+		selectors[i] = r.Selector
+	}
+	return selectors
+}
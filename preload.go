@@ -0,0 +1,242 @@
+package css
+
+import (
+	"path"
+	"strings"
+)
+
+// ResourceHint is a resource referenced by a stylesheet that the browser
+// can start downloading early.
+type ResourceHint struct {
+	URL string
+	// Rel is "preload" for resources needed for the first render and
+	// "prefetch" for the ones that are likely needed later.
+	Rel string
+	// As is the destination of the resource: "font", "image" or "style".
+	As string
+	// Type is the mime type of the resource, empty when it is unknown.
+	Type string
+}
+
+// HintOptions configures ResourceHints.
+type HintOptions struct {
+	// Critical are the selectors of the rules applying above the fold.
+	// The images of these rules are preloaded, the ones of other rules are
+	// prefetched. Selectors are compared after serialization, so
+	// "a>b" matches "a > b".
+	Critical []Rule
+}
+
+// resourceTypes are the mime types of the fonts, by format() name and by
+// file extension, and of the images, by file extension.
+var resourceTypes = map[string]string{
+	"woff2":             "font/woff2",
+	"woff":              "font/woff",
+	"truetype":          "font/ttf",
+	"ttf":               "font/ttf",
+	"opentype":          "font/otf",
+	"otf":               "font/otf",
+	"embedded-opentype": "application/vnd.ms-fontobject",
+	"eot":               "application/vnd.ms-fontobject",
+	"collection":        "font/collection",
+	"ttc":               "font/collection",
+	"svg":               "image/svg+xml",
+	"png":               "image/png",
+	"jpg":               "image/jpeg",
+	"jpeg":              "image/jpeg",
+	"gif":               "image/gif",
+	"webp":              "image/webp",
+	"avif":              "image/avif",
+	"ico":               "image/x-icon",
+	"css":               "text/css",
+}
+
+// FontPreloads returns the fonts of the @font-face rules of the stylesheet
+// to preload, without duplicates. Only the first url of every src is
+// used, since browsers download the first source they support and
+// stylesheets list the preferred format first. The type is taken from
+// the format() hint, or guessed from the file extension.
+func FontPreloads(sheet *Stylesheet) []ResourceHint {
+	var (
+		hints = []ResourceHint{}
+		seen  = map[string]bool{}
+	)
+	for _, r := range sheet.Rules {
+		if !strings.EqualFold(string(r.Selector), "@font-face") {
+			continue
+		}
+		for _, d := range r.Declarations {
+			if !strings.EqualFold(d.Property, "src") {
+				continue
+			}
+			hint, ok := firstFontSource(d.TypedComponents())
+			if ok && !seen[hint.URL] {
+				seen[hint.URL] = true
+				hints = append(hints, hint)
+			}
+		}
+	}
+	return hints
+}
+
+// ResourceHints returns the resources referenced by the stylesheet in
+// priority order: the imported stylesheets, the fonts, the images of the
+// critical rules, which are all preloaded, and finally the prefetched
+// images of the other rules. A resource is only listed once, with its
+// highest priority.
+func ResourceHints(b []byte, opts HintOptions) []ResourceHint {
+	sheet, _ := UnmarshalStylesheet(b)
+
+	var (
+		imports  = []ResourceHint{}
+		critical = []ResourceHint{}
+		later    = []ResourceHint{}
+		isCrit   = map[string]bool{}
+	)
+	for _, r := range opts.Critical {
+		isCrit[r.String()] = true
+	}
+
+	for _, s := range RuleSources(b) {
+		if url := importURL(string(s.Prelude)); url != "" {
+			imports = append(imports, ResourceHint{URL: url, Rel: "preload", As: "style", Type: "text/css"})
+		}
+	}
+
+	for _, r := range sheet.Rules {
+		if strings.HasPrefix(string(r.Selector), "@") {
+			continue
+		}
+		preload := false
+		for _, part := range splitTopLevel(string(r.Selector), ',') {
+			if isCrit[Rule(strings.TrimSpace(part)).String()] {
+				preload = true
+			}
+		}
+		for _, d := range r.Declarations {
+			for _, url := range componentURLs(d.TypedComponents()) {
+				hint := ResourceHint{URL: url, Rel: "prefetch", As: "image", Type: resourceType(url)}
+				if preload {
+					hint.Rel = "preload"
+					critical = append(critical, hint)
+				} else {
+					later = append(later, hint)
+				}
+			}
+		}
+	}
+
+	var (
+		hints = []ResourceHint{}
+		seen  = map[string]bool{}
+	)
+	for _, group := range [][]ResourceHint{imports, FontPreloads(sheet), critical, later} {
+		for _, h := range group {
+			if !seen[h.URL] {
+				seen[h.URL] = true
+				hints = append(hints, h)
+			}
+		}
+	}
+	return hints
+}
+
+// importURL returns the url of an @import prelude, or an empty string if
+// the prelude isn't an @import.
+func importURL(prelude string) string {
+	if atRuleName(prelude) != "import" {
+		return ""
+	}
+	components := parseComponents(strings.TrimSpace(prelude)[len("@import"):])
+	if len(components) == 0 {
+		return ""
+	}
+	if components[0].Type == ComponentFunction {
+		if len(components[0].Args) == 0 {
+			return ""
+		}
+		return components[0].Args[0].Value
+	}
+	return components[0].Value
+}
+
+// componentURLs returns the urls of the url() functions in the
+// components, including the ones nested in other functions such as
+// image-set().
+func componentURLs(components []ComponentValue) []string {
+	urls := []string{}
+	for _, c := range components {
+		if c.Type == ComponentURL {
+			if c.Value != "" && !strings.HasPrefix(c.Value, "data:") {
+				urls = append(urls, c.Value)
+			}
+			continue
+		}
+		urls = append(urls, componentURLs(c.Args)...)
+	}
+	return urls
+}
+
+// resourceType returns the mime type guessed from the extension of the
+// url, ignoring its query and fragment.
+func resourceType(url string) string {
+	url = strings.SplitN(strings.SplitN(url, "#", 2)[0], "?", 2)[0]
+	return resourceTypes[strings.ToLower(strings.TrimPrefix(path.Ext(url), "."))]
+}
+
+// firstFontSource returns the first url of a src value.
+func firstFontSource(components []ComponentValue) (ResourceHint, bool) {
+	for i, c := range components {
+		if c.Type != ComponentURL {
+			continue
+		}
+		hint := ResourceHint{URL: c.Value, Rel: "preload", As: "font"}
+		hint.Type = resourceType(hint.URL)
+		for _, next := range components[i+1:] {
+			if next.Type == ComponentOperator && next.Value == "," {
+				break
+			}
+			if next.Type == ComponentFunction && strings.EqualFold(next.Value, "format") && len(next.Args) > 0 {
+				if t, ok := resourceTypes[strings.ToLower(next.Args[0].Value)]; ok {
+					hint.Type = t
+				}
+			}
+		}
+		return hint, true
+	}
+	return ResourceHint{}, false
+}
+
+// LinkTag returns the <link> tag of the hint. Fonts are always fetched in
+// cors mode, so font tags have the crossorigin attribute even for fonts
+// on the same origin; without it the preloaded font isn't used.
+func (h ResourceHint) LinkTag() string {
+	tag := `<link rel="` + h.Rel + `" href="` + htmlEscaper.Replace(h.URL) + `"`
+	if h.As != "" {
+		tag += ` as="` + h.As + `"`
+	}
+	if h.Type != "" {
+		tag += ` type="` + h.Type + `"`
+	}
+	if h.As == "font" {
+		tag += " crossorigin"
+	}
+	return tag + ">"
+}
+
+// LinkHeader returns the value of the HTTP Link header of the hint.
+func (h ResourceHint) LinkHeader() string {
+	header := "<" + h.URL + ">; rel=" + h.Rel
+	if h.As != "" {
+		header += "; as=" + h.As
+	}
+	if h.Type != "" {
+		header += `; type="` + h.Type + `"`
+	}
+	if h.As == "font" {
+		header += "; crossorigin"
+	}
+	return header
+}
+
+var htmlEscaper = strings.NewReplacer(`&`, "&amp;", `"`, "&quot;", `<`, "&lt;", `>`, "&gt;")
@@ -0,0 +1,87 @@
+package css
+
+import "testing"
+
+func TestFontPreloads(t *testing.T) {
+	sheet, err := UnmarshalStylesheet([]byte(`@font-face {
+	font-family: Inter;
+	src: local("Inter"), url("/fonts/inter.woff2") format("woff2"), url(/fonts/inter.woff) format("woff");
+}
+@font-face {
+	font-family: Mono;
+	src: url(/fonts/mono.ttf?v=2);
+}
+@font-face {
+	font-family: Inter;
+	font-weight: bold;
+	src: url("/fonts/inter.woff2") format("woff2");
+}
+body {
+	background: url(bg.png);
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	hints := FontPreloads(sheet)
+	expected := []ResourceHint{
+		{"/fonts/inter.woff2", "preload", "font", "font/woff2"},
+		{"/fonts/mono.ttf?v=2", "preload", "font", "font/ttf"},
+	}
+	if len(hints) != len(expected) {
+		t.Fatalf("got %v, expected %v", hints, expected)
+	}
+	for i := range expected {
+		if hints[i] != expected[i] {
+			t.Errorf("hint %d: got %v, expected %v", i, hints[i], expected[i])
+		}
+	}
+
+	if tag := hints[0].LinkTag(); tag != `<link rel="preload" href="/fonts/inter.woff2" as="font" type="font/woff2" crossorigin>` {
+		t.Errorf("unexpected tag %s", tag)
+	}
+	if header := hints[1].LinkHeader(); header != `</fonts/mono.ttf?v=2>; rel=preload; as=font; type="font/ttf"; crossorigin` {
+		t.Errorf("unexpected header %s", header)
+	}
+}
+
+func TestResourceHints(t *testing.T) {
+	ex := []byte(`@import url("base.css");
+@import "theme.css" screen;
+@font-face {
+	font-family: Inter;
+	src: url(inter.woff2) format("woff2");
+}
+.footer {
+	background: url(footer.jpg);
+}
+.hero>h1, .banner {
+	background-image: image-set(url("hero.webp") 1x, url("hero@2x.webp") 2x);
+}
+.icon {
+	background: url(data:image/png;base64,AAAA), url(footer.jpg);
+	list-style-image: url(dot.svg);
+}
+`)
+	hints := ResourceHints(ex, HintOptions{Critical: []Rule{".hero > h1"}})
+	expected := []ResourceHint{
+		{"base.css", "preload", "style", "text/css"},
+		{"theme.css", "preload", "style", "text/css"},
+		{"inter.woff2", "preload", "font", "font/woff2"},
+		{"hero.webp", "preload", "image", "image/webp"},
+		{"hero@2x.webp", "preload", "image", "image/webp"},
+		{"footer.jpg", "prefetch", "image", "image/jpeg"},
+		{"dot.svg", "prefetch", "image", "image/svg+xml"},
+	}
+	if len(hints) != len(expected) {
+		t.Fatalf("got %v, expected %v", hints, expected)
+	}
+	for i := range expected {
+		if hints[i] != expected[i] {
+			t.Errorf("hint %d: got %v, expected %v", i, hints[i], expected[i])
+		}
+	}
+	if tag := hints[5].LinkTag(); tag != `<link rel="prefetch" href="footer.jpg" as="image" type="image/jpeg">` {
+		t.Errorf("unexpected tag %s", tag)
+	}
+}
@@ -0,0 +1,106 @@
+package css
+
+import (
+	"bytes"
+	"strings"
+)
+
+// mediaMatch is the result of evaluating a media query list for a media
+// type.
+type mediaMatch int
+
+const (
+	mediaNever mediaMatch = iota
+	// mediaDepends is a match depending on media features, such as
+	// (min-width: 600px), that can't be evaluated from the type alone.
+	mediaDepends
+	mediaAlways
+)
+
+// PrintStylesheet returns the standalone stylesheet applying to printed
+// documents, as used by html to pdf converters. See ExtractMedia.
+func PrintStylesheet(b []byte) []byte {
+	return ExtractMedia(b, "print")
+}
+
+// ExtractMedia returns the rules of the stylesheet applying to the media
+// type, such as "print" or "screen". The content of @media rules always
+// matching the type is unwrapped, @media rules and @import statements
+// never matching it are dropped, and those depending on media features
+// are kept as they are. All other rules are kept unchanged.
+func ExtractMedia(b []byte, mediaType string) []byte {
+	var buf bytes.Buffer
+	extractMedia(&buf, b, strings.ToLower(mediaType))
+	return buf.Bytes()
+}
+
+func extractMedia(buf *bytes.Buffer, b []byte, mediaType string) {
+	for _, item := range topLevelItems(b) {
+		body := string(blankComments([]byte(item)))
+		switch atRuleName(item) {
+		case "media":
+			open, end := strings.IndexByte(body, '{'), strings.LastIndexByte(body, '}')
+			if open < 0 || end < open {
+				continue
+			}
+			query := strings.TrimSpace(body[strings.Index(body, "@")+len("@media") : open])
+			switch evalMediaList(query, mediaType) {
+			case mediaAlways:
+				extractMedia(buf, []byte(item[open+1:end]), mediaType)
+				continue
+			case mediaNever:
+				continue
+			}
+		case "import":
+			statement := strings.TrimSuffix(strings.TrimSpace(body), ";")
+			components := parseComponents(statement[strings.Index(statement, "@")+len("@import"):])
+			if len(components) > 1 {
+				media := joinComponents(components[1:])
+				if evalMediaList(media, mediaType) == mediaNever {
+					continue
+				}
+			}
+		}
+		buf.WriteString(item)
+		buf.WriteByte('\n')
+	}
+}
+
+// evalMediaList evaluates the comma separated media queries for the media
+// type.
+func evalMediaList(list, mediaType string) mediaMatch {
+	result := mediaNever
+	for _, query := range splitTopLevel(list, ',') {
+		if m := evalMediaQuery(query, mediaType); m > result {
+			result = m
+		}
+	}
+	return result
+}
+
+// evalMediaQuery evaluates a single media query such as "only screen and
+// (min-width: 600px)" or "not print" for the media type.
+func evalMediaQuery(query, mediaType string) mediaMatch {
+	words := strings.Fields(strings.ToLower(query))
+	negated := false
+	if len(words) > 0 && (words[0] == "not" || words[0] == "only") {
+		negated = words[0] == "not"
+		words = words[1:]
+	}
+
+	typ := "all"
+	if len(words) > 0 && !strings.HasPrefix(words[0], "(") {
+		typ = words[0]
+		words = words[1:]
+	}
+	features := len(words) > 0
+
+	typeMatches := typ == "all" || typ == mediaType
+	switch {
+	case typeMatches && features:
+		return mediaDepends
+	case typeMatches != negated:
+		return mediaAlways
+	}
+	return mediaNever
+}
@@ -0,0 +1,77 @@
+package css
+
+import "testing"
+
+func TestPrintStylesheet(t *testing.T) {
+	ex := `@import url(screen.css) screen;
+@import url(all.css);
+body {
+	color: gray;
+}
+@media print {
+	/* print only */
+	body {
+		color: black;
+	}
+	@media (orientation: landscape) {
+		.wide {
+			width: 100%;
+		}
+	}
+}
+@media screen, speech {
+	nav {
+		display: block;
+	}
+}
+@media not screen {
+	.ad {
+		display: none;
+	}
+}
+`
+	expected := `@import url(all.css);
+body {
+	color: gray;
+}
+/* print only */
+	body {
+		color: black;
+	}
+@media (orientation: landscape) {
+		.wide {
+			width: 100%;
+		}
+	}
+.ad {
+		display: none;
+	}
+`
+	if out := string(PrintStylesheet([]byte(ex))); out != expected {
+		t.Fatalf("got:\n%s\nexpected:\n%s", out, expected)
+	}
+}
+
+func TestEvalMediaQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		want  mediaMatch
+	}{
+		{"print", mediaAlways},
+		{"all", mediaAlways},
+		{"only print", mediaAlways},
+		{"screen", mediaNever},
+		{"not screen", mediaAlways},
+		{"not print", mediaNever},
+		{"print and (color)", mediaDepends},
+		{"(min-width: 600px)", mediaDepends},
+		{"not screen and (color)", mediaAlways},
+		{"screen, print", mediaAlways},
+		{"screen, (color)", mediaDepends},
+	}
+	for _, test := range tests {
+		if got := evalMediaList(test.query, "print"); got != test.want {
+			t.Errorf("%q: got %v, want %v", test.query, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package css
+
+import (
+	"path"
+	"strings"
+	"text/scanner"
+)
+
+// Project is a set of named stylesheets analysed together, such as the
+// files of a site. Names are slash separated paths, which are used to
+// resolve relative @import urls between the files.
+type Project struct {
+	files []*ProjectFile
+	index map[string]*ProjectFile
+}
+
+// ProjectFile is a stylesheet of a Project.
+type ProjectFile struct {
+	Name  string
+	Data  []byte
+	Sheet *Stylesheet
+	// Imports are the urls of the @import rules, as written.
+	Imports []string
+	// Layers are the cascade layers declared by the file, in order of
+	// first appearance.
+	Layers []string
+}
+
+// ProjectMatch is a declaration found in a file of a project.
+type ProjectMatch struct {
+	File string
+	Match
+}
+
+// VariableUse is a declaration defining or using a custom property.
+type VariableUse struct {
+	File     string
+	Selector Rule
+	Property string
+	Pos      scanner.Position
+}
+
+// NewProject returns an empty project.
+func NewProject() *Project {
+	return &Project{index: map[string]*ProjectFile{}}
+}
+
+// Add parses the stylesheet and adds it to the project, replacing the
+// file with the same name.
+func (p *Project) Add(name string, data []byte) error {
+	sheet, err := UnmarshalStylesheet(data)
+	if err != nil {
+		return err
+	}
+	f := &ProjectFile{Name: name, Data: data, Sheet: sheet, Imports: []string{}, Layers: []string{}}
+	for _, s := range RuleSources(data) {
+		prelude := string(s.Prelude)
+		if url := importURL(prelude); url != "" {
+			f.Imports = append(f.Imports, url)
+		}
+		if atRuleName(prelude) == "layer" {
+			for _, layer := range strings.Split(strings.TrimSpace(prelude)[len("@layer"):], ",") {
+				if layer = strings.TrimSpace(layer); layer != "" && !containsString(f.Layers, layer) {
+					f.Layers = append(f.Layers, layer)
+				}
+			}
+		}
+	}
+
+	if old, ok := p.index[name]; ok {
+		for i := range p.files {
+			if p.files[i] == old {
+				p.files[i] = f
+			}
+		}
+	} else {
+		p.files = append(p.files, f)
+	}
+	p.index[name] = f
+	return nil
+}
+
+// File returns the file with the name, or nil.
+func (p *Project) File(name string) *ProjectFile {
+	return p.index[name]
+}
+
+// Files returns the files of the project in the order they were added.
+func (p *Project) Files() []*ProjectFile {
+	return p.files
+}
+
+// Imports returns the names of the project files imported by the file.
+// Imports of files outside of the project are left out.
+func (p *Project) Imports(name string) []string {
+	f := p.index[name]
+	if f == nil {
+		return nil
+	}
+	names := []string{}
+	for _, url := range f.Imports {
+		if target := p.resolve(name, url); target != "" {
+			names = append(names, target)
+		}
+	}
+	return names
+}
+
+// Importers returns the names of the files importing the file.
+func (p *Project) Importers(name string) []string {
+	names := []string{}
+	for _, f := range p.files {
+		if containsString(p.Imports(f.Name), name) {
+			names = append(names, f.Name)
+		}
+	}
+	return names
+}
+
+// resolve returns the name of the project file the url imported from the
+// file refers to, or an empty string.
+func (p *Project) resolve(from, url string) string {
+	if strings.Contains(url, "://") || strings.HasPrefix(url, "//") {
+		return ""
+	}
+	target := url
+	if !strings.HasPrefix(url, "/") {
+		target = path.Join(path.Dir(from), url)
+	}
+	target = path.Clean(target)
+	for _, candidate := range []string{target, strings.TrimPrefix(target, "/")} {
+		if _, ok := p.index[candidate]; ok {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// Layers returns the cascade layers of the project in the order the
+// browser would establish them if the files were loaded in order.
+func (p *Project) Layers() []string {
+	layers := []string{}
+	for _, f := range p.files {
+		for _, layer := range f.Layers {
+			if !containsString(layers, layer) {
+				layers = append(layers, layer)
+			}
+		}
+	}
+	return layers
+}
+
+// Find returns the declarations of all files matching the predicates,
+// in file and document order.
+func (p *Project) Find(predicates ...Predicate) []ProjectMatch {
+	matches := []ProjectMatch{}
+	for _, f := range p.files {
+		for _, m := range f.Sheet.Find(predicates...) {
+			matches = append(matches, ProjectMatch{f.Name, m})
+		}
+	}
+	return matches
+}
+
+// Variables returns the custom property graph of all files together.
+func (p *Project) Variables() VariableGraph {
+	return NewVariableGraph(p.merged())
+}
+
+// VariableDefinitions returns the declarations of the custom property in
+// all files.
+func (p *Project) VariableDefinitions(name string) []VariableUse {
+	uses := []VariableUse{}
+	for _, m := range p.Find(Property(name)) {
+		uses = append(uses, VariableUse{m.File, m.Rule.Selector, m.Declaration.Property, m.Declaration.Pos})
+	}
+	return uses
+}
+
+// VariableUses returns the declarations using the custom property with
+// var() in all files.
+func (p *Project) VariableUses(name string) []VariableUse {
+	uses := []VariableUse{}
+	using := func(_ *StyleRule, d Declaration) bool {
+		return containsString(variableReferences(d.TypedComponents()), name)
+	}
+	for _, m := range p.Find(using) {
+		uses = append(uses, VariableUse{m.File, m.Rule.Selector, m.Declaration.Property, m.Declaration.Pos})
+	}
+	return uses
+}
+
+// LintReducedMotion runs LintReducedMotion on all files together.
+func (p *Project) LintReducedMotion() []Issue {
+	sheets := make([]*Stylesheet, len(p.files))
+	for i, f := range p.files {
+		sheets[i] = f.Sheet
+	}
+	return LintReducedMotion(sheets...)
+}
+
+// merged returns a stylesheet with the rules of all files.
+func (p *Project) merged() *Stylesheet {
+	sheet := &Stylesheet{}
+	for _, f := range p.files {
+		sheet.Rules = append(sheet.Rules, f.Sheet.Rules...)
+	}
+	return sheet
+}
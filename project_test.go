@@ -0,0 +1,71 @@
+package css
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestProject(t *testing.T) {
+	p := NewProject()
+	files := map[string]string{
+		"css/main.css": `@import url("base/tokens.css");
+@import "https://fonts.example.com/inter.css";
+@layer reset, components;
+.btn {
+	color: var(--primary);
+}
+`,
+		"css/base/tokens.css": `@layer reset {
+	html {
+		--primary: var(--blue);
+		--blue: #00f;
+	}
+}
+`,
+		"css/print.css": `@import "/css/base/tokens.css";
+@layer print;
+.btn {
+	color: var(--ink);
+}
+`,
+	}
+	for _, name := range []string{"css/main.css", "css/base/tokens.css", "css/print.css"} {
+		if err := p.Add(name, []byte(files[name])); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if imports := p.Imports("css/main.css"); len(imports) != 1 || imports[0] != "css/base/tokens.css" {
+		t.Errorf("unexpected imports %v", imports)
+	}
+	if importers := strings.Join(p.Importers("css/base/tokens.css"), " "); importers != "css/main.css css/print.css" {
+		t.Errorf("unexpected importers %s", importers)
+	}
+	if layers := strings.Join(p.Layers(), " "); layers != "reset components print" {
+		t.Errorf("unexpected layers %s", layers)
+	}
+
+	defs := p.VariableDefinitions("--primary")
+	if len(defs) != 1 || defs[0].File != "css/base/tokens.css" || defs[0].Selector != "html" || defs[0].Pos.Line != 3 {
+		t.Errorf("unexpected definitions %v", defs)
+	}
+	uses := p.VariableUses("--primary")
+	if len(uses) != 1 || uses[0].File != "css/main.css" || uses[0].Property != "color" {
+		t.Errorf("unexpected uses %v", uses)
+	}
+	if undefined := p.Variables().Undefined(); len(undefined) != 1 || undefined[0] != "--ink" {
+		t.Errorf("unexpected undefined variables %v", undefined)
+	}
+
+	matches := p.Find(SelectorContains(".btn"))
+	if len(matches) != 2 || matches[0].File != "css/main.css" || matches[1].File != "css/print.css" {
+		t.Errorf("unexpected matches %v", matches)
+	}
+
+	if err := p.Add("css/print.css", []byte(".x {\n\ttop: 0;\n}\n")); err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Files()) != 3 || p.File("css/print.css").Sheet.Rules[0].Selector != ".x" {
+		t.Error("file was not replaced")
+	}
+}
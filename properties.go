@@ -0,0 +1,140 @@
+package css
+
+// PropertyInfo describes how a property takes part in the cascade.
+type PropertyInfo struct {
+	// Inherited is set when the property inherits by default.
+	Inherited bool `json:"inherited"`
+	// Initial is the initial value of the property.
+	Initial string `json:"initial"`
+}
+
+// Properties holds the metadata of the known longhand properties. You can
+// add your own properties or overwrite the existing ones.
+var Properties = map[string]PropertyInfo{
+	"align-content":              {false, "normal"},
+	"align-items":                {false, "normal"},
+	"align-self":                 {false, "auto"},
+	"animation-delay":            {false, "0s"},
+	"animation-direction":        {false, "normal"},
+	"animation-duration":         {false, "0s"},
+	"animation-fill-mode":        {false, "none"},
+	"animation-iteration-count":  {false, "1"},
+	"animation-name":             {false, "none"},
+	"animation-play-state":       {false, "running"},
+	"animation-timing-function":  {false, "ease"},
+	"background-attachment":      {false, "scroll"},
+	"background-clip":            {false, "border-box"},
+	"background-color":           {false, "transparent"},
+	"background-image":           {false, "none"},
+	"background-origin":          {false, "padding-box"},
+	"background-position":        {false, "0% 0%"},
+	"background-repeat":          {false, "repeat"},
+	"background-size":            {false, "auto"},
+	"border-bottom-color":        {false, "currentcolor"},
+	"border-bottom-left-radius":  {false, "0"},
+	"border-bottom-right-radius": {false, "0"},
+	"border-bottom-style":        {false, "none"},
+	"border-bottom-width":        {false, "medium"},
+	"border-collapse":            {true, "separate"},
+	"border-left-color":          {false, "currentcolor"},
+	"border-left-style":          {false, "none"},
+	"border-left-width":          {false, "medium"},
+	"border-right-color":         {false, "currentcolor"},
+	"border-right-style":         {false, "none"},
+	"border-right-width":         {false, "medium"},
+	"border-spacing":             {true, "0"},
+	"border-top-color":           {false, "currentcolor"},
+	"border-top-left-radius":     {false, "0"},
+	"border-top-right-radius":    {false, "0"},
+	"border-top-style":           {false, "none"},
+	"border-top-width":           {false, "medium"},
+	"bottom":                     {false, "auto"},
+	"box-shadow":                 {false, "none"},
+	"box-sizing":                 {false, "content-box"},
+	"caption-side":               {true, "top"},
+	"clear":                      {false, "none"},
+	"clip":                       {false, "auto"},
+	"color":                      {true, "canvastext"},
+	"column-gap":                 {false, "normal"},
+	"contain":                    {false, "none"},
+	"content":                    {false, "normal"},
+	"content-visibility":         {false, "visible"},
+	"counter-increment":          {false, "none"},
+	"counter-reset":              {false, "none"},
+	"cursor":                     {true, "auto"},
+	"direction":                  {true, "ltr"},
+	"display":                    {false, "inline"},
+	"empty-cells":                {true, "show"},
+	"flex-basis":                 {false, "auto"},
+	"flex-direction":             {false, "row"},
+	"flex-grow":                  {false, "0"},
+	"flex-shrink":                {false, "1"},
+	"flex-wrap":                  {false, "nowrap"},
+	"float":                      {false, "none"},
+	"font-family":                {true, "serif"},
+	"font-size":                  {true, "medium"},
+	"font-stretch":               {true, "normal"},
+	"font-style":                 {true, "normal"},
+	"font-variant":               {true, "normal"},
+	"font-weight":                {true, "normal"},
+	"height":                     {false, "auto"},
+	"justify-content":            {false, "normal"},
+	"left":                       {false, "auto"},
+	"letter-spacing":             {true, "normal"},
+	"line-height":                {true, "normal"},
+	"list-style-image":           {true, "none"},
+	"list-style-position":        {true, "outside"},
+	"list-style-type":            {true, "disc"},
+	"margin-bottom":              {false, "0"},
+	"margin-left":                {false, "0"},
+	"margin-right":               {false, "0"},
+	"margin-top":                 {false, "0"},
+	"max-height":                 {false, "none"},
+	"max-width":                  {false, "none"},
+	"min-height":                 {false, "0"},
+	"min-width":                  {false, "0"},
+	"opacity":                    {false, "1"},
+	"order":                      {false, "0"},
+	"orphans":                    {true, "2"},
+	"outline-color":              {false, "currentcolor"},
+	"outline-offset":             {false, "0"},
+	"outline-style":              {false, "none"},
+	"outline-width":              {false, "medium"},
+	"overflow":                   {false, "visible"},
+	"overflow-wrap":              {true, "normal"},
+	"padding-bottom":             {false, "0"},
+	"padding-left":               {false, "0"},
+	"padding-right":              {false, "0"},
+	"padding-top":                {false, "0"},
+	"page-break-after":           {false, "auto"},
+	"page-break-before":          {false, "auto"},
+	"page-break-inside":          {false, "auto"},
+	"pointer-events":             {true, "auto"},
+	"position":                   {false, "static"},
+	"quotes":                     {true, "auto"},
+	"right":                      {false, "auto"},
+	"row-gap":                    {false, "normal"},
+	"table-layout":               {false, "auto"},
+	"text-align":                 {true, "start"},
+	"text-decoration":            {false, "none"},
+	"text-indent":                {true, "0"},
+	"text-overflow":              {false, "clip"},
+	"text-shadow":                {true, "none"},
+	"text-transform":             {true, "none"},
+	"top":                        {false, "auto"},
+	"transform":                  {false, "none"},
+	"transition-delay":           {false, "0s"},
+	"transition-duration":        {false, "0s"},
+	"transition-property":        {false, "all"},
+	"transition-timing-function": {false, "ease"},
+	"unicode-bidi":               {false, "normal"},
+	"vertical-align":             {false, "baseline"},
+	"visibility":                 {true, "visible"},
+	"white-space":                {true, "normal"},
+	"widows":                     {true, "2"},
+	"width":                      {false, "auto"},
+	"will-change":                {false, "auto"},
+	"word-break":                 {true, "normal"},
+	"word-spacing":               {true, "normal"},
+	"z-index":                    {false, "auto"},
+}
@@ -0,0 +1,96 @@
+package css
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Provenance explains the value of a property of an element: the
+// declaration winning the cascade and the declarations it overrides.
+type Provenance struct {
+	// Property is the lowercase property name.
+	Property string
+	Winner   CascadeEntry
+	// Overridden are the losing declarations of the property, in winning
+	// order.
+	Overridden []CascadeEntry
+}
+
+// Source returns where the winning declaration comes from: the file and
+// position of its rule, "style attribute" or "presentation attribute".
+func (p Provenance) Source() string {
+	return entrySource(p.Winner)
+}
+
+// String explains the value, such as
+//
+//	color: green from #intro at a.css:3:1 (specificity 1,0,0), overriding 2 declarations
+func (p Provenance) String() string {
+	var b strings.Builder
+	b.WriteString(p.Winner.Declaration.String())
+	if r := p.Winner.Rule; r != nil {
+		s := p.Winner.Specificity
+		fmt.Fprintf(&b, " from %s at %s (specificity %d,%d,%d)", r.Selector, p.Source(), s[0], s[1], s[2])
+	} else {
+		b.WriteString(" from the " + p.Source())
+	}
+	switch len(p.Overridden) {
+	case 0:
+	case 1:
+		b.WriteString(", overriding 1 declaration")
+	default:
+		fmt.Fprintf(&b, ", overriding %d declarations", len(p.Overridden))
+	}
+	return b.String()
+}
+
+// entrySource returns where the declaration of the entry comes from.
+func entrySource(e CascadeEntry) string {
+	switch {
+	case e.Rule != nil && e.Rule.Location != nil:
+		return e.Rule.Location.String()
+	case e.Rule != nil:
+		return e.Rule.Pos.String()
+	case e.Inline:
+		return "style attribute"
+	}
+	return "presentation attribute"
+}
+
+// Provenances returns the provenance of every property set on the
+// element, sorted by property.
+func (c *Cascade) Provenances(el Element, inline Declarations) []Provenance {
+	candidates := map[string][]CascadeEntry{}
+	for _, e := range c.Entries(el, inline) {
+		p := strings.ToLower(e.Declaration.Property)
+		candidates[p] = append(candidates[p], e)
+	}
+
+	provenances := make([]Provenance, 0, len(candidates))
+	for p, entries := range candidates {
+		sorted := SortCascade(entries)
+		provenances = append(provenances, Provenance{p, sorted[0], sorted[1:]})
+	}
+	sort.Slice(provenances, func(i, j int) bool {
+		return provenances[i].Property < provenances[j].Property
+	})
+	return provenances
+}
+
+// Explain returns why the property of the element has its value, or false
+// when no declaration sets it.
+func (c *Cascade) Explain(el Element, inline Declarations, property string) (Provenance, bool) {
+	property = strings.ToLower(property)
+	entries := []CascadeEntry{}
+	for _, e := range c.Entries(el, inline) {
+		if strings.EqualFold(e.Declaration.Property, property) {
+			entries = append(entries, e)
+		}
+	}
+	if len(entries) == 0 {
+		return Provenance{}, false
+	}
+	sorted := SortCascade(entries)
+	return Provenance{property, sorted[0], sorted[1:]}, true
+}
@@ -0,0 +1,40 @@
+package css
+
+import "testing"
+
+func TestCascadeExplain(t *testing.T) {
+	_, sheet, err := Bundle(
+		SourceFile{Name: "base.css", Data: []byte("p {\n\tcolor: black;\n}\n")},
+		SourceFile{Name: "page.css", Data: []byte("#intro {\n\tcolor: green;\n}\np {\n\tmargin: 0;\n}\n")},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &Cascade{}
+	c.AddStylesheet(sheet, OriginAuthor)
+	el := &SimpleElement{Tag: "p", ID: "intro"}
+
+	p, ok := c.Explain(el, nil, "Color")
+	if !ok {
+		t.Fatal("color not set")
+	}
+	if want := "color: green from #intro at page.css:1:1 (specificity 1,0,0), overriding 1 declaration"; p.String() != want {
+		t.Errorf("got %q, want %q", p, want)
+	}
+	if len(p.Overridden) != 1 || p.Overridden[0].Rule.Location.File != "base.css" {
+		t.Errorf("wrong overridden declarations %+v", p.Overridden)
+	}
+
+	inline := Declarations{NewDeclaration("margin", "4px")}
+	p, _ = c.Explain(el, inline, "margin")
+	if want := "margin: 4px from the style attribute, overriding 1 declaration"; p.String() != want {
+		t.Errorf("got %q, want %q", p, want)
+	}
+
+	if _, ok := c.Explain(el, nil, "padding"); ok {
+		t.Error("padding explained")
+	}
+	if got := c.Provenances(el, inline); len(got) != 2 || got[0].Property != "color" || got[1].Property != "margin" {
+		t.Errorf("wrong provenances %v", got)
+	}
+}
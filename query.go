@@ -0,0 +1,149 @@
+package css
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Predicate reports whether a declaration of a rule matches a query.
+type Predicate func(r *StyleRule, d Declaration) bool
+
+// Match is a declaration found by Find with the rule containing it.
+type Match struct {
+	Rule        *StyleRule
+	Declaration Declaration
+}
+
+// Find returns the declarations matching all of the predicates, in
+// document order. Without predicates every declaration matches.
+func (sheet *Stylesheet) Find(predicates ...Predicate) []Match {
+	matches := []Match{}
+	for _, r := range sheet.Rules {
+		for _, d := range r.Declarations {
+			if And(predicates...)(r, d) {
+				matches = append(matches, Match{r, d})
+			}
+		}
+	}
+	return matches
+}
+
+// And matches the declarations matching all of the predicates.
+func And(predicates ...Predicate) Predicate {
+	return func(r *StyleRule, d Declaration) bool {
+		for _, p := range predicates {
+			if !p(r, d) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Or matches the declarations matching any of the predicates.
+func Or(predicates ...Predicate) Predicate {
+	return func(r *StyleRule, d Declaration) bool {
+		for _, p := range predicates {
+			if p(r, d) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Not matches the declarations not matching the predicate.
+func Not(p Predicate) Predicate {
+	return func(r *StyleRule, d Declaration) bool {
+		return !p(r, d)
+	}
+}
+
+// SelectorContains matches the declarations of the rules whose selector
+// contains s.
+func SelectorContains(s string) Predicate {
+	return func(r *StyleRule, _ Declaration) bool {
+		return strings.Contains(string(r.Selector), s)
+	}
+}
+
+// SelectorMatches matches the declarations of the rules whose selector
+// matches the regular expression.
+func SelectorMatches(re *regexp.Regexp) Predicate {
+	return func(r *StyleRule, _ Declaration) bool {
+		return re.MatchString(string(r.Selector))
+	}
+}
+
+// Property matches the declarations of the property, ignoring case.
+func Property(name string) Predicate {
+	return func(_ *StyleRule, d Declaration) bool {
+		return strings.EqualFold(d.Property, name)
+	}
+}
+
+// Value matches the declarations whose value, without !important, is
+// accepted by fn.
+func Value(fn func(value string) bool) Predicate {
+	return func(_ *StyleRule, d Declaration) bool {
+		return fn(d.Value)
+	}
+}
+
+// ValueMatches matches the declarations whose value, without !important,
+// matches the regular expression.
+func ValueMatches(re *regexp.Regexp) Predicate {
+	return Value(re.MatchString)
+}
+
+// InAtRule matches the declarations of the rules nested in an at-rule
+// whose prelude starts with prefix, such as "@media" or "@media print".
+func InAtRule(prefix string) Predicate {
+	return func(r *StyleRule, _ Declaration) bool {
+		for _, at := range r.AtRules {
+			if strings.HasPrefix(string(at), prefix) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// ReplaceValues rewrites the values of the declarations of the stylesheet.
+// matcher is called with the property and the value, without !important,
+// of every declaration, and returns the new value and true to replace it.
+// The replacement is parsed as a declaration value: it can add the
+// !important flag, and the flag of the declaration is kept otherwise.
+// It returns the number of replaced declarations. When a replacement is
+// not a valid value, such as one containing "}", an error is returned and
+// the stylesheet is left unchanged.
+func ReplaceValues(sheet *Stylesheet, matcher func(property, value string) (string, bool)) (int, error) {
+	type replacement struct {
+		d    *Declaration
+		with Declaration
+	}
+	replacements := []replacement{}
+	for _, r := range sheet.Rules {
+		for i := range r.Declarations {
+			d := &r.Declarations[i]
+			value, ok := matcher(d.Property, d.Value)
+			if !ok {
+				continue
+			}
+			with := NewDeclaration(d.Property, value)
+			if !validValue(with.Value) {
+				return 0, fmt.Errorf("invalid value %q for %s in %s", value, d.Property, r.Selector)
+			}
+			if d.Important && !with.Important {
+				with = NewDeclaration(d.Property, with.Value+" !important")
+			}
+			with.Pos = d.Pos
+			replacements = append(replacements, replacement{d, with})
+		}
+	}
+	for _, r := range replacements {
+		*r.d = r.with
+	}
+	return len(replacements), nil
+}
@@ -0,0 +1,101 @@
+package css
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestFind(t *testing.T) {
+	sheet, err := UnmarshalStylesheet([]byte(`.btn {
+	color: red;
+	margin: 0;
+}
+@media print {
+	.btn {
+		color: black;
+	}
+	.nav {
+		display: none;
+	}
+}
+.btn-large {
+	color: #f00;
+	padding: 10px;
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		query []Predicate
+		want  []string
+	}{
+		{"All", nil, []string{"color", "margin", "color", "display", "color", "padding"}},
+		{"Property", []Predicate{Property("COLOR")}, []string{"color", "color", "color"}},
+		{"SelectorAndProperty", []Predicate{SelectorContains("btn-"), Property("color")}, []string{"color"}},
+		{"SelectorRegexp", []Predicate{SelectorMatches(regexp.MustCompile(`^\.btn$`))}, []string{"color", "margin", "color"}},
+		{"Value", []Predicate{Value(func(v string) bool { return strings.HasPrefix(v, "#") })}, []string{"color"}},
+		{"ValueRegexp", []Predicate{ValueMatches(regexp.MustCompile(`^\d`))}, []string{"margin", "padding"}},
+		{"InAtRule", []Predicate{InAtRule("@media print")}, []string{"color", "display"}},
+		{"NotInAtRule", []Predicate{Not(InAtRule("@media")), Property("color")}, []string{"color", "color"}},
+		{"Or", []Predicate{Or(Property("margin"), Property("padding"))}, []string{"margin", "padding"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			matches := sheet.Find(test.query...)
+			got := []string{}
+			for _, m := range matches {
+				got = append(got, m.Declaration.Property)
+			}
+			if strings.Join(got, " ") != strings.Join(test.want, " ") {
+				t.Fatalf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+
+	m := sheet.Find(InAtRule("@media"), Property("display"))
+	if len(m) != 1 || m[0].Rule.Selector != ".nav" || m[0].Rule.AtRules[0] != "@media print" {
+		t.Fatalf("unexpected rule context %v", m)
+	}
+}
+
+func TestReplaceValues(t *testing.T) {
+	sheet, _ := UnmarshalStylesheet([]byte(`.a { font-family: "Old Sans", sans-serif; color: #ff0000 !important; }
+@media print { .b { border: 1px solid #FF0000; background: white; } }
+`))
+	n, err := ReplaceValues(sheet, func(property, value string) (string, bool) {
+		if strings.Contains(strings.ToLower(value), "#ff0000") {
+			return strings.NewReplacer("#ff0000", "#c00", "#FF0000", "#c00").Replace(value), true
+		}
+		if property == "font-family" {
+			return strings.Replace(value, `"Old Sans"`, `"New Sans"`, 1), true
+		}
+		if property == "background" {
+			return "black !important", true
+		}
+		return "", false
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 4 {
+		t.Errorf("replaced %d values, want 4", n)
+	}
+	a, b := Declarations(sheet.Rules[0].Declarations), Declarations(sheet.Rules[1].Declarations)
+	if got := a.String() + "; " + b.String(); got != `font-family: "New Sans", sans-serif; color: #c00 !important; border: 1px solid #c00; background: black !important` {
+		t.Errorf("got %s", got)
+	}
+	if d, _ := a.Get("color"); d.Raw != "#c00 !important" || d.Pos.Line != 1 {
+		t.Errorf("got %+v", d)
+	}
+
+	if _, err := ReplaceValues(sheet, func(string, string) (string, bool) { return "red; }", true }); err == nil {
+		t.Error("expected an error for an invalid value")
+	}
+	if a[0].Value != `"New Sans", sans-serif` {
+		t.Error("stylesheet changed by a failed replacement")
+	}
+}
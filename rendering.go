@@ -0,0 +1,84 @@
+package css
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxWillChange is the longest will-change property list not reported by
+// AuditRendering.
+const maxWillChange = 3
+
+// RenderingReport lists the rendering hints of a stylesheet, for
+// rendering performance reviews.
+type RenderingReport struct {
+	// WillChange are the will-change declarations.
+	WillChange []Match
+	// Containment are the contain and content-visibility declarations.
+	Containment []Match
+	Issues      []Issue
+}
+
+// AuditRendering returns the will-change, contain and content-visibility
+// declarations of the stylesheet. It reports will-change: all, will-change
+// lists of more than three properties, will-change on the universal
+// selector, since every layer costs memory, and content-visibility: auto
+// without contain-intrinsic-size, which makes the page jump while
+// scrolling.
+func AuditRendering(sheet *Stylesheet) RenderingReport {
+	report := RenderingReport{WillChange: []Match{}, Containment: []Match{}, Issues: []Issue{}}
+	issue := func(m Match, message string, severity int) {
+		report.Issues = append(report.Issues, Issue{
+			Lint:     "rendering",
+			Message:  message,
+			Selector: m.Rule.Selector,
+			Property: m.Declaration.Property,
+			Pos:      m.Declaration.Pos,
+			Severity: severity,
+		})
+	}
+
+	for _, r := range sheet.Rules {
+		for _, d := range r.Declarations {
+			m := Match{r, d}
+			value := strings.ToLower(strings.TrimSpace(d.Value))
+			switch strings.ToLower(d.Property) {
+			case "will-change":
+				report.WillChange = append(report.WillChange, m)
+				properties := splitTopLevel(value, ',')
+				for i := range properties {
+					properties[i] = strings.TrimSpace(properties[i])
+				}
+				switch {
+				case value == "auto":
+				case containsString(properties, "all"):
+					issue(m, "will-change: all promotes every property", 3)
+				case len(properties) > maxWillChange:
+					issue(m, fmt.Sprintf("will-change lists %d properties", len(properties)), 2)
+				}
+				if value != "auto" && containsString(r.Selector.Group().strings(), "*") {
+					issue(m, "will-change on the universal selector", 3)
+				}
+			case "contain":
+				report.Containment = append(report.Containment, m)
+			case "content-visibility":
+				report.Containment = append(report.Containment, m)
+				if value == "auto" && !hasIntrinsicSize(r) {
+					issue(m, "content-visibility: auto without contain-intrinsic-size", 1)
+				}
+			}
+		}
+	}
+	return report
+}
+
+// hasIntrinsicSize reports whether the rule sets a contain-intrinsic-size
+// property.
+func hasIntrinsicSize(r *StyleRule) bool {
+	for _, d := range r.Declarations {
+		if strings.HasPrefix(strings.ToLower(d.Property), "contain-intrinsic-") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package css
+
+import "testing"
+
+func TestAuditRendering(t *testing.T) {
+	sheet, _ := UnmarshalStylesheet([]byte(`div.menu {
+	will-change: transform, opacity;
+}
+div.slider {
+	will-change: all;
+}
+div.card {
+	will-change: transform, opacity, top, left;
+	contain: layout paint;
+}
+*, div.x {
+	will-change: transform;
+}
+section {
+	content-visibility: auto;
+}
+article {
+	content-visibility: auto;
+	contain-intrinsic-size: auto 500px;
+}
+div.reset {
+	will-change: auto;
+}
+`))
+	report := AuditRendering(sheet)
+	if len(report.WillChange) != 5 || len(report.Containment) != 3 {
+		t.Errorf("expected 5 will-change and 3 containment declarations, got %d and %d",
+			len(report.WillChange), len(report.Containment))
+	}
+
+	expected := []struct {
+		message string
+		line    int
+	}{
+		{"will-change: all promotes every property", 5},
+		{"will-change lists 4 properties", 8},
+		{"will-change on the universal selector", 12},
+		{"content-visibility: auto without contain-intrinsic-size", 15},
+	}
+	if len(report.Issues) != len(expected) {
+		t.Fatalf("expected %d issues, got %v", len(expected), report.Issues)
+	}
+	for i, e := range expected {
+		if issue := report.Issues[i]; issue.Message != e.message || issue.Pos.Line != e.line {
+			t.Errorf("expected %s at line %d, got %v", e.message, e.line, issue)
+		}
+	}
+}
@@ -0,0 +1,274 @@
+package css
+
+import (
+	"sort"
+	"strings"
+)
+
+// SimpleElement is an Element described by its tag, id, classes and
+// attributes, for resolving styles without a document tree.
+type SimpleElement struct {
+	Tag     string
+	ID      string
+	Classes []string
+	Attrs   map[string]string
+	// NamespaceURI is the namespace of the element, HTMLNamespace when
+	// empty.
+	NamespaceURI string
+	// ParentElement is the parent of the element, or nil.
+	ParentElement *SimpleElement
+}
+
+func (e *SimpleElement) TagName() string {
+	return e.Tag
+}
+
+func (e *SimpleElement) Attr(name string) (string, bool) {
+	switch {
+	case name == "id" && e.ID != "":
+		return e.ID, true
+	case name == "class" && len(e.Classes) > 0:
+		return strings.Join(e.Classes, " "), true
+	}
+	v, ok := e.Attrs[name]
+	return v, ok
+}
+
+func (e *SimpleElement) Namespace() string {
+	if e.NamespaceURI == "" {
+		return HTMLNamespace
+	}
+	return e.NamespaceURI
+}
+
+func (e *SimpleElement) Parent() Element {
+	if e.ParentElement == nil {
+		return nil
+	}
+	return e.ParentElement
+}
+
+func (e *SimpleElement) PrevSibling() Element { return nil }
+func (e *SimpleElement) NextSibling() Element { return nil }
+
+// Cascade resolves the declarations applying to elements from the
+// registered default stylesheets and the author stylesheet it was created
+// with. Origins, specificity, source order, !important, style attributes
+// and cascade layers are applied as in SortCascade. The zero Cascade has
+// no stylesheets, not even the default ones.
+type Cascade struct {
+	// Environment evaluates the @media rules. When nil, the rules nested
+	// in @media rules are ignored.
+	Environment *MediaEnvironment
+
+	sheets []*cascadeSheet
+	order  int
+	media  *MediaEvaluator
+	// layers numbers the layers of all the stylesheets in order of first
+	// appearance, and ranks maps those numbers to the order of the layers
+	// in the cascade.
+	layers map[string]int
+	ranks  map[int]int
+}
+
+// cascadeSheet is a stylesheet of a cascade with the cascade data of its
+// rules.
+type cascadeSheet struct {
+	origin Origin
+	index  *RuleIndex
+	groups map[*StyleRule][]complexSelector
+	orders map[*StyleRule]int
+	// layers are the numbers of the layers of the rules in
+	// Cascade.layers.
+	layers map[*StyleRule]int
+}
+
+// NewCascade returns a cascade for the author stylesheets, after the
+// default stylesheets registered with RegisterDefaultStylesheet. The
+// stylesheets are in source order, such as the document order of the
+// stylesheets of an HTML page. Cascade layers are ordered by their first
+// @layer block or, for stylesheets returned by ParseStylesheet, by their
+// first @layer statement if it comes first, across all the stylesheets.
+func NewCascade(sheets ...*Stylesheet) *Cascade {
+	c := &Cascade{}
+	for _, d := range DefaultStylesheets() {
+		c.AddStylesheet(d.Stylesheet, d.Origin)
+	}
+	for _, sheet := range sheets {
+		c.AddStylesheet(sheet, OriginAuthor)
+	}
+	return c
+}
+
+// AddStylesheet adds a stylesheet of the origin to the cascade. Its
+// declarations come after those of the stylesheets added before in
+// source order.
+func (c *Cascade) AddStylesheet(sheet *Stylesheet, origin Origin) {
+	cs := &cascadeSheet{
+		origin: origin,
+		index:  NewRuleIndex(sheet),
+		groups: map[*StyleRule][]complexSelector{},
+		orders: map[*StyleRule]int{},
+		layers: map[*StyleRule]int{},
+	}
+
+	if c.layers == nil {
+		c.layers = map[string]int{}
+	}
+	var (
+		addLayer = func(name string) int {
+			if _, ok := c.layers[name]; !ok {
+				c.layers[name] = len(c.layers) + 1
+			}
+			return c.layers[name]
+		}
+		statements = sheet.Statements
+	)
+	for _, r := range sheet.Rules {
+		for len(statements) > 0 && statements[0].Source.Start < r.Source.Start {
+			if prelude := string(statements[0].Prelude); atRuleName(prelude) == "layer" {
+				for _, name := range strings.Split(strings.TrimSpace(prelude)[len("@layer"):], ",") {
+					addLayer(strings.TrimSpace(name))
+				}
+			}
+			statements = statements[1:]
+		}
+
+		if group, err := parseSelectorGroup(string(r.Selector)); err == nil {
+			cs.groups[r] = group
+		}
+		cs.orders[r] = c.order
+		c.order += len(r.Declarations)
+
+		names := []string{}
+		for _, at := range r.AtRules {
+			if atRuleName(string(at)) == "layer" {
+				names = append(names, strings.TrimSpace(strings.TrimSpace(string(at))[len("@layer"):]))
+				// nested layers are ordered inside their parent, which is
+				// registered first
+				cs.layers[r] = addLayer(strings.Join(names, "."))
+			}
+		}
+	}
+
+	c.ranks = layerRanks(c.layers)
+	c.sheets = append(c.sheets, cs)
+}
+
+// layerRanks maps the numbers of the layers, given in order of first
+// appearance, to their position in the cascade, where the sublayers of a
+// layer come before the declarations of the layer itself.
+func layerRanks(layers map[string]int) map[int]int {
+	names := make([]string, 0, len(layers))
+	for name := range layers {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return layerLess(layers, names[i], names[j]) })
+	ranks := make(map[int]int, len(names))
+	for i, name := range names {
+		ranks[layers[name]] = i + 1
+	}
+	return ranks
+}
+
+// layerLess reports whether the layer a comes before b: sibling layers
+// are ordered by first appearance and a layer comes after its sublayers.
+func layerLess(layers map[string]int, a, b string) bool {
+	pa, pb := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(pa) && i < len(pb); i++ {
+		if pa[i] != pb[i] {
+			return layers[strings.Join(pa[:i+1], ".")] < layers[strings.Join(pb[:i+1], ".")]
+		}
+	}
+	// one is the parent of the other, the parent comes last
+	return len(pa) > len(pb)
+}
+
+// Entries returns the declarations applying to the element as cascade
+// entries: the presentation attributes of SVG elements, the declarations
+// of the matching rules, then the inline declarations of its style
+// attribute.
+func (c *Cascade) Entries(el Element, inline Declarations) []CascadeEntry {
+	entries := presentationEntries(el)
+	for _, cs := range c.sheets {
+		for _, r := range cs.index.MatchingRules(el) {
+			if !c.applies(r) {
+				continue
+			}
+			specificity := [3]int{}
+			for _, sel := range cs.groups[r] {
+				if !sel.matches(el, MatchRightToLeft) {
+					continue
+				}
+				a, b, cc := sel.specificity()
+				if s := [3]int{a, b, cc}; specificityLess(specificity, s) {
+					specificity = s
+				}
+			}
+			for i, d := range r.Declarations {
+				entries = append(entries, CascadeEntry{
+					Declaration: d,
+					Origin:      cs.origin,
+					Layer:       c.ranks[cs.layers[r]],
+					Specificity: specificity,
+					Order:       cs.orders[r] + i,
+					Rule:        r,
+				})
+			}
+		}
+	}
+	for i, d := range inline {
+		entries = append(entries, CascadeEntry{Declaration: d, Origin: OriginAuthor, Inline: true, Order: c.order + i})
+	}
+	return entries
+}
+
+// ComputedStyle returns the computed style of the element, see
+// ComputeStyle. parent is the computed style of its parent element, or
+// nil for the root element.
+func (c *Cascade) ComputedStyle(el Element, inline Declarations, parent ComputedStyle) ComputedStyle {
+	return ComputeStyle(c.Entries(el, inline), parent)
+}
+
+// Resolve returns the declaration winning the cascade for every property
+// set on the element, sorted by property. Properties are compared case
+// insensitively.
+func (c *Cascade) Resolve(el Element, inline Declarations) Declarations {
+	provenances := c.Provenances(el, inline)
+	winners := make(Declarations, len(provenances))
+	for i, p := range provenances {
+		winners[i] = p.Winner.Declaration
+	}
+	return winners
+}
+
+// applies reports whether the @media rules around the rule match the
+// environment.
+func (c *Cascade) applies(r *StyleRule) bool {
+	conditions := r.mediaConditions()
+	if len(conditions) == 0 {
+		return true
+	}
+	if c.Environment == nil {
+		return false
+	}
+	if c.media == nil {
+		c.media = NewMediaEvaluator()
+	}
+	for _, condition := range conditions {
+		if !c.media.Matches(condition, *c.Environment) {
+			return false
+		}
+	}
+	return true
+}
+
+// specificityLess reports whether the specificity a is lower than b.
+func specificityLess(a, b [3]int) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return false
+}
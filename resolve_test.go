@@ -0,0 +1,94 @@
+package css
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCascadeResolve(t *testing.T) {
+	sheet, err := ParseStylesheet(strings.NewReader(`
+@layer base, theme;
+p {
+	color: red;
+	margin: 0;
+}
+p.note {
+	color: blue;
+}
+#intro {
+	color: green;
+}
+p {
+	color: black;
+	padding: 1px !important;
+}
+@layer theme {
+	p {
+		font-size: 20px;
+	}
+}
+@layer base {
+	p.note {
+		font-size: 10px;
+	}
+}
+@media print {
+	p {
+		margin: 5px;
+	}
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	el := &SimpleElement{Tag: "p", ID: "intro", Classes: []string{"note"}}
+	inline, err := UnmarshalStyle([]byte("padding: 2px; margin: 3px"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewCascade(sheet)
+	got := c.Resolve(el, inline)
+	want := map[string]string{
+		"color":     "green",
+		"font-size": "20px",
+		"margin":    "3px",
+		"padding":   "1px",
+		// from HTMLStylesheet
+		"display":       "block",
+		"margin-top":    "1em",
+		"margin-bottom": "1em",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d declarations, want %d: %v", len(got), len(want), got)
+	}
+	for _, d := range got {
+		if d.Value != want[d.Property] {
+			t.Errorf("%s: got %q, want %q", d.Property, d.Value, want[d.Property])
+		}
+	}
+
+	c.Environment = &MediaEnvironment{Type: "print"}
+	if margin, _ := c.Resolve(el, nil).Get("margin"); margin.Value != "5px" {
+		t.Errorf("print margin: got %q", margin.Value)
+	}
+}
+
+func TestCascadeLayersAcrossStylesheets(t *testing.T) {
+	var sheets []*Stylesheet
+	for _, src := range []string{
+		`@layer a, b; @layer b { p { color: red; } }`,
+		`@layer c { p { margin: 0; } } @layer d { p { padding: 0; } } @layer a { p { color: blue; } }`,
+	} {
+		sheet, err := ParseStylesheet(strings.NewReader(src))
+		if err != nil {
+			t.Fatal(err)
+		}
+		sheets = append(sheets, sheet)
+	}
+	c := NewCascade(sheets...)
+	// b is declared in the first stylesheet after a, so it wins
+	if color, _ := c.Resolve(&SimpleElement{Tag: "p"}, nil).Get("color"); color.Value != "red" {
+		t.Errorf("got color %q, want red", color.Value)
+	}
+}
@@ -0,0 +1,176 @@
+package css
+
+import (
+	"hash/fnv"
+	"strings"
+)
+
+// RuleIndex finds the rules of a stylesheet matching an element without
+// evaluating every selector. Rules are bucketed by the id, class or tag
+// of their key selector, the rightmost compound, so only the buckets of
+// the element are tried, and selectors whose ancestors need ids, classes
+// or tags missing from the element's ancestors are rejected with a bloom
+// filter before being matched.
+type RuleIndex struct {
+	ids       map[string][]indexedSelector
+	classes   map[string][]indexedSelector
+	tags      map[string][]indexedSelector
+	universal []indexedSelector
+	rules     []*StyleRule
+}
+
+// indexedSelector is a complex selector of a rule in a RuleIndex.
+type indexedSelector struct {
+	sel complexSelector
+	// rule is the index of the rule in RuleIndex.rules.
+	rule int
+	// ancestors are the bloom filter hashes of the ids, classes and tags
+	// required on the ancestors of the matched element.
+	ancestors []uint32
+}
+
+// NewRuleIndex indexes the style rules of the stylesheet. Rules with an
+// invalid selector and at-rules are skipped.
+func NewRuleIndex(sheet *Stylesheet) *RuleIndex {
+	idx := &RuleIndex{
+		ids:     map[string][]indexedSelector{},
+		classes: map[string][]indexedSelector{},
+		tags:    map[string][]indexedSelector{},
+		rules:   sheet.Rules,
+	}
+	for i, r := range sheet.Rules {
+		if strings.HasPrefix(string(r.Selector), "@") {
+			continue
+		}
+		group, err := parseSelectorGroup(string(r.Selector))
+		if err != nil {
+			continue
+		}
+		for _, sel := range group {
+			entry := indexedSelector{sel: sel, rule: i, ancestors: ancestorHashes(sel)}
+			key := sel.compounds[len(sel.compounds)-1]
+			switch {
+			case len(key.ids) > 0:
+				idx.ids[key.ids[0]] = append(idx.ids[key.ids[0]], entry)
+			case len(key.classes) > 0:
+				idx.classes[key.classes[0]] = append(idx.classes[key.classes[0]], entry)
+			case key.tag != "" && key.tag != "*":
+				tag := strings.ToLower(key.tag)
+				idx.tags[tag] = append(idx.tags[tag], entry)
+			default:
+				idx.universal = append(idx.universal, entry)
+			}
+		}
+	}
+	return idx
+}
+
+// MatchingRules returns the rules matching the element in document order.
+func (idx *RuleIndex) MatchingRules(el Element) []*StyleRule {
+	var (
+		filter  = newAncestorFilter(el)
+		matched = make([]bool, len(idx.rules))
+	)
+	try := func(entries []indexedSelector) {
+		for _, e := range entries {
+			if matched[e.rule] || !filter.mayContain(e.ancestors) {
+				continue
+			}
+			if e.sel.matches(el, MatchRightToLeft) {
+				matched[e.rule] = true
+			}
+		}
+	}
+
+	if id, ok := el.Attr("id"); ok {
+		try(idx.ids[id])
+	}
+	if class, ok := el.Attr("class"); ok {
+		for _, c := range strings.Fields(class) {
+			try(idx.classes[c])
+		}
+	}
+	try(idx.tags[strings.ToLower(el.TagName())])
+	try(idx.universal)
+
+	rules := []*StyleRule{}
+	for i, ok := range matched {
+		if ok {
+			rules = append(rules, idx.rules[i])
+		}
+	}
+	return rules
+}
+
+// ancestorHashes returns the hashes of the ids, classes and tags of the
+// compounds joined to the key selector by descendant and child
+// combinators, which must all be found on ancestors of a matching
+// element. Compounds reached through sibling combinators are ignored.
+func ancestorHashes(sel complexSelector) []uint32 {
+	hashes := []uint32{}
+	for i := len(sel.compounds) - 2; i >= 0; i-- {
+		if c := sel.combinators[i]; c == '+' || c == '~' {
+			// the compounds before a sibling combinator match siblings,
+			// not ancestors
+			break
+		}
+		compound := sel.compounds[i]
+		for _, id := range compound.ids {
+			hashes = append(hashes, ancestorHash('#', id))
+		}
+		for _, class := range compound.classes {
+			hashes = append(hashes, ancestorHash('.', class))
+		}
+		if compound.tag != "" && compound.tag != "*" {
+			hashes = append(hashes, ancestorHash(' ', strings.ToLower(compound.tag)))
+		}
+	}
+	return hashes
+}
+
+func ancestorHash(kind byte, name string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte{kind})
+	h.Write([]byte(name))
+	return h.Sum32()
+}
+
+// ancestorFilter is a bloom filter of the ids, classes and tags of the
+// ancestors of an element. It can report false positives but never false
+// negatives.
+type ancestorFilter [8]uint64
+
+func newAncestorFilter(el Element) *ancestorFilter {
+	f := &ancestorFilter{}
+	for p := el.Parent(); p != nil; p = p.Parent() {
+		if id, ok := p.Attr("id"); ok {
+			f.add(ancestorHash('#', id))
+		}
+		if class, ok := p.Attr("class"); ok {
+			for _, c := range strings.Fields(class) {
+				f.add(ancestorHash('.', c))
+			}
+		}
+		f.add(ancestorHash(' ', strings.ToLower(p.TagName())))
+	}
+	return f
+}
+
+// add sets the two bits of the hash, taken from its low and high halves.
+func (f *ancestorFilter) add(h uint32) {
+	for _, bit := range [2]uint32{h & 0x1ff, (h >> 16) & 0x1ff} {
+		f[bit/64] |= 1 << (bit % 64)
+	}
+}
+
+// mayContain reports whether all hashes may have been added.
+func (f *ancestorFilter) mayContain(hashes []uint32) bool {
+	for _, h := range hashes {
+		for _, bit := range [2]uint32{h & 0x1ff, (h >> 16) & 0x1ff} {
+			if f[bit/64]&(1<<(bit%64)) == 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,100 @@
+package css
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestRuleIndex(t *testing.T) {
+	sheet, err := UnmarshalStylesheet([]byte(`p {
+	color: red;
+}
+#main p.note {
+	color: blue;
+}
+article p {
+	color: green;
+}
+* {
+	margin: 0;
+}
+h1 + p {
+	top: 0;
+}
+[lang] {
+	left: 0;
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	idx := NewRuleIndex(sheet)
+
+	root := &testElement{tag: "div", attrs: map[string]string{"id": "main"}}
+	h1 := root.append("h1", nil)
+	p := root.append("p", map[string]string{"class": "note", "lang": "en"})
+
+	selectors := func(rules []*StyleRule) string {
+		s := []string{}
+		for _, r := range rules {
+			s = append(s, string(r.Selector))
+		}
+		return strings.Join(s, ", ")
+	}
+	if got := selectors(idx.MatchingRules(p)); got != "p, #main p.note, *, h1 + p, [lang]" {
+		t.Errorf("unexpected rules for p: %s", got)
+	}
+	if got := selectors(idx.MatchingRules(h1)); got != "*" {
+		t.Errorf("unexpected rules for h1: %s", got)
+	}
+}
+
+func TestAncestorFilter(t *testing.T) {
+	root := &testElement{tag: "section", attrs: map[string]string{"class": "a b"}}
+	el := root.append("div", nil).append("span", nil)
+	f := newAncestorFilter(el)
+	sel, _ := parseComplexSelector("section.b div > span")
+	if !f.mayContain(ancestorHashes(sel)) {
+		t.Fatal("filter rejected the ancestors of the element")
+	}
+	sel, _ = parseComplexSelector("article span")
+	if f.mayContain(ancestorHashes(sel)) {
+		t.Fatal("filter accepted a missing ancestor")
+	}
+}
+
+// benchmarkDocument returns a tree of nested divs with some classes and
+// a stylesheet of rules mostly not matching its leaves.
+func benchmarkDocument(rules int) (*Stylesheet, *testElement) {
+	var b strings.Builder
+	for i := 0; i < rules; i++ {
+		fmt.Fprintf(&b, "div.c%d span.d%d {\n\tcolor: red;\n}\narticle p.x%d {\n\tmargin: 0;\n}\n", i, i, i)
+	}
+	b.WriteString("div span {\n\tcolor: blue;\n}\n")
+	sheet, _ := UnmarshalStylesheet([]byte(b.String()))
+
+	el := &testElement{tag: "html"}
+	for i := 0; i < 20; i++ {
+		el = el.append("div", map[string]string{"class": fmt.Sprintf("c%d", i)})
+	}
+	return sheet, el.append("span", map[string]string{"class": "d1000"})
+}
+
+func BenchmarkMatchingRules(b *testing.B) {
+	sheet, el := benchmarkDocument(500)
+	b.Run("Naive", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, r := range sheet.Rules {
+				r.Selector.Matches(el)
+			}
+		}
+	})
+	b.Run("Index", func(b *testing.B) {
+		idx := NewRuleIndex(sheet)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			idx.MatchingRules(el)
+		}
+	})
+}
@@ -0,0 +1,134 @@
+package css
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var rPropertyName = regexp.MustCompile(`^(--|-?)[a-zA-Z_][a-zA-Z0-9_-]*$`)
+
+// urlProperties are the properties whose values can load resources, so
+// SetPropertySafe accepts urls in them.
+var urlProperties = map[string]bool{
+	"background":          true,
+	"background-image":    true,
+	"border-image":        true,
+	"border-image-source": true,
+	"content":             true,
+	"cursor":              true,
+	"list-style":          true,
+	"list-style-image":    true,
+	"mask":                true,
+	"mask-image":          true,
+	"-webkit-mask":        true,
+	"-webkit-mask-image":  true,
+	"shape-outside":       true,
+}
+
+// urlFunctions are the functions loading the urls of their arguments.
+var urlFunctions = []string{"url", "src", "image", "image-set", "-webkit-image-set", "cross-fade"}
+
+// SetPropertySafe sets the property of the rule to a value provided by a
+// user, such as a color picked in a theme editor, so apps can accept user
+// customization without the risk of css injection. The value is rejected
+// if it could end the declaration or the rule, such as with ";" or a
+// brace, or hide its content, with comments, escapes, control characters
+// or an unclosed string, if it has !important, expression() or a
+// javascript: url, and if it loads a url while the property doesn't take
+// urls, as in "color: url(...)". Strings are written again with double
+// quotes and "<" escaped, so they can't end a surrounding <style>
+// element. The first declaration of the property is replaced and the
+// others are removed, or the declaration is appended if there is none.
+func SetPropertySafe(rule *StyleRule, property, userValue string) error {
+	if !rPropertyName.MatchString(property) {
+		return fmt.Errorf("invalid property %q", property)
+	}
+	value, err := safeValue(strings.ToLower(property), strings.TrimSpace(userValue))
+	if err != nil {
+		return err
+	}
+
+	d := NewDeclaration(property, value)
+	ds := make([]Declaration, 0, len(rule.Declarations)+1)
+	set := false
+	for _, old := range rule.Declarations {
+		if !strings.EqualFold(old.Property, property) {
+			ds = append(ds, old)
+		} else if !set {
+			d.Pos = old.Pos
+			ds = append(ds, d)
+			set = true
+		}
+	}
+	if !set {
+		ds = append(ds, d)
+	}
+	rule.Declarations = ds
+	return nil
+}
+
+// safeValue validates the user value of the property and returns it with
+// its strings quoted again.
+func safeValue(property, value string) (string, error) {
+	if value == "" {
+		return "", fmt.Errorf("empty value for %s", property)
+	}
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case c < 0x20 || c == 0x7f:
+			return "", fmt.Errorf("control character in value %q", value)
+		case c == '"' || c == '\'':
+			end := scanString(value, i)
+			if end-i < 2 || value[end-1] != c {
+				return "", fmt.Errorf("unclosed string in value %q", value)
+			}
+			b.WriteString(quoteString(value[i+1 : end-1]))
+			i = end - 1
+			continue
+		case strings.IndexByte(";{}<>!@\\", c) >= 0,
+			c == '/' && i+1 < len(value) && value[i+1] == '*':
+			return "", fmt.Errorf("unsafe character %q in value %q", c, value)
+		}
+		b.WriteByte(c)
+	}
+	if !validValue(value) {
+		return "", fmt.Errorf("invalid value %q", value)
+	}
+	if err := checkFunctions(property, parseComponents(value)); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// checkFunctions rejects the functions of the components, including
+// nested ones, that are unsafe in a value of the property.
+func checkFunctions(property string, components []ComponentValue) error {
+	for _, c := range components {
+		if c.Type != ComponentFunction {
+			continue
+		}
+		name := strings.ToLower(c.Value)
+		switch {
+		case name == "expression":
+			return fmt.Errorf("unsafe function %s()", c.Value)
+		case containsString(urlFunctions, name):
+			if !urlProperties[property] {
+				return fmt.Errorf("url not allowed in %s", property)
+			}
+			for _, arg := range c.Args {
+				url := strings.ToLower(strings.TrimSpace(arg.Value))
+				if arg.Type == ComponentString &&
+					(strings.HasPrefix(url, "javascript:") || strings.HasPrefix(url, "vbscript:")) {
+					return fmt.Errorf("unsafe url %q", arg.Value)
+				}
+			}
+		}
+		if err := checkFunctions(property, c.Args); err != nil {
+			return err
+		}
+	}
+	return nil
+}
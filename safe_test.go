@@ -0,0 +1,58 @@
+package css
+
+import "testing"
+
+func TestSetPropertySafe(t *testing.T) {
+	sheet, err := UnmarshalStylesheet([]byte(".theme {\n\tcolor: red;\n\tmargin: 0;\n\tCOLOR: blue;\n}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rule := sheet.Rules[0]
+
+	valid := []struct {
+		property, value, want string
+	}{
+		{"color", " #336699 ", "color: #336699; margin: 0"},
+		{"font-family", `'Open Sans', serif`, `color: #336699; margin: 0; font-family: "Open Sans", serif`},
+		{"content", `"</style>"`, `color: #336699; margin: 0; font-family: "Open Sans", serif; content: "\3c /style>"`},
+		{"background-image", `url("img/bg.png")`, `color: #336699; margin: 0; font-family: "Open Sans", serif; content: "\3c /style>"; background-image: url("img/bg.png")`},
+		{"--accent", "rgb(0, 128, 255)", `color: #336699; margin: 0; font-family: "Open Sans", serif; content: "\3c /style>"; background-image: url("img/bg.png"); --accent: rgb(0, 128, 255)`},
+	}
+	for _, c := range valid {
+		if err := SetPropertySafe(rule, c.property, c.value); err != nil {
+			t.Fatalf("%s: %s: %v", c.property, c.value, err)
+		}
+		if got := Declarations(rule.Declarations).String(); got != c.want {
+			t.Fatalf("%s: %s: expected %q, got %q", c.property, c.value, c.want, got)
+		}
+	}
+	if rule.Declarations[0].Pos.Line != 2 {
+		t.Fatalf("replaced declaration should keep its position, got %v", rule.Declarations[0].Pos)
+	}
+
+	invalid := []struct {
+		property, value string
+	}{
+		{"color", "red; background: url(x)"},
+		{"color", "red } body { color: blue"},
+		{"color", "red !important"},
+		{"color", "red /* comment */"},
+		{"color", `\72 ed`},
+		{"color", "red\nblue"},
+		{"color", `"unclosed`},
+		{"color", ""},
+		{"color", "url(x.png)"},
+		{"--accent", "url(x.png)"},
+		{"width", "expression(alert(1))"},
+		{"background", "url(javascript:alert(1))"},
+		{"background", `image-set("JavaScript:alert(1)" 1x)`},
+		{"color", "rgb(0, 0, 0"},
+		{"color: red; x", "red"},
+		{"", "red"},
+	}
+	for _, c := range invalid {
+		if err := SetPropertySafe(rule, c.property, c.value); err == nil {
+			t.Fatalf("%s: %q should be rejected", c.property, c.value)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package css
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// ScanSelectors reads the css from r and returns the selectors of its
+// style rules in document order, including duplicates and the rules
+// nested in at-rules. At-rule preludes and keyframe selectors are
+// skipped. Unlike Selectors, it doesn't tokenize nor build blocks, so it
+// is much cheaper for tools only needing the selectors. Whitespace in the
+// selectors is collapsed to single spaces.
+func ScanSelectors(r io.Reader) ([]Rule, error) {
+	selectors := []Rule{}
+	err := scanItems(r, func(item string, block bool, parents []string) {
+		if !block || strings.HasPrefix(item, "@") {
+			return
+		}
+		if len(parents) > 0 && strings.HasSuffix(atRuleName(parents[len(parents)-1]), "keyframes") {
+			return
+		}
+		selectors = append(selectors, Rule(item))
+	})
+	return selectors, err
+}
+
+// ScanProperties reads the css from r and returns the property names of
+// its declarations in document order, including duplicates, without
+// tokenizing nor building blocks.
+func ScanProperties(r io.Reader) ([]string, error) {
+	properties := []string{}
+	err := scanItems(r, func(item string, block bool, parents []string) {
+		if block || len(parents) == 0 {
+			return
+		}
+		if i := strings.IndexByte(item, ':'); i > 0 {
+			properties = append(properties, strings.TrimSpace(item[:i]))
+		}
+	})
+	return properties, err
+}
+
+// scanItems reads the css from r in a single pass and calls fn with every
+// block prelude, with block set, and every statement, with the preludes of
+// the blocks containing it. Comments are dropped and whitespace outside of
+// strings is collapsed.
+func scanItems(r io.Reader, fn func(item string, block bool, parents []string)) error {
+	var (
+		br      = bufio.NewReader(r)
+		item    strings.Builder
+		parents = []string{}
+		depth   = 0 // parentheses and brackets
+		quote   = byte(0)
+		space   = false
+	)
+	flush := func() string {
+		s := strings.TrimSpace(item.String())
+		item.Reset()
+		space = false
+		return s
+	}
+
+	for {
+		c, err := br.ReadByte()
+		if err == io.EOF {
+			if s := flush(); s != "" {
+				fn(s, false, parents)
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case quote != 0:
+			item.WriteByte(c)
+			if c == '\\' {
+				if next, err := br.ReadByte(); err == nil {
+					item.WriteByte(next)
+				}
+			} else if c == quote {
+				quote = 0
+			}
+			continue
+		case c == '/':
+			if next, _ := br.Peek(1); len(next) == 1 && next[0] == '*' {
+				if err := skipComment(br); err != nil {
+					return err
+				}
+				space = true
+				continue
+			}
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f':
+			space = true
+			continue
+		}
+
+		if space && item.Len() > 0 {
+			item.WriteByte(' ')
+		}
+		space = false
+
+		switch {
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '(' || c == '[':
+			depth++
+		case (c == ')' || c == ']') && depth > 0:
+			depth--
+		case depth > 0:
+		case c == '{':
+			s := flush()
+			fn(s, true, parents)
+			parents = append(parents, s)
+			continue
+		case c == ';' || c == '}':
+			if s := flush(); s != "" {
+				fn(s, false, parents)
+			}
+			if c == '}' && len(parents) > 0 {
+				parents = parents[:len(parents)-1]
+			}
+			continue
+		}
+		item.WriteByte(c)
+	}
+}
+
+// skipComment reads up to and including the end of the comment whose "/"
+// was just read.
+func skipComment(br *bufio.Reader) error {
+	br.ReadByte() // the "*" after "/"
+	prev := byte(0)
+	for {
+		c, err := br.ReadByte()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if prev == '*' && c == '/' {
+			return nil
+		}
+		prev = c
+	}
+}
@@ -0,0 +1,71 @@
+package css
+
+import (
+	"strings"
+	"testing"
+)
+
+const scanExample = `@charset "utf-8";
+/* header { } */
+.a, .b > p {
+	color: red;
+	background: url("a;b{c}.png");
+}
+@media (min-width: 600px) {
+	#main:hover
+	  span {
+		margin: 0 !important;
+	}
+}
+@keyframes spin {
+	from {
+		transform: rotate(0deg);
+	}
+}
+a[title="x{y}"] {
+	content: "a: b"
+}
+`
+
+func TestScanSelectors(t *testing.T) {
+	selectors, err := ScanSelectors(strings.NewReader(scanExample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Rule{".a, .b > p", "#main:hover span", `a[title="x{y}"]`}
+	if len(selectors) != len(expected) {
+		t.Fatalf("got %q, expected %q", selectors, expected)
+	}
+	for i := range expected {
+		if selectors[i] != expected[i] {
+			t.Errorf("selector %d: got %q, expected %q", i, selectors[i], expected[i])
+		}
+	}
+}
+
+func TestScanProperties(t *testing.T) {
+	properties, err := ScanProperties(strings.NewReader(scanExample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "color background margin transform content"
+	if got := strings.Join(properties, " "); got != expected {
+		t.Fatalf("got %q, expected %q", got, expected)
+	}
+}
+
+func BenchmarkScanSelectors(b *testing.B) {
+	src := bundle(1000)
+	b.Run("Selectors", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			Selectors(Tokenize(src))
+		}
+	})
+	b.Run("ScanSelectors", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := ScanSelectors(strings.NewReader(string(src))); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
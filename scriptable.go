@@ -0,0 +1,200 @@
+package css
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// rExpression matches the start of an expression() call, allowing
+// whitespace and comments before the parenthesis.
+var rExpression = regexp.MustCompile(`(?i)expression\s*\(`)
+
+// StripScriptable removes the declarations that can run script in older
+// browsers: expression() values, behavior properties and -moz-binding. It
+// returns the result with the removed declarations.
+//
+// StripScriptable is a minimal hardening pass for user supplied css, not a
+// full sanitizer.
+func StripScriptable(b []byte) ([]byte, []Declaration) {
+	var (
+		spans    = [][2]int{}
+		stripped = []Declaration{}
+	)
+	for _, span := range declarationSpans(b) {
+		d := span.declaration(b)
+		if !isScriptable(d) {
+			continue
+		}
+		spans = append(spans, [2]int{span.start, span.end})
+		stripped = append(stripped, d)
+	}
+	return removeSpans(b, spans), stripped
+}
+
+// isScriptable reports whether the declaration can run script. Comments
+// are removed and escapes decoded first, as in \65 xpression( or
+// expr/**/ession(. Old browsers also ignored the backslash before a hex
+// letter, reading beh\avior as behavior, so both readings are checked.
+func isScriptable(d Declaration) bool {
+	property, value := removeComments(d.Property), removeComments(d.Raw)
+	for _, unescape := range []func(string) string{unescapeCSS, stripBackslashes} {
+		switch unescape(property) {
+		case "behavior", "-ms-behavior", "-moz-binding":
+			return true
+		}
+		if rExpression.MatchString(unescape(value)) {
+			return true
+		}
+	}
+	return false
+}
+
+// stripBackslashes removes every backslash of s and lowercases the
+// result.
+func stripBackslashes(s string) string {
+	return strings.ToLower(strings.Replace(s, "\\", "", -1))
+}
+
+// removeComments removes the comments of s, so expr/**/ession reads as
+// expression.
+func removeComments(s string) string {
+	return rComments.ReplaceAllString(s, "")
+}
+
+// unescapeCSS decodes the escapes of s as described by the CSS Syntax
+// spec and lowercases the result: a backslash followed by up to six hex
+// digits and an optional whitespace is the code point, \0 and invalid
+// code points are U+FFFD, an escaped line break is removed and any other
+// escaped character is the character itself.
+func unescapeCSS(s string) string {
+	if !strings.Contains(s, "\\") {
+		return strings.ToLower(s)
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch {
+		case isHexByte(s[i]):
+			end := i
+			for end < len(s) && end-i < 6 && isHexByte(s[end]) {
+				end++
+			}
+			r, _ := strconv.ParseUint(s[i:end], 16, 32)
+			if r == 0 || r > unicode.MaxRune || r >= 0xd800 && r <= 0xdfff {
+				r = unicode.ReplacementChar
+			}
+			b.WriteRune(rune(r))
+			switch {
+			case strings.HasPrefix(s[end:], "\r\n"):
+				end++
+			case end < len(s) && strings.IndexByte(" \t\n\r\f", s[end]) >= 0:
+			default:
+				end--
+			}
+			i = end
+		case s[i] == '\n' || s[i] == '\f':
+		case s[i] == '\r':
+			if i+1 < len(s) && s[i+1] == '\n' {
+				i++
+			}
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return strings.ToLower(b.String())
+}
+
+// unescapeIdent removes the backslashes escaping letters, which browsers
+// ignore in identifiers: e\xpression is expression.
+func unescapeIdent(s string) string {
+	if !strings.Contains(s, "\\") {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && !isHexByte(s[i+1]) && s[i+1] != '\n' {
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+func isHexByte(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+// declarationSpan is the byte range of a declaration inside a block,
+// including its terminating semicolon.
+type declarationSpan struct {
+	start, end int
+	tokens     []TokenEntry
+}
+
+// declaration returns the declaration in the span of b.
+func (s declarationSpan) declaration(b []byte) Declaration {
+	raw := strings.TrimSpace(string(b[s.start:s.end]))
+	raw = strings.TrimSuffix(raw, ";")
+	// the property is split into several tokens by escapes such as \61 a
+	property := s.tokens[0].value
+	for _, tok := range s.tokens[1:] {
+		if tok.typ() == tokenStyleSeparator {
+			break
+		}
+		property += tok.space + tok.value
+	}
+	value := ""
+	if i := strings.IndexByte(raw, ':'); i >= 0 {
+		value = strings.TrimSpace(raw[i+1:])
+	}
+	d := NewDeclaration(property, value)
+	d.Pos = offsetPosition(b, s.start)
+	return d
+}
+
+// declarationSpans returns the span of every declaration inside a block in
+// b, in document order.
+func declarationSpans(b []byte) []declarationSpan {
+	var (
+		spans = []declarationSpan{}
+		item  = []TokenEntry{}
+		depth = 0
+		end   = 0
+	)
+
+	e := Tokenize(b).Front()
+	for e != nil {
+		tok := e.Value.(TokenEntry)
+
+		switch tok.typ() {
+		case tokenBlockStart:
+			depth++
+			item = nil
+		case tokenStatementEnd, tokenBlockEnd:
+			if len(item) > 0 && depth > 0 && !strings.HasPrefix(item[0].value, "@") {
+				stop := end
+				if tok.typ() == tokenStatementEnd {
+					stop = tok.pos.Offset + 1
+				}
+				spans = append(spans, declarationSpan{item[0].pos.Offset, stop, item})
+			}
+			if tok.typ() == tokenBlockEnd && depth > 0 {
+				depth--
+			}
+			item = nil
+		default:
+			item = append(item, tok)
+		}
+
+		end = tok.pos.Offset + len(tok.value)
+		e = e.Next()
+	}
+
+	return spans
+}
@@ -0,0 +1,74 @@
+package css
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStripScriptable(t *testing.T) {
+	ex := `.a {
+	width: expression(document.body.clientWidth > 800 ? "800px" : "auto");
+	behavior: url(script.htc);
+	color: red;
+}
+.b {
+	-moz-binding: url("xbl.xml#exec");
+	height: e\xpression(alert(1));
+	top: 0
+}
+`
+	out, stripped := StripScriptable([]byte(ex))
+	want := []string{"width", "behavior", "-moz-binding", "height"}
+	if len(stripped) != len(want) {
+		t.Fatalf("got %d stripped declarations %v, want %d", len(stripped), stripped, len(want))
+	}
+	for i, p := range want {
+		if stripped[i].Property != p {
+			t.Errorf("stripped %d: got %q, want %q", i, stripped[i].Property, p)
+		}
+	}
+	if stripped[0].Pos.Line != 2 || stripped[2].Pos.Line != 7 {
+		t.Errorf("unexpected positions %v and %v", stripped[0].Pos, stripped[2].Pos)
+	}
+
+	css, err := Unmarshal(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(css[".a"]) != 1 || css[".a"]["color"] != "red" || len(css[".b"]) != 1 || css[".b"]["top"] != "0" {
+		t.Fatalf("unexpected result %v:\n%s", css, out)
+	}
+	if strings.Contains(string(out), "url") {
+		t.Fatalf("scriptable url left in:\n%s", out)
+	}
+}
+
+func TestStripScriptableEscapes(t *testing.T) {
+	tests := []string{
+		`width: \65 xpression(alert(1))`,
+		`width: \000065xpression(alert(1))`,
+		`width: \45 XPRESSION\28 alert(1))`,
+		`width: expr/**/ession(alert(1))`,
+		`width: e\xpression(alert(1))`,
+		`beh\avior: url(x.htc)`,
+		`beh\61 vior: url(x.htc)`,
+		`-moz-bind\ing: url(x.xml)`,
+	}
+	for _, test := range tests {
+		out, stripped := StripScriptable([]byte("a { " + test + "; color: red; }"))
+		if len(stripped) != 1 {
+			t.Errorf("%s: got stripped declarations %v", test, stripped)
+			continue
+		}
+		if string(out) != "a {  color: red; }" {
+			t.Errorf("%s: got %q", test, out)
+		}
+	}
+
+	if _, stripped := StripScriptable([]byte(`a { content: "\65 x"; width: expressions; }`)); len(stripped) != 0 {
+		t.Errorf("unexpected stripped declarations %v", stripped)
+	}
+	if s := unescapeCSS("\\0 a\\110000\\d800 \\\nb"); s != "�a��b" {
+		t.Errorf("unexpected unescaped string %q", s)
+	}
+}
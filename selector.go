@@ -0,0 +1,789 @@
+package css
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Element is a document element that selectors can be matched against.
+// Methods returning an Element must return a nil interface, not a typed
+// nil pointer, when there is no such element.
+type Element interface {
+	// TagName returns the local name of the element.
+	TagName() string
+	// Attr returns the value of the attribute and whether it is set.
+	Attr(name string) (string, bool)
+	// Parent returns the parent element.
+	Parent() Element
+	// PrevSibling returns the previous element sibling.
+	PrevSibling() Element
+	// NextSibling returns the next element sibling.
+	NextSibling() Element
+}
+
+// The namespaces of the elements of html documents.
+const (
+	HTMLNamespace   = "http://www.w3.org/1999/xhtml"
+	SVGNamespace    = "http://www.w3.org/2000/svg"
+	MathMLNamespace = "http://www.w3.org/1998/Math/MathML"
+)
+
+// NamespacedElement is an Element knowing its namespace URI, needed to
+// match selectors such as svg|a. Elements not implementing it are in the
+// HTMLNamespace.
+type NamespacedElement interface {
+	Element
+	Namespace() string
+}
+
+// elementNamespace returns the namespace URI of the element.
+func elementNamespace(el Element) string {
+	if n, ok := el.(NamespacedElement); ok {
+		return n.Namespace()
+	}
+	return HTMLNamespace
+}
+
+var errInvalidSelector = errors.New("invalid selector")
+
+// complexSelector is a chain of compound selectors joined by combinators.
+// combinators[i] joins compounds[i] and compounds[i+1] and is one of ' ',
+// '>', '+' or '~'.
+type complexSelector struct {
+	compounds   []compoundSelector
+	combinators []byte
+}
+
+// compoundSelector is a sequence of simple selectors without combinators.
+type compoundSelector struct {
+	tag string
+	// prefix is the namespace prefix of the type or universal selector,
+	// such as svg in svg|a, and prefixed is set when there is one,
+	// including the empty prefix of |a.
+	prefix   string
+	prefixed bool
+	// namespace is the namespace URI the element must be in, or nil if
+	// any namespace matches.
+	namespace *string
+	ids       []string
+	classes   []string
+	attrs     []attrSelector
+	pseudos   []pseudoSelector
+}
+
+// attrSelector is an attribute condition such as [type="text" i].
+type attrSelector struct {
+	name        string
+	op          string
+	value       string
+	insensitive bool
+}
+
+// pseudoSelector is a pseudo-class or, when element is set, a
+// pseudo-element.
+type pseudoSelector struct {
+	name    string
+	element bool
+	arg     string
+}
+
+// legacy pseudo-elements can be written with a single colon
+var legacyPseudoElements = map[string]bool{
+	"before":       true,
+	"after":        true,
+	"first-line":   true,
+	"first-letter": true,
+}
+
+// Specificity returns the specificity of the selector: the number of ids,
+// the number of classes, attributes and pseudo-classes, and the number of
+// types and pseudo-elements. For a selector group the highest specificity
+// is returned.
+func (rule Rule) Specificity() (a, b, c int) {
+	group, err := parseSelectorGroup(string(rule))
+	if err != nil {
+		return 0, 0, 0
+	}
+	return maxSpecificity(group)
+}
+
+// Specificity returns the specificity of the selector as defined by
+// Selectors Level 4, like Rule.Specificity: :where() counts nothing,
+// :is(), :not() and :has() count their most specific argument and
+// :nth-child(An+B of S) counts one pseudo-class plus S. Invalid selectors
+// have a specificity of zero.
+func Specificity(selector Rule) (a, b, c int) {
+	return selector.Specificity()
+}
+
+// Matches reports whether the element matches the selector. Selectors
+// with pseudo-elements or dynamic pseudo-classes such as :hover never
+// match.
+func (rule Rule) Matches(el Element) bool {
+	group, err := parseSelectorGroup(string(rule))
+	if err != nil || el == nil {
+		return false
+	}
+	return matchGroup(group, el)
+}
+
+// PseudoElements returns the pseudo-elements of the selector, always
+// written with two colons.
+func (rule Rule) PseudoElements() []string {
+	group, err := parseSelectorGroup(string(rule))
+	if err != nil {
+		return nil
+	}
+	names := []string{}
+	for _, sel := range group {
+		for _, compound := range sel.compounds {
+			for _, p := range compound.pseudos {
+				if p.element {
+					names = append(names, p.String())
+				}
+			}
+		}
+	}
+	return names
+}
+
+// SelectorGroup is the list of selectors of a rule such as
+// "h1, h2, .title".
+type SelectorGroup []Rule
+
+// Group returns the comma separated selectors of the rule, trimmed and
+// with whitespace collapsed outside of strings and brackets, so
+// [title="x  y"] is kept as is. Commas inside functional pseudo-classes
+// such as :is(a, b) don't separate selectors.
+func (rule Rule) Group() SelectorGroup {
+	group := SelectorGroup{}
+	for _, part := range splitTopLevel(string(rule), ',') {
+		if part = collapseSpaces(part); part != "" {
+			group = append(group, Rule(part))
+		}
+	}
+	return group
+}
+
+func (g SelectorGroup) String() string {
+	return strings.Join(g.strings(), ", ")
+}
+
+// strings returns the selectors of the group as strings.
+func (g SelectorGroup) strings() []string {
+	s := make([]string, len(g))
+	for i, rule := range g {
+		s[i] = string(rule)
+	}
+	return s
+}
+
+// String returns the selector serialized as described by the CSSOM spec,
+// or the rule unchanged if it is not a valid selector.
+func (rule Rule) String() string {
+	group, err := parseSelectorGroup(string(rule))
+	if err != nil {
+		return string(rule)
+	}
+	parts := make([]string, len(group))
+	for i, sel := range group {
+		parts[i] = sel.String()
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (sel complexSelector) String() string {
+	var b strings.Builder
+	for i, compound := range sel.compounds {
+		if i > 0 {
+			if sel.combinators[i-1] == ' ' {
+				b.WriteByte(' ')
+			} else {
+				b.WriteString(" " + string(sel.combinators[i-1]) + " ")
+			}
+		}
+		b.WriteString(compound.String())
+	}
+	return b.String()
+}
+
+func (compound compoundSelector) String() string {
+	var b strings.Builder
+	if compound.prefixed {
+		b.WriteString(compound.prefix + "|")
+	}
+	b.WriteString(compound.tag)
+	for _, id := range compound.ids {
+		b.WriteString("#" + id)
+	}
+	for _, class := range compound.classes {
+		b.WriteString("." + class)
+	}
+	for _, attr := range compound.attrs {
+		b.WriteString(attr.String())
+	}
+	for _, p := range compound.pseudos {
+		b.WriteString(p.String())
+	}
+	if b.Len() == 0 {
+		return "*"
+	}
+	return b.String()
+}
+
+func (attr attrSelector) String() string {
+	if attr.op == "" {
+		return "[" + attr.name + "]"
+	}
+	s := "[" + attr.name + attr.op + quoteString(attr.value)
+	if attr.insensitive {
+		s += " i"
+	}
+	return s + "]"
+}
+
+func (p pseudoSelector) String() string {
+	s := ":" + p.name
+	if p.element {
+		s = ":" + s
+	}
+	if p.arg != "" {
+		s += "(" + p.arg + ")"
+	}
+	return s
+}
+
+// parseSelectorGroup parses a comma separated list of selectors.
+func parseSelectorGroup(text string) ([]complexSelector, error) {
+	group := []complexSelector{}
+	for _, part := range splitTopLevel(text, ',') {
+		sel, err := parseComplexSelector(part)
+		if err != nil {
+			return nil, err
+		}
+		group = append(group, sel)
+	}
+	return group, nil
+}
+
+// splitTopLevel splits text on sep, ignoring separators inside quotes,
+// brackets and parenthesis.
+func splitTopLevel(text string, sep byte) []string {
+	var (
+		parts = []string{}
+		depth = 0
+		quote = byte(0)
+		start = 0
+	)
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '(' || c == '[':
+			depth++
+		case c == ')' || c == ']':
+			depth--
+		case c == sep && depth == 0:
+			parts = append(parts, text[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, text[start:])
+}
+
+func parseComplexSelector(text string) (complexSelector, error) {
+	var (
+		sel        = complexSelector{}
+		combinator = byte(0)
+		i          = 0
+	)
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return sel, errInvalidSelector
+	}
+	for i < len(text) {
+		c := text[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f':
+			if combinator == 0 {
+				combinator = ' '
+			}
+			i++
+		case c == '>' || c == '+' || c == '~':
+			if len(sel.compounds) == 0 || (combinator != 0 && combinator != ' ') {
+				return sel, errInvalidSelector
+			}
+			combinator = c
+			i++
+		default:
+			compound, n, err := parseCompoundSelector(text[i:])
+			if err != nil {
+				return sel, err
+			}
+			if len(sel.compounds) > 0 {
+				if combinator == 0 {
+					return sel, errInvalidSelector
+				}
+				sel.combinators = append(sel.combinators, combinator)
+			}
+			sel.compounds = append(sel.compounds, compound)
+			combinator = 0
+			i += n
+		}
+	}
+	if combinator != 0 && combinator != ' ' {
+		return sel, errInvalidSelector
+	}
+	return sel, nil
+}
+
+// parseCompoundSelector parses the compound selector at the start of text
+// and returns it with the number of bytes read.
+func parseCompoundSelector(text string) (compoundSelector, int, error) {
+	var (
+		compound = compoundSelector{}
+		i        = 0
+	)
+	for i < len(text) {
+		c := text[i]
+		switch {
+		case (c == '*' || isNameByte(c) || c == '|') && i == 0:
+			j := i + 1
+			if c != '*' {
+				j = scanName(text, i)
+			}
+			compound.tag = text[i:j]
+			if j < len(text) && text[j] == '|' && (j+1 >= len(text) || text[j+1] != '=') {
+				compound.prefix, compound.prefixed = compound.tag, true
+				j++
+				k := j + 1
+				if j >= len(text) || text[j] != '*' {
+					k = scanName(text, j)
+				}
+				if k == j {
+					return compound, i, errInvalidSelector
+				}
+				compound.tag = text[j:k]
+				if compound.prefix == "" {
+					// |a matches the elements without a namespace
+					compound.namespace = new(string)
+				}
+				j = k
+			}
+			if compound.tag == "" {
+				return compound, i, errInvalidSelector
+			}
+			i = j
+		case c == '#' || c == '.':
+			j := scanName(text, i+1)
+			if j == i+1 {
+				return compound, i, errInvalidSelector
+			}
+			if c == '#' {
+				compound.ids = append(compound.ids, text[i+1:j])
+			} else {
+				compound.classes = append(compound.classes, text[i+1:j])
+			}
+			i = j
+		case c == '[':
+			// the value may contain brackets when quoted
+			j := i + 1
+			for j < len(text) && text[j] != ']' {
+				if text[j] == '"' || text[j] == '\'' {
+					j = scanString(text, j)
+					continue
+				}
+				j++
+			}
+			if j >= len(text) {
+				return compound, i, errInvalidSelector
+			}
+			attr, err := parseAttrSelector(text[i+1 : j])
+			if err != nil {
+				return compound, i, err
+			}
+			compound.attrs = append(compound.attrs, attr)
+			i = j + 1
+		case c == ':':
+			p := pseudoSelector{}
+			j := i + 1
+			if j < len(text) && text[j] == ':' {
+				p.element = true
+				j++
+			}
+			k := scanName(text, j)
+			if k == j {
+				return compound, i, errInvalidSelector
+			}
+			p.name = strings.ToLower(text[j:k])
+			if legacyPseudoElements[p.name] {
+				p.element = true
+			}
+			if k < len(text) && text[k] == '(' {
+				end := scanParens(text, k)
+				if end > len(text) || text[end-1] != ')' {
+					return compound, i, errInvalidSelector
+				}
+				p.arg = strings.TrimSpace(text[k+1 : end-1])
+				k = end
+			}
+			compound.pseudos = append(compound.pseudos, p)
+			i = k
+		default:
+			if i == 0 {
+				return compound, i, errInvalidSelector
+			}
+			return compound, i, nil
+		}
+	}
+	return compound, i, nil
+}
+
+// parseAttrSelector parses the text between the brackets of an attribute
+// selector.
+func parseAttrSelector(text string) (attrSelector, error) {
+	attr := attrSelector{}
+	text = strings.TrimSpace(text)
+	j := scanName(text, 0)
+	if j == 0 {
+		return attr, errInvalidSelector
+	}
+	attr.name = text[:j]
+	rest := strings.TrimSpace(text[j:])
+	if rest == "" {
+		return attr, nil
+	}
+
+	for _, op := range []string{"~=", "|=", "^=", "$=", "*=", "="} {
+		if strings.HasPrefix(rest, op) {
+			attr.op = op
+			rest = strings.TrimSpace(rest[len(op):])
+			break
+		}
+	}
+	if attr.op == "" || rest == "" {
+		return attr, errInvalidSelector
+	}
+
+	if rest[0] == '"' || rest[0] == '\'' {
+		end := scanString(rest, 0)
+		if end < 2 || rest[end-1] != rest[0] {
+			return attr, errInvalidSelector
+		}
+		attr.value = unquote(rest[:end])
+		rest = rest[end:]
+	} else {
+		end := scanName(rest, 0)
+		attr.value = rest[:end]
+		rest = rest[end:]
+	}
+
+	switch strings.ToLower(strings.TrimSpace(rest)) {
+	case "":
+	case "i":
+		attr.insensitive = true
+	case "s":
+	default:
+		return attr, errInvalidSelector
+	}
+	return attr, nil
+}
+
+// maxSpecificity returns the highest specificity of the selectors.
+func maxSpecificity(group []complexSelector) (a, b, c int) {
+	for _, sel := range group {
+		sa, sb, sc := sel.specificity()
+		if sa > a || (sa == a && (sb > b || (sb == b && sc > c))) {
+			a, b, c = sa, sb, sc
+		}
+	}
+	return a, b, c
+}
+
+func (sel complexSelector) specificity() (a, b, c int) {
+	for _, compound := range sel.compounds {
+		if compound.tag != "" && compound.tag != "*" {
+			c++
+		}
+		a += len(compound.ids)
+		b += len(compound.classes) + len(compound.attrs)
+		for _, p := range compound.pseudos {
+			switch {
+			case p.element:
+				c++
+			case p.name == "where":
+			case p.name == "is" || p.name == "not" || p.name == "has" || p.name == "matches":
+				group, err := parseSelectorGroup(p.arg)
+				if err == nil {
+					pa, pb, pc := maxSpecificity(group)
+					a, b, c = a+pa, b+pb, c+pc
+				}
+			case p.name == "nth-child" || p.name == "nth-last-child":
+				b++
+				if i := strings.Index(p.arg, " of "); i >= 0 {
+					group, err := parseSelectorGroup(p.arg[i+4:])
+					if err == nil {
+						pa, pb, pc := maxSpecificity(group)
+						a, b, c = a+pa, b+pb, c+pc
+					}
+				}
+			default:
+				b++
+			}
+		}
+	}
+	return a, b, c
+}
+
+func matchGroup(group []complexSelector, el Element) bool {
+	return matchGroupWith(group, el, MatchRightToLeft)
+}
+
+func matchGroupWith(group []complexSelector, el Element, strategy MatchStrategy) bool {
+	for _, sel := range group {
+		if sel.matches(el, strategy) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchBacktracking reports whether el matches the selector ending with
+// compound i, trying every possible element for every combinator.
+func (sel complexSelector) matchBacktracking(i int, el Element) bool {
+	if !sel.compounds[i].match(el) {
+		return false
+	}
+	if i == 0 {
+		return true
+	}
+	switch sel.combinators[i-1] {
+	case '>':
+		p := el.Parent()
+		return p != nil && sel.matchBacktracking(i-1, p)
+	case '+':
+		s := el.PrevSibling()
+		return s != nil && sel.matchBacktracking(i-1, s)
+	case '~':
+		for s := el.PrevSibling(); s != nil; s = s.PrevSibling() {
+			if sel.matchBacktracking(i-1, s) {
+				return true
+			}
+		}
+	default:
+		for p := el.Parent(); p != nil; p = p.Parent() {
+			if sel.matchBacktracking(i-1, p) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (compound compoundSelector) match(el Element) bool {
+	if compound.tag != "" && compound.tag != "*" && !strings.EqualFold(compound.tag, el.TagName()) {
+		return false
+	}
+	switch {
+	case compound.namespace != nil:
+		if elementNamespace(el) != *compound.namespace {
+			return false
+		}
+	case compound.prefixed && compound.prefix != "*":
+		// the prefix wasn't declared
+		return false
+	}
+	for _, id := range compound.ids {
+		if v, ok := el.Attr("id"); !ok || v != id {
+			return false
+		}
+	}
+	if len(compound.classes) > 0 {
+		v, _ := el.Attr("class")
+		classes := strings.Fields(v)
+		for _, class := range compound.classes {
+			if !containsString(classes, class) {
+				return false
+			}
+		}
+	}
+	for _, attr := range compound.attrs {
+		if !attr.match(el) {
+			return false
+		}
+	}
+	for _, p := range compound.pseudos {
+		if !p.match(el) {
+			return false
+		}
+	}
+	return true
+}
+
+func (attr attrSelector) match(el Element) bool {
+	v, ok := el.Attr(attr.name)
+	if !ok {
+		return false
+	}
+	want := attr.value
+	if attr.insensitive {
+		v, want = strings.ToLower(v), strings.ToLower(want)
+	}
+	switch attr.op {
+	case "":
+		return true
+	case "=":
+		return v == want
+	case "~=":
+		return containsString(strings.Fields(v), want)
+	case "|=":
+		return v == want || strings.HasPrefix(v, want+"-")
+	case "^=":
+		return want != "" && strings.HasPrefix(v, want)
+	case "$=":
+		return want != "" && strings.HasSuffix(v, want)
+	case "*=":
+		return want != "" && strings.Contains(v, want)
+	}
+	return false
+}
+
+func (p pseudoSelector) match(el Element) bool {
+	if p.element {
+		return false
+	}
+	switch p.name {
+	case "root":
+		return el.Parent() == nil
+	case "first-child":
+		return el.PrevSibling() == nil
+	case "last-child":
+		return el.NextSibling() == nil
+	case "only-child":
+		return el.PrevSibling() == nil && el.NextSibling() == nil
+	case "first-of-type":
+		return siblingIndex(el, true, true, nil) == 1
+	case "last-of-type":
+		return siblingIndex(el, false, true, nil) == 1
+	case "only-of-type":
+		return siblingIndex(el, true, true, nil) == 1 && siblingIndex(el, false, true, nil) == 1
+	case "nth-child", "nth-last-child", "nth-of-type", "nth-last-of-type":
+		arg := p.arg
+		var of []complexSelector
+		if i := strings.Index(arg, " of "); i >= 0 && strings.HasSuffix(p.name, "child") {
+			group, err := parseSelectorGroup(arg[i+4:])
+			if err != nil || !matchGroup(group, el) {
+				return false
+			}
+			arg, of = arg[:i], group
+		}
+		a, b, ok := parseNth(arg)
+		if !ok {
+			return false
+		}
+		forward := !strings.Contains(p.name, "last")
+		index := siblingIndex(el, forward, strings.HasSuffix(p.name, "type"), of)
+		return nthMatches(a, b, index)
+	case "not":
+		group, err := parseSelectorGroup(p.arg)
+		return err == nil && !matchGroup(group, el)
+	case "is", "where", "matches":
+		group, err := parseSelectorGroup(p.arg)
+		return err == nil && matchGroup(group, el)
+	case "link", "any-link":
+		_, ok := el.Attr("href")
+		tag := strings.ToLower(el.TagName())
+		return ok && (tag == "a" || tag == "area")
+	case "checked":
+		_, ok := el.Attr("checked")
+		return ok
+	case "disabled":
+		_, ok := el.Attr("disabled")
+		return ok
+	case "enabled":
+		_, ok := el.Attr("disabled")
+		return !ok
+	}
+	return false
+}
+
+// siblingIndex returns the 1-based index of el among its siblings,
+// counting from the start or the end. Only siblings of the same type, or
+// matching the selectors in of, are counted when requested.
+func siblingIndex(el Element, forward, sameType bool, of []complexSelector) int {
+	index := 1
+	next := Element.PrevSibling
+	if !forward {
+		next = Element.NextSibling
+	}
+	for s := next(el); s != nil; s = next(s) {
+		if sameType && !strings.EqualFold(s.TagName(), el.TagName()) {
+			continue
+		}
+		if of != nil && !matchGroup(of, s) {
+			continue
+		}
+		index++
+	}
+	return index
+}
+
+// parseNth parses the an+b notation of the nth pseudo-classes.
+func parseNth(arg string) (a, b int, ok bool) {
+	arg = strings.ToLower(strings.Join(strings.Fields(arg), ""))
+	switch arg {
+	case "odd":
+		return 2, 1, true
+	case "even":
+		return 2, 0, true
+	}
+	n := strings.IndexByte(arg, 'n')
+	if n < 0 {
+		b, err := strconv.Atoi(arg)
+		return 0, b, err == nil
+	}
+	switch arg[:n] {
+	case "", "+":
+		a = 1
+	case "-":
+		a = -1
+	default:
+		var err error
+		if a, err = strconv.Atoi(arg[:n]); err != nil {
+			return 0, 0, false
+		}
+	}
+	if rest := arg[n+1:]; rest != "" {
+		var err error
+		if b, err = strconv.Atoi(rest); err != nil {
+			return 0, 0, false
+		}
+	}
+	return a, b, true
+}
+
+// nthMatches reports whether index is a*n+b for some n >= 0.
+func nthMatches(a, b, index int) bool {
+	if a == 0 {
+		return index == b
+	}
+	n := index - b
+	return n%a == 0 && n/a >= 0
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
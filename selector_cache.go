@@ -0,0 +1,135 @@
+package css
+
+import (
+	"container/list"
+	"sync"
+)
+
+// CompiledSelector is a selector parsed once to be matched many times.
+type CompiledSelector struct {
+	group []complexSelector
+}
+
+// CompileSelector parses the selector for matching.
+func CompileSelector(rule Rule) (*CompiledSelector, error) {
+	group, err := parseSelectorGroup(string(rule))
+	if err != nil {
+		return nil, err
+	}
+	return &CompiledSelector{group}, nil
+}
+
+// Matches reports whether the element matches the selector, like
+// Rule.Matches.
+func (s *CompiledSelector) Matches(el Element) bool {
+	return el != nil && matchGroup(s.group, el)
+}
+
+// MatchesWith is like Matches, using the matching strategy. All
+// strategies give the same result, only their speed differs; it is
+// meant for tests and benchmarks.
+func (s *CompiledSelector) MatchesWith(el Element, strategy MatchStrategy) bool {
+	return el != nil && matchGroupWith(s.group, el, strategy)
+}
+
+// Specificity returns the specificity of the selector, like
+// Rule.Specificity.
+func (s *CompiledSelector) Specificity() (a, b, c int) {
+	return maxSpecificity(s.group)
+}
+
+// SelectorCacheStats are the counters of a SelectorCache.
+type SelectorCacheStats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	// Len is the number of selectors in the cache.
+	Len int
+}
+
+// HitRate returns the ratio of lookups found in the cache, between 0 and
+// 1.
+func (s SelectorCacheStats) HitRate() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// SelectorCache keeps the most recently used compiled selectors, keyed by
+// their text with whitespace normalized. It is safe for concurrent use.
+type SelectorCache struct {
+	mu    sync.Mutex
+	size  int
+	lru   *list.List
+	items map[string]*list.Element
+	stats SelectorCacheStats
+}
+
+// selectorCacheEntry is the value of the elements of SelectorCache.lru.
+type selectorCacheEntry struct {
+	key      string
+	selector *CompiledSelector
+	err      error
+}
+
+// NewSelectorCache returns a cache holding at most size selectors.
+func NewSelectorCache(size int) *SelectorCache {
+	return &SelectorCache{
+		size:  size,
+		lru:   list.New(),
+		items: map[string]*list.Element{},
+	}
+}
+
+// Compile returns the compiled selector from the cache, compiling and
+// adding it when missing. Invalid selectors are cached too, so they are
+// only parsed once.
+func (c *SelectorCache) Compile(rule Rule) (*CompiledSelector, error) {
+	// the whitespace of quoted attribute values is significant
+	key := collapseSpaces(string(rule))
+
+	c.mu.Lock()
+	if e, ok := c.items[key]; ok {
+		c.lru.MoveToFront(e)
+		c.stats.Hits++
+		entry := e.Value.(*selectorCacheEntry)
+		c.mu.Unlock()
+		return entry.selector, entry.err
+	}
+	c.stats.Misses++
+	c.mu.Unlock()
+
+	// compile without holding the lock, another goroutine may add the same
+	// selector meanwhile
+	selector, err := CompileSelector(rule)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.items[key]; !ok && c.size > 0 {
+		c.items[key] = c.lru.PushFront(&selectorCacheEntry{key, selector, err})
+		for c.lru.Len() > c.size {
+			oldest := c.lru.Back()
+			c.lru.Remove(oldest)
+			delete(c.items, oldest.Value.(*selectorCacheEntry).key)
+			c.stats.Evictions++
+		}
+	}
+	return selector, err
+}
+
+// Matches reports whether the element matches the selector, compiling it
+// through the cache.
+func (c *SelectorCache) Matches(rule Rule, el Element) bool {
+	s, err := c.Compile(rule)
+	return err == nil && s.Matches(el)
+}
+
+// Stats returns the counters of the cache.
+func (c *SelectorCache) Stats() SelectorCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.stats
+	stats.Len = c.lru.Len()
+	return stats
+}
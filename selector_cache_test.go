@@ -0,0 +1,64 @@
+package css
+
+import "testing"
+
+func TestSelectorCache(t *testing.T) {
+	root := &testElement{tag: "div"}
+	p := root.append("p", map[string]string{"class": "note"})
+
+	cache := NewSelectorCache(2)
+	if !cache.Matches("div > p.note", p) || !cache.Matches("div  >  p.note", p) {
+		t.Fatal("expected a match")
+	}
+	if cache.Matches("div > [", p) || cache.Matches("div > [", p) {
+		t.Fatal("invalid selector matched")
+	}
+	stats := cache.Stats()
+	if stats.Hits != 2 || stats.Misses != 2 || stats.Len != 2 || stats.HitRate() != 0.5 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+
+	// div > p.note is the least recently used and gets evicted
+	cache.Matches("span", p)
+	cache.Matches("div > p.note", p)
+	stats = cache.Stats()
+	if stats.Evictions != 2 || stats.Misses != 4 || stats.Len != 2 {
+		t.Fatalf("unexpected stats after eviction %+v", stats)
+	}
+
+	s, err := cache.Compile("#a, p.note")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a, b, c := s.Specificity(); a != 1 || b != 0 || c != 0 {
+		t.Fatalf("unexpected specificity %d,%d,%d", a, b, c)
+	}
+
+	// the whitespace of attribute values is kept
+	cache = NewSelectorCache(10)
+	title := root.append("p", map[string]string{"title": "x  y"})
+	if !cache.Matches(`p[title="x  y"]`, title) || cache.Matches(`p[title="x y"]`, title) {
+		t.Fatal("unexpected attribute value match")
+	}
+	if stats := cache.Stats(); stats.Misses != 2 || stats.Len != 2 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
+
+func BenchmarkSelectorCache(b *testing.B) {
+	root := &testElement{tag: "div"}
+	el := root.append("ul", nil).append("li", map[string]string{"class": "item"})
+	rule := Rule("div > ul li.item:first-child")
+
+	b.Run("Uncached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			rule.Matches(el)
+		}
+	})
+	b.Run("Cached", func(b *testing.B) {
+		cache := NewSelectorCache(16)
+		for i := 0; i < b.N; i++ {
+			cache.Matches(rule, el)
+		}
+	})
+}
@@ -0,0 +1,187 @@
+package css
+
+// Combinator joins two compound selectors of a Selector.
+type Combinator byte
+
+const (
+	// Descendant is the whitespace of "ul li".
+	Descendant Combinator = ' '
+	// Child is the > of "div > p".
+	Child Combinator = '>'
+	// NextSibling is the + of "h1 + p".
+	NextSibling Combinator = '+'
+	// SubsequentSibling is the ~ of "a ~ span".
+	SubsequentSibling Combinator = '~'
+)
+
+// String returns the name of the combinator, such as "child".
+func (c Combinator) String() string {
+	switch c {
+	case Descendant:
+		return "descendant"
+	case Child:
+		return "child"
+	case NextSibling:
+		return "next sibling"
+	case SubsequentSibling:
+		return "subsequent sibling"
+	}
+	return "unknown"
+}
+
+// Selector is a parsed complex selector: a chain of compound selectors
+// joined by combinators, such as "ul > li.item a".
+type Selector struct {
+	// Compounds are the compound selectors from left to right. The last
+	// one is the subject of the selector.
+	Compounds []Compound
+	// Combinators[i] joins Compounds[i] and Compounds[i+1].
+	Combinators []Combinator
+
+	sel complexSelector
+}
+
+// Compound is a sequence of simple selectors without combinators, such as
+// "a#home.nav".
+type Compound struct {
+	// Tag is the type selector, "*" for the universal selector, and empty
+	// when the compound has none.
+	Tag string
+	// Prefix is the namespace prefix of the type selector with its bar,
+	// such as "svg|" or "|", and empty when there is none.
+	Prefix     string
+	IDs        []string
+	Classes    []string
+	Attributes []Attribute
+	// Pseudos are the pseudo-classes and pseudo-elements in source order.
+	Pseudos []Pseudo
+
+	compound compoundSelector
+}
+
+// Attribute is an attribute selector such as [type="text" i].
+type Attribute struct {
+	Name string
+	// Operator is one of "=", "~=", "|=", "^=", "$=" and "*=", or empty
+	// for [name] which only requires the attribute to be present.
+	Operator string
+	// Value is the unquoted and unescaped value.
+	Value string
+	// Insensitive is set by the i flag, comparing values ignoring ASCII
+	// case.
+	Insensitive bool
+
+	attr attrSelector
+}
+
+// Pseudo is a pseudo-class such as :nth-child(2n+1) or a pseudo-element
+// such as ::first-line.
+type Pseudo struct {
+	// Name is the lower case name without the colons.
+	Name string
+	// Argument is the text between the parentheses of functional
+	// pseudo-classes, and empty for the others.
+	Argument string
+	// Element is set for pseudo-elements, including the legacy ones
+	// written with one colon such as :before.
+	Element bool
+}
+
+// ParseSelector parses the comma separated selectors of the rule.
+func ParseSelector(rule Rule) ([]Selector, error) {
+	group, err := parseSelectorGroup(string(rule))
+	if err != nil {
+		return nil, err
+	}
+	selectors := make([]Selector, len(group))
+	for i, sel := range group {
+		selectors[i] = newSelector(sel)
+	}
+	return selectors, nil
+}
+
+// Selectors returns the parsed selectors of the rule, see ParseSelector.
+func (r *StyleRule) Selectors() ([]Selector, error) {
+	return ParseSelector(r.Selector)
+}
+
+func newSelector(sel complexSelector) Selector {
+	s := Selector{
+		Compounds:   make([]Compound, len(sel.compounds)),
+		Combinators: make([]Combinator, len(sel.combinators)),
+		sel:         sel,
+	}
+	for i, c := range sel.combinators {
+		s.Combinators[i] = Combinator(c)
+	}
+	for i, compound := range sel.compounds {
+		c := Compound{
+			Tag:      compound.tag,
+			IDs:      compound.ids,
+			Classes:  compound.classes,
+			compound: compound,
+		}
+		if compound.prefixed {
+			c.Prefix = compound.prefix + "|"
+		}
+		for _, attr := range compound.attrs {
+			c.Attributes = append(c.Attributes, Attribute{
+				Name:        attr.name,
+				Operator:    attr.op,
+				Value:       attr.value,
+				Insensitive: attr.insensitive,
+				attr:        attr,
+			})
+		}
+		for _, p := range compound.pseudos {
+			c.Pseudos = append(c.Pseudos, Pseudo{Name: p.name, Argument: p.arg, Element: p.element})
+		}
+		s.Compounds[i] = c
+	}
+	return s
+}
+
+// String returns the selector serialized like Rule.String.
+func (s Selector) String() string {
+	return s.sel.String()
+}
+
+// Specificity returns the specificity of the selector, see
+// Rule.Specificity.
+func (s Selector) Specificity() (a, b, c int) {
+	return s.sel.specificity()
+}
+
+// Matches reports whether the element matches the selector, see
+// Rule.Matches.
+func (s Selector) Matches(el Element) bool {
+	return el != nil && s.sel.matches(el, MatchRightToLeft)
+}
+
+// Subject returns the last compound of the selector, the one matching
+// the selected elements.
+func (s Selector) Subject() Compound {
+	return s.Compounds[len(s.Compounds)-1]
+}
+
+// String returns the compound serialized like Rule.String.
+func (c Compound) String() string {
+	return c.compound.String()
+}
+
+// String returns the attribute selector with the value quoted.
+func (a Attribute) String() string {
+	return a.attr.String()
+}
+
+// Matches reports whether the attribute of the element satisfies the
+// condition.
+func (a Attribute) Matches(el Element) bool {
+	return a.attr.match(el)
+}
+
+// String returns the pseudo-class or pseudo-element, the latter always
+// written with two colons.
+func (p Pseudo) String() string {
+	return pseudoSelector{name: p.Name, element: p.Element, arg: p.Argument}.String()
+}
@@ -0,0 +1,145 @@
+package css
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSelector(t *testing.T) {
+	tests := []struct {
+		rule        Rule
+		tags        []string
+		combinators []Combinator
+	}{
+		{"div > p", []string{"div", "p"}, []Combinator{Child}},
+		{"ul   li", []string{"ul", "li"}, []Combinator{Descendant}},
+		{"h1+p", []string{"h1", "p"}, []Combinator{NextSibling}},
+		{"a ~ span", []string{"a", "span"}, []Combinator{SubsequentSibling}},
+		{"*", []string{"*"}, []Combinator{}},
+		{"ul li > .item ~ *", []string{"ul", "li", "", "*"}, []Combinator{Descendant, Child, SubsequentSibling}},
+	}
+	for _, test := range tests {
+		selectors, err := ParseSelector(test.rule)
+		if err != nil {
+			t.Fatalf("%s: %v", test.rule, err)
+		}
+		if len(selectors) != 1 {
+			t.Fatalf("%s: got %d selectors", test.rule, len(selectors))
+		}
+		sel := selectors[0]
+		tags := []string{}
+		for _, c := range sel.Compounds {
+			tags = append(tags, c.Tag)
+		}
+		if !reflect.DeepEqual(tags, test.tags) || !reflect.DeepEqual(sel.Combinators, test.combinators) {
+			t.Errorf("%s: got %q %q, want %q %q", test.rule, tags, sel.Combinators, test.tags, test.combinators)
+		}
+	}
+
+	if _, err := ParseSelector("div >"); err == nil {
+		t.Error("div >: no error")
+	}
+}
+
+func TestStyleRuleSelectors(t *testing.T) {
+	sheet, err := UnmarshalStylesheet([]byte("svg|a#home.nav.main, div > p {\n\tcolor: red;\n}\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	selectors, err := sheet.Rules[0].Selectors()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(selectors) != 2 {
+		t.Fatalf("got %d selectors", len(selectors))
+	}
+
+	subject := selectors[0].Subject()
+	if subject.Prefix != "svg|" || subject.Tag != "a" || !reflect.DeepEqual(subject.IDs, []string{"home"}) ||
+		!reflect.DeepEqual(subject.Classes, []string{"nav", "main"}) {
+		t.Errorf("wrong compound %+v", subject)
+	}
+	if got := selectors[1].String(); got != "div > p" {
+		t.Errorf("got %q", got)
+	}
+	if a, b, c := selectors[0].Specificity(); a != 1 || b != 2 || c != 1 {
+		t.Errorf("got specificity %d,%d,%d", a, b, c)
+	}
+
+	p := &testElement{tag: "p"}
+	p.parent = &testElement{tag: "div"}
+	if !selectors[1].Matches(p) || selectors[0].Matches(p) {
+		t.Error("wrong matches")
+	}
+}
+
+func TestSelectorAttributes(t *testing.T) {
+	selectors, err := ParseSelector(`input[type="text"][lang|=en][class~='a b'][href^="http"][href$=".pdf" i][title*="a]\"b"][disabled]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Attribute{
+		{Name: "type", Operator: "=", Value: "text"},
+		{Name: "lang", Operator: "|=", Value: "en"},
+		{Name: "class", Operator: "~=", Value: "a b"},
+		{Name: "href", Operator: "^=", Value: "http"},
+		{Name: "href", Operator: "$=", Value: ".pdf", Insensitive: true},
+		{Name: "title", Operator: "*=", Value: `a]"b`},
+		{Name: "disabled"},
+	}
+	got := selectors[0].Subject().Attributes
+	if len(got) != len(want) {
+		t.Fatalf("got %d attributes, want %d", len(got), len(want))
+	}
+	for i, attr := range got {
+		if attr.Name != want[i].Name || attr.Operator != want[i].Operator ||
+			attr.Value != want[i].Value || attr.Insensitive != want[i].Insensitive {
+			t.Errorf("attribute %d: got %+v, want %+v", i, attr, want[i])
+		}
+	}
+	if s := got[5].String(); s != `[title*="a]\"b"]` {
+		t.Errorf("got %s", s)
+	}
+
+	el := &testElement{tag: "a", attrs: map[string]string{"href": "http://a.com/DOC.PDF"}}
+	if !got[3].Matches(el) || !got[4].Matches(el) || got[0].Matches(el) {
+		t.Error("wrong matches")
+	}
+}
+
+func TestSelectorPseudos(t *testing.T) {
+	sheet, err := UnmarshalStylesheet([]byte("li:nth-child(2n+1):hover a:before, p::first-line { color: red; }\n" +
+		"@media print {\n\ta:not(.x):focus {\n\t\tcolor: blue;\n\t}\n}\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sheet.Rules) != 2 {
+		t.Fatalf("got %d rules, want 2", len(sheet.Rules))
+	}
+	if got := sheet.Rules[0].Selector; got != "li:nth-child(2n+1):hover a:before, p::first-line" {
+		t.Errorf("got selector %q", got)
+	}
+	if got := sheet.Rules[1].Selector; got != "a:not(.x):focus" {
+		t.Errorf("got nested selector %q", got)
+	}
+
+	selectors, err := sheet.Rules[0].Selectors()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]Pseudo{
+		{{Name: "nth-child", Argument: "2n+1"}, {Name: "hover"}},
+		{{Name: "before", Element: true}},
+	}
+	for i, c := range selectors[0].Compounds {
+		if !reflect.DeepEqual(c.Pseudos, want[i]) {
+			t.Errorf("compound %d: got %+v, want %+v", i, c.Pseudos, want[i])
+		}
+	}
+	if got := selectors[0].Subject().Pseudos[0].String(); got != "::before" {
+		t.Errorf("got %s", got)
+	}
+	if got := selectors[1].Subject().Pseudos; len(got) != 1 || got[0].Name != "first-line" || !got[0].Element {
+		t.Errorf("got %+v", got)
+	}
+}
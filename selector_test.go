@@ -0,0 +1,188 @@
+package css
+
+import (
+	"reflect"
+	"testing"
+)
+
+// testElement is a minimal Element used by the selector tests.
+type testElement struct {
+	tag      string
+	attrs    map[string]string
+	parent   *testElement
+	children []*testElement
+	// namespace is the namespace URI, HTMLNamespace if empty.
+	namespace string
+}
+
+func (e *testElement) TagName() string { return e.tag }
+
+func (e *testElement) Attr(name string) (string, bool) {
+	v, ok := e.attrs[name]
+	return v, ok
+}
+
+func (e *testElement) Parent() Element {
+	if e.parent == nil {
+		return nil
+	}
+	return e.parent
+}
+
+func (e *testElement) sibling(offset int) Element {
+	if e.parent == nil {
+		return nil
+	}
+	for i, c := range e.parent.children {
+		if c == e && i+offset >= 0 && i+offset < len(e.parent.children) {
+			return e.parent.children[i+offset]
+		}
+	}
+	return nil
+}
+
+func (e *testElement) Namespace() string {
+	if e.namespace == "" {
+		return HTMLNamespace
+	}
+	return e.namespace
+}
+
+func (e *testElement) PrevSibling() Element { return e.sibling(-1) }
+func (e *testElement) NextSibling() Element { return e.sibling(1) }
+
+func (e *testElement) append(tag string, attrs map[string]string) *testElement {
+	c := &testElement{tag: tag, attrs: attrs, parent: e}
+	e.children = append(e.children, c)
+	return c
+}
+
+func TestRuleSpecificity(t *testing.T) {
+	tests := []struct {
+		rule    Rule
+		a, b, c int
+	}{
+		{"*", 0, 0, 0},
+		{"li", 0, 0, 1},
+		{"ul li", 0, 0, 2},
+		{"ul ol+li", 0, 0, 3},
+		{"h1 + *[rel=up]", 0, 1, 1},
+		{"ul ol li.red", 0, 1, 3},
+		{"li.red.level", 0, 2, 1},
+		{"#x34y", 1, 0, 0},
+		{"#s12:not(FOO)", 1, 0, 1},
+		{".foo :is(.bar, #baz)", 1, 1, 0},
+		{"p::first-line", 0, 0, 2},
+		{"a:where(.b, #c)", 0, 0, 1},
+		{"h1, #main", 1, 0, 0},
+	}
+	for _, test := range tests {
+		a, b, c := test.rule.Specificity()
+		if a != test.a || b != test.b || c != test.c {
+			t.Fatalf("%q: expected (%d,%d,%d), got (%d,%d,%d)", test.rule, test.a, test.b, test.c, a, b, c)
+		}
+	}
+}
+
+func TestRuleMatches(t *testing.T) {
+	root := &testElement{tag: "html"}
+	body := root.append("body", nil)
+	ul := body.append("ul", map[string]string{"id": "list", "class": "menu main"})
+	first := ul.append("li", map[string]string{"class": "item"})
+	second := ul.append("li", map[string]string{"class": "item active", "data-x": "en-US"})
+	link := second.append("a", map[string]string{"href": "/home"})
+
+	tests := []struct {
+		rule  Rule
+		el    Element
+		match bool
+	}{
+		{"li", first, true},
+		{"ul > li.item", first, true},
+		{"body > li", first, false},
+		{"html li", second, true},
+		{"li + li", second, true},
+		{"li + li", first, false},
+		{"li ~ .active", second, true},
+		{"#list.menu.main", ul, true},
+		{"[data-x|=en]", second, true},
+		{"[data-x^=EN i]", second, true},
+		{"li:first-child", first, true},
+		{"li:nth-child(2n)", second, true},
+		{"li:nth-child(odd)", second, false},
+		{"li:not(.active)", second, false},
+		{"a:link", link, true},
+		{"a:hover", link, false},
+		{"a::before", link, false},
+		{":root", root, true},
+		{"h1, .active", second, true},
+	}
+	for _, test := range tests {
+		if match := test.rule.Matches(test.el); match != test.match {
+			t.Fatalf("%q: expected match %v, got %v", test.rule, test.match, match)
+		}
+	}
+}
+
+func TestRuleString(t *testing.T) {
+	tests := []struct {
+		rule     Rule
+		expected string
+	}{
+		{"h1+p", "h1 + p"},
+		{"ul   li>a", "ul li > a"},
+		{"input[type=text],a:before", `input[type="text"], a::before`},
+		{"@media print", "@media print"},
+	}
+	for _, test := range tests {
+		if s := test.rule.String(); s != test.expected {
+			t.Fatalf("expected %q, got %q", test.expected, s)
+		}
+	}
+
+	pseudos := Rule("p::first-line, a:after").PseudoElements()
+	if len(pseudos) != 2 || pseudos[0] != "::first-line" || pseudos[1] != "::after" {
+		t.Fatalf("unexpected pseudo-elements %q", pseudos)
+	}
+}
+
+func TestRuleGroup(t *testing.T) {
+	tests := []struct {
+		rule     Rule
+		expected SelectorGroup
+	}{
+		{"h1", SelectorGroup{"h1"}},
+		{"h1,  h2 ,\n\t.title", SelectorGroup{"h1", "h2", ".title"}},
+		{"a:is(.x, .y), b[title='a,b']", SelectorGroup{"a:is(.x, .y)", "b[title='a,b']"}},
+		{"ul  >\n li[title=\"x  y\"] , a", SelectorGroup{"ul > li[title=\"x  y\"]", "a"}},
+		{"", SelectorGroup{}},
+	}
+	for _, test := range tests {
+		if group := test.rule.Group(); !reflect.DeepEqual(group, test.expected) {
+			t.Errorf("%q: expected %q, got %q", string(test.rule), test.expected.strings(), group.strings())
+		}
+	}
+	if s := (SelectorGroup{"h1", "h2"}).String(); s != "h1, h2" {
+		t.Errorf("unexpected string %q", s)
+	}
+}
+
+func TestSpecificityFunction(t *testing.T) {
+	tests := []struct {
+		rule    Rule
+		a, b, c int
+	}{
+		{"#nav .item > a:hover::before", 1, 2, 2},
+		{":where(#a, .b) p", 0, 0, 1},
+		{":is(#a, .b) p", 1, 0, 1},
+		{"li:nth-child(2n+1 of .important)", 0, 2, 1},
+		{"a[href]:not(.x, #y)", 1, 1, 1},
+		{"svg|rect", 0, 0, 1},
+		{"(invalid", 0, 0, 0},
+	}
+	for _, test := range tests {
+		if a, b, c := Specificity(test.rule); a != test.a || b != test.b || c != test.c {
+			t.Errorf("%s: expected (%d,%d,%d), got (%d,%d,%d)", test.rule, test.a, test.b, test.c, a, b, c)
+		}
+	}
+}
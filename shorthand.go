@@ -0,0 +1,207 @@
+package css
+
+import (
+	"sort"
+	"strings"
+)
+
+// boxShorthands maps the shorthands taking one to four values to their
+// top, right, bottom and left longhands.
+var boxShorthands = map[string][4]string{
+	"margin":       {"margin-top", "margin-right", "margin-bottom", "margin-left"},
+	"padding":      {"padding-top", "padding-right", "padding-bottom", "padding-left"},
+	"border-width": {"border-top-width", "border-right-width", "border-bottom-width", "border-left-width"},
+	"border-style": {"border-top-style", "border-right-style", "border-bottom-style", "border-left-style"},
+	"border-color": {"border-top-color", "border-right-color", "border-bottom-color", "border-left-color"},
+}
+
+// ExpandShorthand returns the longhand declarations of a shorthand
+// declaration, or the declaration itself when it is not a supported
+// shorthand. The all shorthand expands to every property in Properties
+// except direction and unicode-bidi, in alphabetical order. Shorthands
+// with var() references are not expanded: the values of the longhands
+// are only known once the variables are substituted.
+func ExpandShorthand(d Declaration) []Declaration {
+	property := strings.ToLower(d.Property)
+	if property == "all" {
+		return expandAll(d)
+	}
+	if longhands, ok := boxShorthands[property]; ok {
+		return expandBox(d, longhands)
+	}
+	return []Declaration{d}
+}
+
+func expandAll(d Declaration) []Declaration {
+	properties := make([]string, 0, len(Properties))
+	for p := range Properties {
+		if p != "direction" && p != "unicode-bidi" {
+			properties = append(properties, p)
+		}
+	}
+	sort.Strings(properties)
+
+	decls := make([]Declaration, len(properties))
+	for i, p := range properties {
+		longhand := d
+		longhand.Property = p
+		decls[i] = longhand
+	}
+	return decls
+}
+
+func expandBox(d Declaration, longhands [4]string) []Declaration {
+	if hasVar(d.Value) {
+		return []Declaration{d}
+	}
+	values := splitSpaces(d.Value)
+	if isWideKeyword(d.Value) {
+		values = []string{d.Value}
+	}
+	switch len(values) {
+	case 1:
+		values = []string{values[0], values[0], values[0], values[0]}
+	case 2:
+		values = []string{values[0], values[1], values[0], values[1]}
+	case 3:
+		values = []string{values[0], values[1], values[2], values[1]}
+	case 4:
+	default:
+		return []Declaration{d}
+	}
+
+	decls := make([]Declaration, 4)
+	for i, p := range longhands {
+		longhand := d
+		longhand.Property = p
+		longhand.Value = values[i]
+		longhand.Raw = values[i]
+		if d.Important {
+			longhand.Raw += " !important"
+		}
+		decls[i] = longhand
+	}
+	return decls
+}
+
+// CollapseShorthands returns the declarations with the four longhands of
+// margin, padding, border-width, border-style and border-color replaced
+// by their shorthand in its shortest form, such as margin: 0 10px for
+// margin-top: 0, margin-right: 10px, margin-bottom: 0 and margin-left:
+// 10px. The shorthand takes the place of the last winning longhand and
+// declarations of the shorthand are merged too. Longhands are only
+// collapsed when the result behaves the same: all four are declared with
+// the same importance, they are all or none CSS-wide keywords, each is a
+// single value without var() references, and no other shorthand such as
+// border sets them.
+func CollapseShorthands(ds Declarations) Declarations {
+	shorthands := make([]string, 0, len(boxShorthands))
+	for shorthand := range boxShorthands {
+		shorthands = append(shorthands, shorthand)
+	}
+	sort.Strings(shorthands)
+
+	for _, shorthand := range shorthands {
+		if collapsed, ok := collapseBox(ds, shorthand, boxShorthands[shorthand]); ok {
+			ds = collapsed
+		}
+	}
+	return ds
+}
+
+// collapseBox collapses the longhands of a box shorthand, reporting
+// whether they could be collapsed.
+func collapseBox(ds Declarations, shorthand string, longhands [4]string) (Declarations, bool) {
+	expanded := Declarations{}
+	for _, d := range ds {
+		property := strings.ToLower(d.Property)
+		if property == shorthand {
+			longs := expandBox(d, longhands)
+			if len(longs) != 4 {
+				return nil, false
+			}
+			expanded = append(expanded, longs...)
+			continue
+		}
+		for _, longhand := range longhands {
+			if property != longhand && strings.HasPrefix(longhand, property+"-") {
+				// set by another shorthand such as border
+				return nil, false
+			}
+		}
+		expanded = append(expanded, d)
+	}
+
+	var (
+		values [4]string
+		wide   = 0
+		last   = -1
+		winner Declaration
+	)
+	for i, longhand := range longhands {
+		d, ok := expanded.Get(longhand)
+		if !ok || (i > 0 && d.Important != winner.Important) {
+			return nil, false
+		}
+		if hasVar(d.Value) || !validValue(d.Value) || len(splitSpaces(d.Value)) != 1 {
+			// the value of the side may not be a single value
+			return nil, false
+		}
+		winner = d
+		values[i] = d.Value
+		if isWideKeyword(d.Value) {
+			wide++
+		}
+	}
+	if wide != 0 && (wide != 4 || boxValue(values[:]) != values[0]) {
+		return nil, false
+	}
+
+	// the shorthand goes where the last winning longhand was
+	for i, d := range expanded {
+		for _, longhand := range longhands {
+			if strings.EqualFold(d.Property, longhand) && d.Important == winner.Important {
+				last = i
+			}
+		}
+	}
+	collapsed := Declarations{}
+	for i, d := range expanded {
+		if i == last {
+			raw := boxValue(values[:])
+			if winner.Important {
+				raw += " !important"
+			}
+			short := NewDeclaration(shorthand, raw)
+			short.Pos = d.Pos
+			collapsed = append(collapsed, short)
+		}
+		if !isLonghand(d.Property, longhands) {
+			collapsed = append(collapsed, d)
+		}
+	}
+	return collapsed, true
+}
+
+func isLonghand(property string, longhands [4]string) bool {
+	for _, longhand := range longhands {
+		if strings.EqualFold(property, longhand) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasVar reports whether the value has a var() reference.
+func hasVar(value string) bool {
+	return strings.Contains(strings.ToLower(value), "var(")
+}
+
+// isWideKeyword reports whether the value is one of the CSS-wide keywords.
+func isWideKeyword(value string) bool {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "inherit", "initial", "unset", "revert", "revert-layer":
+		return true
+	}
+	return false
+}
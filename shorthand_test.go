@@ -0,0 +1,73 @@
+package css
+
+import "testing"
+
+func TestExpandShorthand(t *testing.T) {
+	decls := ExpandShorthand(NewDeclaration("margin", "1px 2px 3px !important"))
+	expected := []string{"margin-top: 1px", "margin-right: 2px", "margin-bottom: 3px", "margin-left: 2px"}
+	if len(decls) != 4 {
+		t.Fatalf("expected 4 longhands, got %v", decls)
+	}
+	for i, d := range decls {
+		if d.Property+": "+d.Value != expected[i] || !d.Important {
+			t.Fatalf("expected %q !important, got %q", expected[i], d)
+		}
+	}
+
+	all := ExpandShorthand(NewDeclaration("all", "unset"))
+	if len(all) != len(Properties)-2 {
+		t.Fatalf("expected %d longhands, got %d", len(Properties)-2, len(all))
+	}
+	for i, d := range all {
+		if d.Property == "direction" || d.Property == "unicode-bidi" || d.Value != "unset" {
+			t.Fatalf("unexpected longhand %q", d)
+		}
+		if i > 0 && all[i-1].Property >= d.Property {
+			t.Fatalf("longhands out of order: %s, %s", all[i-1].Property, d.Property)
+		}
+	}
+
+	decls = ExpandShorthand(NewDeclaration("margin", "0 calc(1px + 2px)"))
+	expected = []string{"margin-top: 0", "margin-right: calc(1px + 2px)", "margin-bottom: 0", "margin-left: calc(1px + 2px)"}
+	if len(decls) != 4 {
+		t.Fatalf("expected 4 longhands, got %v", decls)
+	}
+	for i, d := range decls {
+		if d.Property+": "+d.Value != expected[i] {
+			t.Fatalf("expected %q, got %q", expected[i], d.Property+": "+d.Value)
+		}
+	}
+
+	if decls := ExpandShorthand(NewDeclaration("margin", "var(--m) 0")); len(decls) != 1 || decls[0].Property != "margin" {
+		t.Fatalf("expected the shorthand with var() unexpanded, got %v", decls)
+	}
+}
+
+func TestCollapseShorthands(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"margin-top: 0; margin-right: 10px; color: red; margin-bottom: 0; margin-left: 10px", "color: red; margin: 0 10px"},
+		{"padding: 1px; padding-left: 4px", "padding: 1px 1px 1px 4px"},
+		{"margin-left: 4px; margin: 1px 2px", "margin: 1px 2px"},
+		{"border-top-width: 1px; border-right-width: 2px; border-bottom-width: 3px; border-left-width: 2px", "border-width: 1px 2px 3px"},
+		{"margin-top: 0 !important; margin-right: 0 !important; margin-bottom: 0 !important; margin-left: 0 !important", "margin: 0 !important"},
+		{"margin-top: inherit; margin-right: inherit; margin-bottom: inherit; margin-left: inherit", "margin: inherit"},
+		{"margin: 0 calc(1px + 2px); margin-left: 5px", "margin: 0 calc(1px + 2px) 0 5px"},
+		// not collapsible
+		{"margin: var(--m); margin-left: 0", "margin: var(--m); margin-left: 0"},
+		{"margin-top: var(--m); margin-right: 0; margin-bottom: 0; margin-left: 0", "margin-top: var(--m); margin-right: 0; margin-bottom: 0; margin-left: 0"},
+		{"margin-top: 0 1px; margin-right: 0; margin-bottom: 0; margin-left: 0", "margin-top: 0 1px; margin-right: 0; margin-bottom: 0; margin-left: 0"},
+		{"margin-top: calc(1px; margin-right: 0; margin-bottom: 0; margin-left: 0", "margin-top: calc(1px; margin-right: 0; margin-bottom: 0; margin-left: 0"},
+		{"margin-top: 0; margin-right: 0; margin-bottom: 0", "margin-top: 0; margin-right: 0; margin-bottom: 0"},
+		{"margin-top: 0 !important; margin-right: 0; margin-bottom: 0; margin-left: 0", "margin-top: 0 !important; margin-right: 0; margin-bottom: 0; margin-left: 0"},
+		{"margin-top: inherit; margin-right: 0; margin-bottom: 0; margin-left: 0", "margin-top: inherit; margin-right: 0; margin-bottom: 0; margin-left: 0"},
+		{"border-top-color: red; border-right-color: red; border: 0; border-bottom-color: red; border-left-color: red", "border-top-color: red; border-right-color: red; border: 0; border-bottom-color: red; border-left-color: red"},
+	}
+	for _, test := range tests {
+		ds, _ := UnmarshalStyle([]byte(test.in))
+		if got := CollapseShorthands(ds).String(); got != test.want {
+			t.Errorf("%s:\ngot  %s\nwant %s", test.in, got, test.want)
+		}
+	}
+}
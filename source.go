@@ -0,0 +1,79 @@
+package css
+
+import "sort"
+
+// SourceRange is the [Start, End) byte range of a rule or at-rule in the
+// source it was parsed from.
+type SourceRange struct {
+	Start, End int
+}
+
+// Text returns the original text of the range in b, including comments
+// and formatting.
+func (r SourceRange) Text(b []byte) []byte {
+	return b[r.Start:r.End]
+}
+
+// RuleSource is a rule or at-rule with its byte range in the source.
+type RuleSource struct {
+	// Prelude is the selector of a rule or the at-rule with its
+	// parameters, such as "@media print" or "@import url(a.css)".
+	Prelude Rule
+	SourceRange
+}
+
+// RuleSources returns the byte range of every rule and at-rule in b,
+// including the nested ones and at-rules without a block, ordered by
+// their start.
+func RuleSources(b []byte) []RuleSource {
+	var (
+		sources = []RuleSource{}
+		stack   = []RuleSource{}
+		item    = []TokenEntry{}
+		text    = ""
+		end     = 0
+	)
+
+	e := Tokenize(b).Front()
+	for e != nil {
+		tok := e.Value.(TokenEntry)
+
+		switch tok.typ() {
+		case tokenBlockStart:
+			r := RuleSource{Prelude: Rule(text)}
+			r.Start = tok.pos.Offset
+			if len(item) > 0 {
+				r.Start = item[0].pos.Offset
+			}
+			stack = append(stack, r)
+			item, text = nil, ""
+		case tokenBlockEnd:
+			if len(stack) > 0 {
+				r := stack[len(stack)-1]
+				r.End = tok.pos.Offset + 1
+				sources = append(sources, r)
+				stack = stack[:len(stack)-1]
+			}
+			item, text = nil, ""
+		case tokenStatementEnd:
+			if len(item) > 0 && item[0].value[0] == '@' {
+				r := RuleSource{Prelude: Rule(text)}
+				r.Start, r.End = item[0].pos.Offset, tok.pos.Offset+1
+				sources = append(sources, r)
+			}
+			item, text = nil, ""
+		default:
+			if text != "" && tok.pos.Offset > end {
+				text += " "
+			}
+			text += tok.value
+			item = append(item, tok)
+		}
+
+		end = tok.pos.Offset + len(tok.value)
+		e = e.Next()
+	}
+
+	sort.SliceStable(sources, func(i, j int) bool { return sources[i].Start < sources[j].Start })
+	return sources
+}
@@ -0,0 +1,50 @@
+package css
+
+import "testing"
+
+func TestRuleSources(t *testing.T) {
+	ex := []byte(`@import url(a.css);
+/* keep */
+.a {
+	color: red; /* inline */
+}
+@media print {
+	.b {
+		top: 0;
+	}
+}
+`)
+	want := []struct {
+		prelude Rule
+		text    string
+	}{
+		{"@import url(a.css)", "@import url(a.css);"},
+		{".a", ".a {\n\tcolor: red; /* inline */\n}"},
+		{"@media print", "@media print {\n\t.b {\n\t\ttop: 0;\n\t}\n}"},
+		{".b", ".b {\n\t\ttop: 0;\n\t}"},
+	}
+	sources := RuleSources(ex)
+	if len(sources) != len(want) {
+		t.Fatalf("got %d sources %v, want %d", len(sources), sources, len(want))
+	}
+	for i, w := range want {
+		if sources[i].Prelude != w.prelude || string(sources[i].Text(ex)) != w.text {
+			t.Errorf("source %d: got %q %q, want %q %q",
+				i, sources[i].Prelude, sources[i].Text(ex), w.prelude, w.text)
+		}
+	}
+}
+
+func TestStyleRuleSource(t *testing.T) {
+	ex := []byte(".a {\n\tcolor: red;\n}\n\n.b {\n\ttop: 0\n}\n")
+	sheet, err := UnmarshalStylesheet(ex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sheet.Rules) != 2 {
+		t.Fatalf("expected 2 rules, got %d", len(sheet.Rules))
+	}
+	if got := string(sheet.Rules[1].Source.Text(ex)); got != ".b {\n\ttop: 0\n}" {
+		t.Fatalf("unexpected source %q", got)
+	}
+}
@@ -0,0 +1,137 @@
+package css
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// SpecificityReport describes the specificity of the rules of a
+// stylesheet and which rules override which.
+type SpecificityReport struct {
+	Rules        []RuleSpecificity  `json:"rules"`
+	Distribution []SpecificityCount `json:"distribution"`
+	Overrides    []Override         `json:"overrides"`
+}
+
+// RuleSpecificity is the specificity of a rule.
+type RuleSpecificity struct {
+	Selector    Rule   `json:"selector"`
+	Specificity [3]int `json:"specificity"`
+}
+
+// SpecificityCount is the number of rules with a specificity.
+type SpecificityCount struct {
+	Specificity [3]int `json:"specificity"`
+	Count       int    `json:"count"`
+}
+
+// Override is a rule winning over another one for the properties they
+// both declare, should they match the same element. Winner and Loser are
+// indexes in SpecificityReport.Rules.
+type Override struct {
+	Winner     int      `json:"winner"`
+	Loser      int      `json:"loser"`
+	Properties []string `json:"properties"`
+}
+
+// NewSpecificityReport returns the specificity report of the stylesheet.
+// The distribution is sorted from the highest specificity to the lowest.
+// Overrides are computed with the cascade, so an important declaration
+// beats a more specific normal one.
+func NewSpecificityReport(sheet *Stylesheet) SpecificityReport {
+	report := SpecificityReport{
+		Rules:        make([]RuleSpecificity, len(sheet.Rules)),
+		Distribution: []SpecificityCount{},
+		Overrides:    []Override{},
+	}
+
+	counts := map[[3]int]int{}
+	// declared holds the entries declaring every property, with the index
+	// of their rule as Order
+	declared := map[string][]CascadeEntry{}
+	properties := []string{}
+	for i, r := range sheet.Rules {
+		a, b, c := r.Selector.Specificity()
+		spec := [3]int{a, b, c}
+		report.Rules[i] = RuleSpecificity{r.Selector, spec}
+		counts[spec]++
+
+		for _, d := range r.Declarations {
+			property := strings.ToLower(d.Property)
+			if _, ok := declared[property]; !ok {
+				properties = append(properties, property)
+			}
+			declared[property] = append(declared[property], CascadeEntry{
+				Declaration: d,
+				Origin:      OriginAuthor,
+				Specificity: spec,
+				Order:       i,
+			})
+		}
+	}
+
+	for spec, n := range counts {
+		report.Distribution = append(report.Distribution, SpecificityCount{spec, n})
+	}
+	sort.Slice(report.Distribution, func(i, j int) bool {
+		a, b := report.Distribution[i].Specificity, report.Distribution[j].Specificity
+		return a[0] > b[0] || (a[0] == b[0] && (a[1] > b[1] || (a[1] == b[1] && a[2] > b[2])))
+	})
+
+	index := map[[2]int]int{}
+	for _, property := range properties {
+		entries := declared[property]
+		for x := range entries {
+			for y := range entries {
+				wx, wy := entries[x].Order, entries[y].Order
+				if wx == wy || !cascadeBeats(entries[x], entries[y]) {
+					continue
+				}
+				key := [2]int{wx, wy}
+				i, ok := index[key]
+				if !ok {
+					i = len(report.Overrides)
+					index[key] = i
+					report.Overrides = append(report.Overrides, Override{Winner: wx, Loser: wy})
+				}
+				o := &report.Overrides[i]
+				if !containsString(o.Properties, property) {
+					o.Properties = append(o.Properties, property)
+				}
+			}
+		}
+	}
+	sort.SliceStable(report.Overrides, func(i, j int) bool {
+		a, b := report.Overrides[i], report.Overrides[j]
+		return a.Winner < b.Winner || (a.Winner == b.Winner && a.Loser < b.Loser)
+	})
+
+	return report
+}
+
+// WriteJSON writes the report as indented JSON.
+func (r SpecificityReport) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteDOT writes the overrides as a Graphviz graph, with an edge from the
+// winner to the loser of every override, labelled with the properties.
+func (r SpecificityReport) WriteDOT(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("digraph specificity {\n")
+	for i, rule := range r.Rules {
+		s := rule.Specificity
+		fmt.Fprintf(&b, "\tr%d [label=%q];\n", i, fmt.Sprintf("%s (%d,%d,%d)", rule.Selector, s[0], s[1], s[2]))
+	}
+	for _, o := range r.Overrides {
+		fmt.Fprintf(&b, "\tr%d -> r%d [label=%q];\n", o.Winner, o.Loser, strings.Join(o.Properties, ", "))
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,77 @@
+package css
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestSpecificityReport(t *testing.T) {
+	sheet, err := UnmarshalStylesheet([]byte(`#main p {
+	color: red;
+}
+p {
+	color: blue !important;
+	margin: 0;
+}
+p.note {
+	margin: 1px;
+}
+a {
+	top: 0;
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := NewSpecificityReport(sheet)
+
+	if len(report.Rules) != 4 || report.Rules[0].Specificity != [3]int{1, 0, 1} {
+		t.Fatalf("unexpected rules %v", report.Rules)
+	}
+	expected := []SpecificityCount{{[3]int{1, 0, 1}, 1}, {[3]int{0, 1, 1}, 1}, {[3]int{0, 0, 1}, 2}}
+	if len(report.Distribution) != len(expected) {
+		t.Fatalf("unexpected distribution %v", report.Distribution)
+	}
+	for i := range expected {
+		if report.Distribution[i].Specificity != expected[i].Specificity ||
+			report.Distribution[i].Count != expected[i].Count {
+			t.Fatalf("unexpected distribution %v", report.Distribution)
+		}
+	}
+
+	overrides := []Override{
+		{Winner: 1, Loser: 0, Properties: []string{"color"}},
+		{Winner: 2, Loser: 1, Properties: []string{"margin"}},
+	}
+	if len(report.Overrides) != len(overrides) {
+		t.Fatalf("unexpected overrides %v", report.Overrides)
+	}
+	for i, o := range overrides {
+		got := report.Overrides[i]
+		if got.Winner != o.Winner || got.Loser != o.Loser || strings.Join(got.Properties, ",") != strings.Join(o.Properties, ",") {
+			t.Errorf("override %d: got %v, want %v", i, got, o)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := report.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var decoded SpecificityReport
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil || len(decoded.Overrides) != 2 {
+		t.Fatalf("invalid JSON %v: %s", err, buf.Bytes())
+	}
+
+	buf.Reset()
+	if err := report.WriteDOT(&buf); err != nil {
+		t.Fatal(err)
+	}
+	dot := buf.String()
+	for _, s := range []string{"digraph specificity {", `r0 [label="#main p (1,0,1)"];`, `r2 -> r1 [label="margin"];`} {
+		if !strings.Contains(dot, s) {
+			t.Errorf("expected %q in:\n%s", s, dot)
+		}
+	}
+}
@@ -2,403 +2,984 @@ package css
 
 import (
 	"errors"
-	"strconv"
 	"strings"
 )
 
+// ErrNotImplemented can be returned by the StylesTable handlers of styles
+// whose values are not parsed, so Validate doesn't check them.
+var ErrNotImplemented = errors.New("not implemented")
+
+var (
+	errKeyword = errors.New("invalid keyword")
+	errNumber  = errors.New("invalid number")
+	errValue   = errors.New("invalid value")
+)
+
 func checkColor(color string) error {
-	var errColor = errors.New("invalid color")
-	if strings.HasPrefix(color, "#") {
-		if len(color) == 0 || len(color) > 9 {
-			return errColor
-		}
-		if _, err := strconv.ParseUint(color[1:], 16, 32); err != nil {
-			return errColor
-		}
-		return nil
-	}
-
-	switch color {
-	case
-		"black",
-		"silver",
-		"gray",
-		"white",
-		"maroon",
-		"red",
-		"purple",
-		"fuchsia",
-		"green",
-		"lime",
-		"olive",
-		"yellow",
-		"navy",
-		"blue",
-		"teal",
-		"aqua",
-		"orange",
-		"aliceblue",
-		"antiquewhite",
-		"aquamarine",
-		"azure",
-		"beige",
-		"bisque",
-		"blanchedalmond",
-		"blueviolet",
-		"brown",
-		"burlywood",
-		"cadetblue",
-		"chartreuse",
-		"chocolate",
-		"coral",
-		"cornflowerblue",
-		"cornsilk",
-		"crimson",
-		"cyan",
-		"darkblue",
-		"darkcyan",
-		"darkgoldenrod",
-		"darkgray",
-		"darkgreen ",
-		"darkgrey",
-		"darkkhaki",
-		"darkmagenta",
-		"darkolivegreen",
-		"darkorange",
-		"darkorchid",
-		"darkred",
-		"darksalmon",
-		"darkseagreen",
-		"darkslateblue",
-		"darkslategray",
-		"darkslategrey",
-		"darkturquoise",
-		"darkviolet",
-		"deeppink",
-		"deepskyblue",
-		"dimgray",
-		"dimgrey",
-		"dodgerblue",
-		"firebrick",
-		"floralwhite",
-		"forestgreen",
-		"gainsboro",
-		"ghostwhite",
-		"gold",
-		"goldenrod",
-		"greenyellow",
-		"grey",
-		"honeydew",
-		"hotpink",
-		"indianred",
-		"indigo",
-		"ivory",
-		"khaki",
-		"lavender",
-		"lavnderblush",
-		"lawgreen",
-		"lemonchiffon",
-		"lightblue",
-		"lightcoral",
-		"lightcyan",
-		"lightgoldenrodyellow",
-		"lightgray",
-		"lightgreen",
-		"lightgrey",
-		"lightpink",
-		"lightsalmon",
-		"lightseagreen",
-		"lightskyblue",
-		"lightslategray",
-		"lightslategrey",
-		"lightsteelblue",
-		"lightyellow",
-		"limegreen",
-		"linen",
-		"magenta",
-		"mediumaquamarine",
-		"mediumblue",
-		"mediumorchid",
-		"mediumpurple",
-		"mediumseagreen",
-		"mediumslateblue",
-		"mediumspringgreen",
-		"mediumturquoise",
-		"mediumvioletred",
-		"midnightblue",
-		"mintcream",
-		"mistyrose",
-		"moccasin",
-		"navajowhite",
-		"oldlace",
-		"olivedrab",
-		"orangered",
-		"orchid",
-		"palegoldenrod",
-		"palegreen",
-		"paleturquoise",
-		"palevioletred",
-		"papayawhip",
-		"peachpuff",
-		"peru",
-		"pink",
-		"plum",
-		"powderblue",
-		"rosybrown",
-		"royalblue",
-		"saddlebrown",
-		"salmon",
-		"sandybrown",
-		"seagreen",
-		"seashell",
-		"sienna",
-		"skyblue",
-		"slateblue",
-		"slategray",
-		"slategrey",
-		"snow",
-		"springgreen",
-		"steelblue",
-		"tan",
-		"thistle",
-		"tomato",
-		"turquoise",
-		"violet",
-		"wheat",
-		"whitesmoke",
-		"yellowgreen":
-		return nil
-
-	}
-	return errColor
+	_, err := ParseColor(color)
+	return err
 }
 
-func background(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+// unitTypes are the unit types of the length units.
+var unitTypes = map[string]UnitType{
+	"px":   UnitPixels,
+	"em":   UnitEm,
+	"rem":  UnitRem,
+	"%":    UnitPercent,
+	"pt":   UnitPt,
+	"ex":   UnitEx,
+	"ch":   UnitCh,
+	"vw":   UnitVw,
+	"vh":   UnitVh,
+	"vmin": UnitVmin,
+	"vmax": UnitVmax,
+	"cm":   UnitCm,
+	"mm":   UnitMm,
+	"q":    UnitQ,
+	"in":   UnitIn,
+	"pc":   UnitPc,
 }
-func backgroundAttachment(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+
+// lengthFlags are the values a length style accepts besides the
+// non-negative lengths.
+type lengthFlags int
+
+const (
+	allowPercent lengthFlags = 1 << iota
+	allowNegative
+)
+
+// keywordStyle returns the style of a value that must be one of the
+// keywords, with the lower case keyword as value.
+func keywordStyle(value string, keywords ...string) (Style, error) {
+	v := strings.Join(strings.Fields(strings.ToLower(value)), " ")
+	if !containsString(keywords, v) {
+		return Style{}, errKeyword
+	}
+	if v == "auto" {
+		return Style{Value: v, unit: UnitAuto}, nil
+	}
+	return Style{Value: v}, nil
 }
-func backgroundColor(value string) (Style, error) {
-	if err := checkColor(value); err != nil {
+
+// lengthStyle returns the style of a length, with the number as
+// UnitValue, or of one of the keywords. Math functions such as calc()
+// are kept as strings.
+func lengthStyle(value string, flags lengthFlags, keywords ...string) (Style, error) {
+	v := strings.ToLower(strings.TrimSpace(value))
+	if containsString(keywords, v) {
+		return keywordStyle(v, v)
+	}
+	if isMathFunction(v) {
+		return Style{Value: v}, nil
+	}
+	l, err := ParseLength(v)
+	if err != nil {
 		return Style{}, err
 	}
+	if l.Unit == "%" && flags&allowPercent == 0 || l.Value < 0 && flags&allowNegative == 0 {
+		return Style{}, errLength
+	}
+	return Style{Value: UnitValue(l.Value), unit: unitTypes[l.Unit]}, nil
+}
 
-	style := Style{
-		Value: value,
+// isMathFunction reports whether the value is a calc(), min(), max() or
+// clamp() function.
+func isMathFunction(value string) bool {
+	components := parseComponents(value)
+	return len(components) == 1 && components[0].Type == ComponentFunction &&
+		containsString([]string{"calc", "min", "max", "clamp"}, strings.ToLower(components[0].Value)) &&
+		validValue(value)
+}
+
+// parseNumber parses a number without a unit.
+func parseNumber(value string) (float64, bool) {
+	components := parseComponents(strings.TrimSpace(value))
+	if len(components) != 1 || components[0].Type != ComponentDimension || components[0].Unit != "" {
+		return 0, false
 	}
-	return style, nil
+	return components[0].Number, true
+}
+
+// numberStyle returns the style of a number of at least min, or of an
+// integer if integer is set.
+func numberStyle(value string, min float64, integer bool) (Style, error) {
+	n, ok := parseNumber(value)
+	if !ok || n < min || integer && n != float64(int(n)) {
+		return Style{}, errNumber
+	}
+	return Style{Value: UnitValue(n)}, nil
+}
+
+// splitSpaces splits the value at the whitespace outside of strings and
+// parentheses.
+func splitSpaces(value string) []string {
+	value = strings.NewReplacer("\t", " ", "\n", " ", "\r", " ", "\f", " ").Replace(value)
+	parts := []string{}
+	for _, part := range splitTopLevel(value, ' ') {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
+
+// multiStyle returns the style of a value of min to max parts accepted by
+// the handler, such as the one to four sides of margin, with the styles
+// of the parts as value.
+func multiStyle(value string, min, max int, handler StyleHandler) (Style, error) {
+	parts := splitSpaces(value)
+	if len(parts) < min || len(parts) > max {
+		return Style{}, errValue
+	}
+	styles := make([]Style, len(parts))
+	for i, part := range parts {
+		style, err := handler(part)
+		if err != nil {
+			return Style{}, err
+		}
+		styles[i] = style
+	}
+	return Style{Value: styles}, nil
+}
+
+// shorthandStyle returns the style of a shorthand whose parts are accepted
+// by the handlers in any order, each at most once, such as the width,
+// style and color of border, with the styles of the parts as value.
+func shorthandStyle(value string, handlers ...StyleHandler) (Style, error) {
+	parts := splitSpaces(value)
+	if len(parts) == 0 || len(parts) > len(handlers) {
+		return Style{}, errValue
+	}
+	used := make([]bool, len(handlers))
+	styles := make([]Style, len(parts))
+parts:
+	for i, part := range parts {
+		for j, handler := range handlers {
+			if used[j] {
+				continue
+			}
+			if style, err := handler(part); err == nil {
+				used[j] = true
+				styles[i] = style
+				continue parts
+			}
+		}
+		return Style{}, errValue
+	}
+	return Style{Value: styles}, nil
+}
+
+// imageStyle returns the style of an image: a url or a gradient, with the
+// component as value, or none.
+func imageStyle(value string) (Style, error) {
+	v := strings.TrimSpace(value)
+	if strings.EqualFold(v, "none") {
+		return Style{Value: "none"}, nil
+	}
+	components := ParseValue(v)
+	if len(components) != 1 || !validValue(v) {
+		return Style{}, errValue
+	}
+	switch c := components[0]; {
+	case c.Type == ComponentURL:
+		return Style{Value: c}, nil
+	case c.Type == ComponentFunction && containsString(imageFunctions, strings.ToLower(c.Value)):
+		return Style{Value: c}, nil
+	}
+	return Style{}, errValue
+}
+
+var imageFunctions = []string{
+	"url", "linear-gradient", "radial-gradient", "conic-gradient",
+	"repeating-linear-gradient", "repeating-radial-gradient",
+	"repeating-conic-gradient", "image-set", "-webkit-image-set",
+	"cross-fade", "element",
+}
+
+func background(value string) (Style, error) {
+	layers := splitTopLevel(value, ',')
+	styles := make([][]Style, len(layers))
+	for i, layer := range layers {
+		for _, part := range splitSpaces(layer) {
+			for j, sub := range splitTopLevel(part, '/') {
+				if j > 0 {
+					styles[i] = append(styles[i], Style{Value: "/"})
+				}
+				if sub == "" {
+					continue
+				}
+				style, err := backgroundPart(sub, i == len(layers)-1)
+				if err != nil {
+					return Style{}, err
+				}
+				styles[i] = append(styles[i], style)
+			}
+		}
+		if len(styles[i]) == 0 {
+			return Style{}, errValue
+		}
+	}
+	return Style{Value: styles}, nil
+}
+
+// backgroundPart returns the style of a part of a background layer. Only
+// the last layer can have a color.
+func backgroundPart(part string, last bool) (Style, error) {
+	handlers := []StyleHandler{imageStyle, backgroundRepeatKeyword, backgroundAttachment, backgroundBox, backgroundPositionPart, backgroundSizeKeyword}
+	if last {
+		handlers = append(handlers, colorStyle)
+	}
+	for _, handler := range handlers {
+		if style, err := handler(part); err == nil {
+			return style, nil
+		}
+	}
+	return Style{}, errValue
+}
+func backgroundAttachment(value string) (Style, error) {
+	return keywordStyle(value, "scroll", "fixed", "local")
+}
+func backgroundBox(value string) (Style, error) {
+	return keywordStyle(value, "border-box", "padding-box", "content-box", "text")
+}
+func backgroundColor(value string) (Style, error) {
+	return colorStyle(value)
 }
 func backgroundImage(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+	layers := splitTopLevel(value, ',')
+	styles := make([]Style, len(layers))
+	for i, layer := range layers {
+		style, err := imageStyle(layer)
+		if err != nil {
+			return Style{}, err
+		}
+		styles[i] = style
+	}
+	return Style{Value: styles}, nil
 }
 func backgroundPosition(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+	return multiStyle(value, 1, 4, backgroundPositionPart)
+}
+func backgroundPositionPart(value string) (Style, error) {
+	return lengthStyle(value, allowPercent|allowNegative, "left", "right", "top", "bottom", "center")
 }
 func backgroundRepeat(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+	parts := splitSpaces(value)
+	if len(parts) == 1 {
+		return backgroundRepeatKeyword(value)
+	}
+	return multiStyle(value, 2, 2, func(part string) (Style, error) {
+		return keywordStyle(part, "repeat", "no-repeat", "space", "round")
+	})
+}
+func backgroundRepeatKeyword(value string) (Style, error) {
+	return keywordStyle(value, "repeat", "repeat-x", "repeat-y", "no-repeat", "space", "round")
 }
+func backgroundSizeKeyword(value string) (Style, error) {
+	return keywordStyle(value, "auto", "cover", "contain")
+}
+
+var borderStyles = []string{"none", "hidden", "dotted", "dashed", "solid", "double", "groove", "ridge", "inset", "outset"}
+
 func border(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+	return shorthandStyle(value, borderSideWidth, borderSideStyle, borderSideColor)
 }
 func borderBottom(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+	return border(value)
 }
 func borderBottomColor(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+	return borderSideColor(value)
 }
 func borderBottomStyle(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+	return borderSideStyle(value)
 }
 func borderBottomWidth(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+	return borderSideWidth(value)
 }
 func borderColor(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+	return multiStyle(value, 1, 4, borderSideColor)
 }
 func borderLeft(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+	return border(value)
 }
 func borderLeftColor(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+	return borderSideColor(value)
 }
 func borderLeftStyle(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+	return borderSideStyle(value)
 }
 func borderLeftWidth(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+	return borderSideWidth(value)
 }
 func borderRight(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+	return border(value)
 }
 func borderRightColor(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+	return borderSideColor(value)
 }
 func borderRightStyle(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+	return borderSideStyle(value)
 }
 func borderRightWidth(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+	return borderSideWidth(value)
+}
+func borderSideColor(value string) (Style, error) {
+	return colorStyle(value)
+}
+func borderSideStyle(value string) (Style, error) {
+	return keywordStyle(value, borderStyles...)
+}
+func borderSideWidth(value string) (Style, error) {
+	return lengthStyle(value, 0, "thin", "medium", "thick")
 }
 func borderStyle(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+	return multiStyle(value, 1, 4, borderSideStyle)
 }
 func borderTop(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+	return border(value)
 }
 func borderTopColor(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+	return borderSideColor(value)
 }
 func borderTopStyle(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+	return borderSideStyle(value)
 }
 func borderTopWidth(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+	return borderSideWidth(value)
 }
 func borderWidth(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+	return multiStyle(value, 1, 4, borderSideWidth)
+}
+func borderCollapse(value string) (Style, error) {
+	return keywordStyle(value, "collapse", "separate")
+}
+func bottom(value string) (Style, error) {
+	return offset(value)
+}
+func boxSizing(value string) (Style, error) {
+	return keywordStyle(value, "content-box", "border-box")
+}
+func captionSide(value string) (Style, error) {
+	return keywordStyle(value, "top", "bottom")
 }
 func clear(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+	return keywordStyle(value, "none", "left", "right", "both", "inline-start", "inline-end")
 }
+
+// clip returns the style of auto, or of a rect() function with the styles
+// of its four offsets as value.
 func clip(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+	v := strings.TrimSpace(value)
+	if strings.EqualFold(v, "auto") {
+		return keywordStyle(v, "auto")
+	}
+	components := parseComponents(v)
+	if len(components) != 1 || components[0].Type != ComponentFunction ||
+		!strings.EqualFold(components[0].Value, "rect") || !validValue(v) {
+		return Style{}, errValue
+	}
+	inner := v[strings.IndexByte(v, '(')+1 : strings.LastIndexByte(v, ')')]
+	offsets := splitTopLevel(inner, ',')
+	if len(offsets) == 1 {
+		offsets = splitSpaces(inner)
+	}
+	if len(offsets) != 4 {
+		return Style{}, errValue
+	}
+	styles := make([]Style, 4)
+	for i, o := range offsets {
+		style, err := lengthStyle(o, allowNegative, "auto")
+		if err != nil {
+			return Style{}, err
+		}
+		styles[i] = style
+	}
+	return Style{Value: styles}, nil
 }
 func color(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+	return colorStyle(value)
 }
+
+// colorStyle returns the style of a color value, with the color.RGBA as
+// value, or of currentcolor.
+func colorStyle(value string) (Style, error) {
+	if v := strings.ToLower(strings.TrimSpace(value)); v == "currentcolor" {
+		return Style{Value: v}, nil
+	}
+	c, err := ParseColor(value)
+	if err != nil {
+		return Style{}, err
+	}
+	return Style{Value: c}, nil
+}
+
+var cursors = []string{
+	"auto", "default", "none", "context-menu", "help", "pointer", "progress",
+	"wait", "cell", "crosshair", "text", "vertical-text", "alias", "copy",
+	"move", "no-drop", "not-allowed", "grab", "grabbing", "all-scroll",
+	"col-resize", "row-resize", "n-resize", "e-resize", "s-resize",
+	"w-resize", "ne-resize", "nw-resize", "se-resize", "sw-resize",
+	"ew-resize", "ns-resize", "nesw-resize", "nwse-resize", "zoom-in",
+	"zoom-out",
+}
+
+// cursor returns the style of the cursor keyword, which must follow the
+// cursor images, if any.
 func cursor(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+	items := splitTopLevel(value, ',')
+	for _, item := range items[:len(items)-1] {
+		parts := splitSpaces(item)
+		if len(parts) != 1 && len(parts) != 3 {
+			return Style{}, errValue
+		}
+		if _, err := imageStyle(parts[0]); err != nil {
+			return Style{}, err
+		}
+		for _, coord := range parts[1:] {
+			if _, ok := parseNumber(coord); !ok {
+				return Style{}, errNumber
+			}
+		}
+	}
+	return keywordStyle(items[len(items)-1], cursors...)
 }
+func direction(value string) (Style, error) {
+	return keywordStyle(value, "ltr", "rtl")
+}
+
+var (
+	displayOutside = []string{"block", "inline", "run-in"}
+	displayInside  = []string{"flow", "flow-root", "table", "flex", "grid", "ruby"}
+	displayOther   = []string{
+		"none", "contents", "list-item", "inline-block", "inline-table",
+		"inline-flex", "inline-grid", "table-row-group", "table-header-group",
+		"table-footer-group", "table-row", "table-cell", "table-column-group",
+		"table-column", "table-caption", "ruby-base", "ruby-text",
+		"ruby-base-container", "ruby-text-container",
+	}
+)
+
+// display returns the style of a display keyword, or of the two keyword
+// syntax such as "inline flex".
 func display(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+	parts := splitSpaces(strings.ToLower(value))
+	switch len(parts) {
+	case 1:
+		return keywordStyle(parts[0], append(append(append([]string{}, displayOutside...), displayInside...), displayOther...)...)
+	case 2:
+		outside, inside := parts[0], parts[1]
+		if containsString(displayInside, outside) {
+			outside, inside = inside, outside
+		}
+		if containsString(displayOutside, outside) && (containsString(displayInside, inside) || inside == "list-item") {
+			return Style{Value: parts[0] + " " + parts[1]}, nil
+		}
+	}
+	return Style{}, errKeyword
+}
+func emptyCells(value string) (Style, error) {
+	return keywordStyle(value, "show", "hide")
+}
+
+var filterFunctions = []string{
+	"blur", "brightness", "contrast", "drop-shadow", "grayscale",
+	"hue-rotate", "invert", "opacity", "saturate", "sepia", "url",
 }
+
+// filter returns the style of none, or of filter functions with the
+// components as value.
 func filter(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+	v := strings.TrimSpace(value)
+	if strings.EqualFold(v, "none") {
+		return Style{Value: "none"}, nil
+	}
+	components := ParseValue(v)
+	if len(components) == 0 || !validValue(v) {
+		return Style{}, errValue
+	}
+	for _, c := range components {
+		if c.Type != ComponentURL && (c.Type != ComponentFunction || !containsString(filterFunctions, strings.ToLower(c.Value))) {
+			return Style{}, errValue
+		}
+	}
+	return Style{Value: components}, nil
+}
+
+// flex returns the style of none or auto, or of the flex-grow, flex-shrink
+// and flex-basis given, with a map of their styles as value.
+func flex(value string) (Style, error) {
+	if style, err := keywordStyle(value, "none", "auto"); err == nil {
+		return style, nil
+	}
+	parts := splitSpaces(value)
+	if len(parts) == 0 || len(parts) > 3 {
+		return Style{}, errValue
+	}
+	styles := map[string]Style{}
+	afterGrow := false
+	for _, part := range parts {
+		if n, err := numberStyle(part, 0, false); err == nil {
+			if _, ok := styles["flex-grow"]; !ok {
+				styles["flex-grow"], afterGrow = n, true
+				continue
+			}
+			if _, ok := styles["flex-shrink"]; !ok && afterGrow {
+				styles["flex-shrink"], afterGrow = n, false
+				continue
+			}
+		}
+		afterGrow = false
+		if _, ok := styles["flex-basis"]; ok {
+			return Style{}, errValue
+		}
+		basis, err := flexBasis(part)
+		if err != nil {
+			return Style{}, err
+		}
+		styles["flex-basis"] = basis
+	}
+	return Style{Value: styles}, nil
+}
+func flexBasis(value string) (Style, error) {
+	return lengthStyle(value, allowPercent, "auto", "content", "min-content", "max-content", "fit-content")
+}
+func flexDirection(value string) (Style, error) {
+	return keywordStyle(value, "row", "row-reverse", "column", "column-reverse")
+}
+func flexFlow(value string) (Style, error) {
+	return shorthandStyle(value, flexDirection, flexWrap)
 }
+func flexGrow(value string) (Style, error) {
+	return numberStyle(value, 0, false)
+}
+func flexShrink(value string) (Style, error) {
+	return numberStyle(value, 0, false)
+}
+func flexWrap(value string) (Style, error) {
+	return keywordStyle(value, "nowrap", "wrap", "wrap-reverse")
+}
+func float(value string) (Style, error) {
+	return keywordStyle(value, "none", "left", "right", "inline-start", "inline-end")
+}
+
+var systemFonts = []string{"caption", "icon", "menu", "message-box", "small-caption", "status-bar"}
+
+// font returns the style of a system font, or of the font properties
+// given, with a map of their styles as value.
 func font(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+	parts := splitSpaces(value)
+	if len(parts) == 1 && containsString(systemFonts, strings.ToLower(parts[0])) {
+		return Style{Value: map[string]Style{"font": {Value: strings.ToLower(parts[0])}}}, nil
+	}
+	styles := map[string]Style{}
+	for i, part := range parts {
+		sizeValue, heightValue := part, ""
+		slash := strings.IndexByte(part, '/')
+		if slash >= 0 {
+			sizeValue, heightValue = part[:slash], part[slash+1:]
+		}
+		if s, err := fontSize(sizeValue); err == nil {
+			rest := parts[i+1:]
+			if slash < 0 && len(rest) > 0 && strings.HasPrefix(rest[0], "/") {
+				slash, heightValue, rest = 0, rest[0][1:], rest[1:]
+				if heightValue == "" && len(rest) > 0 {
+					heightValue, rest = rest[0], rest[1:]
+				}
+			}
+			styles["font-size"] = s
+			if slash >= 0 {
+				if styles["line-height"], err = lineHeight(heightValue); err != nil {
+					return Style{}, err
+				}
+			}
+			if styles["font-family"], err = fontFamily(strings.Join(rest, " ")); err != nil {
+				return Style{}, err
+			}
+			return Style{Value: styles}, nil
+		}
+		if strings.EqualFold(part, "normal") {
+			continue
+		}
+		if !fontPart(styles, part) {
+			return Style{}, errValue
+		}
+	}
+	return Style{}, errValue
 }
+
+// fontPart adds the style of a part of the font shorthand before the font
+// size to the styles, and reports whether the part is valid.
+func fontPart(styles map[string]Style, part string) bool {
+	for _, p := range []struct {
+		name    string
+		handler StyleHandler
+	}{
+		{"font-style", fontStyle},
+		{"font-variant", fontVariant},
+		{"font-weight", fontWeight},
+		{"font-stretch", fontStretch},
+	} {
+		if _, ok := styles[p.name]; ok {
+			continue
+		}
+		if style, err := p.handler(part); err == nil {
+			styles[p.name] = style
+			return true
+		}
+	}
+	return false
+}
+
+// fontFamily returns the style of a list of font families, with the
+// names as a []string value.
 func fontFamily(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+	families := []string{}
+	for _, item := range splitTopLevel(value, ',') {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			return Style{}, errValue
+		}
+		if item[0] == '"' || item[0] == '\'' {
+			if end := scanString(item, 0); end != len(item) || len(item) < 2 || item[end-1] != item[0] {
+				return Style{}, errValue
+			}
+			families = append(families, unquote(item))
+			continue
+		}
+		for _, c := range parseComponents(item) {
+			if c.Type != ComponentIdent {
+				return Style{}, errValue
+			}
+		}
+		families = append(families, strings.Join(strings.Fields(item), " "))
+	}
+	return Style{Value: families}, nil
 }
 func fontSize(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+	return lengthStyle(value, allowPercent,
+		"xx-small", "x-small", "small", "medium", "large", "x-large", "xx-large", "xxx-large", "larger", "smaller")
+}
+func fontStretch(value string) (Style, error) {
+	if style, err := lengthStyle(value, allowPercent); err == nil && style.unit == UnitPercent {
+		return style, nil
+	}
+	return keywordStyle(value, "normal", "ultra-condensed", "extra-condensed", "condensed", "semi-condensed",
+		"semi-expanded", "expanded", "extra-expanded", "ultra-expanded")
+}
+func fontStyle(value string) (Style, error) {
+	parts := splitSpaces(value)
+	if len(parts) == 2 && strings.EqualFold(parts[0], "oblique") {
+		c := parseComponents(parts[1])
+		if len(c) == 1 && c[0].Type == ComponentDimension && strings.EqualFold(c[0].Unit, "deg") {
+			return Style{Value: "oblique " + parts[1]}, nil
+		}
+	}
+	return keywordStyle(value, "normal", "italic", "oblique")
 }
 func fontVariant(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+	return keywordStyle(value, "normal", "none", "small-caps", "all-small-caps", "petite-caps",
+		"all-petite-caps", "unicase", "titling-caps")
 }
+
+// fontWeight returns the style of a weight keyword, or of a number from 1
+// to 1000 as UnitValue.
 func fontWeight(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+	if n, ok := parseNumber(value); ok {
+		if n < 1 || n > 1000 {
+			return Style{}, errNumber
+		}
+		return Style{Value: UnitValue(n)}, nil
+	}
+	return keywordStyle(value, "normal", "bold", "bolder", "lighter")
 }
 func height(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+	return size(value)
+}
+func justifyContent(value string) (Style, error) {
+	return keywordStyle(value, "normal", "flex-start", "flex-end", "start", "end", "center", "left", "right",
+		"space-between", "space-around", "space-evenly", "stretch")
 }
 func left(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+	return offset(value)
 }
 func letterSpacing(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+	return lengthStyle(value, allowNegative, "normal")
 }
+
+// lineHeight returns the style of normal, of a number as UnitValue
+// without unit, or of a length.
 func lineHeight(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+	if _, ok := parseNumber(value); ok {
+		return numberStyle(value, 0, false)
+	}
+	return lengthStyle(value, allowPercent, "normal")
 }
 func listStyle(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+	return shorthandStyle(value, listStylePosition, listStyleImage, listStyleType)
 }
 func listStyleImage(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+	return imageStyle(value)
 }
 func listStylePosition(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+	return keywordStyle(value, "inside", "outside")
 }
+
+// listStyleType returns the style of a counter style name, such as disc
+// or lower-roman, or of a string used as marker.
 func listStyleType(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+	v := strings.TrimSpace(value)
+	components := parseComponents(v)
+	if len(components) != 1 {
+		return Style{}, errValue
+	}
+	switch c := components[0]; c.Type {
+	case ComponentIdent:
+		if isWideKeyword(c.Value) {
+			return Style{}, errKeyword
+		}
+		return Style{Value: strings.ToLower(c.Value)}, nil
+	case ComponentString:
+		if validValue(v) {
+			return Style{Value: c.Value}, nil
+		}
+	}
+	return Style{}, errValue
 }
 func margin(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+	return multiStyle(value, 1, 4, marginSide)
 }
 func marginBottom(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+	return marginSide(value)
 }
 func marginLeft(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+	return marginSide(value)
 }
 func marginRight(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+	return marginSide(value)
+}
+func marginSide(value string) (Style, error) {
+	return lengthStyle(value, allowPercent|allowNegative, "auto")
 }
 func marginTop(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+	return marginSide(value)
+}
+func maxSize(value string) (Style, error) {
+	return lengthStyle(value, allowPercent, "none", "min-content", "max-content", "fit-content")
+}
+func minSize(value string) (Style, error) {
+	return lengthStyle(value, allowPercent, "auto", "min-content", "max-content", "fit-content")
+}
+
+// offset returns the style of the top, right, bottom or left offset.
+func offset(value string) (Style, error) {
+	return lengthStyle(value, allowPercent|allowNegative, "auto")
+}
+
+// opacity returns the style of a number or a percentage, clamped between
+// 0 and 1 by browsers.
+func opacity(value string) (Style, error) {
+	if _, ok := parseNumber(value); ok {
+		return numberStyle(value, -1<<31, false)
+	}
+	style, err := lengthStyle(value, allowPercent|allowNegative)
+	if err != nil || style.unit != UnitPercent {
+		return Style{}, errNumber
+	}
+	return style, nil
+}
+func order(value string) (Style, error) {
+	return numberStyle(value, -1<<31, true)
+}
+func outline(value string) (Style, error) {
+	return shorthandStyle(value, borderSideWidth, outlineStyle, outlineColor)
+}
+func outlineColor(value string) (Style, error) {
+	if strings.EqualFold(strings.TrimSpace(value), "invert") {
+		return Style{Value: "invert"}, nil
+	}
+	return colorStyle(value)
+}
+func outlineStyle(value string) (Style, error) {
+	return keywordStyle(value, append([]string{"auto"}, borderStyles...)...)
 }
 func overflow(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+	return multiStyle(value, 1, 2, overflowAxis)
+}
+func overflowAxis(value string) (Style, error) {
+	return keywordStyle(value, "visible", "hidden", "clip", "scroll", "auto")
 }
 func padding(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+	return multiStyle(value, 1, 4, paddingSide)
 }
 func paddingBottom(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+	return paddingSide(value)
 }
 func paddingLeft(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+	return paddingSide(value)
 }
 func paddingRight(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+	return paddingSide(value)
+}
+func paddingSide(value string) (Style, error) {
+	return lengthStyle(value, allowPercent)
 }
 func paddingTop(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+	return paddingSide(value)
 }
 func pageBreakAfter(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+	return pageBreak(value)
 }
 func pageBreakBefore(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+	return pageBreak(value)
+}
+func pageBreak(value string) (Style, error) {
+	return keywordStyle(value, "auto", "always", "avoid", "left", "right", "recto", "verso")
+}
+func pageBreakInside(value string) (Style, error) {
+	return keywordStyle(value, "auto", "avoid")
+}
+func pointerEvents(value string) (Style, error) {
+	return keywordStyle(value, "auto", "none", "visiblepainted", "visiblefill", "visiblestroke", "visible",
+		"painted", "fill", "stroke", "all", "bounding-box")
 }
 func position(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+	return keywordStyle(value, "static", "relative", "absolute", "fixed", "sticky")
 }
-func float(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+func right(value string) (Style, error) {
+	return offset(value)
+}
+
+// size returns the style of the width or the height.
+func size(value string) (Style, error) {
+	return lengthStyle(value, allowPercent, "auto", "min-content", "max-content", "fit-content")
+}
+func tableLayout(value string) (Style, error) {
+	return keywordStyle(value, "auto", "fixed")
 }
 func textAlign(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+	return keywordStyle(value, "left", "right", "center", "justify", "start", "end", "match-parent", "justify-all")
 }
 func textDecoration(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+	if strings.EqualFold(strings.TrimSpace(value), "none") {
+		return Style{Value: "none"}, nil
+	}
+	style, err := shorthandStyle(value, textDecorationLine, textDecorationLine, textDecorationLine, textDecorationLine,
+		textDecorationStyle, colorStyle, textDecorationThickness)
+	if err != nil {
+		return Style{}, err
+	}
+	// every line can only be given once
+	seen := map[interface{}]bool{}
+	for _, part := range style.Value.([]Style) {
+		if seen[part.Value] {
+			return Style{}, errValue
+		}
+		seen[part.Value] = true
+	}
+	return style, nil
 }
 func textDecorationBlink(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+	return textDecorationKeyword(value, "blink")
 }
 func textDecorationLineThrough(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+	return textDecorationKeyword(value, "line-through")
 }
 func textDecorationNone(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+	return textDecorationKeyword(value, "none")
 }
 func textDecorationOverline(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+	return textDecorationKeyword(value, "overline")
 }
 func textDecorationUnderline(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+	return textDecorationKeyword(value, "underline")
+}
+
+// textDecorationKeyword returns the style of the "text-decoration:
+// keyword" entries of StylesTable, whose value is the keyword or empty.
+func textDecorationKeyword(value, keyword string) (Style, error) {
+	if strings.TrimSpace(value) == "" {
+		return Style{Value: keyword}, nil
+	}
+	return keywordStyle(value, keyword)
+}
+func textDecorationLine(value string) (Style, error) {
+	return keywordStyle(value, "underline", "overline", "line-through", "blink")
+}
+func textDecorationStyle(value string) (Style, error) {
+	return keywordStyle(value, "solid", "double", "dotted", "dashed", "wavy")
+}
+func textDecorationThickness(value string) (Style, error) {
+	return lengthStyle(value, allowPercent, "auto", "from-font")
 }
 func textIndent(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+	parts := splitSpaces(value)
+	if len(parts) == 0 {
+		return Style{}, errValue
+	}
+	for _, part := range parts[1:] {
+		if _, err := keywordStyle(part, "hanging", "each-line"); err != nil {
+			return Style{}, err
+		}
+	}
+	return lengthStyle(parts[0], allowPercent|allowNegative)
+}
+func textOverflow(value string) (Style, error) {
+	if c := parseComponents(strings.TrimSpace(value)); len(c) == 1 && c[0].Type == ComponentString && validValue(value) {
+		return Style{Value: c[0].Value}, nil
+	}
+	return keywordStyle(value, "clip", "ellipsis")
 }
 func textTransform(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+	return keywordStyle(value, "none", "capitalize", "uppercase", "lowercase", "full-width", "full-size-kana")
 }
 func top(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+	return offset(value)
+}
+func unicodeBidi(value string) (Style, error) {
+	return keywordStyle(value, "normal", "embed", "isolate", "bidi-override", "isolate-override", "plaintext")
 }
 func verticalAlign(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+	return lengthStyle(value, allowPercent|allowNegative,
+		"baseline", "sub", "super", "top", "text-top", "middle", "bottom", "text-bottom")
 }
 func visibility(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+	return keywordStyle(value, "visible", "hidden", "collapse")
+}
+func whiteSpace(value string) (Style, error) {
+	return keywordStyle(value, "normal", "pre", "nowrap", "pre-wrap", "pre-line", "break-spaces")
 }
 func width(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+	return size(value)
 }
+func wordSpacing(value string) (Style, error) {
+	return lengthStyle(value, allowPercent|allowNegative, "normal")
+}
+
+// zIndex returns the style of auto, or of an integer as an int value.
 func zIndex(value string) (Style, error) {
-	return Style{}, errors.New("not implemented")
+	if style, err := keywordStyle(value, "auto"); err == nil {
+		return style, nil
+	}
+	style, err := numberStyle(value, -1<<31, true)
+	if err != nil {
+		return Style{}, err
+	}
+	return Style{Value: int(style.Value.(UnitValue))}, nil
+}
+
+var alignKeywords = []string{
+	"normal", "stretch", "center", "start", "end", "flex-start", "flex-end",
+	"self-start", "self-end", "baseline", "first baseline", "last baseline",
+}
+
+func alignContent(value string) (Style, error) {
+	return keywordStyle(value, "normal", "center", "start", "end", "flex-start", "flex-end",
+		"space-between", "space-around", "space-evenly", "stretch", "baseline")
+}
+func alignItems(value string) (Style, error) {
+	return keywordStyle(value, alignKeywords...)
+}
+func alignSelf(value string) (Style, error) {
+	return keywordStyle(value, append([]string{"auto"}, alignKeywords...)...)
 }
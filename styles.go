@@ -14,6 +14,17 @@ const (
 	UnitPercent
 	UnitPt
 	UnitAuto
+	UnitEx
+	UnitCh
+	UnitVw
+	UnitVh
+	UnitVmin
+	UnitVmax
+	UnitCm
+	UnitMm
+	UnitQ
+	UnitIn
+	UnitPc
 )
 
 type Style struct {
@@ -35,10 +46,15 @@ type StyleHandler func(value string) (Style, error)
 
 // Common CSS styles. You can overwrite the handlers with your own.
 var StylesTable = map[string]StyleHandler{
+	"align-content":                 alignContent,
+	"align-items":                   alignItems,
+	"align-self":                    alignSelf,
 	"background":                    background,
 	"background-attachment":         backgroundAttachment,
+	"background-clip":               backgroundBox,
 	"background-color":              backgroundColor,
 	"background-image":              backgroundImage,
+	"background-origin":             backgroundBox,
 	"background-position":           backgroundPosition,
 	"background-repeat":             backgroundRepeat,
 	"border":                        border,
@@ -46,6 +62,7 @@ var StylesTable = map[string]StyleHandler{
 	"border-bottom-color":           borderBottomColor,
 	"border-bottom-style":           borderBottomStyle,
 	"border-bottom-width":           borderBottomWidth,
+	"border-collapse":               borderCollapse,
 	"border-color":                  borderColor,
 	"border-left":                   borderLeft,
 	"border-left-color":             borderLeftColor,
@@ -61,18 +78,34 @@ var StylesTable = map[string]StyleHandler{
 	"border-top-style":              borderTopStyle,
 	"border-top-width":              borderTopWidth,
 	"border-width":                  borderWidth,
+	"bottom":                        bottom,
+	"box-sizing":                    boxSizing,
+	"caption-side":                  captionSide,
 	"clear":                         clear,
 	"clip":                          clip,
 	"color":                         color,
 	"cursor":                        cursor,
+	"direction":                     direction,
 	"display":                       display,
+	"empty-cells":                   emptyCells,
 	"filter":                        filter,
+	"flex":                          flex,
+	"flex-basis":                    flexBasis,
+	"flex-direction":                flexDirection,
+	"flex-flow":                     flexFlow,
+	"flex-grow":                     flexGrow,
+	"flex-shrink":                   flexShrink,
+	"flex-wrap":                     flexWrap,
+	"float":                         float,
 	"font":                          font,
 	"font-family":                   fontFamily,
 	"font-size":                     fontSize,
+	"font-stretch":                  fontStretch,
+	"font-style":                    fontStyle,
 	"font-variant":                  fontVariant,
 	"font-weight":                   fontWeight,
 	"height":                        height,
+	"justify-content":               justifyContent,
 	"left":                          left,
 	"letter-spacing":                letterSpacing,
 	"line-height":                   lineHeight,
@@ -85,7 +118,19 @@ var StylesTable = map[string]StyleHandler{
 	"margin-left":                   marginLeft,
 	"margin-right":                  marginRight,
 	"margin-top":                    marginTop,
+	"max-height":                    maxSize,
+	"max-width":                     maxSize,
+	"min-height":                    minSize,
+	"min-width":                     minSize,
+	"opacity":                       opacity,
+	"order":                         order,
+	"outline":                       outline,
+	"outline-color":                 outlineColor,
+	"outline-style":                 outlineStyle,
+	"outline-width":                 borderSideWidth,
 	"overflow":                      overflow,
+	"overflow-x":                    overflowAxis,
+	"overflow-y":                    overflowAxis,
 	"padding":                       padding,
 	"padding-bottom":                paddingBottom,
 	"padding-left":                  paddingLeft,
@@ -93,8 +138,11 @@ var StylesTable = map[string]StyleHandler{
 	"padding-top":                   paddingTop,
 	"page-break-after":              pageBreakAfter,
 	"page-break-before":             pageBreakBefore,
+	"page-break-inside":             pageBreakInside,
+	"pointer-events":                pointerEvents,
 	"position":                      position,
-	"float":                         float,
+	"right":                         right,
+	"table-layout":                  tableLayout,
 	"text-align":                    textAlign,
 	"text-decoration":               textDecoration,
 	"text-decoration: blink":        textDecorationBlink,
@@ -103,10 +151,14 @@ var StylesTable = map[string]StyleHandler{
 	"text-decoration: overline":     textDecorationOverline,
 	"text-decoration: underline":    textDecorationUnderline,
 	"text-indent":                   textIndent,
+	"text-overflow":                 textOverflow,
 	"text-transform":                textTransform,
 	"top":                           top,
+	"unicode-bidi":                  unicodeBidi,
 	"vertical-align":                verticalAlign,
 	"visibility":                    visibility,
+	"white-space":                   whiteSpace,
 	"width":                         width,
+	"word-spacing":                  wordSpacing,
 	"z-index":                       zIndex,
 }
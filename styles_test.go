@@ -1,6 +1,9 @@
 package css
 
-import "testing"
+import (
+	"reflect"
+	"testing"
+)
 
 func TestStyles(t *testing.T) {
 	_, err := CSSStyle("background-color", map[string]string{"background-color": "bla"})
@@ -12,3 +15,99 @@ func TestStyles(t *testing.T) {
 		t.Fatalf("should be valid color, but got %v", err)
 	}
 }
+
+func TestStyleHandlers(t *testing.T) {
+	valid := []struct {
+		property, value string
+		want            interface{}
+		unit            UnitType
+	}{
+		{"display", "Inline-Block", "inline-block", UnitNone},
+		{"display", "inline flex", "inline flex", UnitNone},
+		{"position", "sticky", "sticky", UnitNone},
+		{"float", "left", "left", UnitNone},
+		{"overflow", "hidden auto", []Style{{Value: "hidden"}, {Value: "auto", unit: UnitAuto}}, UnitNone},
+		{"z-index", "-2", -2, UnitNone},
+		{"z-index", "auto", "auto", UnitAuto},
+		{"line-height", "1.5", UnitValue(1.5), UnitNone},
+		{"line-height", "120%", UnitValue(120), UnitPercent},
+		{"width", "12.5vw", UnitValue(12.5), UnitVw},
+		{"width", "calc(100% - 2em)", "calc(100% - 2em)", UnitNone},
+		{"height", "auto", "auto", UnitAuto},
+		{"margin-left", "-1rem", UnitValue(-1), UnitRem},
+		{"margin", "0 auto", []Style{{Value: UnitValue(0)}, {Value: "auto", unit: UnitAuto}}, UnitNone},
+		{"font-weight", "600", UnitValue(600), UnitNone},
+		{"font-family", `"Helvetica Neue", Arial,  sans-serif`, []string{"Helvetica Neue", "Arial", "sans-serif"}, UnitNone},
+		{"text-decoration", "underline dotted red", nil, UnitNone},
+		{"border", "1px solid currentcolor", []Style{{Value: UnitValue(1), unit: UnitPixels}, {Value: "solid"}, {Value: "currentcolor"}}, UnitNone},
+		{"border-top-width", "thick", "thick", UnitNone},
+		{"background", `url("a.png") no-repeat center / cover, #fff`, nil, UnitNone},
+		{"background-image", "linear-gradient(red, blue)", nil, UnitNone},
+		{"background-position", "left 10px top", nil, UnitNone},
+		{"flex", "1 1 0", map[string]Style{"flex-grow": {Value: UnitValue(1)}, "flex-shrink": {Value: UnitValue(1)}, "flex-basis": {Value: UnitValue(0)}}, UnitNone},
+		{"flex", "2 30%", map[string]Style{"flex-grow": {Value: UnitValue(2)}, "flex-basis": {Value: UnitValue(30), unit: UnitPercent}}, UnitNone},
+		{"flex-flow", "column wrap", nil, UnitNone},
+		{"align-items", "first baseline", "first baseline", UnitNone},
+		{"font", "italic bold 12px/1.5 Georgia, serif", map[string]Style{
+			"font-style":  {Value: "italic"},
+			"font-weight": {Value: "bold"},
+			"font-size":   {Value: UnitValue(12), unit: UnitPixels},
+			"line-height": {Value: UnitValue(1.5)},
+			"font-family": {Value: []string{"Georgia", "serif"}},
+		}, UnitNone},
+		{"font", "menu", map[string]Style{"font": {Value: "menu"}}, UnitNone},
+		{"clip", "rect(0, 10px, auto, 0)", nil, UnitNone},
+		{"cursor", `url("hand.cur") 2 2, pointer`, "pointer", UnitNone},
+		{"list-style", "square inside", nil, UnitNone},
+		{"vertical-align", "-2px", UnitValue(-2), UnitPixels},
+		{"opacity", "0.5", UnitValue(0.5), UnitNone},
+		{"text-decoration: underline", "", "underline", UnitNone},
+		{"color", "inherit", "inherit", UnitNone},
+	}
+	for _, c := range valid {
+		style, err := CSSStyle(c.property, map[string]string{c.property: c.value})
+		if err != nil {
+			t.Fatalf("%s: %s: %v", c.property, c.value, err)
+		}
+		if c.want != nil && (!reflect.DeepEqual(style.Value, c.want) || style.Unit() != c.unit) {
+			t.Fatalf("%s: %s: expected %#v (%d), got %#v (%d)", c.property, c.value, c.want, c.unit, style.Value, style.Unit())
+		}
+	}
+
+	invalid := []struct {
+		property, value string
+	}{
+		{"display", "blocky"},
+		{"display", "block inline"},
+		{"position", "absolute relative"},
+		{"z-index", "1.5"},
+		{"width", "-10px"},
+		{"width", "10"},
+		{"padding", "1px 2px 3px 4px 5px"},
+		{"padding-top", "-1px"},
+		{"margin", "1px foo"},
+		{"font-weight", "1001"},
+		{"font-size", "big"},
+		{"font", "bold Arial"},
+		{"font", "12px"},
+		{"font-family", "Arial,"},
+		{"border", "1px solid red blue"},
+		{"border-width", "10%"},
+		{"border-style", "wavy"},
+		{"background", "red, url(a.png)"},
+		{"background-image", "red"},
+		{"flex", "1 2 3 4"},
+		{"flex", "auto 1 auto"},
+		{"opacity", "1px"},
+		{"line-height", "-1"},
+		{"text-decoration", "underline underline"},
+		{"cursor", "url(hand.cur)"},
+		{"clip", "rect(0, 1px)"},
+		{"visibility", ""},
+	}
+	for _, c := range invalid {
+		if _, err := CSSStyle(c.property, map[string]string{c.property: c.value}); err == nil {
+			t.Fatalf("%s: %q should be invalid", c.property, c.value)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package css
+
+import (
+	"io"
+	"sort"
+	"strings"
+	"text/scanner"
+)
+
+// Stylesheet is a parsed stylesheet keeping its rules and declarations in
+// document order, including duplicates.
+type Stylesheet struct {
+	Rules []*StyleRule
+	// Statements and Comments are only set by ParseStylesheet.
+	Statements []Statement
+	Comments   []Comment
+}
+
+// Statement is an at-rule without a block, such as @import or @charset.
+type Statement struct {
+	// Prelude is the at-rule with its parameters, without the semicolon.
+	Prelude Rule
+	Pos     scanner.Position
+	Source  SourceRange
+}
+
+// StyleRule is a selector with its declarations.
+type StyleRule struct {
+	Selector     Rule
+	Declarations []Declaration
+	// Pos is the position of the selector in the source.
+	Pos scanner.Position
+	// Source is the byte range of the rule in the source, from the start
+	// of the selector to the closing brace.
+	Source SourceRange
+	// AtRules are the at-rules the rule is nested in, outermost first,
+	// such as "@media print".
+	AtRules []Rule
+	// Location is the source file of the rule when the stylesheet is a
+	// bundle returned by Bundle, and nil otherwise.
+	Location *SourceLocation
+}
+
+// UnmarshalStylesheet parses the css in b into a Stylesheet.
+func UnmarshalStylesheet(b []byte) (*Stylesheet, error) {
+	return UnmarshalStylesheetWithOptions(b, ParseOptions{})
+}
+
+// UnmarshalStylesheetWithOptions is like UnmarshalStylesheet, parsing as
+// configured in opts.
+func UnmarshalStylesheetWithOptions(b []byte, opts ParseOptions) (*Stylesheet, error) {
+	b, err := opts.resolve(b)
+	if err != nil {
+		return nil, err
+	}
+	blocks, err := opts.unmarshal(b)
+	if err != nil {
+		return nil, err
+	}
+	return opts.Arena.stylesheet(blocks), nil
+}
+
+// ParseStylesheet reads and parses the stylesheet. Unlike
+// UnmarshalStylesheet, it also keeps the at-rule statements and comments
+// of the stylesheet, at the cost of a second pass over the source.
+func ParseStylesheet(r io.Reader) (*Stylesheet, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	sheet, err := UnmarshalStylesheet(b)
+	if err != nil {
+		return nil, err
+	}
+	sheet.Statements = []Statement{}
+	for _, s := range statementSources(b) {
+		sheet.Statements = append(sheet.Statements, Statement{
+			Prelude: s.Prelude,
+			Pos:     offsetPosition(b, s.Start),
+			Source:  s.SourceRange,
+		})
+	}
+	sheet.Comments = CommentsWithPositions(b)
+	return sheet, nil
+}
+
+// statementSources returns the at-rules without a block of b, such as
+// @import and @charset, ordered by their start.
+func statementSources(b []byte) []RuleSource {
+	statements := []RuleSource{}
+	for _, s := range RuleSources(b) {
+		if strings.HasSuffix(string(s.Text(b)), ";") {
+			statements = append(statements, s)
+		}
+	}
+	return statements
+}
+
+// nestBlocks calls write for every block with the depth of its nesting,
+// and open and close around them so that consecutive blocks nested in the
+// same at-rules share the at-rule blocks. open is called with the first
+// block of the at-rule b.atRules[depth].
+func nestBlocks(blocks []block, open func(b block, depth int), close func(depth int), write func(b block, depth int)) {
+	var opened []string
+	for _, b := range blocks {
+		common := 0
+		for common < len(opened) && common < len(b.atRules) && opened[common] == b.atRules[common] {
+			common++
+		}
+		for len(opened) > common {
+			opened = opened[:len(opened)-1]
+			close(len(opened))
+		}
+		for _, at := range b.atRules[common:] {
+			open(b, len(opened))
+			opened = append(opened, at)
+		}
+		write(b, len(opened))
+	}
+	for len(opened) > 0 {
+		opened = opened[:len(opened)-1]
+		close(len(opened))
+	}
+}
+
+func newStylesheet(blocks []block) *Stylesheet {
+	return (*Arena)(nil).stylesheet(blocks)
+}
+
+// stylesheet returns the stylesheet of the blocks, allocating its rules in
+// the arena. A nil arena allocates them separately.
+func (a *Arena) stylesheet(blocks []block) *Stylesheet {
+	sheet := &Stylesheet{Rules: make([]*StyleRule, len(blocks))}
+	for i, b := range blocks {
+		r := a.rule()
+		*r = StyleRule{
+			Selector:     Rule(b.selector),
+			Declarations: b.styles,
+			Pos:          b.pos,
+			Source:       b.source,
+		}
+		for _, at := range b.atRules {
+			r.AtRules = append(r.AtRules, Rule(at))
+		}
+		sheet.Rules[i] = r
+	}
+	return sheet
+}
+
+// ToLegacyMap returns the stylesheet in the format returned by Unmarshal.
+// Rules with the same selector are merged and the last declaration of a
+// property wins. Rules nested in @media rules are left out, see Media,
+// and so are the @font-face rules and keyframes, which don't merge, see
+// FontFaces and Keyframes.
+func (sheet *Stylesheet) ToLegacyMap() map[Rule]map[string]string {
+	css := make(map[Rule]map[string]string)
+	for _, r := range sheet.Rules {
+		if len(r.mediaConditions()) > 0 || strings.EqualFold(string(r.Selector), "@font-face") {
+			continue
+		}
+		if prelude, _ := r.keyframes(); prelude != "" {
+			continue
+		}
+		styles, ok := css[r.Selector]
+		if !ok {
+			styles = map[string]string{}
+		}
+		// later styles override the ones already merged
+		for _, d := range r.Declarations {
+			styles[d.Property] = d.valueString()
+		}
+		css[r.Selector] = styles
+	}
+	return css
+}
+
+// FromLegacyMap returns a Stylesheet holding the rules of a map returned
+// by Unmarshal. Since maps are not ordered, the rules are sorted by
+// selector and the declarations by property.
+func FromLegacyMap(css map[Rule]map[string]string) *Stylesheet {
+	selectors := make([]string, 0, len(css))
+	for selector := range css {
+		selectors = append(selectors, string(selector))
+	}
+	sort.Strings(selectors)
+
+	sheet := &Stylesheet{Rules: make([]*StyleRule, len(selectors))}
+	for i, selector := range selectors {
+		styles := css[Rule(selector)]
+		properties := make([]string, 0, len(styles))
+		for property := range styles {
+			properties = append(properties, property)
+		}
+		sort.Strings(properties)
+
+		r := &StyleRule{Selector: Rule(selector)}
+		for _, property := range properties {
+			r.Declarations = append(r.Declarations, NewDeclaration(property, styles[property]))
+		}
+		sheet.Rules[i] = r
+	}
+	return sheet
+}
@@ -0,0 +1,80 @@
+package css
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestLegacyMap(t *testing.T) {
+	ex1 := []byte(`rule1 {
+	style1: value1;
+	style2: value2 !important;
+}
+rule2 {
+	style3: value3;
+}
+rule1 {
+	style1: value4;
+}`)
+
+	sheet, err := UnmarshalStylesheet(ex1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sheet.Rules) != 3 || sheet.Rules[2].Pos.Line != 8 {
+		t.Fatalf("expected 3 rules in document order, got %v", sheet.Rules)
+	}
+
+	legacy, err := Unmarshal(ex1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(sheet.ToLegacyMap(), legacy) {
+		t.Fatalf("legacy maps differ: %v != %v", sheet.ToLegacyMap(), legacy)
+	}
+
+	back := FromLegacyMap(legacy)
+	if len(back.Rules) != 2 || back.Rules[0].Selector != "rule1" || len(back.Rules[0].Declarations) != 2 {
+		t.Fatalf("unexpected stylesheet from legacy map %v", back.Rules)
+	}
+	if d := back.Rules[0].Declarations[1]; d.Property != "style2" || !d.Important {
+		t.Fatalf("expected important 'style2', got %+v", d)
+	}
+	if !reflect.DeepEqual(back.ToLegacyMap(), legacy) {
+		t.Fatalf("round trip through the legacy map changed it: %v", back.ToLegacyMap())
+	}
+}
+
+func TestParseStylesheet(t *testing.T) {
+	sheet, err := ParseStylesheet(strings.NewReader(`@charset "utf-8";
+@import url(base.css) print;
+/* links */
+a {
+	color: red;
+	color: blue;
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(sheet.Rules) != 1 || len(sheet.Rules[0].Declarations) != 2 || sheet.Rules[0].Pos.Line != 4 {
+		t.Errorf("unexpected rules %v", sheet.Rules)
+	}
+	if len(sheet.Statements) != 2 {
+		t.Fatalf("expected 2 statements, got %v", sheet.Statements)
+	}
+	if s := sheet.Statements[1]; s.Prelude != "@import url(base.css) print" || s.Pos.Line != 2 || s.Source.Start != 18 {
+		t.Errorf("unexpected statement %+v", s)
+	}
+	if len(sheet.Comments) != 1 || sheet.Comments[0].Text != "/* links */" || sheet.Comments[0].Rule != "a" {
+		t.Errorf("unexpected comments %v", sheet.Comments)
+	}
+
+	if _, err := ParseStylesheet(iotest.ErrReader(errors.New("boom"))); err == nil {
+		t.Error("expected the read error")
+	}
+}
@@ -0,0 +1,95 @@
+package css
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// presentationAttributes are the SVG attributes mapping to the CSS
+// property of the same name.
+var presentationAttributes = []string{
+	"alignment-baseline", "baseline-shift", "clip", "clip-path", "clip-rule",
+	"color", "color-interpolation", "color-interpolation-filters",
+	"color-rendering", "cursor", "direction", "display", "dominant-baseline",
+	"fill", "fill-opacity", "fill-rule", "filter", "flood-color",
+	"flood-opacity", "font-family", "font-size", "font-size-adjust",
+	"font-stretch", "font-style", "font-variant", "font-weight",
+	"image-rendering", "letter-spacing", "lighting-color", "marker-end",
+	"marker-mid", "marker-start", "mask", "opacity", "overflow",
+	"paint-order", "pointer-events", "shape-rendering", "stop-color",
+	"stop-opacity", "stroke", "stroke-dasharray", "stroke-dashoffset",
+	"stroke-linecap", "stroke-linejoin", "stroke-miterlimit",
+	"stroke-opacity", "stroke-width", "text-anchor", "text-decoration",
+	"text-overflow", "text-rendering", "transform", "transform-origin",
+	"unicode-bidi", "vector-effect", "visibility", "white-space",
+	"word-spacing", "writing-mode",
+}
+
+// presentationLengths are the presentation attributes taking lengths,
+// where SVG allows unitless numbers that CSS reads as pixels.
+var presentationLengths = map[string]bool{
+	"baseline-shift":    true,
+	"font-size":         true,
+	"letter-spacing":    true,
+	"stroke-dashoffset": true,
+	"word-spacing":      true,
+}
+
+// IsPresentationAttribute reports whether the SVG attribute is a
+// presentation attribute.
+func IsPresentationAttribute(name string) bool {
+	i := sort.SearchStrings(presentationAttributes, name)
+	return i < len(presentationAttributes) && presentationAttributes[i] == name
+}
+
+// PresentationDeclarations returns the declarations of the presentation
+// attributes of an SVG element, in attribute name order. Unitless lengths
+// are given the px unit. Elements outside the SVG namespace have none.
+func PresentationDeclarations(el Element) Declarations {
+	if elementNamespace(el) != SVGNamespace {
+		return nil
+	}
+	decls := Declarations{}
+	for _, name := range presentationAttributes {
+		v, ok := el.Attr(name)
+		if v = strings.TrimSpace(v); !ok || v == "" {
+			continue
+		}
+		if presentationLengths[name] {
+			if _, err := strconv.ParseFloat(v, 64); err == nil {
+				v += "px"
+			}
+		}
+		decls = append(decls, Declaration{Property: name, Value: v})
+	}
+	return decls
+}
+
+// PresentationAttributes returns the SVG presentation attributes setting
+// the declarations. Declarations of other properties are skipped, and so
+// are the important ones which an attribute can not express. The last
+// declaration of a property wins.
+func PresentationAttributes(decls Declarations) map[string]string {
+	attrs := map[string]string{}
+	for _, d := range decls {
+		name := strings.ToLower(d.Property)
+		if d.Important || !IsPresentationAttribute(name) {
+			continue
+		}
+		attrs[name] = d.Value
+	}
+	return attrs
+}
+
+// presentationEntries returns the cascade entries of the presentation
+// attributes of the element. They count as author declarations with zero
+// specificity, preceding every author style sheet.
+func presentationEntries(el Element) []CascadeEntry {
+	decls := PresentationDeclarations(el)
+	entries := make([]CascadeEntry, len(decls))
+	for i, d := range decls {
+		entries[i] = CascadeEntry{Declaration: d, Origin: OriginAuthor, Order: i - len(decls)}
+	}
+	return entries
+}
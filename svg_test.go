@@ -0,0 +1,58 @@
+package css
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPresentationDeclarations(t *testing.T) {
+	el := &SimpleElement{
+		Tag:          "circle",
+		NamespaceURI: SVGNamespace,
+		Attrs:        map[string]string{"fill": "red", "stroke-width": "2", "font-size": "12", "r": "5"},
+	}
+	want := Declarations{
+		{Property: "fill", Value: "red"},
+		{Property: "font-size", Value: "12px"},
+		{Property: "stroke-width", Value: "2"},
+	}
+	if got := PresentationDeclarations(el); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+
+	el.NamespaceURI = ""
+	if got := PresentationDeclarations(el); len(got) != 0 {
+		t.Fatalf("HTML element: got %v", got)
+	}
+}
+
+func TestPresentationAttributes(t *testing.T) {
+	decls, err := UnmarshalStyle([]byte("fill: blue; margin: 0; stroke: red !important; Stroke-Width: 3px"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"fill": "blue", "stroke-width": "3px"}
+	if got := PresentationAttributes(decls); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestCascadePresentationAttributes(t *testing.T) {
+	sheet, err := ParseStylesheet(strings.NewReader("* {\n\tstroke: black;\n}\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	el := &SimpleElement{
+		Tag:          "rect",
+		NamespaceURI: SVGNamespace,
+		Attrs:        map[string]string{"fill": "red", "stroke": "blue"},
+	}
+	got := NewCascade(sheet).Resolve(el, nil)
+	if fill, _ := got.Get("fill"); fill.Value != "red" {
+		t.Errorf("fill: got %q", fill.Value)
+	}
+	if stroke, _ := got.Get("stroke"); stroke.Value != "black" {
+		t.Errorf("stroke: got %q", stroke.Value)
+	}
+}
@@ -0,0 +1,238 @@
+package css
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ParamType is the type of a Template parameter. Values are validated
+// against it before they are substituted.
+type ParamType int
+
+const (
+	// ParamIdent is a keyword such as bold.
+	ParamIdent ParamType = iota
+	// ParamColor is a hex color, a color keyword or a color function.
+	ParamColor
+	// ParamLength is a dimension with a length unit, or 0.
+	ParamLength
+	// ParamNumber is a number without a unit.
+	ParamNumber
+	// ParamString is any text, written as a quoted string.
+	ParamString
+	// ParamURL is a url, written as url("...").
+	ParamURL
+)
+
+var (
+	rTemplateMarker = regexp.MustCompile(`/\*\s*css:var\s+([\w-]+)\s*\*/[ \t]*`)
+
+	lengthUnits = []string{
+		"px", "em", "rem", "ex", "ch", "vw", "vh", "vmin", "vmax",
+		"cm", "mm", "q", "in", "pt", "pc", "%",
+	}
+	colorFunctions = []string{
+		"rgb", "rgba", "hsl", "hsla", "hwb", "lab", "lch", "oklab", "oklch", "color",
+	}
+)
+
+// Template is a stylesheet with placeholders that are substituted with
+// values at render time, such as the colors of a theme.
+//
+// A placeholder is either a var() reference to a parameter, as in
+// "color: var(--brand, #00f)", or a marker comment in front of a value,
+// as in "color: /* css:var brand */ #00f". The fallback and the value
+// after the marker are used when no value is given.
+type Template struct {
+	b      []byte
+	params map[string]ParamType
+	slots  []templateSlot
+}
+
+// templateSlot is a placeholder in the source of a template.
+type templateSlot struct {
+	name       string
+	start, end int
+	fallback   string
+}
+
+// ParseTemplate returns the template of the stylesheet with the
+// parameters. Parameter names are written without the leading "--".
+// Marker comments naming unknown parameters and unclosed var() references
+// are an error; var() references to other custom properties are left
+// alone.
+func ParseTemplate(b []byte, params map[string]ParamType) (*Template, error) {
+	t := &Template{b: b, params: params}
+	blank := string(blankComments(b))
+
+	for i := 0; ; i++ {
+		next := strings.Index(blank[i:], "var(")
+		if next < 0 {
+			break
+		}
+		i += next
+		if i > 0 && isNameByte(blank[i-1]) {
+			continue
+		}
+		end := scanFunction(blank, i+len("var"))
+		if end == len(blank) {
+			return nil, fmt.Errorf("unclosed var() at %d", i)
+		}
+		args := strings.SplitN(blank[i+len("var("):end], ",", 2)
+		name := strings.TrimPrefix(strings.TrimSpace(args[0]), "--")
+		if _, ok := params[name]; ok {
+			s := templateSlot{name: name, start: i, end: end + 1}
+			if len(args) == 2 {
+				s.fallback = strings.TrimSpace(args[1])
+			}
+			t.slots = append(t.slots, s)
+		}
+	}
+
+	for _, m := range rTemplateMarker.FindAllSubmatchIndex(b, -1) {
+		name := string(b[m[2]:m[3]])
+		if _, ok := params[name]; !ok {
+			return nil, fmt.Errorf("unknown template parameter %q at %d", name, m[0])
+		}
+		end := m[1]
+		for end < len(blank) && strings.IndexByte(";}!\n", blank[end]) < 0 {
+			end++
+		}
+		value := strings.TrimSpace(string(b[m[1]:end]))
+		t.slots = append(t.slots, templateSlot{name, m[0], m[1] + len(value), value})
+	}
+
+	sort.Slice(t.slots, func(i, j int) bool { return t.slots[i].start < t.slots[j].start })
+	return t, nil
+}
+
+// Render returns the stylesheet with the placeholders substituted with
+// the values. Values are validated against the type of their parameter
+// and strings and urls are escaped, so a value can't change the structure
+// of the stylesheet.
+func (t *Template) Render(values map[string]string) ([]byte, error) {
+	for name := range values {
+		if _, ok := t.params[name]; !ok {
+			return nil, fmt.Errorf("unknown template parameter %q", name)
+		}
+	}
+
+	var (
+		buf   bytes.Buffer
+		start = 0
+	)
+	for _, s := range t.slots {
+		if s.start < start {
+			continue
+		}
+		value, ok := values[s.name]
+		if ok {
+			var err error
+			if value, err = t.params[s.name].format(value); err != nil {
+				return nil, fmt.Errorf("template parameter %q: %v", s.name, err)
+			}
+		} else if value = s.fallback; value == "" {
+			return nil, fmt.Errorf("missing value for template parameter %q", s.name)
+		}
+		buf.Write(t.b[start:s.start])
+		buf.WriteString(value)
+		start = s.end
+	}
+	buf.Write(t.b[start:])
+	return buf.Bytes(), nil
+}
+
+// format validates the value and returns it as css.
+func (typ ParamType) format(value string) (string, error) {
+	switch typ {
+	case ParamString:
+		return quoteString(value), nil
+	case ParamURL:
+		switch urlScheme(value) {
+		case "", "http", "https", "data":
+		default:
+			return "", fmt.Errorf("unsafe url %q", value)
+		}
+		return "url(" + quoteString(value) + ")", nil
+	}
+
+	value = strings.TrimSpace(value)
+	components := parseComponents(value)
+	if strings.ContainsAny(value, ";{}<>\\") || strings.Contains(value, "/*") || len(components) != 1 {
+		return "", fmt.Errorf("invalid value %q", value)
+	}
+	c := components[0]
+	valid := false
+	switch typ {
+	case ParamIdent:
+		valid = c.Type == ComponentIdent && !strings.HasPrefix(c.Value, "--")
+	case ParamColor:
+		switch c.Type {
+		case ComponentHash:
+			valid = strings.Trim(strings.ToLower(c.Value), "0123456789abcdef") == "" &&
+				(len(c.Value) == 3 || len(c.Value) == 4 || len(c.Value) == 6 || len(c.Value) == 8)
+		case ComponentIdent:
+			name := strings.ToLower(c.Value)
+			valid = name == "transparent" || name == "currentcolor" || checkColor(name) == nil
+		case ComponentFunction:
+			valid = containsString(colorFunctions, strings.ToLower(c.Value))
+		}
+	case ParamLength:
+		valid = c.Type == ComponentDimension &&
+			(containsString(lengthUnits, strings.ToLower(c.Unit)) || c.Unit == "" && c.Number == 0)
+	case ParamNumber:
+		valid = c.Type == ComponentDimension && c.Unit == ""
+	}
+	if !valid {
+		return "", fmt.Errorf("invalid value %q", value)
+	}
+	return value, nil
+}
+
+// urlScheme returns the lowercased scheme of the url, or an empty string
+// for a relative url. Like browsers, it ignores the tabs and line breaks
+// of the url and the control characters and spaces around it, so
+// "java\tscript:" is the javascript scheme.
+func urlScheme(url string) string {
+	url = strings.TrimFunc(url, func(r rune) bool { return r <= ' ' })
+	url = strings.NewReplacer("\t", "", "\n", "", "\r", "").Replace(url)
+	for i := 0; i < len(url); i++ {
+		c := url[i]
+		switch {
+		case c == ':':
+			if i == 0 {
+				return ""
+			}
+			return strings.ToLower(url[:i])
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+		case i > 0 && (c >= '0' && c <= '9' || c == '+' || c == '-' || c == '.'):
+		default:
+			return ""
+		}
+	}
+	return ""
+}
+
+// quoteString returns s as a double quoted css string. Line breaks and
+// "<" are escaped as hex escapes, so the string can't end the string or a
+// surrounding <style> element.
+func quoteString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"', '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case '\n', '\r', '\f', '<', 0:
+			fmt.Fprintf(&b, "\\%x ", r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
@@ -0,0 +1,91 @@
+package css
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTemplate(t *testing.T) {
+	src := `.header {
+	color: var(--brand, #00f);
+	background: var(--background) no-repeat;
+	padding: /* css:var gap */ 4px;
+	font-family: var(--font, sans-serif);
+	margin: var(--other);
+}
+.header::after {
+	content: /* css:var tagline */ "";
+}
+`
+	tmpl, err := ParseTemplate([]byte(src), map[string]ParamType{
+		"brand":      ParamColor,
+		"background": ParamURL,
+		"gap":        ParamLength,
+		"font":       ParamIdent,
+		"tagline":    ParamString,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := tmpl.Render(map[string]string{
+		"brand":      "#ff8800",
+		"background": "/img/a b.png",
+		"tagline":    `say "hi"</style>`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `.header {
+	color: #ff8800;
+	background: url("/img/a b.png") no-repeat;
+	padding: 4px;
+	font-family: sans-serif;
+	margin: var(--other);
+}
+.header::after {
+	content: "say \"hi\"\3c /style>";
+}
+`
+	if string(out) != expected {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+
+	invalid := []map[string]string{
+		{"background": "a.png", "brand": "red; } body { color: red"},
+		{"background": "a.png", "gap": "10"},
+		{"background": "a.png", "gap": "1px 2px"},
+		{"background": "a.png", "font": "--x"},
+		{"background": "javascript:alert(1)"},
+		{"background": " JavaScript:alert(1)"},
+		{"background": "java\tscript:alert(1)"},
+		{"background": "vbscript:msgbox(1)"},
+		{"background": "file:///etc/passwd"},
+		{"background": "a.png", "brand": "notacolor"},
+		{"background": "a.png", "unknown": "x"},
+		{},
+	}
+	for _, values := range invalid {
+		if _, err := tmpl.Render(values); err == nil {
+			t.Errorf("expected an error for %v", values)
+		}
+	}
+
+	for _, values := range []map[string]string{
+		{"background": "https://example.com/a.png", "brand": "transparent"},
+		{"background": "data:image/png;base64,AAAA", "brand": "currentColor"},
+		{"background": "img/a:b.png", "brand": "rebeccapurple"},
+	} {
+		if _, err := tmpl.Render(values); err != nil {
+			t.Errorf("unexpected error for %v: %v", values, err)
+		}
+	}
+
+	if _, err := ParseTemplate([]byte("a { color: var(--brand"), map[string]ParamType{"brand": ParamColor}); err == nil {
+		t.Error("expected an error for an unclosed var()")
+	}
+
+	if _, err := ParseTemplate([]byte("a {\n\tcolor: /* css:var x */ red;\n}\n"), nil); err == nil || !strings.Contains(err.Error(), `"x"`) {
+		t.Errorf("expected an unknown parameter error, got %v", err)
+	}
+}
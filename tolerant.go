@@ -0,0 +1,19 @@
+package css
+
+// ParseTolerant parses the css in b recovering from syntax errors the way
+// browsers do, instead of the undefined results of Unmarshal on invalid
+// css. Invalid declarations are skipped, a stray statement or "}" between
+// rules invalidates the prelude of the next rule, which is dropped with
+// its content, and the blocks left open at the end of the source are
+// closed. It returns the best-effort stylesheet with the recoverable
+// errors in source order.
+func ParseTolerant(b []byte) (*Stylesheet, []*ParseError) {
+	p := newBlockParser(ParseOptions{})
+	p.tolerant = true
+	p.diagnostics = []*ParseError{}
+	for e := Tokenize(b).Front(); e != nil; e = e.Next() {
+		p.feed(e.Value.(TokenEntry))
+	}
+	p.finish()
+	return newStylesheet(p.blocks), p.diagnostics
+}
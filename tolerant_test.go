@@ -0,0 +1,67 @@
+package css
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTolerant(t *testing.T) {
+	src := []byte(`a {
+	color red;
+	margin: 0;
+}
+stray: value;
+b {
+	color: blue;
+}
+c {
+	color: green;
+}
+}
+d {
+	color: black;
+}
+@media print {
+	e {
+		color: white;
+	}
+	f {
+		padding: 1px`)
+
+	sheet, diagnostics := ParseTolerant(src)
+	var got []string
+	for _, r := range sheet.Rules {
+		got = append(got, string(r.Selector)+" { "+Declarations(r.Declarations).String()+" }")
+	}
+	want := []string{
+		"a { margin: 0 }",
+		"c { color: green }",
+		"e { color: white }",
+		"f { padding: 1px }",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected rules %q, got %q", want, got)
+	}
+	last := sheet.Rules[len(sheet.Rules)-1]
+	if last.Source.End != len(src) || len(last.AtRules) != 1 {
+		t.Fatalf("unexpected closed rule %+v", last)
+	}
+
+	var errs []string
+	for _, d := range diagnostics {
+		errs = append(errs, d.Error())
+	}
+	wantErrs := []string{
+		`2:2: unexpected "color", expected property`,
+		`5:1: unexpected "stray", expected {`,
+		`12:1: unexpected "}", expected selector or at-rule`,
+		`21:15: unexpected end of input, expected }`,
+	}
+	if !reflect.DeepEqual(errs, wantErrs) {
+		t.Fatalf("expected diagnostics %q, got %q", wantErrs, errs)
+	}
+
+	if _, diagnostics := ParseTolerant([]byte("a { color: red; }")); len(diagnostics) != 0 {
+		t.Fatalf("expected no diagnostics, got %v", diagnostics)
+	}
+}
@@ -0,0 +1,112 @@
+package css
+
+import (
+	"fmt"
+	"strings"
+	"text/scanner"
+)
+
+// ValidationError is a problem found by Validate.
+type ValidationError struct {
+	Pos scanner.Position
+	// Selector is the selector of the rule, and Property the property of
+	// the declaration, empty for malformed selectors.
+	Selector Rule
+	Property string
+	Message  string
+}
+
+func (e ValidationError) Error() string {
+	if e.Property == "" {
+		return fmt.Sprintf("%d:%d: %s: %s", e.Pos.Line, e.Pos.Column, string(e.Selector), e.Message)
+	}
+	return fmt.Sprintf("%d:%d: %s: %s", e.Pos.Line, e.Pos.Column, e.Property, e.Message)
+}
+
+// otherProperties are the common properties that are neither in
+// StylesTable nor in Properties, such as the shorthands and the grid
+// properties.
+var otherProperties = []string{
+	"accent-color", "all", "animation", "appearance", "aspect-ratio",
+	"backdrop-filter", "background-blend-mode", "border-block",
+	"border-image", "border-inline", "border-radius", "caret-color",
+	"column-count", "column-rule", "column-width", "columns",
+	"font-display", "font-feature-settings", "gap", "grid",
+	"grid-area", "grid-auto-columns", "grid-auto-flow", "grid-auto-rows",
+	"grid-column", "grid-column-end", "grid-column-start", "grid-row",
+	"grid-row-end", "grid-row-start", "grid-template", "grid-template-areas",
+	"grid-template-columns", "grid-template-rows", "hyphens", "inset",
+	"isolation", "justify-items", "justify-self", "mask", "mask-image",
+	"mix-blend-mode", "object-fit", "object-position",
+	"overscroll-behavior", "place-content",
+	"place-items", "place-self", "resize", "scroll-behavior",
+	"scroll-margin", "scroll-padding", "scroll-snap-align",
+	"scroll-snap-type", "tab-size", "text-decoration-color",
+	"text-decoration-line", "text-decoration-style", "text-rendering",
+	"text-underline-offset", "touch-action", "transform-origin",
+	"transition", "user-select",
+}
+
+// Validate checks the stylesheet against the known properties, like a
+// lint. It reports the declarations of unknown properties, the values
+// rejected by the StylesTable handlers of their properties, and the
+// malformed selectors, in document order. A property is known when it is
+// in StylesTable, Properties or VendorPrefixes, or is one of the common
+// shorthands and recent properties. Custom properties, vendor prefixed
+// properties and the descriptors of at-rules such as @font-face are not
+// checked, and neither are the values with var() references, the css-wide
+// keywords such as inherit, and the styles whose handler returns
+// ErrNotImplemented.
+func Validate(sheet *Stylesheet) []ValidationError {
+	errs := []ValidationError{}
+	for _, r := range sheet.Rules {
+		if strings.HasPrefix(string(r.Selector), "@") {
+			continue
+		}
+		if isStyleRule(r) {
+			if _, err := ParseSelector(r.Selector); err != nil {
+				errs = append(errs, ValidationError{
+					Pos:      r.Pos,
+					Selector: r.Selector,
+					Message:  "malformed selector: " + err.Error(),
+				})
+				continue
+			}
+		}
+		for _, d := range r.Declarations {
+			if message := validateDeclaration(d); message != "" {
+				errs = append(errs, ValidationError{
+					Pos:      d.Pos,
+					Selector: r.Selector,
+					Property: d.Property,
+					Message:  message,
+				})
+			}
+		}
+	}
+	return errs
+}
+
+// validateDeclaration returns the problem of the declaration, or an empty
+// string.
+func validateDeclaration(d Declaration) string {
+	property := strings.ToLower(d.Property)
+	if strings.HasPrefix(property, "-") {
+		return ""
+	}
+	handler, ok := StylesTable[property]
+	if !ok {
+		if _, known := Properties[property]; !known && VendorPrefixes[property] == nil &&
+			!containsString(otherProperties, property) {
+			return "unknown property"
+		}
+		return ""
+	}
+	if isWideKeyword(d.Value) || strings.Contains(strings.ToLower(d.Value), "var(") {
+		return ""
+	}
+	if _, err := handler(d.Value); err != nil && err != ErrNotImplemented {
+		return fmt.Sprintf("invalid value %q: %v", d.Value, err)
+	}
+	return ""
+}
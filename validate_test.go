@@ -0,0 +1,75 @@
+package css
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	sheet, err := UnmarshalStylesheet([]byte(`a {
+	color: red;
+	colr: blue;
+	background-color: notacolor;
+	color: inherit;
+	color: var(--brand);
+	display: flex;
+	gap: 1em;
+	-webkit-box-flex: 1;
+	--brand: #123;
+}
+a >> b {
+	color: red;
+}
+@font-face {
+	font-family: Foo;
+	src: url(foo.woff2);
+}
+@keyframes spin {
+	to { transfrm: rotate(1turn); }
+}
+@media print {
+	p > { color: blue; }
+}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, e := range Validate(sheet) {
+		got = append(got, e.Error())
+	}
+	want := []string{
+		`3:2: colr: unknown property`,
+		`4:2: background-color: invalid value "notacolor": invalid color`,
+		`12:1: a >> b: malformed selector: ` + selectorError(t, "a >> b"),
+		`20:7: transfrm: unknown property`,
+		`23:2: p >: malformed selector: ` + selectorError(t, "p >"),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+func selectorError(t *testing.T, selector Rule) string {
+	_, err := ParseSelector(selector)
+	if err == nil {
+		t.Fatalf("%s should be malformed", selector)
+	}
+	return err.Error()
+}
+
+func TestValidateValidCSS(t *testing.T) {
+	sheets := []*Stylesheet{HTMLStylesheet}
+	for seed := int64(0); seed < 10; seed++ {
+		sheet, err := UnmarshalStylesheet(Generate(GenerateOptions{Seed: seed, Media: 0.2}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		sheets = append(sheets, sheet)
+	}
+	for _, sheet := range sheets {
+		if errs := Validate(sheet); len(errs) != 0 {
+			t.Fatalf("valid css should have no validation errors, got %v", errs)
+		}
+	}
+}
@@ -0,0 +1,311 @@
+package css
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ComponentType is the type of a ComponentValue.
+type ComponentType int
+
+const (
+	// ComponentIdent is a keyword such as auto or red.
+	ComponentIdent ComponentType = iota
+	// ComponentDimension is a number with an optional unit, such as 10px,
+	// 50% or 1.5.
+	ComponentDimension
+	// ComponentFunction is a function with its arguments, such as
+	// rgb(0, 0, 0) or url(a.png).
+	ComponentFunction
+	// ComponentString is a quoted string.
+	ComponentString
+	// ComponentHash is a hash such as #fff.
+	ComponentHash
+	// ComponentOperator is a delimiter such as a comma or a slash.
+	ComponentOperator
+	// ComponentPercentage is a percentage such as 50%. Number is 50 and
+	// Unit is "%".
+	ComponentPercentage
+	// ComponentColor is a color: a hex color such as #fff, a color
+	// function such as rgb(0, 0, 0) with its Args, or a named color.
+	ComponentColor
+	// ComponentURL is a url().
+	ComponentURL
+)
+
+// ComponentValue is a single typed part of a declaration value.
+type ComponentValue struct {
+	Type ComponentType
+	// Value is the name of an ident or function, the content of a string,
+	// the name of a hash without "#", the operator or the number of a
+	// dimension as written. For colors it is the hex color with its "#",
+	// the function name or the color name, and for urls the url.
+	Value string
+	// Number and Unit are set for dimensions. Percentages have the "%"
+	// unit.
+	Number float64
+	Unit   string
+	// Args are the arguments of a function.
+	Args []ComponentValue
+}
+
+// Components returns the value of the declaration split into component
+// values. Unlike TypedComponents, percentages are dimensions, colors are
+// hashes, idents or functions, and urls are url functions with the url
+// as string argument.
+func (d Declaration) Components() []ComponentValue {
+	return parseComponents(d.Value)
+}
+
+// TypedComponents returns the value of the declaration split into typed
+// component values, see ParseValue.
+func (d Declaration) TypedComponents() []ComponentValue {
+	return ParseValue(d.Value)
+}
+
+// ParseValue splits a declaration value into typed component values,
+// such as a dimension, a keyword and a percentage for "10px auto 50%".
+// Percentages, colors and urls have their own types, including in the
+// arguments of functions.
+func ParseValue(value string) []ComponentValue {
+	return typeComponents(parseComponents(value))
+}
+
+// typeComponents gives the components returned by parseComponents their
+// types: parseComponents only knows the syntax, so percentages are
+// dimensions, colors are hashes, idents or functions, and urls are url
+// functions with the url as string argument.
+func typeComponents(components []ComponentValue) []ComponentValue {
+	for i := range components {
+		c := &components[i]
+		switch c.Type {
+		case ComponentDimension:
+			if c.Unit == "%" {
+				c.Type = ComponentPercentage
+			}
+		case ComponentHash:
+			if n := len(c.Value); (n == 3 || n == 4 || n == 6 || n == 8) && checkColor("#"+c.Value) == nil {
+				c.Type, c.Value = ComponentColor, "#"+c.Value
+			}
+		case ComponentIdent:
+			switch name := strings.ToLower(c.Value); name {
+			case "transparent", "currentcolor":
+				c.Type = ComponentColor
+			default:
+				if checkColor(name) == nil {
+					c.Type = ComponentColor
+				}
+			}
+		case ComponentFunction:
+			name := strings.ToLower(c.Value)
+			if name == "url" && len(c.Args) == 1 && c.Args[0].Type == ComponentString {
+				*c = ComponentValue{Type: ComponentURL, Value: c.Args[0].Value}
+				break
+			}
+			if containsString(colorFunctions, name) {
+				c.Type = ComponentColor
+			}
+			c.Args = typeComponents(c.Args)
+		}
+	}
+	return components
+}
+
+// String returns the component as css.
+func (c ComponentValue) String() string {
+	switch c.Type {
+	case ComponentDimension, ComponentPercentage:
+		return c.Value + c.Unit
+	case ComponentFunction:
+		return c.Value + "(" + joinComponents(c.Args) + ")"
+	case ComponentColor:
+		if c.Args != nil {
+			return c.Value + "(" + joinComponents(c.Args) + ")"
+		}
+	case ComponentURL:
+		return "url(" + strconv.Quote(c.Value) + ")"
+	case ComponentString:
+		return strconv.Quote(c.Value)
+	case ComponentHash:
+		return "#" + c.Value
+	}
+	return c.Value
+}
+
+// joinComponents serializes the components, separating them with spaces
+// except before commas.
+func joinComponents(components []ComponentValue) string {
+	var b strings.Builder
+	for i, c := range components {
+		if i > 0 && !(c.Type == ComponentOperator && c.Value == ",") {
+			b.WriteByte(' ')
+		}
+		b.WriteString(c.String())
+	}
+	return b.String()
+}
+
+// parseComponents splits a value into component values.
+func parseComponents(value string) []ComponentValue {
+	var (
+		components = []ComponentValue{}
+		i          = 0
+	)
+	for i < len(value) {
+		c := value[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f':
+			i++
+		case c == '"' || c == '\'':
+			end := scanString(value, i)
+			components = append(components, ComponentValue{
+				Type:  ComponentString,
+				Value: unquote(value[i:end]),
+			})
+			i = end
+		case c == '#' && i+1 < len(value) && isNameByte(value[i+1]):
+			end := scanName(value, i+1)
+			components = append(components, ComponentValue{Type: ComponentHash, Value: value[i+1 : end]})
+			i = end
+		case startsNumber(value, i):
+			end := scanNumber(value, i)
+			dim := ComponentValue{Type: ComponentDimension, Value: value[i:end]}
+			dim.Number, _ = strconv.ParseFloat(dim.Value, 64)
+			if end < len(value) && value[end] == '%' {
+				dim.Unit = "%"
+				end++
+			} else {
+				unitEnd := scanName(value, end)
+				dim.Unit = value[end:unitEnd]
+				end = unitEnd
+			}
+			components = append(components, dim)
+			i = end
+		case isNameByte(c) || c == '\\' && i+1 < len(value):
+			// a lone trailing backslash is a delim, scanned by the
+			// default case
+			end := scanName(value, i)
+			name := value[i:end]
+			if name == "-" {
+				components = append(components, ComponentValue{Type: ComponentOperator, Value: name})
+				i = end
+				break
+			}
+			if end < len(value) && value[end] == '(' {
+				closing := scanFunction(value, end)
+				inner := value[end+1 : closing]
+				if closing < len(value) {
+					closing++
+				}
+				fn := ComponentValue{Type: ComponentFunction, Value: name}
+				trimmed := strings.TrimSpace(inner)
+				if strings.EqualFold(name, "url") && trimmed != "" && trimmed[0] != '"' && trimmed[0] != '\'' {
+					fn.Args = []ComponentValue{{Type: ComponentString, Value: trimmed}}
+				} else {
+					fn.Args = parseComponents(inner)
+				}
+				components = append(components, fn)
+				i = closing
+				break
+			}
+			components = append(components, ComponentValue{Type: ComponentIdent, Value: name})
+			i = end
+		default:
+			components = append(components, ComponentValue{Type: ComponentOperator, Value: string(c)})
+			i++
+		}
+	}
+	return components
+}
+
+// startsNumber reports whether a number starts at index i.
+func startsNumber(s string, i int) bool {
+	isDigit := func(j int) bool { return j < len(s) && s[j] >= '0' && s[j] <= '9' }
+	if s[i] == '+' || s[i] == '-' {
+		i++
+	}
+	return isDigit(i) || (i < len(s) && s[i] == '.' && isDigit(i+1))
+}
+
+// scanNumber returns the index after the number starting at index i.
+func scanNumber(s string, i int) int {
+	digits := func(j int) int {
+		for j < len(s) && s[j] >= '0' && s[j] <= '9' {
+			j++
+		}
+		return j
+	}
+	if s[i] == '+' || s[i] == '-' {
+		i++
+	}
+	i = digits(i)
+	if i+1 < len(s) && s[i] == '.' && s[i+1] >= '0' && s[i+1] <= '9' {
+		i = digits(i + 1)
+	}
+	if i+1 < len(s) && (s[i] == 'e' || s[i] == 'E') {
+		j := i + 1
+		if s[j] == '+' || s[j] == '-' {
+			j++
+		}
+		if j < len(s) && s[j] >= '0' && s[j] <= '9' {
+			i = digits(j)
+		}
+	}
+	return i
+}
+
+// scanString returns the index after the quoted string starting at i.
+func scanString(s string, i int) int {
+	quote := s[i]
+	for i++; i < len(s); i++ {
+		if s[i] == '\\' {
+			i++
+		} else if s[i] == quote {
+			return i + 1
+		}
+	}
+	return len(s)
+}
+
+// scanFunction returns the index of the parenthesis closing the one
+// opened at i, or len(s) if it is not closed.
+func scanFunction(s string, i int) int {
+	depth := 0
+	for ; i < len(s); i++ {
+		switch s[i] {
+		case '"', '\'':
+			i = scanString(s, i) - 1
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(s)
+}
+
+// unquote returns the content of a quoted string with escapes removed.
+func unquote(s string) string {
+	if len(s) < 2 {
+		return s
+	}
+	quote := s[0]
+	s = s[1:]
+	if strings.HasSuffix(s, string(quote)) {
+		s = s[:len(s)-1]
+	}
+	if !strings.Contains(s, "\\") {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
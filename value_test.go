@@ -0,0 +1,120 @@
+package css
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestComponents(t *testing.T) {
+	d := NewDeclaration("background", `url(img/a.png) no-repeat, rgba(0, 0, 0, .5) -10px 50% / "x" #fff`)
+	components := d.Components()
+
+	expected := []struct {
+		typ   ComponentType
+		value string
+	}{
+		{ComponentFunction, "url"},
+		{ComponentIdent, "no-repeat"},
+		{ComponentOperator, ","},
+		{ComponentFunction, "rgba"},
+		{ComponentDimension, "-10"},
+		{ComponentDimension, "50"},
+		{ComponentOperator, "/"},
+		{ComponentString, "x"},
+		{ComponentHash, "fff"},
+	}
+	if len(components) != len(expected) {
+		t.Fatalf("expected %d components, got %d: %v", len(expected), len(components), components)
+	}
+	for i, e := range expected {
+		if components[i].Type != e.typ || components[i].Value != e.value {
+			t.Fatalf("component %d: expected %v %q, got %v %q", i, e.typ, e.value, components[i].Type, components[i].Value)
+		}
+	}
+
+	if url := components[0].Args; len(url) != 1 || url[0].Value != "img/a.png" {
+		t.Fatalf("unexpected url arguments %v", url)
+	}
+	if args := components[3].Args; len(args) != 7 || args[6].Number != 0.5 {
+		t.Fatalf("unexpected rgba arguments %v", args)
+	}
+	if components[4].Unit != "px" || components[4].Number != -10 || components[5].Unit != "%" {
+		t.Fatalf("unexpected dimensions %v %v", components[4], components[5])
+	}
+	if s := components[3].String(); s != "rgba(0, 0, 0, .5)" {
+		t.Fatalf("unexpected serialization %q", s)
+	}
+}
+
+func TestParseValue(t *testing.T) {
+	components := ParseValue(`0 auto 10px 50% #fff #abcdefgh "a b" url(a.png) url("b c.png") Red rgb(0 0 0 / 50%) var(--x, 1em)`)
+	want := []struct {
+		typ   ComponentType
+		value string
+	}{
+		{ComponentDimension, "0"},
+		{ComponentIdent, "auto"},
+		{ComponentDimension, "10"},
+		{ComponentPercentage, "50"},
+		{ComponentColor, "#fff"},
+		{ComponentHash, "abcdefgh"},
+		{ComponentString, "a b"},
+		{ComponentURL, "a.png"},
+		{ComponentURL, "b c.png"},
+		{ComponentColor, "Red"},
+		{ComponentColor, "rgb"},
+		{ComponentFunction, "var"},
+	}
+	if len(components) != len(want) {
+		t.Fatalf("got %d components, want %d: %v", len(components), len(want), components)
+	}
+	for i, w := range want {
+		if c := components[i]; c.Type != w.typ || c.Value != w.value {
+			t.Errorf("component %d: got %d %q, want %d %q", i, c.Type, c.Value, w.typ, w.value)
+		}
+	}
+
+	rgb := components[10]
+	if len(rgb.Args) != 5 || rgb.Args[4].Type != ComponentPercentage || rgb.Args[4].Number != 50 {
+		t.Errorf("got rgb arguments %v", rgb.Args)
+	}
+	if got := components[8].String() + " " + rgb.String(); got != `url("b c.png") rgb(0 0 0 / 50%)` {
+		t.Errorf("got %q", got)
+	}
+	if got := components[4].String(); got != "#fff" {
+		t.Errorf("got %q", got)
+	}
+
+	typed := NewDeclaration("background", "url(a.png) 50% #fff").TypedComponents()
+	if len(typed) != 3 || typed[0].Type != ComponentURL || typed[1].Type != ComponentPercentage || typed[2].Type != ComponentColor {
+		t.Errorf("got typed components %v", typed)
+	}
+}
+
+func TestComponentsTrailingBackslash(t *testing.T) {
+	got := Declaration{Value: `c\`}.Components()
+	want := []ComponentValue{
+		{Type: ComponentIdent, Value: "c"},
+		{Type: ComponentOperator, Value: `\`},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+	if got := ParseValue(`\`); len(got) != 1 || got[0].Value != `\` {
+		t.Fatalf("unexpected components %+v", got)
+	}
+	if _, err := ParseColor(`red\`); err == nil {
+		t.Fatal(`red\ should not be a color`)
+	}
+	src := []byte(`a{b:c\}`)
+	sheet, err := UnmarshalStylesheet(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	NewVariableGraph(sheet)
+	ResourceHints(src, HintOptions{})
+	Validate(sheet)
+	if _, err := CSSStyle("width", map[string]string{"width": `c\`}); err == nil {
+		t.Fatal(`c\ should not be a width`)
+	}
+}
@@ -0,0 +1,202 @@
+package css
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// VariableGraph is the graph of the custom properties of a stylesheet:
+// which custom properties reference which others through var(), and which
+// rules consume them in regular properties.
+type VariableGraph struct {
+	// References maps every declared custom property to the custom
+	// properties its values reference, sorted and without duplicates.
+	References map[string][]string
+	// Consumers maps every referenced custom property to the selectors of
+	// the rules using it in a regular property, in document order.
+	Consumers map[string][]Rule
+}
+
+// NewVariableGraph returns the custom property graph of the stylesheet.
+func NewVariableGraph(sheet *Stylesheet) VariableGraph {
+	g := VariableGraph{
+		References: map[string][]string{},
+		Consumers:  map[string][]Rule{},
+	}
+	for _, r := range sheet.Rules {
+		for _, d := range r.Declarations {
+			refs := variableReferences(d.TypedComponents())
+			if strings.HasPrefix(d.Property, "--") {
+				g.References[d.Property] = mergeSorted(g.References[d.Property], refs)
+				continue
+			}
+			for _, name := range refs {
+				consumers := g.Consumers[name]
+				if len(consumers) == 0 || consumers[len(consumers)-1] != r.Selector {
+					g.Consumers[name] = append(consumers, r.Selector)
+				}
+			}
+		}
+	}
+	return g
+}
+
+// variableReferences returns the custom properties referenced with var()
+// in the components, including in fallbacks.
+func variableReferences(components []ComponentValue) []string {
+	refs := []string{}
+	for _, c := range components {
+		if c.Type == ComponentFunction && strings.EqualFold(c.Value, "var") && len(c.Args) > 0 &&
+			c.Args[0].Type == ComponentIdent && strings.HasPrefix(c.Args[0].Value, "--") {
+			refs = append(refs, c.Args[0].Value)
+		}
+		// var() can be nested in any function, including color functions
+		refs = append(refs, variableReferences(c.Args)...)
+	}
+	return refs
+}
+
+// mergeSorted returns the sorted union of a and b without duplicates.
+func mergeSorted(a, b []string) []string {
+	all := append(append([]string{}, a...), b...)
+	sort.Strings(all)
+	out := all[:0]
+	for i, s := range all {
+		if i == 0 || s != all[i-1] {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// Undefined returns the referenced custom properties that are never
+// declared, sorted.
+func (g VariableGraph) Undefined() []string {
+	undefined := []string{}
+	add := func(name string) {
+		if _, ok := g.References[name]; !ok && !containsString(undefined, name) {
+			undefined = append(undefined, name)
+		}
+	}
+	for _, refs := range g.References {
+		for _, name := range refs {
+			add(name)
+		}
+	}
+	for name := range g.Consumers {
+		add(name)
+	}
+	sort.Strings(undefined)
+	return undefined
+}
+
+// Cycles returns the groups of custom properties referencing each other
+// in a cycle. Browsers treat all of them as invalid at computed-value
+// time. Every cycle is sorted, and the cycles are sorted by their first
+// property.
+func (g VariableGraph) Cycles() [][]string {
+	names := make([]string, 0, len(g.References))
+	for name := range g.References {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// Tarjan's strongly connected components
+	var (
+		cycles  = [][]string{}
+		index   = map[string]int{}
+		low     = map[string]int{}
+		onStack = map[string]bool{}
+		stack   = []string{}
+		visit   func(name string)
+	)
+	visit = func(name string) {
+		index[name] = len(index)
+		low[name] = index[name]
+		stack = append(stack, name)
+		onStack[name] = true
+
+		selfLoop := false
+		for _, ref := range g.References[name] {
+			if ref == name {
+				selfLoop = true
+			}
+			if _, seen := index[ref]; !seen {
+				if _, declared := g.References[ref]; !declared {
+					continue
+				}
+				visit(ref)
+				low[name] = min(low[name], low[ref])
+			} else if onStack[ref] {
+				low[name] = min(low[name], index[ref])
+			}
+		}
+
+		if low[name] != index[name] {
+			return
+		}
+		component := []string{}
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[top] = false
+			component = append(component, top)
+			if top == name {
+				break
+			}
+		}
+		if len(component) > 1 || selfLoop {
+			sort.Strings(component)
+			cycles = append(cycles, component)
+		}
+	}
+	for _, name := range names {
+		if _, seen := index[name]; !seen {
+			visit(name)
+		}
+	}
+
+	sort.Slice(cycles, func(i, j int) bool { return cycles[i][0] < cycles[j][0] })
+	return cycles
+}
+
+// WriteDOT writes the graph in the Graphviz format. Custom properties are
+// ellipses and rules are boxes, with an edge from every custom property
+// or rule to the custom properties it uses.
+func (g VariableGraph) WriteDOT(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("digraph variables {\n")
+
+	names := make([]string, 0, len(g.References))
+	for name := range g.References {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, ref := range g.References[name] {
+			fmt.Fprintf(&b, "\t%q -> %q;\n", name, ref)
+		}
+	}
+
+	consumed := make([]string, 0, len(g.Consumers))
+	for name := range g.Consumers {
+		consumed = append(consumed, name)
+	}
+	sort.Strings(consumed)
+	rules := map[Rule]bool{}
+	for _, name := range consumed {
+		for _, r := range g.Consumers[name] {
+			if !rules[r] {
+				rules[r] = true
+				fmt.Fprintf(&b, "\t%q [shape=box];\n", string(r))
+			}
+			fmt.Fprintf(&b, "\t%q -> %q;\n", string(r), name)
+		}
+	}
+
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
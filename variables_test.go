@@ -0,0 +1,62 @@
+package css
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestVariableGraph(t *testing.T) {
+	sheet, err := UnmarshalStylesheet([]byte(`html {
+	--blue: #00f;
+	--primary: var(--blue);
+	--text: var(--primary, var(--fallback));
+	--a: var(--b);
+	--b: var(--a);
+	--self: var(--self);
+}
+.btn {
+	color: var(--text);
+	background: var(--primary);
+}
+.link {
+	color: var(--primary);
+	border-color: rgb(var(--blue) / 50%);
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := NewVariableGraph(sheet)
+
+	if refs := strings.Join(g.References["--text"], " "); refs != "--fallback --primary" {
+		t.Errorf("unexpected references of --text: %s", refs)
+	}
+	if len(g.References["--blue"]) != 0 {
+		t.Errorf("unexpected references of --blue: %v", g.References["--blue"])
+	}
+	if c := g.Consumers["--primary"]; len(c) != 2 || c[0] != ".btn" || c[1] != ".link" {
+		t.Errorf("unexpected consumers of --primary: %v", c)
+	}
+	if c := g.Consumers["--blue"]; len(c) != 1 || c[0] != ".link" {
+		t.Errorf("unexpected consumers of --blue: %v", c)
+	}
+	if u := g.Undefined(); len(u) != 1 || u[0] != "--fallback" {
+		t.Errorf("unexpected undefined properties %v", u)
+	}
+
+	cycles := g.Cycles()
+	if len(cycles) != 2 || strings.Join(cycles[0], " ") != "--a --b" || strings.Join(cycles[1], " ") != "--self" {
+		t.Errorf("unexpected cycles %v", cycles)
+	}
+
+	var buf bytes.Buffer
+	if err := g.WriteDOT(&buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{`"--primary" -> "--blue";`, `".btn" [shape=box];`, `".btn" -> "--text";`} {
+		if !strings.Contains(buf.String(), s) {
+			t.Errorf("expected %q in:\n%s", s, buf.String())
+		}
+	}
+}
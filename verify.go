@@ -0,0 +1,104 @@
+package css
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/scanner"
+)
+
+// Divergence is a difference between a stylesheet and the result of
+// serializing and parsing it again, found by Verify.
+type Divergence struct {
+	// Pos is the position in the original css of the rule, declaration or
+	// statement that changed.
+	Pos     scanner.Position
+	Message string
+}
+
+func (d Divergence) String() string {
+	return fmt.Sprintf("%d:%d: %s", d.Pos.Line, d.Pos.Column, d.Message)
+}
+
+// Verify parses the css, serializes it with MarshalStylesheet, parses the
+// result again and compares both stylesheets after normalizing
+// whitespace. It returns the differences, which are none when the round
+// trip preserves the stylesheet, so transforms built on the parser can be
+// trusted with it. An error is returned when the stylesheet can't be
+// parsed or serialized.
+func Verify(b []byte) ([]Divergence, error) {
+	sheet, err := ParseStylesheet(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	out, err := MarshalStylesheet(sheet)
+	if err != nil {
+		return nil, err
+	}
+	again, err := ParseStylesheet(bytes.NewReader(out))
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		divergences = []Divergence{}
+		report      = func(pos scanner.Position, format string, args ...interface{}) {
+			divergences = append(divergences, Divergence{Pos: pos, Message: fmt.Sprintf(format, args...)})
+		}
+	)
+	for i, s := range sheet.Statements {
+		if i >= len(again.Statements) {
+			report(s.Pos, "statement %s is lost", s.Prelude)
+		} else if got := normalizeSpace(string(again.Statements[i].Prelude)); got != normalizeSpace(string(s.Prelude)) {
+			report(s.Pos, "statement %s becomes %s", s.Prelude, got)
+		}
+	}
+	for i, r := range sheet.Rules {
+		if i >= len(again.Rules) {
+			report(r.Pos, "rule %s is lost", r.Selector)
+			continue
+		}
+		verifyRule(r, again.Rules[i], report)
+	}
+	for _, r := range again.Rules[min(len(sheet.Rules), len(again.Rules)):] {
+		report(scanner.Position{}, "rule %s is added", r.Selector)
+	}
+	return divergences, nil
+}
+
+// verifyRule reports the differences between the rule and its round trip.
+func verifyRule(r, again *StyleRule, report func(scanner.Position, string, ...interface{})) {
+	if got := normalizeSpace(string(again.Selector)); got != normalizeSpace(string(r.Selector)) {
+		report(r.Pos, "selector %s becomes %s", r.Selector, got)
+		return
+	}
+	if got, want := atRulesString(again.AtRules), atRulesString(r.AtRules); got != want {
+		report(r.Pos, "at-rules of %s change from %q to %q", r.Selector, want, got)
+	}
+	for j, d := range r.Declarations {
+		if j >= len(again.Declarations) {
+			report(d.Pos, "declaration %s is lost", d)
+			continue
+		}
+		got := again.Declarations[j]
+		if !strings.EqualFold(got.Property, d.Property) || got.Important != d.Important ||
+			normalizeValue(got.Value) != normalizeValue(d.Value) {
+			report(d.Pos, "declaration %s becomes %s", d, got)
+		}
+	}
+	for _, d := range again.Declarations[min(len(r.Declarations), len(again.Declarations)):] {
+		report(r.Pos, "declaration %s is added to %s", d, r.Selector)
+	}
+}
+
+func atRulesString(atRules []Rule) string {
+	parts := make([]string, len(atRules))
+	for i, at := range atRules {
+		parts[i] = normalizeSpace(string(at))
+	}
+	return strings.Join(parts, " ")
+}
+
+func normalizeSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,43 @@
+package css
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"text/scanner"
+)
+
+func TestVerify(t *testing.T) {
+	divergences, err := Verify([]byte(`@import "a.css" screen;
+/* comment */
+.a > .b, .c { color: red; margin: 0 auto !important; }
+@media (min-width: 600px) {
+	.a:hover::before { content: "}"; background: url(a.png) }
+}
+@font-face { font-family: X; src: url(x.woff2) format("woff2"); }
+@keyframes spin { from { opacity: 0 } 50.5% { opacity: .5 } }
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(divergences) != 0 {
+		t.Errorf("got divergences %v", divergences)
+	}
+}
+
+func TestVerifyRule(t *testing.T) {
+	var got []string
+	report := func(pos scanner.Position, format string, args ...interface{}) {
+		got = append(got, Divergence{Pos: pos, Message: fmt.Sprintf(format, args...)}.String())
+	}
+	r := &StyleRule{
+		Selector:     ".a",
+		Pos:          scanner.Position{Line: 1, Column: 1},
+		Declarations: []Declaration{{Property: "color", Value: "red", Pos: scanner.Position{Line: 2, Column: 2}}, {Property: "top", Value: "0"}},
+	}
+	verifyRule(r, &StyleRule{Selector: ".a", Declarations: []Declaration{{Property: "color", Value: "blue"}}}, report)
+	want := "2:2: declaration color: red becomes color: blue\n0:0: declaration top: 0 is lost"
+	if strings.Join(got, "\n") != want {
+		t.Errorf("got:\n%s\nwant:\n%s", strings.Join(got, "\n"), want)
+	}
+}
@@ -0,0 +1,211 @@
+package css
+
+import (
+	"bytes"
+	"os"
+	"sync"
+	"text/scanner"
+	"time"
+)
+
+// ChangeKind is the kind of a RuleChange.
+type ChangeKind int
+
+const (
+	// RuleAdded is a rule that only exists in the new stylesheet.
+	RuleAdded ChangeKind = iota
+	// RuleRemoved is a rule that only exists in the old stylesheet.
+	RuleRemoved
+	// RuleModified is a rule whose declarations changed.
+	RuleModified
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case RuleAdded:
+		return "added"
+	case RuleRemoved:
+		return "removed"
+	case RuleModified:
+		return "modified"
+	}
+	return "unknown"
+}
+
+// RuleChange is a rule that changed between two versions of a stylesheet.
+type RuleChange struct {
+	// File is the name of the changed file. It is empty for changes
+	// returned by DiffStylesheets.
+	File     string
+	Kind     ChangeKind
+	Selector Rule
+	// Pos is the position of the rule in the new stylesheet, or in the old
+	// one for removed rules.
+	Pos scanner.Position
+}
+
+// ruleKey identifies a rule by its selector and the number of rules with
+// the same selector before it.
+type ruleKey struct {
+	selector Rule
+	n        int
+}
+
+// DiffStylesheets returns the rules added, removed and modified between
+// the from and to stylesheets. Rules are matched by selector; repeated
+// selectors are matched in order of appearance. Added and modified rules
+// are in the order of to, followed by the removed rules in the order of
+// from.
+func DiffStylesheets(from, to *Stylesheet) []RuleChange {
+	var (
+		changes = []RuleChange{}
+		before  = map[ruleKey]*StyleRule{}
+		seen    = map[ruleKey]bool{}
+	)
+	keys := func(sheet *Stylesheet, fn func(ruleKey, *StyleRule)) {
+		count := map[Rule]int{}
+		for _, r := range sheet.Rules {
+			fn(ruleKey{r.Selector, count[r.Selector]}, r)
+			count[r.Selector]++
+		}
+	}
+
+	keys(from, func(k ruleKey, r *StyleRule) { before[k] = r })
+	keys(to, func(k ruleKey, r *StyleRule) {
+		seen[k] = true
+		prev, ok := before[k]
+		switch {
+		case !ok:
+			changes = append(changes, RuleChange{Kind: RuleAdded, Selector: r.Selector, Pos: r.Pos})
+		case prev.Hash() != r.Hash():
+			changes = append(changes, RuleChange{Kind: RuleModified, Selector: r.Selector, Pos: r.Pos})
+		}
+	})
+	keys(from, func(k ruleKey, r *StyleRule) {
+		if !seen[k] {
+			changes = append(changes, RuleChange{Kind: RuleRemoved, Selector: r.Selector, Pos: r.Pos})
+		}
+	})
+
+	return changes
+}
+
+// Watcher polls stylesheet files and reports the rules that changed every
+// time one of them is modified.
+type Watcher struct {
+	events chan RuleChange
+	errors chan error
+	done   chan struct{}
+	once   sync.Once
+	files  map[string]*watchedFile
+}
+
+type watchedFile struct {
+	modTime time.Time
+	data    []byte
+	sheet   *Stylesheet
+}
+
+// NewWatcher starts watching the files, checking them for changes every
+// interval. The files must exist and are parsed before NewWatcher returns.
+func NewWatcher(interval time.Duration, files ...string) (*Watcher, error) {
+	w := &Watcher{
+		events: make(chan RuleChange),
+		errors: make(chan error),
+		done:   make(chan struct{}),
+		files:  map[string]*watchedFile{},
+	}
+	for _, name := range files {
+		info, err := os.Stat(name)
+		if err != nil {
+			return nil, err
+		}
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		sheet, err := UnmarshalStylesheet(data)
+		if err != nil {
+			return nil, err
+		}
+		w.files[name] = &watchedFile{info.ModTime(), data, sheet}
+	}
+	go w.run(interval, files)
+	return w, nil
+}
+
+// Events returns the channel the rule changes are delivered on.
+func (w *Watcher) Events() <-chan RuleChange {
+	return w.events
+}
+
+// Errors returns the channel the errors reading or parsing the files are
+// delivered on.
+func (w *Watcher) Errors() <-chan error {
+	return w.errors
+}
+
+// Close stops watching the files.
+func (w *Watcher) Close() error {
+	w.once.Do(func() { close(w.done) })
+	return nil
+}
+
+func (w *Watcher) run(interval time.Duration, files []string) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+		}
+		for _, name := range files {
+			changes, err := w.check(name)
+			if err != nil {
+				select {
+				case w.errors <- err:
+				case <-w.done:
+					return
+				}
+			}
+			for _, c := range changes {
+				select {
+				case w.events <- c:
+				case <-w.done:
+					return
+				}
+			}
+		}
+	}
+}
+
+// check re-parses the file if it was modified and returns its changes.
+func (w *Watcher) check(name string) ([]RuleChange, error) {
+	f := w.files[name]
+	info, err := os.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+	if info.ModTime().Equal(f.modTime) && info.Size() == int64(len(f.data)) {
+		return nil, nil
+	}
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	f.modTime = info.ModTime()
+	if bytes.Equal(data, f.data) {
+		return nil, nil
+	}
+	sheet, err := UnmarshalStylesheet(data)
+	if err != nil {
+		return nil, err
+	}
+	changes := DiffStylesheets(f.sheet, sheet)
+	for i := range changes {
+		changes[i].File = name
+	}
+	f.data, f.sheet = data, sheet
+	return changes, nil
+}
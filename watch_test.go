@@ -0,0 +1,60 @@
+package css
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDiffStylesheets(t *testing.T) {
+	old, _ := UnmarshalStylesheet([]byte(".a {\n\tcolor: red;\n}\n.b {\n\ttop: 0;\n}\n.c {\n\tleft: 0;\n}\n"))
+	new, _ := UnmarshalStylesheet([]byte(".a {\n\tcolor: blue;\n}\n.c {\n\tleft: 0;\n}\n.d {\n\tright: 0;\n}\n"))
+
+	changes := DiffStylesheets(old, new)
+	want := []struct {
+		kind     ChangeKind
+		selector Rule
+		line     int
+	}{
+		{RuleModified, ".a", 1},
+		{RuleAdded, ".d", 7},
+		{RuleRemoved, ".b", 4},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("got %d changes %v, want %d", len(changes), changes, len(want))
+	}
+	for i, w := range want {
+		c := changes[i]
+		if c.Kind != w.kind || c.Selector != w.selector || c.Pos.Line != w.line {
+			t.Errorf("change %d: got %v %q line %d, want %v %q line %d",
+				i, c.Kind, c.Selector, c.Pos.Line, w.kind, w.selector, w.line)
+		}
+	}
+}
+
+func TestWatcher(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "style.css")
+	if err := os.WriteFile(name, []byte(".a {\n\tcolor: red;\n}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	w, err := NewWatcher(5*time.Millisecond, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	if err := os.WriteFile(name, []byte(".a {\n\tcolor: red;\n}\n.b {\n\ttop: 0;\n}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case c := <-w.Events():
+		if c.File != name || c.Kind != RuleAdded || c.Selector != ".b" {
+			t.Fatalf("unexpected change %v", c)
+		}
+	case err := <-w.Errors():
+		t.Fatal(err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("no change reported")
+	}
+}
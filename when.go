@@ -0,0 +1,342 @@
+package css
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+)
+
+// ConditionKind is the kind of a Condition.
+type ConditionKind int
+
+const (
+	// ConditionMedia is a media() test with a media query.
+	ConditionMedia ConditionKind = iota
+	// ConditionSupports is a supports() test with a supports condition.
+	ConditionSupports
+	// ConditionNot negates its single child.
+	ConditionNot
+	// ConditionAnd is true when all of its children are.
+	ConditionAnd
+	// ConditionOr is true when any of its children is.
+	ConditionOr
+)
+
+// Condition is a boolean condition of the @when and @else rules, such as
+// media(print) and supports(display: grid).
+type Condition struct {
+	Kind ConditionKind
+	// Query is the argument of media() and supports() tests.
+	Query    string
+	Children []*Condition
+}
+
+var errInvalidCondition = errors.New("invalid condition")
+
+// ParseCondition parses the condition of an @when or @else rule.
+func ParseCondition(s string) (*Condition, error) {
+	p := conditionParser{s: s}
+	c, err := p.condition()
+	if err != nil {
+		return nil, err
+	}
+	if p.skipSpace(); p.i < len(p.s) {
+		return nil, errInvalidCondition
+	}
+	return c, nil
+}
+
+func (c *Condition) String() string {
+	switch c.Kind {
+	case ConditionMedia:
+		return "media(" + c.Query + ")"
+	case ConditionSupports:
+		return "supports(" + c.Query + ")"
+	case ConditionNot:
+		return "not " + c.Children[0].operand()
+	}
+	op := " and "
+	if c.Kind == ConditionOr {
+		op = " or "
+	}
+	parts := make([]string, len(c.Children))
+	for i, child := range c.Children {
+		parts[i] = child.operand()
+	}
+	return strings.Join(parts, op)
+}
+
+// operand returns the condition as an operand of not, and or or.
+func (c *Condition) operand() string {
+	if c.Kind == ConditionAnd || c.Kind == ConditionOr {
+		return "(" + c.String() + ")"
+	}
+	return c.String()
+}
+
+type conditionParser struct {
+	s string
+	i int
+}
+
+func (p *conditionParser) skipSpace() {
+	for p.i < len(p.s) && strings.IndexByte(" \t\n\r\f", p.s[p.i]) >= 0 {
+		p.i++
+	}
+}
+
+// keyword consumes the keyword if it is next.
+func (p *conditionParser) keyword(word string) bool {
+	p.skipSpace()
+	end := scanName(p.s, p.i)
+	if !strings.EqualFold(p.s[p.i:end], word) {
+		return false
+	}
+	p.i = end
+	return true
+}
+
+func (p *conditionParser) condition() (*Condition, error) {
+	if p.keyword("not") {
+		c, err := p.operand()
+		if err != nil {
+			return nil, err
+		}
+		return &Condition{Kind: ConditionNot, Children: []*Condition{c}}, nil
+	}
+	first, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+	var combined *Condition
+	for {
+		kind := ConditionAnd
+		if p.keyword("or") {
+			kind = ConditionOr
+		} else if !p.keyword("and") {
+			break
+		}
+		if combined == nil {
+			combined = &Condition{Kind: kind, Children: []*Condition{first}}
+		} else if combined.Kind != kind {
+			// and and or can't be mixed without parentheses
+			return nil, errInvalidCondition
+		}
+		c, err := p.operand()
+		if err != nil {
+			return nil, err
+		}
+		combined.Children = append(combined.Children, c)
+	}
+	if combined == nil {
+		return first, nil
+	}
+	return combined, nil
+}
+
+func (p *conditionParser) operand() (*Condition, error) {
+	p.skipSpace()
+	if p.i < len(p.s) && p.s[p.i] == '(' {
+		end := scanFunction(p.s, p.i)
+		if end == len(p.s) {
+			return nil, errInvalidCondition
+		}
+		inner := conditionParser{s: p.s[p.i+1 : end]}
+		c, err := inner.condition()
+		if err != nil {
+			return nil, err
+		}
+		if inner.skipSpace(); inner.i < len(inner.s) {
+			return nil, errInvalidCondition
+		}
+		p.i = end + 1
+		return c, nil
+	}
+
+	end := scanName(p.s, p.i)
+	name := strings.ToLower(p.s[p.i:end])
+	if end == len(p.s) || p.s[end] != '(' || (name != "media" && name != "supports") {
+		return nil, errInvalidCondition
+	}
+	closing := scanFunction(p.s, end)
+	if closing == len(p.s) {
+		return nil, errInvalidCondition
+	}
+	c := &Condition{Kind: ConditionMedia, Query: strings.TrimSpace(p.s[end+1 : closing])}
+	if name == "supports" {
+		c.Kind = ConditionSupports
+	}
+	p.i = closing + 1
+	return c, nil
+}
+
+// conditionTest is a media() or supports() test, possibly negated.
+type conditionTest struct {
+	kind    ConditionKind
+	query   string
+	negated bool
+}
+
+// disjunction returns the condition, negated when negated is set, as a
+// list of alternatives that are each a list of tests that must all be
+// true.
+func (c *Condition) disjunction(negated bool) [][]conditionTest {
+	switch c.Kind {
+	case ConditionMedia, ConditionSupports:
+		parts := splitTopLevel(c.Query, ',')
+		if c.Kind == ConditionSupports || !negated || len(parts) == 1 {
+			return [][]conditionTest{{{c.Kind, c.Query, negated}}}
+		}
+		// a negated media query list is true when none of its queries is
+		tests := []conditionTest{}
+		for _, part := range parts {
+			tests = append(tests, conditionTest{c.Kind, strings.TrimSpace(part), true})
+		}
+		return [][]conditionTest{tests}
+	case ConditionNot:
+		return c.Children[0].disjunction(!negated)
+	}
+	// not (a and b) is not a or not b, and not (a or b) is not a and not b
+	if (c.Kind == ConditionOr) != negated {
+		alternatives := [][]conditionTest{}
+		for _, child := range c.Children {
+			alternatives = append(alternatives, child.disjunction(negated)...)
+		}
+		return alternatives
+	}
+	alternatives := [][]conditionTest{{}}
+	for _, child := range c.Children {
+		product := [][]conditionTest{}
+		for _, a := range alternatives {
+			for _, b := range child.disjunction(negated) {
+				product = append(product, append(append([]conditionTest{}, a...), b...))
+			}
+		}
+		alternatives = product
+	}
+	return alternatives
+}
+
+// DownlevelWhen rewrites the top-level @when and @else rules of the
+// stylesheet into @media and @supports rules understood by current
+// browsers. An @else rule applies when its own condition is true and
+// the conditions of all previous rules of the chain are false. Rules
+// whose condition has alternatives are repeated once per alternative.
+// Chains with an invalid condition are dropped, as browsers do.
+func DownlevelWhen(b []byte) []byte {
+	var (
+		buf bytes.Buffer
+		// previous holds the conditions of the current @when chain
+		previous = []*Condition{}
+		valid    = false
+	)
+	for _, item := range topLevelItems(b) {
+		name := atRuleName(item)
+		if name != "when" && name != "else" {
+			buf.WriteString(item)
+			buf.WriteByte('\n')
+			previous = previous[:0]
+			continue
+		}
+
+		body := string(blankComments([]byte(item)))
+		open, end := strings.IndexByte(body, '{'), strings.LastIndexByte(body, '}')
+		if open < 0 || end < open {
+			continue
+		}
+		prelude := strings.TrimSpace(body[strings.IndexByte(body, '@')+1+len(name) : open])
+		if name == "when" {
+			previous, valid = previous[:0], true
+		} else if len(previous) == 0 {
+			// @else without @when is invalid
+			continue
+		}
+
+		var own *Condition
+		if prelude != "" || name == "when" {
+			c, err := ParseCondition(prelude)
+			if err != nil {
+				valid = false
+			}
+			own = c
+		}
+		if !valid {
+			continue
+		}
+
+		cond := &Condition{Kind: ConditionAnd}
+		for _, p := range previous {
+			cond.Children = append(cond.Children, &Condition{Kind: ConditionNot, Children: []*Condition{p}})
+		}
+		if own != nil {
+			cond.Children = append(cond.Children, own)
+			previous = append(previous, own)
+		}
+
+		content := strings.TrimSpace(item[open+1 : end])
+		for _, tests := range cond.disjunction(false) {
+			writeConditional(&buf, tests, content)
+		}
+	}
+	return buf.Bytes()
+}
+
+// writeConditional writes the content nested in an @media rule for every
+// media test and an @supports rule with all supports tests.
+func writeConditional(buf *bytes.Buffer, tests []conditionTest, content string) {
+	closing := 0
+	supports := []string{}
+	for _, t := range tests {
+		if t.kind == ConditionSupports {
+			s := "(" + t.query + ")"
+			if t.negated {
+				s = "not " + s
+			}
+			supports = append(supports, s)
+			continue
+		}
+		query := mediaQuery(t.query)
+		if t.negated {
+			query = negateMediaQuery(query)
+		}
+		buf.WriteString("@media " + query + " {\n")
+		closing++
+	}
+	if len(supports) > 0 {
+		buf.WriteString("@supports " + strings.Join(supports, " and ") + " {\n")
+		closing++
+	}
+	buf.WriteString(content)
+	buf.WriteByte('\n')
+	buf.WriteString(strings.Repeat("}\n", closing))
+}
+
+// mediaTypes are the media types of media queries.
+var mediaTypes = map[string]bool{"all": true, "print": true, "screen": true, "speech": true}
+
+// mediaQuery returns the argument of a media() test as a media query. The
+// argument is usually a media feature without its parentheses, such as
+// width >= 600px, but media types and full queries are accepted too.
+func mediaQuery(arg string) string {
+	words := strings.Fields(strings.ToLower(arg))
+	if strings.HasPrefix(arg, "(") || len(words) == 0 || mediaTypes[words[0]] ||
+		words[0] == "not" || words[0] == "only" {
+		return arg
+	}
+	return "(" + arg + ")"
+}
+
+// negateMediaQuery returns the media query matching when the single
+// query doesn't.
+func negateMediaQuery(query string) string {
+	lower := strings.ToLower(query)
+	switch {
+	case strings.HasPrefix(lower, "not "):
+		return strings.TrimSpace(query[len("not "):])
+	case strings.HasPrefix(lower, "only "):
+		return "not " + strings.TrimSpace(query[len("only "):])
+	case strings.HasPrefix(query, "("):
+		return "not all and " + query
+	}
+	return "not " + query
+}
@@ -0,0 +1,113 @@
+package css
+
+import "testing"
+
+func TestParseCondition(t *testing.T) {
+	tests := []struct {
+		in, out string
+	}{
+		{"media(print)", "media(print)"},
+		{"media(width >= 600px) and supports(display: grid)", "media(width >= 600px) and supports(display: grid)"},
+		{"not media(print)", "not media(print)"},
+		{"media(print) or (supports(display: grid) and media(color))", "media(print) or (supports(display: grid) and media(color))"},
+		{"((media(print)))", "media(print)"},
+	}
+	for _, test := range tests {
+		c, err := ParseCondition(test.in)
+		if err != nil {
+			t.Errorf("%q: %v", test.in, err)
+			continue
+		}
+		if c.String() != test.out {
+			t.Errorf("%q: got %q, want %q", test.in, c.String(), test.out)
+		}
+	}
+
+	for _, invalid := range []string{"", "print", "media(print) and", "media(a) and media(b) or media(c)", "media(print"} {
+		if _, err := ParseCondition(invalid); err == nil {
+			t.Errorf("%q: expected an error", invalid)
+		}
+	}
+}
+
+func TestDownlevelWhen(t *testing.T) {
+	ex := `@when media(width >= 600px) and supports(display: grid) {
+	.a { display: grid; }
+}
+@else media(print) {
+	.a { display: block; }
+}
+@else {
+	.a { display: flex; }
+}
+.b {
+	color: red;
+}
+@else {
+	.c { color: red; }
+}
+`
+	expected := `@media (width >= 600px) {
+@supports (display: grid) {
+.a { display: grid; }
+}
+}
+@media not all and (width >= 600px) {
+@media print {
+.a { display: block; }
+}
+}
+@media print {
+@supports not (display: grid) {
+.a { display: block; }
+}
+}
+@media not all and (width >= 600px) {
+@media not print {
+.a { display: flex; }
+}
+}
+@media not print {
+@supports not (display: grid) {
+.a { display: flex; }
+}
+}
+.b {
+	color: red;
+}
+`
+	if out := string(DownlevelWhen([]byte(ex))); out != expected {
+		t.Fatalf("got:\n%s\nexpected:\n%s", out, expected)
+	}
+}
+
+func TestNegateMediaQuery(t *testing.T) {
+	tests := map[string]string{
+		"print":                   "not print",
+		"(color)":                 "not all and (color)",
+		"not screen":              "screen",
+		"only screen and (color)": "not screen and (color)",
+		"screen and (color)":      "not screen and (color)",
+	}
+	for in, want := range tests {
+		if got := negateMediaQuery(in); got != want {
+			t.Errorf("%q: got %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestWhenParses(t *testing.T) {
+	var warnings []Warning
+	sheet, err := UnmarshalStylesheet([]byte("@when media(print) {\n\t.a {\n\t\tcolor: black;\n\t}\n}\n@else {\n\t.a {\n\t\tcolor: red;\n\t}\n}\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = UnmarshalWithOptions([]byte("@when media(print) {\n\t.a {\n\t\tcolor: black;\n\t}\n}\n"),
+		ParseOptions{OnWarning: func(w Warning) { warnings = append(warnings, w) }})
+	if len(warnings) != 0 {
+		t.Fatalf("unexpected warnings %v", warnings)
+	}
+	if len(sheet.Rules) != 2 || sheet.Rules[0].AtRules[0] != "@when media(print)" || sheet.Rules[1].AtRules[0] != "@else" {
+		t.Fatalf("unexpected rules %v", sheet.Rules)
+	}
+}